@@ -1,12 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -20,6 +24,14 @@ func main() {
 
 	devices := device.Enumerate(nil)
 	log.Logf(logger.Info, "Found %d attached SDR(s)\n", len(devices))
+	if viper.GetBool("json") {
+		printCapabilities(devices, log)
+		return
+	}
+	if dir := viper.GetString("record"); dir != "" {
+		recordSessions(devices, dir, log)
+		return
+	}
 
 	for i, dev := range devices {
 		var devInfo strings.Builder
@@ -70,6 +82,8 @@ func main() {
 func parseCommandLine() (logger.LoggingLevel, string) {
 	pflag.Bool("debug", false, "Log debug information")
 	pflag.String("out", os.Getenv("HOME")+"/enumerate_sdrs.log", "Log filename. If 'stdout', messages are logged to 'stdout.")
+	pflag.Bool("json", false, "Print the capabilities of each SDR to stdout as JSON, rather than exercising the SDRs")
+	pflag.String("record", "", "Record a session file for each SDR in the specified directory, for replaying in tests")
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
 	debug := viper.GetBool("debug")
@@ -91,6 +105,58 @@ func initLogfile(level logger.LoggingLevel, fileName string) *logger.Logger {
 	return log
 }
 
+// printCapabilities prints a JSON array that holds the capabilities of each of the devices to stdout.
+func printCapabilities(devices []map[string]string, log *logger.Logger) {
+	allCaps := []sdr.Capabilities{}
+	for _, dev := range devices {
+		soapyDev := &sdr.SoapyDevice{}
+		if err := sdr.Make(soapyDev, dev, log); err != nil {
+			continue
+		}
+		allCaps = append(allCaps, soapyDev.Device.GetCapabilities(soapyDev, log))
+		sdr.Unmake(soapyDev, log)
+	}
+	data, err := json.MarshalIndent(allCaps, "", "  ")
+	if err != nil {
+		log.Logf(logger.Error, "Could not convert capabilities to JSON: %s\n", err.Error())
+		fmt.Println("Unable to print the capabilities. See log file for more info.")
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+// recordSessions records the calls that retrieve the capabilities of each of the devices, and saves them in a session
+// file in dir. The session files can be replayed with sdr.ReplayDevice, so that tests can be run without the devices.
+func recordSessions(devices []map[string]string, dir string, log *logger.Logger) {
+	for _, dev := range devices {
+		recorder := sdr.NewRecordingDevice(&sdr.SoapyDevice{})
+		if err := sdr.Make(recorder, dev, log); err != nil {
+			continue
+		}
+		sdr.GetCapabilities(recorder, log)
+		sdr.Unmake(recorder, log)
+		fileName := filepath.Join(dir, sessionFileName(dev))
+		if err := recorder.Session().Save(fileName); err != nil {
+			log.Logf(logger.Error, "Could not save session file %s: %s\n", fileName, err.Error())
+			fmt.Println("Unable to save a session file. See log file for more info.")
+			os.Exit(1)
+		}
+		log.Logf(logger.Info, "Recorded session for %s in %s\n", dev["label"], fileName)
+	}
+}
+
+// sessionFileName returns the name of the session file for the device with the specified args, which is made from
+// its driver and serial number.
+func sessionFileName(dev map[string]string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		return '_'
+	}, dev["driver"]+"-"+dev["serial"])
+	return name + ".json"
+}
+
 func logHardwareInfo(sdr *device.SDRDevice, log *logger.Logger) {
 	var hwInfo strings.Builder
 	hwInfo.WriteString(fmt.Sprintln("Hardware Info:"))
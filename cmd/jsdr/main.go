@@ -8,7 +8,6 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"github.com/jimorc/jsdr/internal/logger"
-	"github.com/jimorc/jsdr/internal/sdr"
 	"github.com/jimorc/jsdr/internal/ui"
 
 	"github.com/spf13/pflag"
@@ -20,6 +19,8 @@ var mainWin *fyne.Window
 
 func main() {
 	logLevel, LogFile := parseCommandLine()
+	ui.FileDev.Dir = viper.GetString("recordings")
+	ui.RtlTcpDev.Servers = viper.GetStringSlice("rtltcp")
 
 	log = initLogfile(logLevel, LogFile)
 	defer log.Close()
@@ -31,9 +32,8 @@ func main() {
 	log.Log(logger.Debug, "Displaying main window\n")
 	mainWin.ShowAndRun()
 	log.Log(logger.Debug, "Terminated main window\n")
-	if ui.SoapyDev.Device != nil {
-		sdr.Unmake(ui.SoapyDev, log)
-	}
+	ui.StopDeviceWatcher()
+	ui.UnmakeDevice(log)
 
 	log.Logf(logger.Info, "jsdr terminated at %v\n", time.Now().UTC())
 }
@@ -53,6 +53,8 @@ func parseCommandLine() (logger.LoggingLevel, string) {
 	pflag.Bool("info", false, "Log fatal, error, and info messages")
 	pflag.Bool("debug", false, "Log fatal, error, info, and debug messages")
 	pflag.String("out", os.Getenv("HOME")+"/jsdr.log", "Log filename. If 'stdout', messages are logged to 'stdout'.")
+	pflag.String("recordings", os.Getenv("HOME")+"/jsdr/recordings", "Directory containing IQ recordings to play back.")
+	pflag.StringSlice("rtltcp", []string{}, "Addresses (host:port) of rtl_tcp servers to list as SDRs.")
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
 	debug := viper.GetBool("debug")
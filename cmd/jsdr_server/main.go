@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func main() {
+	logLevel, logFile, address, serial := parseCommandLine()
+
+	log := initLogfile(logLevel, logFile)
+	defer log.Close()
+	log.Logf(logger.Info, "jsdr_server started at %v\n", time.Now().UTC())
+
+	soapyDev := &sdr.SoapyDevice{}
+	args, err := selectDevice(soapyDev, log, serial)
+	if err != nil {
+		log.Logf(logger.Error, "%s\n", err.Error())
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if err := sdr.Make(soapyDev, args, log); err != nil {
+		fmt.Printf("Could not make the device with label '%s': %s\n", args["label"], err.Error())
+		os.Exit(1)
+	}
+	defer sdr.Unmake(soapyDev, log)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		fmt.Printf("Could not listen on %s: %s\n", address, err.Error())
+		os.Exit(1)
+	}
+	log.Logf(logger.Info, "Serving '%s' on %s\n", args["label"], listener.Addr())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := newServer(soapyDev, log).serve(ctx, listener); err != nil {
+		log.Logf(logger.Error, "The server stopped with an error: %s\n", err.Error())
+	}
+	log.Logf(logger.Info, "jsdr_server terminated at %v\n", time.Now().UTC())
+}
+
+// selectDevice returns the args for the attached device with the specified serial number, or for the device with
+// the first label in alphabetical order if serial is empty.
+func selectDevice(sdrD sdr.Enumerate, log *logger.Logger, serial string) (map[string]string, error) {
+	devices := sdr.EnumerateWithoutAudio(sdrD, log)
+	for _, label := range slices.Sorted(maps.Keys(devices)) {
+		if args := devices[label]; serial == "" || args["serial"] == serial {
+			return args, nil
+		}
+	}
+	if serial == "" {
+		return nil, errors.New("no SDRs were found")
+	}
+	return nil, fmt.Errorf("no SDR with serial number '%s' was found", serial)
+}
+
+func parseCommandLine() (logger.LoggingLevel, string, string, string) {
+	pflag.Bool("debug", false, "Log debug information")
+	pflag.String("out", os.Getenv("HOME")+"/jsdr_server.log", "Log filename. If 'stdout', messages are logged to 'stdout'.")
+	pflag.String("address", ":1234", "Address (host:port) to listen for rtl_tcp clients on.")
+	pflag.String("serial", "", "Serial number of the SDR to serve. If not set, the first SDR found is served.")
+	pflag.Parse()
+	viper.BindPFlags(pflag.CommandLine)
+	logLevel := logger.Info
+	if viper.GetBool("debug") {
+		logLevel = logger.Debug
+	}
+	return logLevel, viper.GetString("out"), viper.GetString("address"), viper.GetString("serial")
+}
+
+func initLogfile(level logger.LoggingLevel, fileName string) *logger.Logger {
+	log, err := logger.NewFileLogger(fileName)
+	if err != nil {
+		fmt.Printf("Error trying to open log file '%s': %s\n", fileName, err.Error())
+		os.Exit(1)
+	}
+	log.SetMaxLevel(level)
+	return log
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// serverDevice is the set of interfaces that the server uses to control and stream from the device.
+type serverDevice interface {
+	sdr.ControlDevice
+	sdr.SampleStreams
+}
+
+// rxChannel is the receive channel of the device that is served.
+const rxChannel = 0
+
+// clientQueueLength is the number of blocks that may be waiting to be sent to a client before blocks are dropped.
+const clientQueueLength = 32
+
+// controlTimeout is the longest time that a client's command may wait to be applied to the device.
+const controlTimeout = 2 * time.Second
+
+// serverTuner is the tuner type that is reported to clients. Clients use the tuner type to choose the gains that they
+// offer, and the R820T's gains of 0 to 49.6 dB suit most devices.
+const serverTuner = sdr.RtlTcpTunerR820T
+
+// server serves a device to rtl_tcp clients.
+//
+// All clients receive the device's samples, converted to CU8, but only the controlling client can change the
+// device's settings. The first client to connect is the controlling client. When it disconnects, the client that
+// has been connected the longest takes control. The device is only streamed while there are clients connected.
+type server struct {
+	dev serverDevice
+	log *logger.Logger
+
+	// mutex protects clients. clients are in the order that they connected, so clients[0] is the controlling client.
+	mutex   sync.Mutex
+	clients []*client
+
+	// control serializes changes to the device's settings, including those that are in progress when control passes
+	// to another client.
+	control *sdr.DeviceController
+
+	// streamMutex serializes starting and stopping the receiver.
+	streamMutex sync.Mutex
+	receiver    *sdr.Receiver
+	cancel      context.CancelFunc
+}
+
+// client is a connected rtl_tcp client.
+type client struct {
+	conn   net.Conn
+	blocks chan []byte
+	// dropped is the number of blocks that were not sent because the client was not reading them fast enough.
+	dropped uint64
+}
+
+// newServer creates a server for the specified device, which must already have been made.
+func newServer(dev serverDevice, log *logger.Logger) *server {
+	return &server{dev: dev, log: log, control: sdr.NewDeviceController(dev, log)}
+}
+
+// serve accepts clients from listener until ctx is cancelled, then disconnects the clients and stops streaming.
+func (s *server) serve(ctx context.Context, listener net.Listener) error {
+	s.control.Start(ctx)
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.disconnectAll()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		s.addClient(conn)
+	}
+}
+
+// addClient starts sending samples to a new client and reading its commands.
+func (s *server) addClient(conn net.Conn) {
+	c := &client{conn: conn, blocks: make(chan []byte, clientQueueLength)}
+	if err := sdr.WriteRtlTcpHeader(conn, sdr.RtlTcpHeader{Tuner: serverTuner,
+		NumGains: uint32(len(serverTuner.Gains()))}); err != nil {
+		s.log.Logf(logger.Error, "Could not send the rtl_tcp header to %s: %s\n", conn.RemoteAddr(), err.Error())
+		conn.Close()
+		return
+	}
+	s.mutex.Lock()
+	s.clients = append(s.clients, c)
+	controlling := len(s.clients) == 1
+	s.mutex.Unlock()
+	s.log.Logf(logger.Info, "Client %s connected. Controlling client: %v\n", conn.RemoteAddr(), controlling)
+	go c.send()
+	go s.readCommands(c)
+	s.updateStreaming()
+}
+
+// removeClient disconnects a client. If it was the controlling client, the next client takes control.
+func (s *server) removeClient(c *client) {
+	s.mutex.Lock()
+	index := slices.Index(s.clients, c)
+	if index >= 0 {
+		s.clients = slices.Delete(s.clients, index, index+1)
+		close(c.blocks)
+		if index == 0 && len(s.clients) > 0 {
+			s.log.Logf(logger.Info, "Client %s is now the controlling client\n", s.clients[0].conn.RemoteAddr())
+		}
+	}
+	s.mutex.Unlock()
+	if index < 0 {
+		return
+	}
+	c.conn.Close()
+	s.log.Logf(logger.Info, "Client %s disconnected. %d blocks were dropped because it was too slow\n",
+		c.conn.RemoteAddr(), c.dropped)
+	s.updateStreaming()
+}
+
+// disconnectAll disconnects all of the clients.
+func (s *server) disconnectAll() {
+	s.mutex.Lock()
+	clients := append([]*client{}, s.clients...)
+	s.mutex.Unlock()
+	for _, c := range clients {
+		s.removeClient(c)
+	}
+}
+
+// isController returns true if c is the controlling client.
+func (s *server) isController(c *client) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients) > 0 && s.clients[0] == c
+}
+
+// numClients returns the number of connected clients.
+func (s *server) numClients() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients)
+}
+
+// streaming returns true if the device is being streamed to the clients.
+func (s *server) streaming() bool {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+	return s.receiver != nil
+}
+
+// updateStreaming starts streaming the device when there are clients, and stops streaming when there are none.
+func (s *server) updateStreaming() {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+	haveClients := s.numClients() > 0
+	switch {
+	case haveClients && s.receiver == nil:
+		source, err := sdr.NewSampleSource(s.dev, s.log, device.DirectionRX, []uint{rxChannel})
+		if err != nil {
+			s.log.Logf(logger.Error, "Could not set up a stream for the clients: %s\n", err.Error())
+			return
+		}
+		tagger, err := s.control.StreamTagger(context.Background(), device.DirectionRX, rxChannel)
+		if err != nil {
+			s.log.Logf(logger.Error, "Could not get the sample rate for the clients: %s\n", err.Error())
+			source.Close(s.log)
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		receiver := sdr.NewReceiver(source, s.log, tagger.Tags().SampleRate, s.broadcast)
+		receiver.SetStreamTagger(tagger)
+		if err := receiver.Start(ctx); err != nil {
+			cancel()
+			s.log.Logf(logger.Error, "Could not start streaming to the clients: %s\n", err.Error())
+			return
+		}
+		s.receiver, s.cancel = receiver, cancel
+		s.log.Log(logger.Debug, "Started streaming to the clients\n")
+	case !haveClients && s.receiver != nil:
+		s.cancel()
+		if err := s.receiver.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+			s.log.Logf(logger.Error, "Streaming stopped with an error: %s\n", err.Error())
+		}
+		s.receiver, s.cancel = nil, nil
+		s.log.Log(logger.Debug, "Stopped streaming because there are no clients\n")
+	}
+}
+
+// broadcast converts a block of samples to CU8 and queues it for each of the clients. A block is dropped for a client
+// that is not reading the blocks fast enough, so that a slow client does not hold up the others.
+func (s *server) broadcast(block sdr.Block) {
+	if block.Retuned {
+		s.log.Logf(logger.Debug, "Streaming at %.0f Hz, %.0f samples/s from block %d\n", block.Tags.CenterFrequency,
+			block.Tags.SampleRate, block.Sequence)
+	}
+	data := toCU8(block.Samples[0])
+	block.Release()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clients {
+		select {
+		case c.blocks <- data:
+		default:
+			c.dropped++
+		}
+	}
+}
+
+// readCommands reads commands from a client until it disconnects.
+func (s *server) readCommands(c *client) {
+	for {
+		command, param, err := sdr.ReadRtlTcpCommand(c.conn)
+		if err != nil {
+			break
+		}
+		s.handleCommand(c, command, param)
+	}
+	s.removeClient(c)
+}
+
+// handleCommand applies a command from the controlling client to the device. Commands from other clients are ignored.
+func (s *server) handleCommand(c *client, command sdr.RtlTcpCommand, param uint32) {
+	if !s.isController(c) {
+		s.log.Logf(logger.Debug, "Ignoring %s from %s, which is not the controlling client\n", command, c.conn.RemoteAddr())
+		return
+	}
+	s.log.Logf(logger.Info, "Applying %s %d from %s\n", command, int32(param), c.conn.RemoteAddr())
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	var err error
+	switch command {
+	case sdr.RtlTcpSetFrequency:
+		err = s.control.Tune(ctx, device.DirectionRX, rxChannel, float64(param))
+	case sdr.RtlTcpSetSampleRate:
+		err = s.control.SetSampleRate(ctx, device.DirectionRX, rxChannel, float64(param))
+	case sdr.RtlTcpSetGainMode:
+		// Gain mode 0 is automatic gain.
+		err = s.control.EnableAgc(ctx, device.DirectionRX, rxChannel, param == 0)
+	case sdr.RtlTcpSetGain:
+		err = s.control.SetGain(ctx, device.DirectionRX, rxChannel, float64(int32(param))/10)
+	default:
+		s.log.Logf(logger.Info, "The %s command is not supported\n", command)
+	}
+	if err != nil {
+		s.log.Logf(logger.Error, "Could not apply %s %d: %s\n", command, int32(param), err.Error())
+	}
+}
+
+// send writes the queued blocks to the client until its queue is closed or a write fails.
+func (c *client) send() {
+	for data := range c.blocks {
+		if _, err := c.conn.Write(data); err != nil {
+			// Closing the connection ends readCommands, which removes the client.
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// toCU8 converts normalised samples to interleaved CU8 values.
+func toCU8(samples []complex64) []byte {
+	data := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		data[2*i] = cu8Value(real(s))
+		data[2*i+1] = cu8Value(imag(s))
+	}
+	return data
+}
+
+// cu8Value converts a normalised value to CU8, clipping values that are outside the CU8 range.
+func cu8Value(v float32) byte {
+	return byte(max(-128, min(127, math.Round(float64(v)*128))) + 128)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer starts a server for a StubDevice on a loopback port. The returned function stops the server.
+func startServer(t *testing.T, testLogger *logger.Logger) (*server, *sdr.StubDevice, string, func()) {
+	stub := &sdr.StubDevice{}
+	require.Nil(t, sdr.Make(stub, map[string]string{"serial": "2"}, testLogger))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	srv := newServer(stub, testLogger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- srv.serve(ctx, listener) }()
+	stopped := false
+	stop := func() {
+		if !stopped {
+			stopped = true
+			cancel()
+			assert.Nil(t, <-done)
+		}
+	}
+	t.Cleanup(stop)
+	return srv, stub, listener.Addr().String(), stop
+}
+
+// dial connects an rtl_tcp client to the server and reads the header.
+func dial(t *testing.T, address string) net.Conn {
+	conn, err := net.Dial("tcp", address)
+	require.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	header, err := sdr.ReadRtlTcpHeader(conn)
+	require.Nil(t, err)
+	assert.Equal(t, sdr.RtlTcpTunerR820T, header.Tuner)
+	assert.Equal(t, uint32(29), header.NumGains)
+	return conn
+}
+
+// deviceState calls state on the server's device controller, so that the device's settings can be checked while
+// commands are being applied to the device.
+func deviceState(srv *server, state func() bool) func() bool {
+	return func() bool {
+		result := false
+		err := srv.control.Do(context.Background(), func(_ sdr.ControlDevice) error {
+			result = state()
+			return nil
+		})
+		return err == nil && result
+	}
+}
+
+func TestServer_StreamsCU8(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	srv, _, address, _ := startServer(t, testLogger)
+	assert.False(t, srv.streaming())
+	conn := dial(t, address)
+	data := make([]byte, 40000)
+	_, err := io.ReadFull(conn, data)
+	require.Nil(t, err)
+	// StubDevice's CS8 pattern of -2, 0, -1, -2 converted to CU8.
+	pattern := []byte{126, 128, 127, 126}
+	for i, b := range data {
+		require.Equal(t, pattern[i%4], b)
+	}
+	assert.True(t, srv.streaming())
+}
+
+func TestServer_StreamsOnlyWithClients(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	srv, _, address, _ := startServer(t, testLogger)
+	conn := dial(t, address)
+	require.Eventually(t, srv.streaming, time.Second, time.Millisecond)
+	conn.Close()
+	require.Eventually(t, func() bool { return !srv.streaming() }, time.Second, time.Millisecond)
+	assert.Equal(t, 0, srv.numClients())
+	dial(t, address)
+	require.Eventually(t, srv.streaming, time.Second, time.Millisecond)
+}
+
+func TestServer_Commands(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	srv, stub, address, stop := startServer(t, testLogger)
+	controller := dial(t, address)
+	require.Nil(t, sdr.WriteRtlTcpCommand(controller, sdr.RtlTcpSetFrequency, 433920000))
+	require.Nil(t, sdr.WriteRtlTcpCommand(controller, sdr.RtlTcpSetGainMode, 1))
+	require.Nil(t, sdr.WriteRtlTcpCommand(controller, sdr.RtlTcpSetGain, 200))
+	require.Nil(t, sdr.WriteRtlTcpCommand(controller, sdr.RtlTcpSetSampleRate, 1024000))
+	require.Nil(t, sdr.WriteRtlTcpCommand(controller, sdr.RtlTcpSetDirectSampling, 2))
+	require.Eventually(t, deviceState(srv, func() bool {
+		return stub.GetOverallCenterFrequency(device.DirectionRX, 0) == 433.92e6 &&
+			!stub.AgcIsEnabled(device.DirectionRX, 0) &&
+			stub.GetOverallGain(device.DirectionRX, 0) == 20.
+	}), time.Second, time.Millisecond)
+	require.Nil(t, sdr.WriteRtlTcpCommand(controller, sdr.RtlTcpSetGainMode, 0))
+	require.Eventually(t, deviceState(srv, func() bool {
+		return stub.AgcIsEnabled(device.DirectionRX, 0)
+	}), time.Second, time.Millisecond)
+
+	// Commands from clients that are not in control are ignored.
+	second := dial(t, address)
+	require.Eventually(t, func() bool { return srv.numClients() == 2 }, time.Second, time.Millisecond)
+	srv.mutex.Lock()
+	secondClient := srv.clients[1]
+	srv.mutex.Unlock()
+	srv.handleCommand(secondClient, sdr.RtlTcpSetFrequency, 145000000)
+	assert.True(t, deviceState(srv, func() bool {
+		return stub.GetOverallCenterFrequency(device.DirectionRX, 0) == 433.92e6
+	})())
+
+	// The remaining client takes control when the controlling client disconnects.
+	controller.Close()
+	require.Eventually(t, func() bool { return srv.numClients() == 1 }, time.Second, time.Millisecond)
+	require.Nil(t, sdr.WriteRtlTcpCommand(second, sdr.RtlTcpSetFrequency, 144800000))
+	require.Eventually(t, deviceState(srv, func() bool {
+		return stub.GetOverallCenterFrequency(device.DirectionRX, 0) == 144.8e6
+	}), time.Second, time.Millisecond)
+
+	stop()
+	testLogger.Close()
+	assert.Contains(t, log.String(), "Applying set sample rate 1024000 from ")
+	assert.Contains(t, log.String(), "The set direct sampling command is not supported\n")
+	assert.Contains(t, log.String(), "Client "+second.LocalAddr().String()+" is now the controlling client\n")
+}
+
+func TestToCU8(t *testing.T) {
+	assert.Equal(t, []byte{128, 128, 255, 0, 64, 160},
+		toCU8([]complex64{0, complex(1, -1.5), complex(-0.5, 0.25)}))
+}
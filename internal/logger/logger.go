@@ -88,6 +88,14 @@ func (l *Logger) Logf(level LoggingLevel, format string, args ...any) {
 	l.Log(level, fmt.Sprintf(format, args...))
 }
 
+// Enabled returns true if messages at the specified level are output by the logger.
+//
+// Use Enabled to avoid building the arguments for messages that would be discarded, for example in code
+// that must not allocate memory.
+func (l *Logger) Enabled(level LoggingLevel) bool {
+	return l.level >= level
+}
+
 // SetMaxLevel sets the max logging level.
 func (l *Logger) SetMaxLevel(level LoggingLevel) {
 	l.level = Info
@@ -123,3 +123,16 @@ func TestNewFileLogger_InvalidFile(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, log)
 }
+
+func TestEnabled(t *testing.T) {
+	logBuf := new(strings.Builder)
+	l := logger.New(logBuf)
+	defer l.Close()
+
+	assert.True(t, l.Enabled(logger.Error))
+	assert.True(t, l.Enabled(logger.Info))
+	assert.False(t, l.Enabled(logger.Debug))
+
+	l.SetMaxLevel(logger.Debug)
+	assert.True(t, l.Enabled(logger.Debug))
+}
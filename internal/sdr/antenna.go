@@ -16,16 +16,16 @@ type Antenna interface {
 	SetAntenna(device.Direction, uint, string) error
 }
 
-// GetCurrentAntenna returns the currently selected RX antenna for channel 0 of the SDR.
-func GetCurrentAntenna(sdrD Antenna, log *logger.Logger) string {
-	antenna := sdrD.GetCurrentAntenna(device.DirectionRX, 0)
+// GetCurrentAntenna returns the currently selected antenna for the specified direction and channel of the SDR.
+func GetCurrentAntenna(sdrD Antenna, log *logger.Logger, direction device.Direction, channel uint) string {
+	antenna := sdrD.GetCurrentAntenna(direction, channel)
 	log.Logf(logger.Debug, "Current antenna is %s\n", antenna)
 	return antenna
 }
 
-// GetAntennaNames returns the list of RX antenna names for channel 0.
-func GetAntennaNames(sdrD Antenna, log *logger.Logger) []string {
-	antennas := sdrD.GetAntennaNames(device.DirectionRX, 0)
+// GetAntennaNames returns the list of antenna names for the specified direction and channel.
+func GetAntennaNames(sdrD Antenna, log *logger.Logger, direction device.Direction, channel uint) []string {
+	antennas := sdrD.GetAntennaNames(direction, channel)
 	var aMsg strings.Builder
 	if len(antennas) == 0 {
 		aMsg.WriteString("No antennas for this SDR\n")
@@ -39,11 +39,11 @@ func GetAntennaNames(sdrD Antenna, log *logger.Logger) []string {
 	return antennas
 }
 
-// SetAntenna sets the antenna for RX channel 0
+// SetAntenna sets the antenna for the specified direction and channel.
 // Returns nil on success, or error message on failure.
-func SetAntenna(sdrD Antenna, log *logger.Logger, antennaName string) error {
+func SetAntenna(sdrD Antenna, log *logger.Logger, direction device.Direction, channel uint, antennaName string) error {
 	log.Logf(logger.Debug, "Attempting to set antenna %s\n", antennaName)
-	err := sdrD.SetAntenna(device.DirectionRX, 0, antennaName)
+	err := sdrD.SetAntenna(direction, channel, antennaName)
 	if err != nil {
 		log.Logf(logger.Debug, "Error returned on SetAntenna call: %s\n", err.Error())
 	}
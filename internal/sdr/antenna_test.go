@@ -5,6 +5,7 @@ import (
 
 	"github.com/jimorc/jsdr/internal/logger"
 	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,28 +13,28 @@ import (
 func TestGetAntennaNames(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
-	antennas := sdr.GetAntennaNames(&stub, testLogger)
+	antennas := sdr.GetAntennaNames(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, []string{"RX"}, antennas)
 }
 
 func TestGetCurrentAntenna(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
-	ant := sdr.GetCurrentAntenna(&stub, testLogger)
+	ant := sdr.GetCurrentAntenna(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, "RX", ant)
 }
 
 func TestSetAntenna(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
-	err := sdr.SetAntenna(&stub, testLogger, "RX")
+	err := sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 0, "RX")
 	assert.Nil(t, err)
 }
 
 func TestSetAntenna_BadName(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
-	err := sdr.SetAntenna(&stub, testLogger, "RX2")
+	err := sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 0, "RX2")
 	assert.NotNil(t, err)
 	assert.Equal(t, "invalid antenna: RX2", err.Error())
 }
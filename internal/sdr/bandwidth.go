@@ -0,0 +1,140 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// Bandwidth interface specifies the methods for the analog filter bandwidth of an SDR device.
+type Bandwidth interface {
+	GetBandwidthRanges(device.Direction, uint) []device.SDRRange
+	GetBandwidth(device.Direction, uint) float64
+	SetBandwidth(device.Direction, uint, float64) error
+}
+
+// SampleRatesBandwidth is the set of interfaces that SetSampleRateAndBandwidth uses.
+type SampleRatesBandwidth interface {
+	SampleRates
+	Bandwidth
+}
+
+// GetBandwidthRanges retrieves the analog filter bandwidth ranges for the specified direction and channel.
+//
+// Returns the bandwidth ranges, or an error if the device does not report any bandwidth ranges.
+func GetBandwidthRanges(sdrD Bandwidth, log *logger.Logger, direction device.Direction, channel uint) ([]device.SDRRange, error) {
+	bwRanges := sdrD.GetBandwidthRanges(direction, channel)
+	if len(bwRanges) == 0 {
+		log.Log(logger.Debug, "The device does not report any bandwidth ranges.\n")
+		return bwRanges, errors.New("the device does not report any bandwidth ranges")
+	}
+	var bMsg strings.Builder
+	bMsg.WriteString("Bandwidth Ranges:\n")
+	for _, bwRange := range bwRanges {
+		bMsg.WriteString(fmt.Sprintf("         %v\n", bwRange))
+	}
+	log.Log(logger.Debug, bMsg.String())
+	return bwRanges, nil
+}
+
+// GetBandwidth retrieves the analog filter bandwidth in Hz for the specified direction and channel.
+func GetBandwidth(sdrD Bandwidth, log *logger.Logger, direction device.Direction, channel uint) float64 {
+	bw := sdrD.GetBandwidth(direction, channel)
+	log.Logf(logger.Debug, "Bandwidth: %.1f\n", bw)
+	return bw
+}
+
+// SetBandwidth sets the analog filter bandwidth in Hz for the specified direction and channel.
+//
+// Returns an error if the device does not report any bandwidth ranges, the requested bandwidth is not within
+// the bandwidth ranges, or the bandwidth could not be set.
+func SetBandwidth(sdrD Bandwidth, log *logger.Logger, direction device.Direction, channel uint, bw float64) error {
+	bwRanges, err := GetBandwidthRanges(sdrD, log, direction, channel)
+	if err != nil {
+		log.Logf(logger.Error, "Cannot set bandwidth to %.1f. There are no bandwidth ranges for this device.\n", bw)
+		return fmt.Errorf("cannot set bandwidth to %.1f. There are no bandwidth ranges for this device", bw)
+	}
+	return setBandwidthInRanges(sdrD, log, direction, channel, bwRanges, bw)
+}
+
+// setBandwidthInRanges sets the analog filter bandwidth in Hz for the specified direction and channel, after checking
+// that it is within bwRanges, the bandwidth ranges that the device reports.
+func setBandwidthInRanges(sdrD Bandwidth, log *logger.Logger, direction device.Direction, channel uint,
+	bwRanges []device.SDRRange, bw float64) error {
+	if !withinRanges(bwRanges, bw) {
+		var rMsg strings.Builder
+		rMsg.WriteString(fmt.Sprintf("Cannot set bandwidth to %.1f\n"+
+			"Requested bandwidth is not within the bandwidth ranges:\n", bw))
+		for _, bwRange := range bwRanges {
+			rMsg.WriteString(fmt.Sprintf("         %v\n", bwRange))
+		}
+		log.Log(logger.Error, rMsg.String())
+		return fmt.Errorf("requested bandwidth: %.1f is not within the bandwidth ranges for this device", bw)
+	}
+	if err := sdrD.SetBandwidth(direction, channel, bw); err != nil {
+		log.Logf(logger.Error, "Cannot set requested bandwidth: %.1f: %s\n", bw, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have set bandwidth to %.1f\n", bw)
+	return nil
+}
+
+// SetBandwidthForSampleRate sets the analog filter bandwidth to suit the specified sample rate. The bandwidth is set
+// to the widest bandwidth that the device supports that does not exceed the sample rate, so that the filter
+// removes signals that would alias into the samples. If every supported bandwidth exceeds the sample rate, the
+// narrowest supported bandwidth is set.
+//
+// Returns an error if the device does not report any bandwidth ranges, or the bandwidth could not be set.
+func SetBandwidthForSampleRate(sdrD Bandwidth, log *logger.Logger, direction device.Direction, channel uint, sampleRate float64) error {
+	bwRanges, err := GetBandwidthRanges(sdrD, log, direction, channel)
+	if err != nil {
+		log.Logf(logger.Error, "Cannot set bandwidth for sample rate %.1f. There are no bandwidth ranges for this device.\n",
+			sampleRate)
+		return fmt.Errorf("cannot set bandwidth for sample rate %.1f. There are no bandwidth ranges for this device",
+			sampleRate)
+	}
+	return setBandwidthInRanges(sdrD, log, direction, channel, bwRanges, bandwidthForSampleRate(bwRanges, sampleRate))
+}
+
+// SetSampleRateAndBandwidth sets the sample rate to the specified value. If autoBandwidth is true, the analog
+// filter bandwidth is then tied to the new sample rate as described for SetBandwidthForSampleRate.
+//
+// Returns an error if the sample rate or the bandwidth could not be set.
+func SetSampleRateAndBandwidth(sdrD SampleRatesBandwidth, log *logger.Logger, direction device.Direction, channel uint,
+	rate float64, autoBandwidth bool) error {
+	if err := SetSampleRate(sdrD, log, direction, channel, rate); err != nil {
+		return err
+	}
+	if !autoBandwidth {
+		return nil
+	}
+	return SetBandwidthForSampleRate(sdrD, log, direction, channel, sdrD.GetSampleRate(direction, channel))
+}
+
+// bandwidthForSampleRate returns the widest bandwidth within bwRanges that does not exceed sampleRate, or the
+// narrowest bandwidth if every bandwidth exceeds sampleRate. Bandwidths that are not a whole number of steps
+// above the minimum of their range are not considered.
+func bandwidthForSampleRate(bwRanges []device.SDRRange, sampleRate float64) float64 {
+	widest := -1.
+	narrowest := math.Inf(1)
+	for _, bwRange := range bwRanges {
+		narrowest = min(narrowest, bwRange.Minimum)
+		if bwRange.Minimum > sampleRate {
+			continue
+		}
+		bw := min(bwRange.Maximum, sampleRate)
+		if bwRange.Step > 0 {
+			bw = bwRange.Minimum + math.Floor((bw-bwRange.Minimum)/bwRange.Step)*bwRange.Step
+		}
+		widest = max(widest, bw)
+	}
+	if widest < 0 {
+		return narrowest
+	}
+	return widest
+}
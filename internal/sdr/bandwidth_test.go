@@ -0,0 +1,92 @@
+package sdr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBandwidthRanges(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	bwRanges, err := sdr.GetBandwidthRanges(&stub, testLogger, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(bwRanges))
+}
+
+func TestGetBandwidthRanges_NoDevice(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	_, err := sdr.GetBandwidthRanges(&stub, testLogger, device.DirectionRX, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "the device does not report any bandwidth ranges", err.Error())
+	err = sdr.SetBandwidth(&stub, testLogger, device.DirectionRX, 0, 1e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "cannot set bandwidth to 1000000.0. There are no bandwidth ranges for this device", err.Error())
+}
+
+func TestSetBandwidth(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	require.Nil(t, sdr.SetBandwidth(&stub, testLogger, device.DirectionRX, 0, 1.5e6))
+	assert.Equal(t, 1.5e6, sdr.GetBandwidth(&stub, testLogger, device.DirectionRX, 0))
+
+	// 3 MHz is between the two bandwidth ranges.
+	err := sdr.SetBandwidth(&stub, testLogger, device.DirectionRX, 0, 3e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "requested bandwidth: 3000000.0 is not within the bandwidth ranges for this device", err.Error())
+	assert.Equal(t, 1.5e6, sdr.GetBandwidth(&stub, testLogger, device.DirectionRX, 0))
+}
+
+func TestSetBandwidth_Error(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{Errors: map[string]error{"SetBandwidth": errors.New("could not set bandwidth to 1000000.0")}}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	err := sdr.SetBandwidth(&stub, testLogger, device.DirectionRX, 0, 1e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "could not set bandwidth to 1000000.0", err.Error())
+}
+
+func TestSetBandwidthForSampleRate(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	for _, test := range []struct {
+		sampleRate float64
+		bw         float64
+	}{
+		{1.5e6, 1.5e6},
+		{3.2e6, 2e6},
+		{6.5e6, 6e6},
+		{10e6, 8e6},
+		{100e3, 200e3},
+	} {
+		before := stub.CallCount("GetBandwidthRanges")
+		require.Nil(t, sdr.SetBandwidthForSampleRate(&stub, testLogger, device.DirectionRX, 0, test.sampleRate))
+		// The bandwidth ranges are only retrieved once.
+		assert.Equal(t, before+1, stub.CallCount("GetBandwidthRanges"))
+		assert.Equal(t, test.bw, sdr.GetBandwidth(&stub, testLogger, device.DirectionRX, 0),
+			"sample rate: %.1f", test.sampleRate)
+	}
+
+	noDevice := sdr.StubDevice{}
+	assert.NotNil(t, sdr.SetBandwidthForSampleRate(&noDevice, testLogger, device.DirectionRX, 0, 2e6))
+}
+
+func TestSetSampleRateAndBandwidth(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	require.Nil(t, sdr.SetSampleRateAndBandwidth(&stub, testLogger, device.DirectionRX, 0, 2.048e6, false))
+	assert.Equal(t, 0., sdr.GetBandwidth(&stub, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetSampleRateAndBandwidth(&stub, testLogger, device.DirectionRX, 0, 2.048e6, true))
+	assert.Equal(t, 2e6, sdr.GetBandwidth(&stub, testLogger, device.DirectionRX, 0))
+}
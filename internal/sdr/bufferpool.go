@@ -0,0 +1,76 @@
+package sdr
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Buffer is a multi-channel buffer that is obtained from, and returned to, a BufferPool.
+type Buffer[T any] struct {
+	// Data contains one slice of values for each channel.
+	Data [][]T
+
+	// generation is incremented each time that a Block releases the buffer, so that releasing copies of the Block
+	// does not return the buffer to the pool again.
+	generation atomic.Uint64
+}
+
+// BufferPool is a pool of Buffers that all have the same number of channels and the same number of values per
+// channel.
+//
+// Buffers are reused once they are returned to the pool, so reading a stream into pooled buffers does not
+// allocate memory once the pool contains enough buffers. A BufferPool is safe for use by multiple go routines.
+type BufferPool[T any] struct {
+	numChannels int
+	size        int
+	pool        sync.Pool
+}
+
+// NewBufferPool creates a BufferPool whose Buffers have numChannels channels of size values each.
+func NewBufferPool[T any](numChannels int, size int) *BufferPool[T] {
+	bufferPool := &BufferPool[T]{numChannels: numChannels, size: size}
+	bufferPool.pool.New = func() any {
+		buffer := &Buffer[T]{Data: make([][]T, numChannels)}
+		for ch := range buffer.Data {
+			buffer.Data[ch] = make([]T, size)
+		}
+		return buffer
+	}
+	return bufferPool
+}
+
+// NumChannels returns the number of channels in each of the pool's Buffers.
+func (bufferPool *BufferPool[T]) NumChannels() int {
+	return bufferPool.numChannels
+}
+
+// Size returns the number of values in each channel of the pool's Buffers.
+func (bufferPool *BufferPool[T]) Size() int {
+	return bufferPool.size
+}
+
+// Get returns a Buffer from the pool, allocating a new one if the pool is empty.
+//
+// The contents of the returned Buffer are undefined. Each channel's slice has the pool's full size.
+func (bufferPool *BufferPool[T]) Get() *Buffer[T] {
+	buffer := bufferPool.pool.Get().(*Buffer[T])
+	for ch := range buffer.Data {
+		buffer.Data[ch] = buffer.Data[ch][:bufferPool.size]
+	}
+	return buffer
+}
+
+// Put returns buffer to the pool. Buffers that do not match the pool's dimensions are discarded.
+//
+// buffer must not be used after it has been returned to the pool.
+func (bufferPool *BufferPool[T]) Put(buffer *Buffer[T]) {
+	if buffer == nil || len(buffer.Data) != bufferPool.numChannels {
+		return
+	}
+	for _, chData := range buffer.Data {
+		if cap(chData) < bufferPool.size {
+			return
+		}
+	}
+	bufferPool.pool.Put(buffer)
+}
@@ -0,0 +1,58 @@
+package sdr_test
+
+import (
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferPool_Get(t *testing.T) {
+	pool := sdr.NewBufferPool[int16](2, 100)
+	assert.Equal(t, 2, pool.NumChannels())
+	assert.Equal(t, 100, pool.Size())
+	buffer := pool.Get()
+	assert.Equal(t, 2, len(buffer.Data))
+	assert.Equal(t, 100, len(buffer.Data[0]))
+	assert.Equal(t, 100, len(buffer.Data[1]))
+}
+
+func TestBufferPool_PutRestoresSize(t *testing.T) {
+	pool := sdr.NewBufferPool[complex64](1, 100)
+	buffer := pool.Get()
+	buffer.Data[0] = buffer.Data[0][:10]
+	pool.Put(buffer)
+	buffer = pool.Get()
+	assert.Equal(t, 100, len(buffer.Data[0]))
+}
+
+func TestBufferPool_PutDiscardsMismatchedBuffers(t *testing.T) {
+	pool := sdr.NewBufferPool[int](2, 100)
+	pool.Put(nil)
+	pool.Put(&sdr.Buffer[int]{Data: [][]int{make([]int, 100)}})
+	pool.Put(&sdr.Buffer[int]{Data: [][]int{make([]int, 100), make([]int, 50)}})
+	for i := 0; i < 10; i++ {
+		buffer := pool.Get()
+		assert.Equal(t, 2, len(buffer.Data))
+		assert.Equal(t, 100, len(buffer.Data[1]))
+	}
+}
+
+// skipAllocationTestWithRace skips tests that count allocations when the race detector is enabled. The race
+// detector allocates memory of its own, and makes sync.Pool discard buffers at random.
+func skipAllocationTestWithRace(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations cannot be counted with the race detector enabled")
+	}
+}
+
+func TestBufferPool_NoAllocations(t *testing.T) {
+	skipAllocationTestWithRace(t)
+	pool := sdr.NewBufferPool[int](2, 100)
+	pool.Put(pool.Get())
+	allocs := testing.AllocsPerRun(100, func() {
+		pool.Put(pool.Get())
+	})
+	assert.Equal(t, 0., allocs)
+}
@@ -0,0 +1,194 @@
+package sdr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// CapabilitiesDevice is the set of interfaces that every device must implement for its capabilities to be
+// retrieved by GetCapabilities.
+//
+// The capabilities that are specified by the Bandwidth, FrontendCorrections, Sensors, Settings, Clock, and
+// HardwareTime interfaces are only retrieved if the device also implements those interfaces.
+type CapabilitiesDevice interface {
+	KeyValues
+	Channels
+	Frequency
+	Agc
+	Gain
+	SampleRates
+	Antenna
+	Stream
+}
+
+// Capabilities is a snapshot of what a device can do. It is retrieved in one call by GetCapabilities, and can be
+// converted to JSON.
+type Capabilities struct {
+	HardwareKey string
+	// RX and TX hold the capabilities of each of the device's receive and transmit channels, indexed by channel
+	// number.
+	RX []ChannelCapabilities
+	TX []ChannelCapabilities
+
+	Sensors          []string
+	Settings         []Setting
+	MasterClockRates []device.SDRRange
+	ClockSources     []string
+	TimeSources      []string
+	HasHardwareTime  bool
+}
+
+// ChannelCapabilities holds the capabilities of one channel of a device.
+type ChannelCapabilities struct {
+	Channel          uint
+	FrequencyRanges  []device.SDRRange
+	TunableElements  []TunableElement
+	GainElements     []GainElement
+	SupportsAGC      bool
+	SampleRateRanges []device.SDRRange
+	BandwidthRanges  []device.SDRRange
+	Antennas         []string
+	StreamFormats    []string
+	// NativeStreamFormat is the format that the device produces without conversion, and NativeFullScale is the
+	// maximum value of a sample in that format.
+	NativeStreamFormat     string
+	NativeFullScale        float64
+	HasDCOffsetMode        bool
+	HasDCOffset            bool
+	HasIQBalance           bool
+	HasFrequencyCorrection bool
+	Sensors                []string
+	Settings               []Setting
+}
+
+// TunableElement holds the name and frequency ranges of a tunable element of a channel, for example "RF".
+type TunableElement struct {
+	Name   string
+	Ranges []device.SDRRange
+}
+
+// GainElement holds the name and gain range of a gain element of a channel, for example "TUNER".
+type GainElement struct {
+	Name  string
+	Range device.SDRRange
+}
+
+// GetCapabilities retrieves the capabilities of a device and of each of its channels.
+//
+// The capabilities are logged as a single Debug message, rather than each of them being logged as it is retrieved.
+func GetCapabilities(sdrD CapabilitiesDevice, log *logger.Logger) Capabilities {
+	caps := Capabilities{HardwareKey: sdrD.GetHardwareKey()}
+	for ch := uint(0); ch < sdrD.GetNumChannels(device.DirectionRX); ch++ {
+		caps.RX = append(caps.RX, getChannelCapabilities(sdrD, device.DirectionRX, ch))
+	}
+	for ch := uint(0); ch < sdrD.GetNumChannels(device.DirectionTX); ch++ {
+		caps.TX = append(caps.TX, getChannelCapabilities(sdrD, device.DirectionTX, ch))
+	}
+	if sensors, ok := sdrD.(Sensors); ok {
+		caps.Sensors = sensors.GetSensorNames()
+	}
+	if settings, ok := sdrD.(Settings); ok {
+		for _, info := range settings.GetSettingInfo() {
+			caps.Settings = append(caps.Settings, newSetting(info))
+		}
+	}
+	if clock, ok := sdrD.(Clock); ok {
+		caps.MasterClockRates = clock.GetMasterClockRates()
+		caps.ClockSources = clock.GetClockSources()
+	}
+	if hwTime, ok := sdrD.(HardwareTime); ok {
+		caps.TimeSources = hwTime.GetTimeSources()
+		caps.HasHardwareTime = hwTime.HasHardwareTime(TimeNow)
+	}
+	if log.Enabled(logger.Debug) {
+		log.Log(logger.Debug, caps.String())
+	}
+	return caps
+}
+
+// getChannelCapabilities retrieves the capabilities of the specified direction and channel of a device.
+func getChannelCapabilities(sdrD CapabilitiesDevice, direction device.Direction, channel uint) ChannelCapabilities {
+	caps := ChannelCapabilities{
+		Channel:          channel,
+		FrequencyRanges:  sdrD.GetFrequencyRanges(direction, channel),
+		SupportsAGC:      sdrD.SupportsAGC(direction, channel),
+		SampleRateRanges: sdrD.GetSampleRateRange(direction, channel),
+		Antennas:         sdrD.GetAntennaNames(direction, channel),
+		StreamFormats:    sdrD.GetStreamFormats(direction, channel),
+	}
+	for _, name := range sdrD.GetTunableElementNames(direction, channel) {
+		caps.TunableElements = append(caps.TunableElements,
+			TunableElement{Name: name, Ranges: sdrD.GetTunableElementFrequencyRanges(direction, channel, name)})
+	}
+	for _, name := range sdrD.GetGainElementNames(direction, channel) {
+		caps.GainElements = append(caps.GainElements,
+			GainElement{Name: name, Range: sdrD.GetElementGainRange(direction, channel, name)})
+	}
+	caps.NativeStreamFormat, caps.NativeFullScale = sdrD.GetNativeStreamFormat(direction, channel)
+	if bandwidth, ok := sdrD.(Bandwidth); ok {
+		caps.BandwidthRanges = bandwidth.GetBandwidthRanges(direction, channel)
+	}
+	if corrections, ok := sdrD.(FrontendCorrections); ok {
+		caps.HasDCOffsetMode = corrections.HasDCOffsetMode(direction, channel)
+		caps.HasDCOffset = corrections.HasDCOffset(direction, channel)
+		caps.HasIQBalance = corrections.HasIQBalance(direction, channel)
+		caps.HasFrequencyCorrection = corrections.HasFrequencyCorrection(direction, channel)
+	}
+	if sensors, ok := sdrD.(Sensors); ok {
+		caps.Sensors = sensors.GetChannelSensorNames(direction, channel)
+	}
+	if settings, ok := sdrD.(Settings); ok {
+		for _, info := range settings.GetChannelSettingInfo(direction, channel) {
+			setting := newSetting(info)
+			setting.IsChannelSetting, setting.Direction, setting.Channel = true, direction, channel
+			caps.Settings = append(caps.Settings, setting)
+		}
+	}
+	return caps
+}
+
+// JSON returns the capabilities as indented JSON.
+func (caps Capabilities) JSON() ([]byte, error) {
+	return json.MarshalIndent(caps, "", "  ")
+}
+
+// String returns a summary of the capabilities, with a line for the device and one for each channel.
+func (caps Capabilities) String() string {
+	var cMsg strings.Builder
+	cMsg.WriteString(fmt.Sprintf("Capabilities of %s:\n", caps.HardwareKey))
+	cMsg.WriteString(fmt.Sprintf("         Sensors: %v, Settings: %d, Clock sources: %v, Time sources: %v\n",
+		caps.Sensors, len(caps.Settings), caps.ClockSources, caps.TimeSources))
+	for _, chCaps := range caps.RX {
+		cMsg.WriteString(chCaps.summary("RX"))
+	}
+	for _, chCaps := range caps.TX {
+		cMsg.WriteString(chCaps.summary("TX"))
+	}
+	return cMsg.String()
+}
+
+// summary returns a one line summary of a channel's capabilities.
+func (caps ChannelCapabilities) summary(direction string) string {
+	var gains []string
+	for _, gain := range caps.GainElements {
+		gains = append(gains, gain.Name)
+	}
+	return fmt.Sprintf("         %s%d: Frequency ranges: %v, Gain elements: %v, AGC: %v, Antennas: %v, "+
+		"Stream formats: %v\n", direction, caps.Channel, caps.FrequencyRanges, gains, caps.SupportsAGC,
+		caps.Antennas, caps.StreamFormats)
+}
+
+// GetCapabilities returns the capabilities of the SDR device. They are retrieved from sdrD by the first call, and
+// the same capabilities are returned by later calls.
+func (sdr *Sdr) GetCapabilities(sdrD CapabilitiesDevice, log *logger.Logger) Capabilities {
+	if sdr.capabilities == nil {
+		caps := GetCapabilities(sdrD, log)
+		sdr.capabilities = &caps
+	}
+	return *sdr.capabilities
+}
@@ -0,0 +1,84 @@
+package sdr_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// All of the device types must implement CapabilitiesDevice.
+var (
+	_ sdr.CapabilitiesDevice = &sdr.SoapyDevice{}
+	_ sdr.CapabilitiesDevice = &sdr.StubDevice{}
+	_ sdr.CapabilitiesDevice = &sdr.SimDevice{}
+	_ sdr.CapabilitiesDevice = &sdr.FileDevice{}
+	_ sdr.CapabilitiesDevice = &sdr.RtlTcpDevice{}
+	_ sdr.CapabilitiesDevice = &sdr.RecordingDevice{}
+	_ sdr.CapabilitiesDevice = &sdr.ReplayDevice{}
+)
+
+func TestGetCapabilities(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	caps := sdr.GetCapabilities(&stub, testLogger)
+	assert.Equal(t, "hardKey", caps.HardwareKey)
+	assert.Equal(t, []string{"temperature", "clock_source"}, caps.Sensors)
+	assert.Equal(t, 3, len(caps.Settings))
+	assert.Equal(t, []string{"internal", "external"}, caps.ClockSources)
+	assert.True(t, caps.HasHardwareTime)
+
+	require.Equal(t, 2, len(caps.RX))
+	rx0 := caps.RX[0]
+	assert.Equal(t, uint(0), rx0.Channel)
+	assert.Equal(t, []device.SDRRange{{Minimum: 0.0, Maximum: 6e+09}}, rx0.FrequencyRanges)
+	require.Equal(t, 1, len(rx0.TunableElements))
+	assert.Equal(t, "RF", rx0.TunableElements[0].Name)
+	require.Equal(t, 2, len(rx0.GainElements))
+	assert.Equal(t, "RF", rx0.GainElements[0].Name)
+	assert.True(t, rx0.SupportsAGC)
+	assert.Equal(t, []string{"RX"}, rx0.Antennas)
+	assert.Equal(t, 2, len(rx0.BandwidthRanges))
+	assert.Equal(t, []string{"CS8", "CS16", "CF32"}, rx0.StreamFormats)
+	assert.Equal(t, "CS8", rx0.NativeStreamFormat)
+	assert.True(t, rx0.HasFrequencyCorrection)
+	assert.Equal(t, []string{"lo_locked", "rssi"}, rx0.Sensors)
+	require.Equal(t, 2, len(rx0.Settings))
+	assert.True(t, rx0.Settings[0].IsChannelSetting)
+	assert.Equal(t, []string{"RX", "RX2"}, caps.RX[1].Antennas)
+
+	require.Equal(t, 1, len(caps.TX))
+	assert.False(t, caps.TX[0].SupportsAGC)
+	assert.False(t, caps.TX[0].HasFrequencyCorrection)
+}
+
+func TestCapabilities_JSON(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	caps := sdr.GetCapabilities(&stub, testLogger)
+	data, err := caps.JSON()
+	require.Nil(t, err)
+	var decoded sdr.Capabilities
+	require.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, caps, decoded)
+}
+
+func TestSdr_GetCapabilities(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	caps := stub.Device.GetCapabilities(&stub, testLogger)
+	assert.Equal(t, 2, len(caps.RX))
+
+	// The capabilities are cached, so a device with different capabilities does not change them.
+	other := sdr.StubDevice{}
+	assert.Equal(t, caps, stub.Device.GetCapabilities(&other, testLogger))
+	assert.NotEqual(t, caps, sdr.GetCapabilities(&other, testLogger))
+}
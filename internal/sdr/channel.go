@@ -0,0 +1,27 @@
+package sdr
+
+import (
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// Channels interface specifies the channel related methods for an SDR device.
+type Channels interface {
+	GetNumChannels(device.Direction) uint
+}
+
+// GetNumChannels returns the number of channels that the SDR device supports in the specified direction.
+func GetNumChannels(sdrD Channels, log *logger.Logger, direction device.Direction) uint {
+	numChannels := sdrD.GetNumChannels(direction)
+	log.Logf(logger.Debug, "Number of %s channels: %d\n", directionAsString(direction), numChannels)
+	return numChannels
+}
+
+// directionAsString returns "RX" or "TX" for the specified direction.
+func directionAsString(direction device.Direction) string {
+	if direction == device.DirectionTX {
+		return "TX"
+	}
+	return "RX"
+}
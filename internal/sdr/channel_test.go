@@ -0,0 +1,72 @@
+package sdr_test
+
+import (
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNumChannels(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, uint(2), sdr.GetNumChannels(&stub, testLogger, device.DirectionRX))
+	assert.Equal(t, uint(1), sdr.GetNumChannels(&stub, testLogger, device.DirectionTX))
+}
+
+func TestSdrChannel(t *testing.T) {
+	s := sdr.Sdr{}
+	rx0 := s.Channel(device.DirectionRX, 0)
+	require.NotNil(t, rx0)
+	rx0.SampleRate = 2048000.
+	rx1 := s.Channel(device.DirectionRX, 1)
+	rx1.SampleRate = 1024000.
+	assert.Equal(t, 2048000., s.Channel(device.DirectionRX, 0).SampleRate)
+	assert.Equal(t, 1024000., s.Channel(device.DirectionRX, 1).SampleRate)
+	assert.Equal(t, 0., s.Channel(device.DirectionTX, 0).SampleRate)
+}
+
+func TestMultiChannel_Antennas(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	err := sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"RX"}, sdr.GetAntennaNames(&stub, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, []string{"RX", "RX2"}, sdr.GetAntennaNames(&stub, testLogger, device.DirectionRX, 1))
+
+	err = sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 0, "RX2")
+	assert.NotNil(t, err)
+	err = sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 1, "RX2")
+	assert.Nil(t, err)
+	assert.Equal(t, "RX", sdr.GetCurrentAntenna(&stub, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, "RX2", sdr.GetCurrentAntenna(&stub, testLogger, device.DirectionRX, 1))
+	assert.Equal(t, "RX2", stub.Device.Channel(device.DirectionRX, 1).Antenna)
+	assert.Equal(t, []string{"RX", "RX2"}, stub.Device.Channel(device.DirectionRX, 1).Antennas)
+
+	err = sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 1, "RX")
+	assert.Nil(t, err)
+}
+
+func TestMultiChannel_CenterFrequency(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	ch0Freq := sdr.GetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0)
+	err := sdr.SetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 1, 433920000., map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, 433920000., sdr.GetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 1))
+	assert.Equal(t, ch0Freq, sdr.GetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0))
+}
+
+func TestMultiChannel_SampleRate(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	err := sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger)
+	require.Nil(t, err)
+	sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 1)
+	assert.Equal(t, 2000000., stub.Device.Channel(device.DirectionRX, 1).SampleRate)
+	assert.Equal(t, 0., stub.Device.Channel(device.DirectionRX, 0).SampleRate)
+}
@@ -0,0 +1,99 @@
+package sdr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// Clock interface specifies the methods for an SDR's master clock and its clock source.
+type Clock interface {
+	GetMasterClockRate() float64
+	SetMasterClockRate(float64) error
+	GetMasterClockRates() []device.SDRRange
+	GetClockSources() []string
+	GetClockSource() string
+	SetClockSource(string) error
+}
+
+// GetMasterClockRate returns the master clock rate of the device in Hz.
+func GetMasterClockRate(sdrD Clock, log *logger.Logger) float64 {
+	rate := sdrD.GetMasterClockRate()
+	log.Logf(logger.Debug, "Master clock rate: %.1f\n", rate)
+	return rate
+}
+
+// GetMasterClockRates returns the ranges of master clock rates that the device supports. Devices that do not
+// support changing the master clock rate return no ranges.
+func GetMasterClockRates(sdrD Clock, log *logger.Logger) []device.SDRRange {
+	rates := sdrD.GetMasterClockRates()
+	if len(rates) == 0 {
+		log.Log(logger.Debug, "Master clock rates: none\n")
+		return rates
+	}
+	var rMsg strings.Builder
+	rMsg.WriteString("Master clock rates:\n")
+	for _, rate := range rates {
+		rMsg.WriteString(fmt.Sprintf("         %v\n", rate))
+	}
+	log.Log(logger.Debug, rMsg.String())
+	return rates
+}
+
+// SetMasterClockRate sets the master clock rate of the device in Hz.
+//
+// Returns an error if the device does not report any master clock rates, the requested rate is not within the
+// master clock rates, or the rate could not be set.
+func SetMasterClockRate(sdrD Clock, log *logger.Logger, rate float64) error {
+	rates := GetMasterClockRates(sdrD, log)
+	if len(rates) == 0 {
+		log.Logf(logger.Error, "Cannot set master clock rate to %.1f. The device does not report any master clock rates.\n", rate)
+		return fmt.Errorf("cannot set master clock rate to %.1f. The device does not report any master clock rates", rate)
+	}
+	if !withinRanges(rates, rate) {
+		log.Logf(logger.Error, "Requested master clock rate: %.1f is not within the master clock rates\n", rate)
+		return fmt.Errorf("requested master clock rate: %.1f is not within the master clock rates for this device", rate)
+	}
+	if err := sdrD.SetMasterClockRate(rate); err != nil {
+		log.Logf(logger.Error, "Could not set master clock rate to %.1f: %s\n", rate, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have set master clock rate to %.1f\n", rate)
+	return nil
+}
+
+// GetClockSources returns the names of the device's clock sources, for example "internal" and "external".
+func GetClockSources(sdrD Clock, log *logger.Logger) []string {
+	sources := sdrD.GetClockSources()
+	log.Logf(logger.Debug, "Clock sources: %v\n", sources)
+	return sources
+}
+
+// GetClockSource returns the name of the device's current clock source.
+func GetClockSource(sdrD Clock, log *logger.Logger) string {
+	source := sdrD.GetClockSource()
+	log.Logf(logger.Debug, "Clock source: %s\n", source)
+	return source
+}
+
+// SetClockSource selects the device's clock source.
+//
+// Returns an error if the device does not have the named clock source, or the clock source could not be selected.
+func SetClockSource(sdrD Clock, log *logger.Logger, source string) error {
+	sources := sdrD.GetClockSources()
+	if !slices.Contains(sources, source) {
+		log.Logf(logger.Error, "Attempting to select clock source: %s, but that clock source does not exist.\n"+
+			"Clock sources are: %v\n", source, sources)
+		return fmt.Errorf("cannot select non-existent clock source: %s", source)
+	}
+	if err := sdrD.SetClockSource(source); err != nil {
+		log.Logf(logger.Error, "Could not select clock source %s: %s\n", source, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have selected clock source %s\n", source)
+	return nil
+}
@@ -0,0 +1,39 @@
+package sdr_test
+
+import (
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterClockRate(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, 28.8e6, sdr.GetMasterClockRate(&stub, testLogger))
+	assert.Equal(t, 1, len(sdr.GetMasterClockRates(&stub, testLogger)))
+	require.Nil(t, sdr.SetMasterClockRate(&stub, testLogger, 24e6))
+	assert.Equal(t, 24e6, sdr.GetMasterClockRate(&stub, testLogger))
+
+	err := sdr.SetMasterClockRate(&stub, testLogger, 40e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "requested master clock rate: 40000000.0 is not within the master clock rates for this device", err.Error())
+	assert.Equal(t, 24e6, sdr.GetMasterClockRate(&stub, testLogger))
+}
+
+func TestClockSource(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, []string{"internal", "external"}, sdr.GetClockSources(&stub, testLogger))
+	assert.Equal(t, "internal", sdr.GetClockSource(&stub, testLogger))
+	require.Nil(t, sdr.SetClockSource(&stub, testLogger, "external"))
+	assert.Equal(t, "external", sdr.GetClockSource(&stub, testLogger))
+
+	err := sdr.SetClockSource(&stub, testLogger, "gpsdo")
+	require.NotNil(t, err)
+	assert.Equal(t, "cannot select non-existent clock source: gpsdo", err.Error())
+	assert.Equal(t, "external", sdr.GetClockSource(&stub, testLogger))
+}
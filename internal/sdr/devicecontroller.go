@@ -0,0 +1,264 @@
+package sdr
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// ControlDevice is the set of interfaces that a DeviceController uses to control a device.
+type ControlDevice interface {
+	Frequency
+	Gain
+	Agc
+	Antenna
+	SampleRates
+}
+
+// ErrControllerStopped is returned by the DeviceController methods once the controller has stopped.
+var ErrControllerStopped = errors.New("the device controller has stopped")
+
+// DeviceController owns a device, and serializes the operations that control it, such as tuning and setting the gain,
+// antenna, and sample rate. The operations are run one at a time on the controller's own go routine, so a device
+// can be controlled from several go routines, including while it is streaming.
+//
+// Each operation is a request that waits for its response. If the context that is passed with a request is
+// cancelled or times out before the operation has completed, the request returns the context's error. An operation
+// that has already started is allowed to complete.
+type DeviceController struct {
+	sdrD     ControlDevice
+	log      *logger.Logger
+	requests chan controlRequest
+	done     chan struct{}
+	// taggers holds the stream taggers that have been created for each direction and channel. It is only used on
+	// the controller's go routine.
+	taggers map[taggerKey]*StreamTagger
+}
+
+// taggerKey identifies the direction and channel of a StreamTagger.
+type taggerKey struct {
+	direction device.Direction
+	channel   uint
+}
+
+// controlRequest is an operation that is waiting to be run by a DeviceController.
+type controlRequest struct {
+	ctx    context.Context
+	op     func(ControlDevice) error
+	result chan error
+}
+
+// NewDeviceController creates a DeviceController for sdrD, which must already have been made. Once the controller
+// has been started, sdrD should only be controlled through the controller.
+func NewDeviceController(sdrD ControlDevice, log *logger.Logger) *DeviceController {
+	return &DeviceController{sdrD: sdrD, log: log, requests: make(chan controlRequest), done: make(chan struct{}),
+		taggers: make(map[taggerKey]*StreamTagger)}
+}
+
+// Start runs the requested operations on a new go routine until ctx is cancelled.
+func (c *DeviceController) Start(ctx context.Context) {
+	c.log.Log(logger.Debug, "Device controller started.\n")
+	go c.run(ctx)
+}
+
+// Done returns a channel that is closed when the controller has stopped.
+func (c *DeviceController) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait waits for the controller to stop.
+func (c *DeviceController) Wait() {
+	<-c.done
+}
+
+// Do runs op on the controller's go routine, and returns the error that op returns.
+//
+// op must not call the controller's methods, because the controller runs one operation at a time.
+//
+// Returns ctx's error if ctx is done before op has completed, or ErrControllerStopped if the controller has stopped.
+func (c *DeviceController) Do(ctx context.Context, op func(sdrD ControlDevice) error) error {
+	req := controlRequest{ctx: ctx, op: op, result: make(chan error, 1)}
+	select {
+	case c.requests <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return ErrControllerStopped
+	}
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		c.log.Logf(logger.Debug, "A device control request did not complete: %s\n", ctx.Err().Error())
+		return ctx.Err()
+	}
+}
+
+// Tune sets the overall center frequency of the specified direction and channel. See SetOverallCenterFrequency.
+func (c *DeviceController) Tune(ctx context.Context, direction device.Direction, channel uint, freq float64) error {
+	return c.Do(ctx, func(sdrD ControlDevice) error {
+		if err := SetOverallCenterFrequency(sdrD, c.log, direction, channel, freq, map[string]string{}); err != nil {
+			return err
+		}
+		c.retuned(direction, channel, func(tags *StreamTags) {
+			tags.CenterFrequency = GetOverallCenterFrequency(sdrD, c.log, direction, channel)
+		})
+		return nil
+	})
+}
+
+// GetCenterFrequency returns the overall center frequency of the specified direction and channel.
+func (c *DeviceController) GetCenterFrequency(ctx context.Context, direction device.Direction,
+	channel uint) (float64, error) {
+	var freq float64
+	if err := c.Do(ctx, func(sdrD ControlDevice) error {
+		freq = GetOverallCenterFrequency(sdrD, c.log, direction, channel)
+		return nil
+	}); err != nil {
+		return 0.0, err
+	}
+	return freq, nil
+}
+
+// SetGain sets the overall gain of the specified direction and channel. See SetOverallGain.
+func (c *DeviceController) SetGain(ctx context.Context, direction device.Direction, channel uint, gain float64) error {
+	return c.Do(ctx, func(sdrD ControlDevice) error {
+		if err := SetOverallGain(sdrD, c.log, direction, channel, gain); err != nil {
+			return err
+		}
+		c.retuned(direction, channel, func(tags *StreamTags) {
+			tags.Gain = GetOverallGain(sdrD, c.log, direction, channel)
+		})
+		return nil
+	})
+}
+
+// GetGain returns the overall gain of the specified direction and channel.
+func (c *DeviceController) GetGain(ctx context.Context, direction device.Direction, channel uint) (float64, error) {
+	var gain float64
+	if err := c.Do(ctx, func(sdrD ControlDevice) error {
+		gain = GetOverallGain(sdrD, c.log, direction, channel)
+		return nil
+	}); err != nil {
+		return 0.0, err
+	}
+	return gain, nil
+}
+
+// EnableAgc enables or disables automatic gain control for the specified direction and channel. See EnableAgc.
+func (c *DeviceController) EnableAgc(ctx context.Context, direction device.Direction, channel uint,
+	enable bool) error {
+	return c.Do(ctx, func(sdrD ControlDevice) error {
+		return EnableAgc(sdrD, c.log, direction, channel, enable)
+	})
+}
+
+// SetAntenna selects the named antenna for the specified direction and channel. See SetAntenna.
+func (c *DeviceController) SetAntenna(ctx context.Context, direction device.Direction, channel uint,
+	name string) error {
+	return c.Do(ctx, func(sdrD ControlDevice) error {
+		return SetAntenna(sdrD, c.log, direction, channel, name)
+	})
+}
+
+// GetAntenna returns the name of the selected antenna for the specified direction and channel.
+func (c *DeviceController) GetAntenna(ctx context.Context, direction device.Direction, channel uint) (string, error) {
+	var name string
+	if err := c.Do(ctx, func(sdrD ControlDevice) error {
+		name = GetCurrentAntenna(sdrD, c.log, direction, channel)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// SetSampleRate sets the sample rate of the specified direction and channel. See SetSampleRate.
+func (c *DeviceController) SetSampleRate(ctx context.Context, direction device.Direction, channel uint,
+	rate float64) error {
+	return c.Do(ctx, func(sdrD ControlDevice) error {
+		if err := SetSampleRate(sdrD, c.log, direction, channel, rate); err != nil {
+			return err
+		}
+		c.retuned(direction, channel, func(tags *StreamTags) {
+			tags.SampleRate = GetSampleRate(sdrD, c.log, direction, channel)
+		})
+		return nil
+	})
+}
+
+// GetSampleRate returns the sample rate of the specified direction and channel.
+func (c *DeviceController) GetSampleRate(ctx context.Context, direction device.Direction,
+	channel uint) (float64, error) {
+	var rate float64
+	if err := c.Do(ctx, func(sdrD ControlDevice) error {
+		rate = GetSampleRate(sdrD, c.log, direction, channel)
+		return nil
+	}); err != nil {
+		return 0.0, err
+	}
+	return rate, nil
+}
+
+// StreamTagger returns the StreamTagger for the specified direction and channel, which holds the current center
+// frequency, gain, and sample rate, and is updated by Tune, SetGain, and SetSampleRate. Pass the tagger to
+// Receiver.SetStreamTagger so that the blocks that the receiver reads are tagged with their settings.
+//
+// Changes that are made through Do are not seen by the tagger unless op calls StreamTagger.Retuned.
+func (c *DeviceController) StreamTagger(ctx context.Context, direction device.Direction,
+	channel uint) (*StreamTagger, error) {
+	var tagger *StreamTagger
+	if err := c.Do(ctx, func(sdrD ControlDevice) error {
+		key := taggerKey{direction: direction, channel: channel}
+		tagger = c.taggers[key]
+		if tagger == nil {
+			tagger = NewStreamTagger(direction, channel, StreamTags{
+				CenterFrequency: GetOverallCenterFrequency(sdrD, c.log, direction, channel),
+				SampleRate:      GetSampleRate(sdrD, c.log, direction, channel),
+				Gain:            GetOverallGain(sdrD, c.log, direction, channel),
+			})
+			c.taggers[key] = tagger
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tagger, nil
+}
+
+// retuned updates the tagger for the specified direction and channel, if there is one, with the hardware time at
+// which the settings changed. It must be called on the controller's go routine.
+func (c *DeviceController) retuned(direction device.Direction, channel uint, update func(*StreamTags)) {
+	tagger := c.taggers[taggerKey{direction: direction, channel: channel}]
+	if tagger == nil {
+		return
+	}
+	var changedAtNs uint
+	if timeDev, ok := c.sdrD.(HardwareTime); ok && timeDev.HasHardwareTime(TimeNow) {
+		changedAtNs = timeDev.GetHardwareTime(TimeNow)
+	}
+	tagger.Retuned(changedAtNs, update)
+	c.log.Logf(logger.Debug, "Stream tags changed: %+v\n", tagger.Tags())
+}
+
+// run is the controller's request loop.
+func (c *DeviceController) run(ctx context.Context) {
+	defer close(c.done)
+	for {
+		select {
+		case <-ctx.Done():
+			c.log.Log(logger.Debug, "Device controller stopped.\n")
+			return
+		case req := <-c.requests:
+			// A request whose context was cancelled while it was being handed over is not run.
+			if err := req.ctx.Err(); err != nil {
+				req.result <- err
+				continue
+			}
+			req.result <- req.op(c.sdrD)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package sdr_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startController makes a StubDevice and starts a DeviceController for it. The controller is stopped when the test
+// completes.
+func startController(t *testing.T, testLogger *logger.Logger) (*sdr.DeviceController, *sdr.StubDevice,
+	context.CancelFunc) {
+	stub := &sdr.StubDevice{}
+	require.Nil(t, sdr.Make(stub, map[string]string{"driver": "stub"}, testLogger))
+	controller := sdr.NewDeviceController(stub, testLogger)
+	ctx, cancel := context.WithCancel(context.Background())
+	controller.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		controller.Wait()
+	})
+	return controller, stub, cancel
+}
+
+func TestDeviceController(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	controller, _, stop := startController(t, testLogger)
+	ctx := context.Background()
+
+	require.Nil(t, controller.Tune(ctx, device.DirectionRX, 0, 433.92e6))
+	freq, err := controller.GetCenterFrequency(ctx, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 433.92e6, freq)
+	// StubDevice has a frequency range of 0 to 6 GHz.
+	assert.NotNil(t, controller.Tune(ctx, device.DirectionRX, 0, 7e9))
+
+	require.Nil(t, controller.SetGain(ctx, device.DirectionRX, 0, 20.))
+	gain, err := controller.GetGain(ctx, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 20., gain)
+	require.Nil(t, controller.EnableAgc(ctx, device.DirectionRX, 0, false))
+
+	require.Nil(t, controller.SetAntenna(ctx, device.DirectionRX, 0, "RX"))
+	antenna, err := controller.GetAntenna(ctx, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, "RX", antenna)
+	assert.NotNil(t, controller.SetAntenna(ctx, device.DirectionRX, 0, "TX"))
+
+	require.Nil(t, controller.SetSampleRate(ctx, device.DirectionRX, 0, 2.4e6))
+	rate, err := controller.GetSampleRate(ctx, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 2.4e6, rate)
+
+	stop()
+	controller.Wait()
+	assert.ErrorIs(t, controller.Tune(ctx, device.DirectionRX, 0, 100e6), sdr.ErrControllerStopped)
+	_, err = controller.GetSampleRate(ctx, device.DirectionRX, 0)
+	assert.ErrorIs(t, err, sdr.ErrControllerStopped)
+}
+
+func TestDeviceController_Timeout(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	controller, _, _ := startController(t, testLogger)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocked := make(chan error)
+	go func() {
+		blocked <- controller.Do(context.Background(), func(_ sdr.ControlDevice) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// The controller is busy, so the request times out before it is run.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, controller.Tune(ctx, device.DirectionRX, 0, 145e6), context.DeadlineExceeded)
+
+	close(release)
+	assert.Nil(t, <-blocked)
+	freq, err := controller.GetCenterFrequency(context.Background(), device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.NotEqual(t, 145e6, freq)
+}
+
+// TestDeviceController_WhileStreaming controls a device from several go routines while it is streaming. Run it with
+// go test -race to check that the controller serializes the operations.
+func TestDeviceController_WhileStreaming(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	controller, stub, _ := startController(t, testLogger)
+	source, err := sdr.NewSampleSource(stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	receiver := sdr.NewReceiver(source, testLogger, 0., func(block sdr.Block) { block.Release() })
+	require.Nil(t, receiver.Start(ctx))
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 25 {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				assert.Nil(t, controller.Tune(ctx, device.DirectionRX, 0, float64(100e6+1e6*(i*25+j))))
+				assert.Nil(t, controller.SetGain(ctx, device.DirectionRX, 0, float64(j)))
+				assert.Nil(t, controller.SetSampleRate(ctx, device.DirectionRX, 0, []float64{1.024e6, 2.048e6}[j%2]))
+				_, err := controller.GetCenterFrequency(ctx, device.DirectionRX, 0)
+				assert.Nil(t, err)
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Eventually(t, func() bool { return receiver.Stats().Blocks > 0 }, time.Second, time.Millisecond)
+	cancel()
+	assert.Nil(t, receiver.Wait())
+}
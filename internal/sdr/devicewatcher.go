@@ -0,0 +1,211 @@
+package sdr
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+)
+
+// DeviceEventKind specifies whether a DeviceEvent is for a device that has been attached or removed.
+type DeviceEventKind int
+
+// The kinds of device events.
+const (
+	// DeviceAdded is the kind of event for a device that has been attached.
+	DeviceAdded DeviceEventKind = iota
+	// DeviceRemoved is the kind of event for a device that has been removed.
+	DeviceRemoved
+)
+
+// DeviceEvent reports that a device has been attached or removed.
+type DeviceEvent struct {
+	Kind DeviceEventKind
+	// Key identifies the device. See DeviceKey.
+	Key string
+	// Args are the device's args, as returned by Enumerate.
+	Args map[string]string
+	// Unmade is true if the device was removed while it was made, and the watcher has unmade it.
+	Unmade bool
+}
+
+// String returns the kind of event and the device's label, for example "added: Generic RTL2832U :: 00000001".
+func (event DeviceEvent) String() string {
+	kind := "added"
+	if event.Kind == DeviceRemoved {
+		kind = "removed"
+	}
+	return fmt.Sprintf("%s: %s", kind, event.Args["label"])
+}
+
+// DeviceKey returns the key that identifies a device with the specified args: its serial number, or its label if it
+// does not have a serial number.
+func DeviceKey(args map[string]string) string {
+	if serial := args["serial"]; serial != "" {
+		return serial
+	}
+	return args["label"]
+}
+
+// DeviceWatcher enumerates the attached devices on an interval on its own go routine, and publishes the devices
+// that have been attached or removed.
+//
+// The watcher can also watch the device that has been made. If that device is removed, the watcher unmakes it.
+type DeviceWatcher struct {
+	sdrD     Enumerate
+	log      *logger.Logger
+	interval time.Duration
+	callback func([]DeviceEvent)
+	done     chan struct{}
+
+	// unmaking is held by the watcher while it releases and unmakes a removed made device, so that
+	// UnwatchMadeDevice can wait for the device to be unmade. It is always locked before mutex.
+	unmaking sync.Mutex
+	mutex    sync.Mutex
+	devices  map[string]map[string]string
+	made     MakeDevice
+	madeArgs map[string]string
+	release  func()
+}
+
+// NewDeviceWatcher creates a DeviceWatcher for the devices that sdrD enumerates. Audio devices are ignored.
+//
+// Params:
+//   - interval: the time between enumerations.
+//   - callback: called on the watcher's go routine with the devices that have been attached or removed since the
+//     previous enumeration. All of the attached devices are passed to the first call as DeviceAdded events.
+func NewDeviceWatcher(sdrD Enumerate, log *logger.Logger, interval time.Duration,
+	callback func([]DeviceEvent)) *DeviceWatcher {
+	return &DeviceWatcher{sdrD: sdrD, log: log, interval: interval, callback: callback, done: make(chan struct{})}
+}
+
+// Start enumerates the devices, then continues to enumerate them on a new go routine until ctx is cancelled.
+func (w *DeviceWatcher) Start(ctx context.Context) {
+	w.log.Logf(logger.Debug, "Watching for attached and removed devices every %v\n", w.interval)
+	go w.run(ctx)
+}
+
+// Done returns a channel that is closed when the watcher has stopped.
+func (w *DeviceWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Wait waits for the watcher to stop.
+func (w *DeviceWatcher) Wait() {
+	<-w.done
+}
+
+// Devices returns the devices that were attached when they were last enumerated, indexed by DeviceKey.
+func (w *DeviceWatcher) Devices() map[string]map[string]string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return maps.Clone(w.devices)
+}
+
+// WatchMadeDevice watches the device that dev was made for with args. If the device is removed, the watcher calls
+// release, then unmakes dev, then passes the device's DeviceRemoved event with Unmade set to the callback.
+//
+// release is called on the watcher's go routine, and should stop anything that is using dev. It may be nil. release
+// must not call UnwatchMadeDevice, or wait for a go routine that does.
+func (w *DeviceWatcher) WatchMadeDevice(dev MakeDevice, args map[string]string, release func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.made, w.madeArgs, w.release = dev, args, release
+	w.log.Logf(logger.Debug, "Watching made device %s\n", DeviceKey(args))
+}
+
+// UnwatchMadeDevice stops watching the made device. Call UnwatchMadeDevice before unmaking the device. If the watcher
+// is unmaking the device because it has been removed, UnwatchMadeDevice waits until the device has been unmade.
+//
+// Returns true if the device was being watched, or false if there was no made device or the watcher has already
+// unmade it because it was removed.
+func (w *DeviceWatcher) UnwatchMadeDevice() bool {
+	w.unmaking.Lock()
+	defer w.unmaking.Unlock()
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	watched := w.made != nil
+	w.made, w.madeArgs, w.release = nil, nil, nil
+	return watched
+}
+
+// run is the watcher's enumeration loop.
+func (w *DeviceWatcher) run(ctx context.Context) {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.poll()
+		select {
+		case <-ctx.Done():
+			w.log.Log(logger.Debug, "Device watcher stopped.\n")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll enumerates the devices, unmakes the made device if it has been removed, and passes the devices that have been
+// attached or removed to the callback.
+func (w *DeviceWatcher) poll() {
+	devices := make(map[string]map[string]string)
+	for _, args := range enumerateWithoutAudio(w.sdrD) {
+		devices[DeviceKey(args)] = args
+	}
+	w.unmaking.Lock()
+	w.mutex.Lock()
+	previous := w.devices
+	w.devices = devices
+	var unmake MakeDevice
+	var unmakeArgs map[string]string
+	var release func()
+	if _, ok := devices[DeviceKey(w.madeArgs)]; w.made != nil && !ok {
+		unmake, unmakeArgs, release = w.made, w.madeArgs, w.release
+		w.made, w.madeArgs, w.release = nil, nil, nil
+	}
+	w.mutex.Unlock()
+
+	var events []DeviceEvent
+	for _, key := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := devices[key]; !ok {
+			events = append(events, DeviceEvent{Kind: DeviceRemoved, Key: key, Args: previous[key]})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(devices)) {
+		if _, ok := previous[key]; !ok {
+			events = append(events, DeviceEvent{Kind: DeviceAdded, Key: key, Args: devices[key]})
+		}
+	}
+	if unmake != nil {
+		w.log.Log(logger.Info, "The made device has been removed. Unmaking it.\n")
+		if release != nil {
+			release()
+		}
+		Unmake(unmake, w.log)
+		events = markUnmade(events, unmakeArgs)
+	}
+	w.unmaking.Unlock()
+	for _, event := range events {
+		w.log.Logf(logger.Info, "Device %s\n", event.String())
+	}
+	if len(events) > 0 && w.callback != nil {
+		w.callback(events)
+	}
+}
+
+// markUnmade sets Unmade in the DeviceRemoved event for the device with args. The event is added if the device was
+// attached and removed between enumerations, so that there is no DeviceRemoved event for it.
+func markUnmade(events []DeviceEvent, args map[string]string) []DeviceEvent {
+	key := DeviceKey(args)
+	for i := range events {
+		if events[i].Kind == DeviceRemoved && events[i].Key == key {
+			events[i].Unmade = true
+			return events
+		}
+	}
+	return append(events, DeviceEvent{Kind: DeviceRemoved, Key: key, Args: args, Unmade: true})
+}
@@ -0,0 +1,167 @@
+package sdr_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// attachedDevices is an Enumerate whose devices can be changed while a DeviceWatcher is enumerating them.
+type attachedDevices struct {
+	mutex   sync.Mutex
+	devices []map[string]string
+}
+
+func (a *attachedDevices) Enumerate(_ map[string]string) []map[string]string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.devices
+}
+
+func (a *attachedDevices) set(devices ...map[string]string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.devices = devices
+}
+
+func TestDeviceKey(t *testing.T) {
+	assert.Equal(t, "00000001", sdr.DeviceKey(map[string]string{"label": "RTL", "serial": "00000001"}))
+	assert.Equal(t, "RTL", sdr.DeviceKey(map[string]string{"label": "RTL"}))
+}
+
+func TestDeviceWatcher(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	rtl := map[string]string{"driver": "rtlsdr", "label": "Generic RTL2832U :: 00000001", "serial": "00000001"}
+	hackRF := map[string]string{"driver": "hackrf", "label": "HackRF One"}
+	audio := map[string]string{"driver": "audio", "label": "Built-in Audio"}
+	attached := &attachedDevices{}
+	attached.set(rtl, audio)
+	events := make(chan []sdr.DeviceEvent, 10)
+	watcher := sdr.NewDeviceWatcher(attached, testLogger, time.Millisecond, func(e []sdr.DeviceEvent) {
+		select {
+		case events <- e:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher.Start(ctx)
+	defer func() {
+		cancel()
+		watcher.Wait()
+	}()
+
+	// The devices that are attached when the watcher starts are reported as added. Audio devices are ignored.
+	first := <-events
+	require.Equal(t, 1, len(first))
+	assert.Equal(t, sdr.DeviceAdded, first[0].Kind)
+	assert.Equal(t, "00000001", first[0].Key)
+	assert.Equal(t, "added: Generic RTL2832U :: 00000001", first[0].String())
+
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, rtl, testLogger))
+	released := make(chan struct{})
+	watcher.WatchMadeDevice(&stub, rtl, func() { close(released) })
+
+	attached.set(rtl, hackRF, audio)
+	second := <-events
+	require.Equal(t, 1, len(second))
+	assert.Equal(t, sdr.DeviceAdded, second[0].Kind)
+	assert.Equal(t, "HackRF One", second[0].Key)
+	assert.Equal(t, 2, len(watcher.Devices()))
+
+	attached.set(hackRF)
+	third := <-events
+	require.Equal(t, 1, len(third))
+	assert.Equal(t, sdr.DeviceRemoved, third[0].Kind)
+	assert.Equal(t, "00000001", third[0].Key)
+	assert.True(t, third[0].Unmade)
+	assert.Equal(t, "removed: Generic RTL2832U :: 00000001", third[0].String())
+	<-released
+	assert.Nil(t, stub.Device)
+	assert.False(t, watcher.UnwatchMadeDevice())
+}
+
+func TestDeviceWatcher_UnwatchMadeDevice(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	rtl := map[string]string{"driver": "rtlsdr", "label": "Generic RTL2832U :: 00000001", "serial": "00000001"}
+	attached := &attachedDevices{}
+	attached.set(rtl)
+	events := make(chan []sdr.DeviceEvent, 10)
+	watcher := sdr.NewDeviceWatcher(attached, testLogger, time.Millisecond, func(e []sdr.DeviceEvent) {
+		select {
+		case events <- e:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher.Start(ctx)
+	defer func() {
+		cancel()
+		watcher.Wait()
+	}()
+	<-events
+
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, rtl, testLogger))
+	watcher.WatchMadeDevice(&stub, rtl, nil)
+	assert.True(t, watcher.UnwatchMadeDevice())
+
+	// The device is no longer watched, so it is not unmade when it is removed.
+	attached.set()
+	removed := <-events
+	require.Equal(t, 1, len(removed))
+	assert.Equal(t, sdr.DeviceRemoved, removed[0].Kind)
+	assert.False(t, removed[0].Unmade)
+	assert.NotNil(t, stub.Device)
+}
+
+func TestDeviceWatcher_UnwatchWhileUnmaking(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	rtl := map[string]string{"driver": "rtlsdr", "label": "Generic RTL2832U :: 00000001", "serial": "00000001"}
+	attached := &attachedDevices{}
+	attached.set(rtl)
+	events := make(chan []sdr.DeviceEvent, 10)
+	watcher := sdr.NewDeviceWatcher(attached, testLogger, time.Millisecond, func(e []sdr.DeviceEvent) {
+		select {
+		case events <- e:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher.Start(ctx)
+	defer func() {
+		cancel()
+		watcher.Wait()
+	}()
+	<-events
+
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, rtl, testLogger))
+	releasing := make(chan struct{})
+	proceed := make(chan struct{})
+	watcher.WatchMadeDevice(&stub, rtl, func() {
+		close(releasing)
+		<-proceed
+	})
+	attached.set()
+	<-releasing
+
+	// UnwatchMadeDevice does not return until the watcher has unmade the device.
+	unwatched := make(chan bool)
+	go func() { unwatched <- watcher.UnwatchMadeDevice() }()
+	select {
+	case <-unwatched:
+		t.Fatal("UnwatchMadeDevice returned while the device was being unmade")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(proceed)
+	assert.False(t, <-unwatched)
+	assert.Nil(t, stub.Device)
+}
@@ -0,0 +1,16 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// CheckSoapyWriteBufferLayout exports checkSoapyWriteBufferLayout for the tests.
+var CheckSoapyWriteBufferLayout = checkSoapyWriteBufferLayout
+
+// NewUnallocatedSoapyStreamCS8 returns a CS8 stream for the specified direction and channels whose go-soapy-sdr
+// stream has not been set up by SoapySDR, so that the checks that SoapyDevice makes before it calls go-soapy-sdr can
+// be tested without an SDR.
+func NewUnallocatedSoapyStreamCS8(sD *SoapyDevice, direction device.Direction, channels []uint) *StreamCS8 {
+	return &StreamCS8{streamState: newStreamState(FormatCS8, direction, channels), stream: &device.SDRStreamCS8{},
+		device: sD}
+}
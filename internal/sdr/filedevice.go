@@ -0,0 +1,192 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileDevice is a device that plays back IQ recordings as though they were being received by an SDR.
+//
+// Recordings may be raw CU8, CS8, CS16, or CF32 files, SigMF recordings, or two channel WAV files. The sample rate
+// and center frequency of a recording are taken from its metadata (see Make). Playback is either as fast as
+// possible, or paced in real time, and may loop back to the start of the recording when the end is reached.
+//
+// FileDevice emulates a single channel receiver whose frequency, sample rate, and gain cannot be changed.
+// A FileDevice may be used concurrently on multiple go routines, for example to seek while a stream is being read.
+type FileDevice struct {
+	Device *Sdr
+	Args   map[string]string
+	// Dir is the directory that Enumerate searches for recordings.
+	Dir string
+
+	mutex    sync.Mutex
+	file     *os.File
+	metadata fileMetadata
+	realtime bool
+	looping  bool
+	// position is the index of the next sample to be read from the recording.
+	position uint64
+	// activatedAt and activatedPosition are used to pace reads when the recording is played back in real time.
+	activatedAt       time.Time
+	activatedPosition uint64
+	// raw and scratch hold the recording data and the decoded samples for each read.
+	raw     []byte
+	scratch []complex64
+}
+
+// fileMTU is the maximum number of samples that are read from a recording at a time.
+const fileMTU = 16384
+
+// fileDeviceExtensions lists the file name extensions of the recordings that FileDevice can play back.
+var fileDeviceExtensions = []string{".cu8", ".cs8", ".cs16", ".cf32", ".wav", ".sigmf-data"}
+
+// Enumerate returns the args for each recording in the device's directory.
+//
+// If args contains a "dir" value, that directory is searched instead of Dir.
+func (dev *FileDevice) Enumerate(args map[string]string) []map[string]string {
+	dir := dev.Dir
+	if args["dir"] != "" {
+		dir = args["dir"]
+	}
+	recordings := []map[string]string{}
+	if dir == "" {
+		return recordings
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return recordings
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains(fileDeviceExtensions, filepath.Ext(entry.Name())) {
+			continue
+		}
+		recordings = append(recordings, map[string]string{
+			"driver": "file",
+			"label":  entry.Name() + " :: file",
+			"serial": entry.Name(),
+			"path":   filepath.Join(dir, entry.Name()),
+		})
+	}
+	return recordings
+}
+
+// Make opens the recording specified by args["path"].
+//
+// The format, sample rate, and center frequency of the recording are determined, in order of priority, from:
+//   - the "format", "rate", and "frequency" values in args.
+//   - the recording's WAV header or SigMF metadata file.
+//   - the file name. The format is determined from the extension, and the frequency and sample rate from
+//     values such as "100.1MHz" and "2.048Msps".
+//
+// Returns an error if the recording cannot be opened, or its format or sample rate cannot be determined.
+func (dev *FileDevice) Make(args map[string]string) error {
+	path := args["path"]
+	if path == "" {
+		return errors.New("no recording path provided")
+	}
+	metadata, err := readFileMetadata(path, args)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(metadata.dataOffset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.file != nil {
+		dev.file.Close()
+	}
+	dev.file = file
+	dev.metadata = metadata
+	dev.position = 0
+	dev.Args = args
+	dev.Device = &Sdr{DeviceProperties: args}
+	return nil
+}
+
+// Unmake closes the recording.
+func (dev *FileDevice) Unmake() error {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.file == nil {
+		return errors.New("no recording to unmake")
+	}
+	err := dev.file.Close()
+	dev.file = nil
+	dev.Device = nil
+	dev.Args = nil
+	return err
+}
+
+// GetHardwareKey returns the hardware key for the file device.
+func (dev *FileDevice) GetHardwareKey() string {
+	return "FileDevice"
+}
+
+// SetRealtime sets whether the recording is played back in real time, or as fast as it can be read.
+func (dev *FileDevice) SetRealtime(realtime bool) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.realtime = realtime
+	dev.activatedAt = time.Now()
+	dev.activatedPosition = dev.position
+}
+
+// SetLooping sets whether playback restarts at the beginning of the recording when the end is reached.
+func (dev *FileDevice) SetLooping(looping bool) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.looping = looping
+}
+
+// NumSamples returns the number of samples in the recording.
+func (dev *FileDevice) NumSamples() uint64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.metadata.numSamples
+}
+
+// Position returns the index of the next sample that will be played back.
+func (dev *FileDevice) Position() uint64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.position
+}
+
+// Seek sets the index of the next sample that will be played back.
+//
+// Returns an error if no recording is open, or sample is beyond the end of the recording.
+func (dev *FileDevice) Seek(sample uint64) error {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.file == nil {
+		return errors.New("no recording to seek in")
+	}
+	if sample >= dev.metadata.numSamples {
+		return fmt.Errorf("cannot seek to sample %d; the recording has %d samples", sample, dev.metadata.numSamples)
+	}
+	return dev.seek(sample)
+}
+
+// seek moves the file offset to the specified sample. dev.mutex must be held by the caller.
+func (dev *FileDevice) seek(sample uint64) error {
+	offset := dev.metadata.dataOffset + int64(sample)*int64(dev.metadata.bytesPerSample())
+	if _, err := dev.file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	dev.position = sample
+	dev.activatedAt = time.Now()
+	dev.activatedPosition = sample
+	return nil
+}
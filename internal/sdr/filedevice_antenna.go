@@ -0,0 +1,38 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetAntennaNames returns the single "RX" antenna of the file device.
+func (dev *FileDevice) GetAntennaNames(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	antennas := []string{"RX"}
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).Antennas = antennas
+	}
+	return antennas
+}
+
+// GetCurrentAntenna returns "RX" for the receive channel.
+func (dev *FileDevice) GetCurrentAntenna(direction device.Direction, channel uint) string {
+	if !dev.isChannel(direction, channel) {
+		return ""
+	}
+	return "RX"
+}
+
+// SetAntenna returns an error unless antenna is "RX".
+func (dev *FileDevice) SetAntenna(direction device.Direction, channel uint, antenna string) error {
+	if !dev.isChannel(direction, channel) || antenna != "RX" {
+		return fmt.Errorf("invalid antenna: %s", antenna)
+	}
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).Antenna = antenna
+	}
+	return nil
+}
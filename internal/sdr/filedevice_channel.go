@@ -0,0 +1,20 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetNumChannels returns the number of channels for the specified direction.
+//
+// A recording has a single receive channel.
+func (dev *FileDevice) GetNumChannels(direction device.Direction) uint {
+	if direction == device.DirectionRX {
+		return 1
+	}
+	return 0
+}
+
+// isChannel returns true if the file device has the specified direction and channel.
+func (dev *FileDevice) isChannel(direction device.Direction, channel uint) bool {
+	return channel < dev.GetNumChannels(direction)
+}
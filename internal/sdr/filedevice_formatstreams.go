@@ -0,0 +1,23 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SetupFormatStream sets up a CU8, CS16, or CF32 stream for the recording. The recording's samples are converted
+// to the stream's format as they are read. The recording remains open when the stream is closed, until the device
+// is unmade.
+func (dev *FileDevice) SetupFormatStream(format string, direction device.Direction, channels []uint,
+	_ map[string]string) (formatStreamDevice, error) {
+	if err := dev.validateStreamChannels(direction, channels); err != nil {
+		return nil, err
+	}
+	return newConvertedFormatStream(format, sampleProducer{
+		mtu: fileMTU,
+		activate: func() error {
+			dev.activate()
+			return nil
+		},
+		readSamples: dev.readSamples,
+	})
+}
@@ -0,0 +1,64 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetFrequencyRanges returns a range containing only the recording's center frequency.
+func (dev *FileDevice) GetFrequencyRanges(direction device.Direction, channel uint) []device.SDRRange {
+	if !dev.isChannel(direction, channel) {
+		return []device.SDRRange{}
+	}
+	frequency := dev.GetOverallCenterFrequency(direction, channel)
+	return []device.SDRRange{{Minimum: frequency, Maximum: frequency, Step: 0}}
+}
+
+// GetOverallCenterFrequency returns the center frequency of the recording.
+func (dev *FileDevice) GetOverallCenterFrequency(_ device.Direction, _ uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.metadata.centerFrequency
+}
+
+// SetOverallCenterFrequency returns an error unless newFreq is the recording's center frequency, because a
+// recording cannot be retuned.
+func (dev *FileDevice) SetOverallCenterFrequency(direction device.Direction, channel uint, newFreq float64,
+	_ map[string]string) error {
+	return dev.SetTunableElementFrequency(direction, channel, "RF", newFreq)
+}
+
+// GetTunableElementNames returns the single tunable element of the file device.
+func (dev *FileDevice) GetTunableElementNames(_ device.Direction, _ uint) []string {
+	return []string{"RF"}
+}
+
+// GetTunableElementFrequencyRanges returns the frequency range of the "RF" tunable element.
+func (dev *FileDevice) GetTunableElementFrequencyRanges(direction device.Direction, channel uint,
+	name string) []device.SDRRange {
+	if name != "RF" {
+		return []device.SDRRange{}
+	}
+	return dev.GetFrequencyRanges(direction, channel)
+}
+
+// GetTunableElementFrequency returns the recording's center frequency for the "RF" tunable element.
+func (dev *FileDevice) GetTunableElementFrequency(direction device.Direction, channel uint, name string) float64 {
+	if name != "RF" {
+		return 0.0
+	}
+	return dev.GetOverallCenterFrequency(direction, channel)
+}
+
+// SetTunableElementFrequency returns an error unless newFreq is the recording's center frequency.
+func (dev *FileDevice) SetTunableElementFrequency(direction device.Direction, channel uint, name string,
+	newFreq float64) error {
+	if !dev.isChannel(direction, channel) || name != "RF" {
+		return fmt.Errorf("invalid tunable element: %s", name)
+	}
+	if frequency := dev.GetOverallCenterFrequency(direction, channel); newFreq != frequency {
+		return fmt.Errorf("cannot tune a recording to %.1f Hz; it was recorded at %.1f Hz", newFreq, frequency)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SupportsAGC returns false, because the gain of a recording cannot be changed.
+func (dev *FileDevice) SupportsAGC(_ device.Direction, _ uint) bool {
+	return false
+}
+
+// AgcIsEnabled returns false.
+func (dev *FileDevice) AgcIsEnabled(_ device.Direction, _ uint) bool {
+	return false
+}
+
+// EnableAgc returns an error, because the gain of a recording cannot be changed.
+func (dev *FileDevice) EnableAgc(_ device.Direction, _ uint, _ bool) error {
+	return errors.New("AGC is not supported for recordings")
+}
+
+// GetGainElementNames returns an empty slice, because a recording has no gain elements.
+func (dev *FileDevice) GetGainElementNames(_ device.Direction, _ uint) []string {
+	return []string{}
+}
+
+// GetElementGain returns an error, because a recording has no gain elements.
+func (dev *FileDevice) GetElementGain(_ device.Direction, _ uint, eltName string) (float64, error) {
+	return 0.0, fmt.Errorf("gain element '%s' is invalid", eltName)
+}
+
+// SetElementGain returns an error, because a recording has no gain elements.
+func (dev *FileDevice) SetElementGain(_ device.Direction, _ uint, eltName string, _ float64) error {
+	return fmt.Errorf("gain element '%s' is invalid", eltName)
+}
+
+// GetElementGainRange returns an empty range.
+func (dev *FileDevice) GetElementGainRange(_ device.Direction, _ uint, _ string) device.SDRRange {
+	return device.SDRRange{Minimum: 0, Maximum: 0, Step: 0}
+}
+
+// GetOverallGain returns 0.0, because samples are played back as they were recorded.
+func (dev *FileDevice) GetOverallGain(_ device.Direction, _ uint) float64 {
+	return 0.0
+}
+
+// SetOverallGain returns an error unless overallGain is 0.0.
+func (dev *FileDevice) SetOverallGain(_ device.Direction, _ uint, overallGain float64) error {
+	if overallGain != 0.0 {
+		return fmt.Errorf("requested overall gain = %.1f dB, but the gain of a recording cannot be changed",
+			overallGain)
+	}
+	return nil
+}
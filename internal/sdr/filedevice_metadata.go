@@ -0,0 +1,259 @@
+package sdr
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fileMetadata describes the samples in an IQ recording.
+type fileMetadata struct {
+	format          string
+	sampleRate      float64
+	centerFrequency float64
+	// dataOffset is the offset in bytes of the first sample in the file.
+	dataOffset int64
+	numSamples uint64
+}
+
+// bytesPerSample returns the number of bytes in each complex sample of the recording.
+func (metadata *fileMetadata) bytesPerSample() int {
+	switch metadata.format {
+	case FormatCU8, FormatCS8:
+		return 2
+	case FormatCS16:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// fullScale returns the value in the recording that is normalised to 1.0.
+func (metadata *fileMetadata) fullScale() float64 {
+	return defaultFullScales[metadata.format]
+}
+
+// formatsByExtension maps raw recording file name extensions to stream formats.
+var formatsByExtension = map[string]string{
+	".cu8":  FormatCU8,
+	".cs8":  FormatCS8,
+	".cs16": FormatCS16,
+	".cf32": FormatCF32,
+}
+
+// sigMFFormats maps SigMF datatypes to stream formats.
+var sigMFFormats = map[string]string{
+	"cu8":     FormatCU8,
+	"ci8":     FormatCS8,
+	"ci16_le": FormatCS16,
+	"cf32_le": FormatCF32,
+}
+
+// frequencyRegexp and sampleRateRegexp match frequencies such as "100.1MHz" and sample rates such as
+// "2.048Msps" in file names.
+var frequencyRegexp = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)([kMG]?)Hz`)
+var sampleRateRegexp = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)([kM]?)sps`)
+
+// unitMultipliers maps SI prefixes to multipliers.
+var unitMultipliers = map[string]float64{"": 1, "k": 1e3, "M": 1e6, "G": 1e9}
+
+// readFileMetadata determines the metadata for the recording at path. Values in args override those in the
+// recording's metadata.
+func readFileMetadata(path string, args map[string]string) (fileMetadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileMetadata{}, err
+	}
+	metadata := fileMetadata{format: formatsByExtension[filepath.Ext(path)]}
+	parseFileName(filepath.Base(path), &metadata)
+	dataSize := info.Size()
+	switch filepath.Ext(path) {
+	case ".wav":
+		dataSize, err = readWAVHeader(path, &metadata)
+	case ".sigmf-data":
+		err = readSigMFMetadata(strings.TrimSuffix(path, ".sigmf-data")+".sigmf-meta", &metadata)
+	}
+	if err != nil {
+		return fileMetadata{}, err
+	}
+	if err := applyMetadataArgs(args, &metadata); err != nil {
+		return fileMetadata{}, err
+	}
+	if metadata.format == "" {
+		return fileMetadata{}, fmt.Errorf("the format of recording %s is unknown", path)
+	}
+	if metadata.sampleRate <= 0 {
+		return fileMetadata{}, fmt.Errorf("the sample rate of recording %s is unknown", path)
+	}
+	metadata.numSamples = uint64(dataSize) / uint64(metadata.bytesPerSample())
+	return metadata, nil
+}
+
+// parseFileName sets the center frequency and sample rate from values such as "100.1MHz" and "2.048Msps" in name.
+func parseFileName(name string, metadata *fileMetadata) {
+	if match := frequencyRegexp.FindStringSubmatch(name); match != nil {
+		frequency, _ := strconv.ParseFloat(match[1], 64)
+		metadata.centerFrequency = frequency * unitMultipliers[match[2]]
+	}
+	if match := sampleRateRegexp.FindStringSubmatch(name); match != nil {
+		rate, _ := strconv.ParseFloat(match[1], 64)
+		metadata.sampleRate = rate * unitMultipliers[match[2]]
+	}
+}
+
+// applyMetadataArgs overrides metadata values with the "format", "rate", and "frequency" values in args.
+func applyMetadataArgs(args map[string]string, metadata *fileMetadata) error {
+	if format, ok := args["format"]; ok {
+		if _, supported := defaultFullScales[format]; !supported {
+			return fmt.Errorf("unsupported recording format: %s", format)
+		}
+		metadata.format = format
+	}
+	for key, value := range map[string]*float64{"rate": &metadata.sampleRate, "frequency": &metadata.centerFrequency} {
+		if arg, ok := args[key]; ok {
+			v, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %s", key, arg)
+			}
+			*value = v
+		}
+	}
+	return nil
+}
+
+// wavFormat is the fmt chunk of a WAV file.
+type wavFormat struct {
+	AudioFormat   uint16
+	NumChannels   uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// WAV audio formats.
+const (
+	wavFormatPCM        = 1
+	wavFormatIEEEFloat  = 3
+	wavFormatExtensible = 0xfffe
+)
+
+// readWAVHeader sets the format, sample rate, and data offset from the WAV file at path. The center frequency is
+// read from the "auxi" chunk that SDR# and other SDR programs write, if there is one.
+//
+// Returns the size of the sample data in bytes.
+func readWAVHeader(path string, metadata *fileMetadata) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var riff [12]byte
+	if _, err := io.ReadFull(file, riff[:]); err != nil || string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("%s is not a WAV file", path)
+	}
+	offset := int64(len(riff))
+	haveFormat := false
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(file, chunkHeader[:]); err != nil {
+			return 0, fmt.Errorf("WAV file %s does not contain any sample data", path)
+		}
+		chunkID := string(chunkHeader[0:4])
+		chunkSize := int64(binary.LittleEndian.Uint32(chunkHeader[4:8]))
+		offset += int64(len(chunkHeader))
+		switch chunkID {
+		case "fmt ":
+			var format wavFormat
+			if err := binary.Read(file, binary.LittleEndian, &format); err != nil {
+				return 0, err
+			}
+			if err := setWAVFormat(format, metadata); err != nil {
+				return 0, fmt.Errorf("WAV file %s: %s", path, err.Error())
+			}
+			haveFormat = true
+		case "auxi":
+			// The center frequency follows the start and stop times, which are each 16 byte SYSTEMTIME structures.
+			var auxi [36]byte
+			if chunkSize >= int64(len(auxi)) {
+				if _, err := io.ReadFull(file, auxi[:]); err != nil {
+					return 0, err
+				}
+				metadata.centerFrequency = float64(binary.LittleEndian.Uint32(auxi[32:36]))
+			}
+		case "data":
+			if !haveFormat {
+				return 0, fmt.Errorf("WAV file %s has no fmt chunk before its data", path)
+			}
+			metadata.dataOffset = offset
+			return chunkSize, nil
+		}
+		// Chunks are padded to an even number of bytes.
+		offset += chunkSize + chunkSize%2
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// setWAVFormat sets the format and sample rate from a WAV fmt chunk. IQ WAV files have two channels, I and Q.
+func setWAVFormat(format wavFormat, metadata *fileMetadata) error {
+	if format.NumChannels != 2 {
+		return fmt.Errorf("IQ recordings must have 2 channels, not %d", format.NumChannels)
+	}
+	switch {
+	case format.AudioFormat == wavFormatIEEEFloat && format.BitsPerSample == 32:
+		metadata.format = FormatCF32
+	case format.AudioFormat != wavFormatPCM && format.AudioFormat != wavFormatExtensible:
+		return fmt.Errorf("unsupported audio format %d", format.AudioFormat)
+	case format.BitsPerSample == 8:
+		// 8 bit WAV samples are unsigned.
+		metadata.format = FormatCU8
+	case format.BitsPerSample == 16:
+		metadata.format = FormatCS16
+	default:
+		return fmt.Errorf("unsupported bits per sample %d", format.BitsPerSample)
+	}
+	metadata.sampleRate = float64(format.SampleRate)
+	return nil
+}
+
+// sigMFMetadata contains the SigMF metadata fields that FileDevice uses.
+type sigMFMetadata struct {
+	Global struct {
+		Datatype   string  `json:"core:datatype"`
+		SampleRate float64 `json:"core:sample_rate"`
+	} `json:"global"`
+	Captures []struct {
+		Frequency float64 `json:"core:frequency"`
+	} `json:"captures"`
+}
+
+// readSigMFMetadata sets the format, sample rate, and center frequency from the SigMF metadata file at path.
+func readSigMFMetadata(path string, metadata *fileMetadata) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var sigMF sigMFMetadata
+	if err := json.Unmarshal(data, &sigMF); err != nil {
+		return fmt.Errorf("invalid SigMF metadata in %s: %s", path, err.Error())
+	}
+	format, ok := sigMFFormats[sigMF.Global.Datatype]
+	if !ok {
+		return errors.New("unsupported SigMF datatype: " + sigMF.Global.Datatype)
+	}
+	metadata.format = format
+	metadata.sampleRate = sigMF.Global.SampleRate
+	if len(sigMF.Captures) > 0 {
+		metadata.centerFrequency = sigMF.Captures[0].Frequency
+	}
+	return nil
+}
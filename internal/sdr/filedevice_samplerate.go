@@ -0,0 +1,36 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetSampleRateRange returns a range containing only the recording's sample rate.
+func (dev *FileDevice) GetSampleRateRange(direction device.Direction, channel uint) []device.SDRRange {
+	if !dev.isChannel(direction, channel) {
+		return []device.SDRRange{}
+	}
+	rate := dev.GetSampleRate(direction, channel)
+	return []device.SDRRange{{Minimum: rate, Maximum: rate, Step: 0}}
+}
+
+// GetSampleRate returns the recording's sample rate.
+func (dev *FileDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
+	dev.mutex.Lock()
+	rate := dev.metadata.sampleRate
+	dev.mutex.Unlock()
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).SampleRate = rate
+	}
+	return rate
+}
+
+// SetSampleRate returns an error unless rate is the recording's sample rate.
+func (dev *FileDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
+	if current := dev.GetSampleRate(direction, channel); rate != current {
+		return fmt.Errorf("cannot change the sample rate of a recording to %.1f; it was recorded at %.1f",
+			rate, current)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sdr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetStreamFormats returns the stream formats that the file device supports. Samples are converted from the
+// recording's format to the stream's format as they are read.
+func (dev *FileDevice) GetStreamFormats(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	return []string{FormatCU8, FormatCS8, FormatCS16, FormatCF32}
+}
+
+// GetNativeStreamFormat returns the format of the recording and its full scale value.
+func (dev *FileDevice) GetNativeStreamFormat(_ device.Direction, _ uint) (string, float64) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.metadata.format, dev.metadata.fullScale()
+}
+
+// validateStreamChannels returns an error if no recording is open, or any of the channels does not exist.
+func (dev *FileDevice) validateStreamChannels(direction device.Direction, channels []uint) error {
+	dev.mutex.Lock()
+	open := dev.file != nil
+	dev.mutex.Unlock()
+	if !open {
+		return errors.New("no recording has been opened")
+	}
+	for _, channel := range channels {
+		if !dev.isChannel(direction, channel) {
+			return fmt.Errorf("FileDevice does not have %s channel %d", directionAsString(direction), channel)
+		}
+	}
+	return nil
+}
+
+// activate restarts the real time pacing of playback.
+func (dev *FileDevice) activate() {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.activatedAt = time.Now()
+	dev.activatedPosition = dev.position
+}
+
+// readSamples reads up to numElems samples from the recording and passes them to store, which copies them into a
+// stream buffer.
+//
+// When the recording is played back in real time, readSamples waits until the samples would have been received
+// by a real device, and returns a timeout error if that is longer than timeoutUs. When the end of the recording is
+// reached, playback restarts at the beginning if looping is set; otherwise, the remaining samples are returned with
+// the StreamFlagEndBurst flag set, and later reads return ErrStreamEnd.
+//
+// Returns the timestamp of the first sample in nanoseconds, the number of samples read, and an error or nil.
+func (dev *FileDevice) readSamples(numElems uint, outputFlags *[1]int, timeoutUs uint,
+	store func([]complex64)) (uint, uint, error) {
+	outputFlags[0] = 0
+	if wait := dev.pacingDelay(numElems); wait > 0 {
+		if timeout := time.Duration(timeoutUs) * time.Microsecond; wait > timeout {
+			time.Sleep(timeout)
+			return 0, 0, ErrStreamTimeout
+		}
+		time.Sleep(wait)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.file == nil {
+		return 0, 0, errors.New("no recording has been opened")
+	}
+	if dev.position >= dev.metadata.numSamples {
+		if !dev.looping || dev.metadata.numSamples == 0 {
+			return 0, 0, ErrStreamEnd
+		}
+		if err := dev.seek(0); err != nil {
+			return 0, 0, err
+		}
+	}
+	timeNs := uint(float64(dev.position) * 1e9 / dev.metadata.sampleRate)
+	numElems = uint(min(uint64(numElems), dev.metadata.numSamples-dev.position))
+	bytesPerSample := dev.metadata.bytesPerSample()
+	// raw is sized in bytes and scratch in samples, so each is checked separately. The recording's format, and so
+	// the number of bytes per sample, changes when the device is made with another recording.
+	if cap(dev.raw) < int(numElems)*bytesPerSample {
+		dev.raw = make([]byte, int(numElems)*bytesPerSample)
+	}
+	if cap(dev.scratch) < int(numElems) {
+		dev.scratch = make([]complex64, numElems)
+	}
+	raw := dev.raw[:int(numElems)*bytesPerSample]
+	if _, err := io.ReadFull(dev.file, raw); err != nil {
+		return 0, 0, err
+	}
+	samples := dev.scratch[:numElems]
+	decodeSamples(dev.metadata.format, samples, raw)
+	dev.position += uint64(numElems)
+	store(samples)
+	outputFlags[0] = int(device.StreamFlagHasTime)
+	if dev.position == dev.metadata.numSamples && !dev.looping {
+		outputFlags[0] |= int(device.StreamFlagEndBurst)
+	}
+	return timeNs, numElems, nil
+}
+
+// pacingDelay returns how long to wait before numElems more samples are due when playing back in real time.
+func (dev *FileDevice) pacingDelay(numElems uint) time.Duration {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if !dev.realtime || dev.activatedAt.IsZero() || dev.position < dev.activatedPosition {
+		return 0
+	}
+	samples := float64(dev.position - dev.activatedPosition + uint64(numElems))
+	due := dev.activatedAt.Add(time.Duration(samples / dev.metadata.sampleRate * float64(time.Second)))
+	return time.Until(due)
+}
+
+// decodeSamples converts raw little endian recording data in the specified format to normalised samples.
+func decodeSamples(format string, samples []complex64, raw []byte) {
+	switch format {
+	case FormatCU8:
+		for i := range samples {
+			samples[i] = complex((float32(raw[2*i])-128)/128, (float32(raw[2*i+1])-128)/128)
+		}
+	case FormatCS8:
+		for i := range samples {
+			samples[i] = complex(float32(int8(raw[2*i]))/128, float32(int8(raw[2*i+1]))/128)
+		}
+	case FormatCS16:
+		for i := range samples {
+			re := int16(binary.LittleEndian.Uint16(raw[4*i:]))
+			im := int16(binary.LittleEndian.Uint16(raw[4*i+2:]))
+			samples[i] = complex(float32(re)/32768, float32(im)/32768)
+		}
+	case FormatCF32:
+		for i := range samples {
+			re := math.Float32frombits(binary.LittleEndian.Uint32(raw[8*i:]))
+			im := math.Float32frombits(binary.LittleEndian.Uint32(raw[8*i+4:]))
+			samples[i] = complex(re, im)
+		}
+	}
+}
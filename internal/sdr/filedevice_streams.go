@@ -0,0 +1,58 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SetupCS8Stream sets up a CS8 stream for the recording.
+func (dev *FileDevice) SetupCS8Stream(direction device.Direction, channels []uint,
+	_ map[string]string) (*StreamCS8, error) {
+	if err := dev.validateStreamChannels(direction, channels); err != nil {
+		return nil, err
+	}
+	return &StreamCS8{streamState: newStreamState(FormatCS8, direction, channels), device: dev}, nil
+}
+
+// CloseCS8Stream closes the specified stream. The recording remains open until the device is unmade.
+func (dev *FileDevice) CloseCS8Stream(_ *StreamCS8) error {
+	return nil
+}
+
+// GetCS8MTU returns the stream's maximum transmission unit in number of elements.
+func (dev *FileDevice) GetCS8MTU(_ *StreamCS8) int {
+	return fileMTU
+}
+
+// ActivateCS8Stream activates the specified stream.
+func (dev *FileDevice) ActivateCS8Stream(_ *StreamCS8, _ device.StreamFlag, _ int, _ int) error {
+	dev.activate()
+	return nil
+}
+
+// DeactivateCS8Stream deactivates the specified stream.
+func (dev *FileDevice) DeactivateCS8Stream(_ *StreamCS8, _ device.StreamFlag, _ int) error {
+	return nil
+}
+
+// ReadCS8Stream fills each of the stream's buffers with samples from the recording, converted to CS8.
+func (dev *FileDevice) ReadCS8Stream(_ *StreamCS8, buff [][]int, numElemsToRead uint, outputFlags *[1]int,
+	timeoutUs uint) (timeNs uint, numElemsRead uint, err error) {
+	for _, chBuff := range buff {
+		if maxElems := uint(len(chBuff) / 2); numElemsToRead > maxElems {
+			numElemsToRead = maxElems
+		}
+	}
+	return dev.readSamples(numElemsToRead, outputFlags, timeoutUs, func(samples []complex64) {
+		storeCS8(buff, samples)
+	})
+}
+
+// WriteCS8Stream returns ErrStreamNotSupported because FileDevice only plays back recordings.
+func (dev *FileDevice) WriteCS8Stream(_ *StreamCS8, _ [][]int, _ uint, _ device.StreamFlag, _ uint, _ uint) (uint, error) {
+	return 0, ErrStreamNotSupported
+}
+
+// ReadCS8StreamStatus returns ErrStreamNotSupported because FileDevice does not have transmit streams.
+func (dev *FileDevice) ReadCS8StreamStatus(_ *StreamCS8, _ *[1]int, _ uint) (uint, error) {
+	return 0, ErrStreamNotSupported
+}
@@ -0,0 +1,363 @@
+package sdr_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ sdr.Enumerate = (*sdr.FileDevice)(nil)
+var _ sdr.MakeDevice = (*sdr.FileDevice)(nil)
+var _ sdr.Channels = (*sdr.FileDevice)(nil)
+var _ sdr.Frequency = (*sdr.FileDevice)(nil)
+var _ sdr.Gain = (*sdr.FileDevice)(nil)
+var _ sdr.Agc = (*sdr.FileDevice)(nil)
+var _ sdr.Antenna = (*sdr.FileDevice)(nil)
+var _ sdr.SampleRates = (*sdr.FileDevice)(nil)
+var _ sdr.SampleStreams = (*sdr.FileDevice)(nil)
+
+// writeRecording writes data to the named file in dir and returns the file's path.
+func writeRecording(t *testing.T, dir string, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	require.Nil(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+// cs8Recording returns numSamples CS8 samples whose I values count up from -64 and whose Q values are -I.
+func cs8Recording(numSamples int) []byte {
+	data := make([]byte, 2*numSamples)
+	for i := 0; i < numSamples; i++ {
+		v := int8(i%128 - 64)
+		data[2*i] = byte(v)
+		data[2*i+1] = byte(-v)
+	}
+	return data
+}
+
+// wavRecording returns a WAV file containing data. If centerFrequency is not zero, an SDR# style auxi chunk
+// containing the frequency is included.
+func wavRecording(audioFormat uint16, bitsPerSample uint16, numChannels uint16, sampleRate uint32,
+	centerFrequency uint32, data []byte) []byte {
+	var chunks bytes.Buffer
+	chunks.WriteString("WAVE")
+	chunks.WriteString("fmt ")
+	binary.Write(&chunks, binary.LittleEndian, uint32(16))
+	blockAlign := numChannels * bitsPerSample / 8
+	binary.Write(&chunks, binary.LittleEndian, struct {
+		AudioFormat, NumChannels  uint16
+		SampleRate, ByteRate      uint32
+		BlockAlign, BitsPerSample uint16
+	}{audioFormat, numChannels, sampleRate, sampleRate * uint32(blockAlign), blockAlign, bitsPerSample})
+	if centerFrequency != 0 {
+		chunks.WriteString("auxi")
+		binary.Write(&chunks, binary.LittleEndian, uint32(36))
+		chunks.Write(make([]byte, 32))
+		binary.Write(&chunks, binary.LittleEndian, centerFrequency)
+	}
+	chunks.WriteString("data")
+	binary.Write(&chunks, binary.LittleEndian, uint32(len(data)))
+	chunks.Write(data)
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	binary.Write(&wav, binary.LittleEndian, uint32(chunks.Len()))
+	wav.Write(chunks.Bytes())
+	return wav.Bytes()
+}
+
+// makeFileDevice makes a FileDevice for the recording at path.
+func makeFileDevice(t *testing.T, testLogger *logger.Logger, args map[string]string) *sdr.FileDevice {
+	fileDev := &sdr.FileDevice{}
+	require.Nil(t, sdr.Make(fileDev, args, testLogger))
+	t.Cleanup(func() { fileDev.Unmake() })
+	return fileDev
+}
+
+func TestFileDevice_Enumerate(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	dir := t.TempDir()
+	writeRecording(t, dir, "fm_100.1MHz_2.048Msps.cs8", cs8Recording(10))
+	writeRecording(t, dir, "notes.txt", []byte("not a recording"))
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "subdir.wav"), 0o755))
+
+	fileDev := &sdr.FileDevice{Dir: dir}
+	sdrs := sdr.EnumerateWithoutAudio(fileDev, testLogger)
+	require.Equal(t, 1, len(sdrs))
+	args := sdrs["fm_100.1MHz_2.048Msps.cs8 :: file"]
+	assert.Equal(t, "file", args["driver"])
+	assert.Equal(t, filepath.Join(dir, "fm_100.1MHz_2.048Msps.cs8"), args["path"])
+
+	assert.Equal(t, 0, len((&sdr.FileDevice{}).Enumerate(nil)))
+	assert.Equal(t, 1, len((&sdr.FileDevice{}).Enumerate(map[string]string{"dir": dir})))
+}
+
+func TestFileDevice_RawCS8FromFileName(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	path := writeRecording(t, t.TempDir(), "fm_100.1MHz_2.048Msps.cs8", cs8Recording(1000))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	assert.Equal(t, 100.1e6, sdr.GetOverallCenterFrequency(fileDev, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, 2.048e6, fileDev.GetSampleRate(device.DirectionRX, 0))
+	assert.Equal(t, uint64(1000), fileDev.NumSamples())
+	format, fullScale := fileDev.GetNativeStreamFormat(device.DirectionRX, 0)
+	assert.Equal(t, sdr.FormatCS8, format)
+	assert.Equal(t, 128., fullScale)
+	assert.NotNil(t, sdr.SetOverallCenterFrequency(fileDev, testLogger, device.DirectionRX, 0, 90e6, nil))
+	assert.NotNil(t, fileDev.SetSampleRate(device.DirectionRX, 0, 1e6))
+	assert.NotNil(t, fileDev.SetOverallGain(device.DirectionRX, 0, 10))
+
+	stream, err := sdr.SetupCS8Stream(fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]int{make([]int, 2*600)}
+	var outputFlags [1]int
+	_, numRead, err := stream.ReadCS8FromStream(testLogger, buffer, 600, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(600), numRead)
+	assert.Equal(t, []int{-64, 64, -63, 63}, buffer[0][:4])
+	assert.Equal(t, int(device.StreamFlagHasTime), outputFlags[0])
+
+	// Only 400 samples remain, so the last read is short and marks the end of the recording.
+	timeNs, numRead, err := fileDev.ReadCS8Stream(stream, buffer, 600, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(400), numRead)
+	assert.InDelta(t, 600*1e9/2.048e6, float64(timeNs), 1)
+	assert.NotZero(t, outputFlags[0]&int(device.StreamFlagEndBurst))
+	_, _, err = fileDev.ReadCS8Stream(stream, buffer, 600, &outputFlags, 0)
+	assert.True(t, errors.Is(err, sdr.ErrStreamEnd))
+}
+
+func TestFileDevice_ArgsOverrideMetadata(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	path := writeRecording(t, t.TempDir(), "capture_100MHz_2Msps.bin", []byte{128, 128, 255, 0, 0, 255})
+	fileDev := makeFileDevice(t, testLogger,
+		map[string]string{"path": path, "format": "CU8", "rate": "250000", "frequency": "433920000"})
+	assert.Equal(t, 433.92e6, fileDev.GetOverallCenterFrequency(device.DirectionRX, 0))
+	assert.Equal(t, 250000., fileDev.GetSampleRate(device.DirectionRX, 0))
+	assert.Equal(t, uint64(3), fileDev.NumSamples())
+
+	source, err := sdr.NewSampleSource(fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	assert.Equal(t, sdr.FormatCU8, source.Format())
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	samples := [][]complex64{make([]complex64, 3)}
+	var outputFlags [1]int
+	_, _, err = source.ReadSamples(testLogger, samples, 3, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, []complex64{0, complex(127./128., -1), complex(-1, 127./128.)}, samples[0])
+}
+
+func TestFileDevice_MissingMetadata(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	dir := t.TempDir()
+	path := writeRecording(t, dir, "capture.cs8", cs8Recording(10))
+	err := sdr.Make(&sdr.FileDevice{}, map[string]string{"path": path}, testLogger)
+	assert.Equal(t, "the sample rate of recording "+path+" is unknown", err.Error())
+	path = writeRecording(t, dir, "capture_2Msps.bin", cs8Recording(10))
+	err = sdr.Make(&sdr.FileDevice{}, map[string]string{"path": path}, testLogger)
+	assert.Equal(t, "the format of recording "+path+" is unknown", err.Error())
+	err = sdr.Make(&sdr.FileDevice{}, map[string]string{"path": path, "format": "CF64"}, testLogger)
+	assert.Equal(t, "unsupported recording format: CF64", err.Error())
+	assert.NotNil(t, sdr.Make(&sdr.FileDevice{}, map[string]string{}, testLogger))
+}
+
+func TestFileDevice_WAV(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, []int16{16384, -16384, -32768, 32767})
+	path := writeRecording(t, t.TempDir(), "SDRSharp_IQ.wav", wavRecording(1, 16, 2, 1000000, 145500000, data.Bytes()))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	assert.Equal(t, 145.5e6, fileDev.GetOverallCenterFrequency(device.DirectionRX, 0))
+	assert.Equal(t, 1e6, fileDev.GetSampleRate(device.DirectionRX, 0))
+	assert.Equal(t, uint64(2), fileDev.NumSamples())
+
+	stream, err := sdr.SetupFormatStream[complex64](fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]complex64{make([]complex64, 2)}
+	var outputFlags [1]int
+	_, _, err = stream.ReadFromStream(testLogger, buffer, 2, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, []complex64{complex(0.5, -0.5), complex(-1, 32767./32768.)}, buffer[0])
+}
+
+func TestFileDevice_WAVFormats(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	dir := t.TempDir()
+	path := writeRecording(t, dir, "u8.wav", wavRecording(1, 8, 2, 250000, 0, []byte{128, 0}))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	format, _ := fileDev.GetNativeStreamFormat(device.DirectionRX, 0)
+	assert.Equal(t, sdr.FormatCU8, format)
+	assert.Equal(t, 0., fileDev.GetOverallCenterFrequency(device.DirectionRX, 0))
+
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, []float32{0.25, -0.75})
+	path = writeRecording(t, dir, "f32_7.1MHz.wav", wavRecording(3, 32, 2, 48000, 0, data.Bytes()))
+	fileDev = makeFileDevice(t, testLogger, map[string]string{"path": path})
+	format, fullScale := fileDev.GetNativeStreamFormat(device.DirectionRX, 0)
+	assert.Equal(t, sdr.FormatCF32, format)
+	assert.Equal(t, 1., fullScale)
+	assert.Equal(t, 7.1e6, fileDev.GetOverallCenterFrequency(device.DirectionRX, 0))
+
+	path = writeRecording(t, dir, "mono.wav", wavRecording(1, 16, 1, 48000, 0, []byte{0, 0}))
+	err := sdr.Make(&sdr.FileDevice{}, map[string]string{"path": path}, testLogger)
+	assert.Equal(t, "WAV file "+path+": IQ recordings must have 2 channels, not 1", err.Error())
+	path = writeRecording(t, dir, "bad.wav", []byte("RIFF...."))
+	err = sdr.Make(&sdr.FileDevice{}, map[string]string{"path": path}, testLogger)
+	assert.Equal(t, path+" is not a WAV file", err.Error())
+}
+
+func TestFileDevice_SigMF(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	dir := t.TempDir()
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, []float32{0.5, 0.25, -0.5, -0.25})
+	path := writeRecording(t, dir, "adsb.sigmf-data", data.Bytes())
+	writeRecording(t, dir, "adsb.sigmf-meta", []byte(`{"global": {"core:datatype": "cf32_le",
+		"core:sample_rate": 2400000, "core:version": "1.0.0"},
+		"captures": [{"core:sample_start": 0, "core:frequency": 1090000000}], "annotations": []}`))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	assert.Equal(t, 1090e6, fileDev.GetOverallCenterFrequency(device.DirectionRX, 0))
+	assert.Equal(t, 2.4e6, fileDev.GetSampleRate(device.DirectionRX, 0))
+	assert.Equal(t, uint64(2), fileDev.NumSamples())
+
+	stream, err := sdr.SetupFormatStream[int16](fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]int16{make([]int16, 4)}
+	var outputFlags [1]int
+	_, _, err = stream.ReadFromStream(testLogger, buffer, 2, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, []int16{16384, 8192, -16384, -8192}, buffer[0])
+}
+
+// TestFileDevice_RemakeWithNarrowerFormat checks that reads from a recording with fewer bytes per sample than the
+// previous recording are not limited by the buffers that were sized for the previous recording.
+func TestFileDevice_RemakeWithNarrowerFormat(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	dir := t.TempDir()
+	cf32Path := writeRecording(t, dir, "wide_1Msps.cf32", make([]byte, 8*1000))
+	cu8Path := writeRecording(t, dir, "narrow_1Msps.cu8", bytes.Repeat([]byte{255, 1}, 4000))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": cf32Path})
+	readAll := func(numSamples uint) [][]int {
+		stream, err := sdr.SetupCS8Stream(fileDev, testLogger, device.DirectionRX, []uint{0})
+		require.Nil(t, err)
+		defer stream.Close(testLogger)
+		require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+		defer stream.Deactivate(testLogger, 0, 0)
+		buffer := [][]int{make([]int, 2*numSamples)}
+		var outputFlags [1]int
+		_, numRead, err := stream.ReadCS8FromStream(testLogger, buffer, numSamples, &outputFlags, 0)
+		require.Nil(t, err)
+		assert.Equal(t, numSamples, numRead)
+		return buffer
+	}
+	readAll(1000)
+
+	require.Nil(t, fileDev.Unmake())
+	require.Nil(t, sdr.Make(fileDev, map[string]string{"path": cu8Path}, testLogger))
+	buffer := readAll(4000)
+	assert.Equal(t, []int{127, -127}, buffer[0][7998:])
+}
+
+func TestFileDevice_LoopingAndSeeking(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	path := writeRecording(t, t.TempDir(), "loop_1Msps.cs8", cs8Recording(100))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	fileDev.SetLooping(true)
+	stream, err := sdr.SetupCS8Stream(fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+
+	// Reads continue from the start of the recording when the end is reached.
+	buffer := [][]int{make([]int, 2*250)}
+	var outputFlags [1]int
+	_, numRead, err := stream.ReadCS8FromStream(testLogger, buffer, 250, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(250), numRead)
+	assert.Equal(t, buffer[0][:100], buffer[0][200:300])
+	assert.Equal(t, uint64(50), fileDev.Position())
+
+	require.Nil(t, fileDev.Seek(10))
+	assert.Equal(t, uint64(10), fileDev.Position())
+	timeNs, _, err := fileDev.ReadCS8Stream(stream, buffer, 1, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(10000), timeNs)
+	assert.Equal(t, []int{-54, 54}, buffer[0][:2])
+	assert.NotNil(t, fileDev.Seek(100))
+}
+
+func TestFileDevice_Realtime(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	path := writeRecording(t, t.TempDir(), "paced_1Msps.cs8", cs8Recording(100000))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	fileDev.SetRealtime(true)
+	stream, err := sdr.SetupCS8Stream(fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]int{make([]int, 2*20000)}
+	var outputFlags [1]int
+	start := time.Now()
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 20000, &outputFlags, 100000)
+	require.Nil(t, err)
+	// 20000 samples at 1 MS/s take 20 ms.
+	assert.GreaterOrEqual(t, time.Since(start), 19*time.Millisecond)
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 20000, &outputFlags, 1000)
+	assert.Equal(t, sdr.ErrStreamTimeout, err)
+}
+
+func TestFileDevice_Receiver(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	path := writeRecording(t, t.TempDir(), "capture_2.048Msps.cs8", cs8Recording(50000))
+	fileDev := makeFileDevice(t, testLogger, map[string]string{"path": path})
+	source, err := sdr.NewSampleSource(fileDev, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, fileDev.GetSampleRate(device.DirectionRX, 0), nil)
+	require.Nil(t, receiver.Start(context.Background()))
+	numSamples := 0
+	for block := range receiver.Blocks() {
+		assert.False(t, block.Discontinuity)
+		if block.Sequence == 0 {
+			assert.Equal(t, complex64(complex(-0.5, 0.5)), block.Samples[0][0])
+		}
+		numSamples += len(block.Samples[0])
+		block.Release()
+	}
+	// The receiver stops at the end of the recording.
+	assert.True(t, errors.Is(receiver.Wait(), sdr.ErrStreamEnd))
+	assert.Equal(t, 50000, numSamples)
+}
@@ -0,0 +1,157 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// StreamElement is the type of the values of a FormatStream: uint8 for CU8 streams, int16 for CS16 streams, and
+// complex64 for CF32 streams.
+type StreamElement interface {
+	uint8 | int16 | complex64
+}
+
+// FormatStreams interface specifies the method that a device must satisfy to provide CU8, CS16, and CF32 streams.
+type FormatStreams interface {
+	SetupFormatStream(string, device.Direction, []uint, map[string]string) (formatStreamDevice, error)
+}
+
+// formatStreamDevice is the device side of a FormatStream. A device returns one from SetupFormatStream for each
+// stream that it sets up.
+type formatStreamDevice interface {
+	close() error
+	mtu() int
+	activate(device.StreamFlag, int, int) error
+	deactivate(device.StreamFlag, int) error
+}
+
+// formatStreamReader is the device side of a FormatStream of T values. The device side returned by
+// SetupFormatStream must be a formatStreamReader for the element type of the requested format.
+type formatStreamReader[T StreamElement] interface {
+	formatStreamDevice
+	read(*FormatStream[T], [][]T, uint, *[1]int, uint) (uint, uint, error)
+}
+
+// FormatStream is a stream of CU8, CS16, or CF32 data, whose values are of type T.
+type FormatStream[T StreamElement] struct {
+	streamState
+	device formatStreamReader[T]
+}
+
+// streamElementFormat returns the stream format whose values are of type T, and the number of T values that make
+// up one element of the stream.
+func streamElementFormat[T StreamElement]() (string, uint) {
+	var value T
+	switch any(value).(type) {
+	case uint8:
+		return FormatCU8, 2
+	case int16:
+		return FormatCS16, 2
+	}
+	return FormatCF32, 1
+}
+
+// SetupFormatStream initializes a stream of T values for the specified direction and channels. T selects the
+// format of the stream: uint8 for CU8, int16 for CS16, or complex64 for CF32.
+//
+// Each channel in channels is a channel number of the device. Data for each of the channels
+// is returned in a separate buffer when the stream is read. The wire format is left for the
+// device driver to choose.
+//
+// Returns a stream pointer and an error. The returned stream may not be used
+// concurrently on multiple go routines.
+func SetupFormatStream[T StreamElement](sdrD FormatStreams, log *logger.Logger, direction device.Direction,
+	channels []uint) (*FormatStream[T], error) {
+	if err := validateChannels(log, channels); err != nil {
+		return nil, err
+	}
+	format, _ := streamElementFormat[T]()
+	devStream, err := sdrD.SetupFormatStream(format, direction, channels, map[string]string{})
+	if err != nil {
+		log.Logf(logger.Error, "Could not set up stream: %s\n", err.Error())
+		return nil, err
+	}
+	reader, ok := devStream.(formatStreamReader[T])
+	if !ok {
+		log.Logf(logger.Error, "The device did not set up a %s stream.\n", format)
+		return nil, fmt.Errorf("the device did not set up a %s stream", format)
+	}
+	log.Logf(logger.Debug, "%s stream setup complete for %s channels %v.\n", format, directionAsString(direction),
+		channels)
+	return &FormatStream[T]{streamState: newStreamState(format, direction, channels), device: reader}, nil
+}
+
+// Close closes an open stream, that is, a stream that was set up with a call to sdr.SetupFormatStream.
+func (stream *FormatStream[T]) Close(log *logger.Logger) error {
+	err := stream.device.close()
+	if err != nil {
+		log.Logf(logger.Error, "Could not close a stream: %s\n", err.Error())
+		return err
+	}
+	log.Log(logger.Debug, "Stream closed.\n")
+	return nil
+}
+
+// GetMTU gets stream's maximum transmission unit in number of elements.
+//
+// Return the MTU in number of stream elements (never zero)
+func (stream *FormatStream[T]) GetMTU(log *logger.Logger) uint {
+	mtu := stream.device.mtu()
+	if log.Enabled(logger.Debug) {
+		log.Logf(logger.Debug, "%s stream MTU is %d\n", stream.format, mtu)
+	}
+	return uint(mtu)
+}
+
+// Activate activates a stream. See StreamCS8.Activate for a description of the parameters.
+func (stream *FormatStream[T]) Activate(log *logger.Logger, flag device.StreamFlag, timeNs int, numElems int) error {
+	return activateStream(log, &stream.streamState, flag, timeNs, func() error {
+		return stream.device.activate(flag, timeNs, numElems)
+	})
+}
+
+// ActivateAt activates a stream at the specified hardware time. See StreamCS8.ActivateAt for a description of the
+// parameters.
+func (stream *FormatStream[T]) ActivateAt(log *logger.Logger, timeNs uint, numElems int) error {
+	return stream.Activate(log, device.StreamFlagHasTime, int(timeNs), numElems)
+}
+
+// Deactivate deactivates a stream. See StreamCS8.Deactivate for a description of the parameters.
+func (stream *FormatStream[T]) Deactivate(log *logger.Logger, flags device.StreamFlag, timeNs int) error {
+	return deactivateStream(log, &stream.streamState, func() error {
+		return stream.device.deactivate(flags, timeNs)
+	})
+}
+
+// ReadFromStream reads elementsToRead elements from each of the stream's channels.
+//
+// Params:
+//   - buff: an array of buffers, one for each of the stream's channels, that will hold the data that is read.
+//     Each buffer must hold at least 2 * elementsToRead uint8 or int16 values for CU8 and CS16 streams, or
+//     elementsToRead complex64 values for CF32 streams. CU8 values are offset by 128, so 128 represents 0.
+//
+// Returns the buffer's timestamp in nanoseconds, the number of elements read, and an error or nil.
+// SoapySDR stream errors are returned as one of the ErrStream... errors.
+func (stream *FormatStream[T]) ReadFromStream(log *logger.Logger, buff [][]T, elementsToRead uint,
+	outputFlags *[1]int, timeoutUs uint) (timeNs uint, numElemsRead uint, err error) {
+	_, valuesPerElem := streamElementFormat[T]()
+	return readStream(log, &stream.streamState, buff, valuesPerElem, elementsToRead, outputFlags,
+		func(readBuff [][]T, numElems uint) (uint, uint, error) {
+			return stream.device.read(stream, readBuff, numElems, outputFlags, timeoutUs)
+		})
+}
+
+// maxElements returns numElems, reduced if necessary so that each channel's buffer in buff can hold that many
+// elements of T values.
+func maxElements[T StreamElement](buff [][]T, numElems uint) uint {
+	_, valuesPerElem := streamElementFormat[T]()
+	for _, chBuff := range buff {
+		if maxElems := uint(len(chBuff)) / valuesPerElem; numElems > maxElems {
+			numElems = maxElems
+		}
+	}
+	return numElems
+}
@@ -0,0 +1,165 @@
+package sdr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formatStreamTest is a test of the FormatStream for one stream format.
+type formatStreamTest interface {
+	name() string
+	run(t *testing.T)
+}
+
+// formatStreamCase holds the values that a FormatStream of T values reads from a StubDevice.
+type formatStreamCase[T sdr.StreamElement] struct {
+	format        string
+	valuesPerElem uint
+	// first and last are the first values of the first and last elements that are read for channels 0 and 1.
+	first [2]T
+	last  [2]T
+}
+
+func TestFormatStream(t *testing.T) {
+	tests := []formatStreamTest{
+		formatStreamCase[uint8]{format: sdr.FormatCU8, valuesPerElem: 2, first: [2]uint8{126, 124},
+			last: [2]uint8{127, 126}},
+		formatStreamCase[int16]{format: sdr.FormatCS16, valuesPerElem: 2, first: [2]int16{-512, -1024},
+			last: [2]int16{-256, -512}},
+		formatStreamCase[complex64]{format: sdr.FormatCF32, valuesPerElem: 1, first: [2]complex64{-0.25, -0.5},
+			last: [2]complex64{complex(-0.125, -0.25), complex(-0.25, -0.5)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name(), test.run)
+	}
+}
+
+func (test formatStreamCase[T]) name() string {
+	return test.format
+}
+
+// buffers returns buffers for numChannels channels that each hold numElems elements.
+func (test formatStreamCase[T]) buffers(numChannels int, numElems uint) [][]T {
+	buffer := make([][]T, numChannels)
+	for ch := range buffer {
+		buffer[ch] = make([]T, test.valuesPerElem*numElems)
+	}
+	return buffer
+}
+
+func (test formatStreamCase[T]) run(t *testing.T) {
+	t.Run("Setup", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		stub := sdr.StubDevice{}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0})
+		assert.Nil(t, err)
+		require.NotNil(t, stream)
+		assert.Equal(t, test.format, stream.Format())
+		assert.Equal(t, uint(10000), stream.GetMTU(testLogger))
+		assert.Nil(t, stream.Close(testLogger))
+		assert.Equal(t, 1, stub.CallCount("Close"+test.format+"Stream"))
+	})
+
+	t.Run("SetupError", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		setup := "Setup" + test.format + "Stream"
+		stub := sdr.StubDevice{Errors: map[string]error{setup: errors.New("bad args passed to " + setup)}}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0})
+		assert.NotNil(t, err)
+		assert.Equal(t, "bad args passed to "+setup, err.Error())
+		assert.Nil(t, stream)
+	})
+
+	t.Run("Activate", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		stub := sdr.StubDevice{}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0})
+		require.Nil(t, err)
+		defer stream.Close(testLogger)
+		err = stream.Deactivate(testLogger, 0, 0)
+		assert.NotNil(t, err)
+		assert.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+		assert.True(t, stream.IsActive())
+		assert.Nil(t, stream.Deactivate(testLogger, 0, 0))
+		assert.False(t, stream.IsActive())
+	})
+
+	t.Run("ReadTwoChannels", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		stub := sdr.StubDevice{}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0, 1})
+		require.Nil(t, err)
+		defer stream.Close(testLogger)
+		require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+		defer stream.Deactivate(testLogger, 0, 0)
+		mtu := stream.GetMTU(testLogger)
+		buffer := test.buffers(2, mtu)
+		var outputFlags [1]int
+		timeNs, numElemsRead, err := stream.ReadFromStream(testLogger, buffer, mtu, &outputFlags, 0)
+		assert.Nil(t, err)
+		// The hardware time is 0 until it is set, so the first element that is read has a timestamp of 0.
+		assert.Equal(t, uint(0), timeNs)
+		assert.Equal(t, mtu, numElemsRead)
+		assert.Equal(t, int(device.StreamFlagHasTime), outputFlags[0])
+		lastElem := test.valuesPerElem * (mtu - 1)
+		for ch := range buffer {
+			assert.Equal(t, test.first[ch], buffer[ch][0])
+			assert.Equal(t, test.last[ch], buffer[ch][lastElem])
+		}
+	})
+
+	t.Run("ReadNotActivated", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		stub := sdr.StubDevice{}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0})
+		require.Nil(t, err)
+		defer stream.Close(testLogger)
+		var outputFlags [1]int
+		_, _, err = stream.ReadFromStream(testLogger, test.buffers(1, 10000), 10000, &outputFlags, 0)
+		assert.NotNil(t, err)
+		assert.Equal(t, "attempting to read from an inactive stream", err.Error())
+	})
+
+	t.Run("ReadBufferTooSmall", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		stub := sdr.StubDevice{}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0})
+		require.Nil(t, err)
+		defer stream.Close(testLogger)
+		require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+		defer stream.Deactivate(testLogger, 0, 0)
+		var outputFlags [1]int
+		_, _, err = stream.ReadFromStream(testLogger, test.buffers(1, 1000), 10000, &outputFlags, 0)
+		assert.NotNil(t, err)
+		assert.Equal(t, "read buffer is too small to hold 10000 elements", err.Error())
+	})
+
+	t.Run("ReadTimeout", func(t *testing.T) {
+		var log strings.Builder
+		testLogger := logger.New(&log)
+		stub := sdr.StubDevice{Errors: map[string]error{"Read" + test.format + "Stream": sdr.ErrStreamTimeout}}
+		stream, err := sdr.SetupFormatStream[T](&stub, testLogger, device.DirectionRX, []uint{0})
+		require.Nil(t, err)
+		defer stream.Close(testLogger)
+		require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+		defer stream.Deactivate(testLogger, 0, 0)
+		var outputFlags [1]int
+		_, numElemsRead, err := stream.ReadFromStream(testLogger, test.buffers(1, 10000), 10000, &outputFlags, 1000)
+		assert.True(t, errors.Is(err, sdr.ErrStreamTimeout))
+		assert.Equal(t, uint(0), numElemsRead)
+	})
+}
@@ -25,9 +25,9 @@ type Frequency interface {
 //
 // There may be one or more ranges depending on the SDR device.
 //
-// Returns the frequency ranges for RX channel 0, or an error if there are no frequency ranges.
-func GetFrequencyRanges(sdrD Frequency, log *logger.Logger) ([]device.SDRRange, error) {
-	frequencyRanges := sdrD.GetFrequencyRanges(device.DirectionRX, 0)
+// Returns the frequency ranges for the specified direction and channel, or an error if there are no frequency ranges.
+func GetFrequencyRanges(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint) ([]device.SDRRange, error) {
+	frequencyRanges := sdrD.GetFrequencyRanges(direction, channel)
 	if len(frequencyRanges) == 0 {
 		log.Log(logger.Error, "The attached SDR seems defective; there are no specified frequency ranges.\n")
 		return frequencyRanges, errors.New("the attached SDR seems defective; there are no specified frequency ranges")
@@ -44,8 +44,8 @@ func GetFrequencyRanges(sdrD Frequency, log *logger.Logger) ([]device.SDRRange,
 // GetTunableElementNames retrieves the list of tunable elements by name for the device.
 //
 // These elements will be in the order from RF to baseband.
-func GetTunableElementNames(sdrD Frequency, log *logger.Logger) []string {
-	elts := sdrD.GetTunableElementNames(device.DirectionRX, 0)
+func GetTunableElementNames(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint) []string {
+	elts := sdrD.GetTunableElementNames(direction, channel)
 	if len(elts) == 0 {
 		log.Log(logger.Debug, "Device has no tunable frequency elements.\n")
 	} else {
@@ -60,11 +60,11 @@ func GetTunableElementNames(sdrD Frequency, log *logger.Logger) []string {
 
 // GetTunableElementFrequencyRanges retrieves the freequency ranges for the specified tunable element.
 //
-// Ranges are retrieved for RX channel 0 only.
+// Ranges are retrieved for the specified direction and channel.
 // If the requested tunable element name does not match a name returned by GetTunableElementNames,
 // then an error is returned.
-func GetTunableElementFrequencyRanges(sdrD Frequency, log *logger.Logger, tunableElement string) ([]device.SDRRange, error) {
-	tElts := sdrD.GetTunableElementNames(device.DirectionRX, 0)
+func GetTunableElementFrequencyRanges(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint, tunableElement string) ([]device.SDRRange, error) {
+	tElts := sdrD.GetTunableElementNames(direction, channel)
 	if !slices.Contains(tElts, tunableElement) {
 		var eMsg strings.Builder
 		eMsg.WriteString(fmt.Sprintf("Invalid tunable element name: %s\n", tunableElement))
@@ -72,7 +72,7 @@ func GetTunableElementFrequencyRanges(sdrD Frequency, log *logger.Logger, tunabl
 		log.Log(logger.Error, eMsg.String())
 		return []device.SDRRange{}, fmt.Errorf("invalid tunable element name: %s", tunableElement)
 	}
-	fRanges := sdrD.GetTunableElementFrequencyRanges(device.DirectionRX, 0, tunableElement)
+	fRanges := sdrD.GetTunableElementFrequencyRanges(direction, channel, tunableElement)
 	var rMsg strings.Builder
 	rMsg.WriteString(fmt.Sprintf("FrequencyRanges for tunable element: %s\n", tunableElement))
 	for _, fR := range fRanges {
@@ -84,11 +84,11 @@ func GetTunableElementFrequencyRanges(sdrD Frequency, log *logger.Logger, tunabl
 
 // GetTunableElementFrequency retrieves the tuned frequency in Hz for the named tunable element.
 //
-// Frequency is retrieved for RX channel 0 only.
+// Frequency is retrieved for the specified direction and channel.
 // If the requested tunable element name does not match a name returned by GetTunableElementNames,
 // then an error is returned.
-func GetTunableElementFrequency(sdrD Frequency, log *logger.Logger, name string) (float64, error) {
-	tElts := sdrD.GetTunableElementNames(device.DirectionRX, 0)
+func GetTunableElementFrequency(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint, name string) (float64, error) {
+	tElts := sdrD.GetTunableElementNames(direction, channel)
 	if !slices.Contains(tElts, name) {
 		var eMsg strings.Builder
 		eMsg.WriteString(fmt.Sprintf("Invalid tunable element name: %s\n", name))
@@ -96,7 +96,7 @@ func GetTunableElementFrequency(sdrD Frequency, log *logger.Logger, name string)
 		log.Log(logger.Error, eMsg.String())
 		return 0.0, fmt.Errorf("invalid tunable element name: %s", name)
 	}
-	eltFreq := sdrD.GetTunableElementFrequency(device.DirectionRX, 0, name)
+	eltFreq := sdrD.GetTunableElementFrequency(direction, channel, name)
 	log.Logf(logger.Debug, "Current frequency for element %s: %.1f\n", name, eltFreq)
 	return eltFreq, nil
 }
@@ -106,8 +106,8 @@ func GetTunableElementFrequency(sdrD Frequency, log *logger.Logger, name string)
 // Returns an error the element name is invalid or the requested frequency is not within the element's
 // tunable range.
 // Returns nil on success.
-func SetTunableElementFrequency(sdrD Frequency, log *logger.Logger, name string, freq float64) error {
-	tElts := sdrD.GetTunableElementNames(device.DirectionRX, 0)
+func SetTunableElementFrequency(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint, name string, freq float64) error {
+	tElts := sdrD.GetTunableElementNames(direction, channel)
 	if !slices.Contains(tElts, name) {
 		var eMsg strings.Builder
 		eMsg.WriteString(fmt.Sprintf("Invalid tunable element name: %s\n", name))
@@ -116,7 +116,7 @@ func SetTunableElementFrequency(sdrD Frequency, log *logger.Logger, name string,
 		return fmt.Errorf("cannot set frequency. Invalid tunable element name: %s", name)
 	}
 
-	eltRanges, err := GetTunableElementFrequencyRanges(sdrD, log, name)
+	eltRanges, err := GetTunableElementFrequencyRanges(sdrD, log, direction, channel, name)
 	if err != nil {
 		return errors.New("cannot set frequency. Cannot retrieve tunable element frequency ranges")
 	}
@@ -124,22 +124,22 @@ func SetTunableElementFrequency(sdrD Frequency, log *logger.Logger, name string,
 		return errors.New("cannot set frequency. Requested frequency not within element's frequency ranges")
 	}
 
-	sdrD.SetTunableElementFrequency(device.DirectionRX, 0, name, freq)
+	sdrD.SetTunableElementFrequency(direction, channel, name, freq)
 	log.Logf(logger.Debug, "Setting element %s frequency to %.1f\n", name, freq)
-	sdrD.GetTunableElementFrequency(device.DirectionRX, 0, name)
+	sdrD.GetTunableElementFrequency(direction, channel, name)
 	return nil
 }
 
-// GetOverallCenterFrequency retrieves the overall center frequency in Hz for RX channel 0.
-func GetOverallCenterFrequency(sdrD Frequency, log *logger.Logger) float64 {
-	currentFreq := sdrD.GetOverallCenterFrequency(device.DirectionRX, 0)
+// GetOverallCenterFrequency retrieves the overall center frequency in Hz for the specified direction and channel.
+func GetOverallCenterFrequency(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint) float64 {
+	currentFreq := sdrD.GetOverallCenterFrequency(direction, channel)
 	log.Logf(logger.Debug, "Center frequency: %.1f\n", currentFreq)
 	return currentFreq
 }
 
-// SetOverallCenterFrequency sets the overall center frequency for RX channel 0.
-func SetOverallCenterFrequency(sdrD Frequency, log *logger.Logger, newFreq float64, args map[string]string) error {
-	freqRanges, err := GetFrequencyRanges(sdrD, log)
+// SetOverallCenterFrequency sets the overall center frequency for the specified direction and channel.
+func SetOverallCenterFrequency(sdrD Frequency, log *logger.Logger, direction device.Direction, channel uint, newFreq float64, args map[string]string) error {
+	freqRanges, err := GetFrequencyRanges(sdrD, log, direction, channel)
 	if err != nil {
 		log.Logf(logger.Error, "There are no frequency ranges for this device.\n")
 		return fmt.Errorf("cannot set overall center frequency to %.1f.\nThere are no frequency ranges for this device", newFreq)
@@ -154,7 +154,7 @@ func SetOverallCenterFrequency(sdrD Frequency, log *logger.Logger, newFreq float
 		log.Log(logger.Error, rMsg.String())
 		return fmt.Errorf("requested frequency: %.1f is not within the frequency ranges for this device", newFreq)
 	}
-	err = sdrD.SetOverallCenterFrequency(device.DirectionRX, 0, newFreq, args)
+	err = sdrD.SetOverallCenterFrequency(direction, channel, newFreq, args)
 	if err != nil {
 		log.Logf(logger.Error, "Cannot set requested overall center frequency: %.1f: %s\n", newFreq, err.Error())
 		return fmt.Errorf("cannot set requested overall center frequency: %.1f: %s", newFreq, err.Error())
@@ -1,17 +1,19 @@
 package sdr_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/jimorc/jsdr/internal/logger"
 	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetFrequencyRanges_OneRange(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	ranges, err := sdr.GetFrequencyRanges(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	ranges, err := sdr.GetFrequencyRanges(&stub, testLogger, device.DirectionRX, 0)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(ranges))
 	assert.Equal(t, 0.0, ranges[0].Minimum)
@@ -21,8 +23,9 @@ func TestGetFrequencyRanges_OneRange(t *testing.T) {
 
 func TestGetFrequencyRanges_TwoRanges(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "3"}}
-	ranges, err := sdr.GetFrequencyRanges(&stub, testLogger)
+	stub := sdr.StubDevice{FrequencyRanges: []device.SDRRange{{Minimum: 0.0, Maximum: 6e+09, Step: 0.0},
+		{Minimum: 6.1e+09, Maximum: 1e+10, Step: 0.0}}}
+	ranges, err := sdr.GetFrequencyRanges(&stub, testLogger, device.DirectionRX, 0)
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(ranges))
 	assert.Equal(t, 0.0, ranges[0].Minimum)
@@ -35,8 +38,8 @@ func TestGetFrequencyRanges_TwoRanges(t *testing.T) {
 
 func TestGetFrequencyRanges_NoRanges(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	ranges, err := sdr.GetFrequencyRanges(&stub, testLogger)
+	stub := sdr.StubDevice{FrequencyRanges: []device.SDRRange{}}
+	ranges, err := sdr.GetFrequencyRanges(&stub, testLogger, device.DirectionRX, 0)
 	assert.NotNil(t, err)
 	assert.Equal(t, "the attached SDR seems defective; there are no specified frequency ranges", err.Error())
 	assert.Equal(t, 0, len(ranges))
@@ -44,16 +47,16 @@ func TestGetFrequencyRanges_NoRanges(t *testing.T) {
 
 func TestGetTunableElementNames(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	tElts := sdr.GetTunableElementNames(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	tElts := sdr.GetTunableElementNames(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, 1, len(tElts))
 	assert.Equal(t, "RF", tElts[0])
 }
 
 func TestGetTunableElementsFrequencyRanges(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	fRanges, err := sdr.GetTunableElementFrequencyRanges(&stub, testLogger, "RF")
+	stub := sdr.StubDevice{}
+	fRanges, err := sdr.GetTunableElementFrequencyRanges(&stub, testLogger, device.DirectionRX, 0, "RF")
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(fRanges))
 	assert.Equal(t, 0.0, fRanges[0].Minimum)
@@ -66,8 +69,8 @@ func TestGetTunableElementsFrequencyRanges(t *testing.T) {
 
 func TestGetTunableElementsFrequencyRanges_BadElement(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	fRanges, err := sdr.GetTunableElementFrequencyRanges(&stub, testLogger, "IF")
+	stub := sdr.StubDevice{}
+	fRanges, err := sdr.GetTunableElementFrequencyRanges(&stub, testLogger, device.DirectionRX, 0, "IF")
 	assert.NotNil(t, err)
 	assert.Equal(t, "invalid tunable element name: IF", err.Error())
 	assert.Equal(t, 0, len(fRanges))
@@ -75,16 +78,16 @@ func TestGetTunableElementsFrequencyRanges_BadElement(t *testing.T) {
 
 func TestGetTunableElementFrequency(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	freq, err := sdr.GetTunableElementFrequency(&stub, testLogger, "RF")
+	stub := sdr.StubDevice{}
+	freq, err := sdr.GetTunableElementFrequency(&stub, testLogger, device.DirectionRX, 0, "RF")
 	assert.Nil(t, err)
 	assert.Equal(t, 1e+08, freq)
 }
 
 func TestGetTunableElementFrequency_BadElement(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	freq, err := sdr.GetTunableElementFrequency(&stub, testLogger, "IF")
+	stub := sdr.StubDevice{}
+	freq, err := sdr.GetTunableElementFrequency(&stub, testLogger, device.DirectionRX, 0, "IF")
 	assert.NotNil(t, err)
 	assert.Equal(t, "invalid tunable element name: IF", err.Error())
 	assert.Equal(t, 0.0, freq)
@@ -92,73 +95,74 @@ func TestGetTunableElementFrequency_BadElement(t *testing.T) {
 
 func TestSetTunableElementFrequency(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
+	stub := sdr.StubDevice{}
 	newFreq := 50000000.
-	err := sdr.SetTunableElementFrequency(&stub, testLogger, "RF", newFreq)
+	err := sdr.SetTunableElementFrequency(&stub, testLogger, device.DirectionRX, 0, "RF", newFreq)
 	assert.Nil(t, err)
-	freq, err := sdr.GetTunableElementFrequency(&stub, testLogger, "RF")
+	freq, err := sdr.GetTunableElementFrequency(&stub, testLogger, device.DirectionRX, 0, "RF")
 	assert.Nil(t, err)
 	assert.Equal(t, newFreq, freq)
 }
 
 func TestSetTunableElementFrequency_BadElement(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
+	stub := sdr.StubDevice{}
 	newFreq := 50000000.
-	err := sdr.SetTunableElementFrequency(&stub, testLogger, "IF", newFreq)
+	err := sdr.SetTunableElementFrequency(&stub, testLogger, device.DirectionRX, 0, "IF", newFreq)
 	assert.NotNil(t, err)
 	assert.Equal(t, "cannot set frequency. Invalid tunable element name: IF", err.Error())
 }
 
 func TestSetTunableElementFrequency_BadFreq(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
+	stub := sdr.StubDevice{}
 	newFreq := -100.
-	err := sdr.SetTunableElementFrequency(&stub, testLogger, "RF", newFreq)
+	err := sdr.SetTunableElementFrequency(&stub, testLogger, device.DirectionRX, 0, "RF", newFreq)
 	assert.NotNil(t, err)
 	assert.Equal(t, "cannot set frequency. Requested frequency not within element's frequency ranges", err.Error())
 }
 
 func TestGetOverallCenterFrequency(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	centerFreq := sdr.GetOverallCenterFrequency(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	centerFreq := sdr.GetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, 100000000., centerFreq)
 }
 
 func TestSetOverallCenterFrequency(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "3"}}
+	stub := sdr.StubDevice{}
 	newFreq := 50000000.
-	err := sdr.SetOverallCenterFrequency(&stub, testLogger, newFreq, map[string]string{})
+	err := sdr.SetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0, newFreq, map[string]string{})
 	assert.Nil(t, err)
-	centerFreq := sdr.GetOverallCenterFrequency(&stub, testLogger)
+	centerFreq := sdr.GetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, newFreq, centerFreq)
 }
 
 func TestSetOverallCenterFrequency_NoRanges(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
+	stub := sdr.StubDevice{FrequencyRanges: []device.SDRRange{}}
 	newFreq := 50000000.
-	err := sdr.SetOverallCenterFrequency(&stub, testLogger, newFreq, map[string]string{})
+	err := sdr.SetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0, newFreq, map[string]string{})
 	assert.NotNil(t, err)
 	assert.Equal(t, "cannot set overall center frequency to 50000000.0.\nThere are no frequency ranges for this device", err.Error())
 }
 
 func TestSetOverallCenterFrequency_OutsideRanges(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
+	stub := sdr.StubDevice{}
 	newFreq := 7e+09
-	err := sdr.SetOverallCenterFrequency(&stub, testLogger, newFreq, map[string]string{})
+	err := sdr.SetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0, newFreq, map[string]string{})
 	assert.NotNil(t, err)
 	assert.Equal(t, "requested frequency: 7000000000.0 is not within the frequency ranges for this device", err.Error())
 }
 
 func TestSetOverallCenterFrequency_ErrorSettingFrequency(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "4"}}
+	stub := sdr.StubDevice{Errors: map[string]error{"SetOverallCenterFrequency": errors.New("tuner is not locked")}}
 	newFreq := 50000000.
-	err := sdr.SetOverallCenterFrequency(&stub, testLogger, newFreq, map[string]string{})
+	err := sdr.SetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0, newFreq, map[string]string{})
 	assert.NotNil(t, err)
-	assert.Equal(t, "cannot set requested overall center frequency: 50000000.0: serial # = 4", err.Error())
+	assert.Equal(t, "cannot set requested overall center frequency: 50000000.0: tuner is not locked", err.Error())
+	assert.Equal(t, 100000000., sdr.GetOverallCenterFrequency(&stub, testLogger, device.DirectionRX, 0))
 }
@@ -0,0 +1,218 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// FrontendCorrections interface specifies the methods that correct for imperfections in an SDR's front end: DC
+// offset, IQ imbalance, and the frequency error of the SDR's reference oscillator.
+type FrontendCorrections interface {
+	HasDCOffsetMode(device.Direction, uint) bool
+	GetDCOffsetMode(device.Direction, uint) bool
+	SetDCOffsetMode(device.Direction, uint, bool) error
+	HasDCOffset(device.Direction, uint) bool
+	GetDCOffset(device.Direction, uint) (float64, float64, error)
+	SetDCOffset(device.Direction, uint, float64, float64) error
+	HasIQBalance(device.Direction, uint) bool
+	GetIQBalance(device.Direction, uint) (float64, float64, error)
+	SetIQBalance(device.Direction, uint, float64, float64) error
+	HasFrequencyCorrection(device.Direction, uint) bool
+	GetFrequencyCorrection(device.Direction, uint) float64
+	SetFrequencyCorrection(device.Direction, uint, float64) error
+}
+
+// MaxFrequencyCorrection is the largest frequency correction, in parts per million, that SetFrequencyCorrection
+// accepts. Even the cheapest RTL dongles are within 100 PPM of their nominal frequency.
+const MaxFrequencyCorrection = 200.
+
+// HasDCOffsetMode returns whether the device supports automatic DC offset correction for the specified direction
+// and channel.
+func HasDCOffsetMode(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) bool {
+	hasMode := sdrD.HasDCOffsetMode(direction, channel)
+	log.Logf(logger.Debug, "Device has automatic DC offset mode: %v\n", hasMode)
+	return hasMode
+}
+
+// GetDCOffsetMode returns whether automatic DC offset correction is enabled for the specified direction and channel.
+//
+// Returns false if the device does not support automatic DC offset correction.
+func GetDCOffsetMode(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) bool {
+	if !sdrD.HasDCOffsetMode(direction, channel) {
+		return false
+	}
+	automatic := sdrD.GetDCOffsetMode(direction, channel)
+	log.Logf(logger.Debug, "Automatic DC offset correction enabled: %v\n", automatic)
+	return automatic
+}
+
+// SetDCOffsetMode enables or disables automatic DC offset correction for the specified direction and channel.
+//
+// Returns an error if the device does not support automatic DC offset correction, or the mode could not be set.
+func SetDCOffsetMode(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint, automatic bool) error {
+	if !sdrD.HasDCOffsetMode(direction, channel) {
+		log.Log(logger.Error, "Attempting to set the DC offset mode, but the device does not support automatic DC offset correction\n")
+		return errors.New("the device does not support automatic DC offset correction")
+	}
+	err := sdrD.SetDCOffsetMode(direction, channel, automatic)
+	if err != nil {
+		log.Logf(logger.Error, "Could not set automatic DC offset correction to %v: %s\n", automatic, err.Error())
+	} else {
+		log.Logf(logger.Debug, "Have set automatic DC offset correction to %v\n", automatic)
+	}
+	return err
+}
+
+// HasDCOffset returns whether the device supports manual DC offset correction for the specified direction and channel.
+func HasDCOffset(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) bool {
+	hasOffset := sdrD.HasDCOffset(direction, channel)
+	log.Logf(logger.Debug, "Device has DC offset correction: %v\n", hasOffset)
+	return hasOffset
+}
+
+// GetDCOffset returns the I and Q DC offset corrections for the specified direction and channel.
+//
+// Returns an error if the device does not support DC offset correction, or the correction could not be retrieved.
+func GetDCOffset(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) (float64, float64, error) {
+	if !sdrD.HasDCOffset(direction, channel) {
+		log.Log(logger.Error, "Attempting to get the DC offset, but the device does not support DC offset correction\n")
+		return 0., 0., errors.New("the device does not support DC offset correction")
+	}
+	offsetI, offsetQ, err := sdrD.GetDCOffset(direction, channel)
+	if err != nil {
+		log.Logf(logger.Error, "Error getting the DC offset: %s\n", err.Error())
+		return 0., 0., err
+	}
+	log.Logf(logger.Debug, "DC offset: I: %.3f, Q: %.3f\n", offsetI, offsetQ)
+	return offsetI, offsetQ, nil
+}
+
+// SetDCOffset sets the I and Q DC offset corrections for the specified direction and channel. The corrections are
+// relative to full scale, so each must be between -1.0 and 1.0.
+//
+// Returns an error if the device does not support DC offset correction, a correction is out of range, or the
+// correction could not be set.
+func SetDCOffset(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint, offsetI, offsetQ float64) error {
+	if !sdrD.HasDCOffset(direction, channel) {
+		log.Log(logger.Error, "Attempting to set the DC offset, but the device does not support DC offset correction\n")
+		return errors.New("the device does not support DC offset correction")
+	}
+	if err := checkCorrection("DC offset", offsetI, offsetQ); err != nil {
+		log.Logf(logger.Error, "%s\n", err.Error())
+		return err
+	}
+	err := sdrD.SetDCOffset(direction, channel, offsetI, offsetQ)
+	if err != nil {
+		log.Logf(logger.Error, "Could not set the DC offset to I: %.3f, Q: %.3f: %s\n", offsetI, offsetQ, err.Error())
+	} else {
+		log.Logf(logger.Debug, "Have set the DC offset to I: %.3f, Q: %.3f\n", offsetI, offsetQ)
+	}
+	return err
+}
+
+// HasIQBalance returns whether the device supports IQ balance correction for the specified direction and channel.
+func HasIQBalance(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) bool {
+	hasBalance := sdrD.HasIQBalance(direction, channel)
+	log.Logf(logger.Debug, "Device has IQ balance correction: %v\n", hasBalance)
+	return hasBalance
+}
+
+// GetIQBalance returns the I and Q balance corrections for the specified direction and channel.
+//
+// Returns an error if the device does not support IQ balance correction, or the correction could not be retrieved.
+func GetIQBalance(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) (float64, float64, error) {
+	if !sdrD.HasIQBalance(direction, channel) {
+		log.Log(logger.Error, "Attempting to get the IQ balance, but the device does not support IQ balance correction\n")
+		return 0., 0., errors.New("the device does not support IQ balance correction")
+	}
+	balanceI, balanceQ, err := sdrD.GetIQBalance(direction, channel)
+	if err != nil {
+		log.Logf(logger.Error, "Error getting the IQ balance: %s\n", err.Error())
+		return 0., 0., err
+	}
+	log.Logf(logger.Debug, "IQ balance: I: %.3f, Q: %.3f\n", balanceI, balanceQ)
+	return balanceI, balanceQ, nil
+}
+
+// SetIQBalance sets the I and Q balance corrections for the specified direction and channel. Each correction must be
+// between -1.0 and 1.0.
+//
+// Returns an error if the device does not support IQ balance correction, a correction is out of range, or the
+// correction could not be set.
+func SetIQBalance(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint, balanceI, balanceQ float64) error {
+	if !sdrD.HasIQBalance(direction, channel) {
+		log.Log(logger.Error, "Attempting to set the IQ balance, but the device does not support IQ balance correction\n")
+		return errors.New("the device does not support IQ balance correction")
+	}
+	if err := checkCorrection("IQ balance", balanceI, balanceQ); err != nil {
+		log.Logf(logger.Error, "%s\n", err.Error())
+		return err
+	}
+	err := sdrD.SetIQBalance(direction, channel, balanceI, balanceQ)
+	if err != nil {
+		log.Logf(logger.Error, "Could not set the IQ balance to I: %.3f, Q: %.3f: %s\n", balanceI, balanceQ, err.Error())
+	} else {
+		log.Logf(logger.Debug, "Have set the IQ balance to I: %.3f, Q: %.3f\n", balanceI, balanceQ)
+	}
+	return err
+}
+
+// HasFrequencyCorrection returns whether the device supports frequency correction for the specified direction and
+// channel.
+func HasFrequencyCorrection(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) bool {
+	hasCorrection := sdrD.HasFrequencyCorrection(direction, channel)
+	log.Logf(logger.Debug, "Device has frequency correction: %v\n", hasCorrection)
+	return hasCorrection
+}
+
+// GetFrequencyCorrection returns the frequency correction in parts per million for the specified direction and
+// channel.
+//
+// Returns 0.0 if the device does not support frequency correction.
+func GetFrequencyCorrection(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint) float64 {
+	if !sdrD.HasFrequencyCorrection(direction, channel) {
+		return 0.
+	}
+	ppm := sdrD.GetFrequencyCorrection(direction, channel)
+	log.Logf(logger.Debug, "Frequency correction: %.1f PPM\n", ppm)
+	return ppm
+}
+
+// SetFrequencyCorrection sets the frequency correction in parts per million for the specified direction and channel.
+// The correction must be between -MaxFrequencyCorrection and MaxFrequencyCorrection.
+//
+// Returns an error if the device does not support frequency correction, the correction is out of range, or the
+// correction could not be set.
+func SetFrequencyCorrection(sdrD FrontendCorrections, log *logger.Logger, direction device.Direction, channel uint, ppm float64) error {
+	if !sdrD.HasFrequencyCorrection(direction, channel) {
+		log.Log(logger.Error, "Attempting to set the frequency correction, but the device does not support frequency correction\n")
+		return errors.New("the device does not support frequency correction")
+	}
+	if math.IsNaN(ppm) || math.Abs(ppm) > MaxFrequencyCorrection {
+		log.Logf(logger.Error, "Requested frequency correction of %.1f PPM is out of range\n", ppm)
+		return fmt.Errorf("requested frequency correction = %.1f PPM, but must be between %.1f and %.1f PPM",
+			ppm, -MaxFrequencyCorrection, MaxFrequencyCorrection)
+	}
+	err := sdrD.SetFrequencyCorrection(direction, channel, ppm)
+	if err != nil {
+		log.Logf(logger.Error, "Could not set the frequency correction to %.1f PPM: %s\n", ppm, err.Error())
+	} else {
+		log.Logf(logger.Debug, "Have set the frequency correction to %.1f PPM\n", ppm)
+	}
+	return err
+}
+
+// checkCorrection returns an error if either the I or Q value of the named correction is not between -1.0 and 1.0.
+func checkCorrection(name string, i, q float64) error {
+	for _, v := range []float64{i, q} {
+		if math.IsNaN(v) || math.Abs(v) > 1. {
+			return fmt.Errorf("requested %s = I: %.3f, Q: %.3f, but each must be between -1.0 and 1.0", name, i, q)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package sdr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDCOffsetMode(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.True(t, sdr.HasDCOffsetMode(&stub, testLogger, device.DirectionRX, 0))
+	assert.False(t, sdr.GetDCOffsetMode(&stub, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetDCOffsetMode(&stub, testLogger, device.DirectionRX, 0, true))
+	assert.True(t, sdr.GetDCOffsetMode(&stub, testLogger, device.DirectionRX, 0))
+
+	assert.False(t, sdr.HasDCOffsetMode(&stub, testLogger, device.DirectionTX, 0))
+	assert.False(t, sdr.GetDCOffsetMode(&stub, testLogger, device.DirectionTX, 0))
+	err := sdr.SetDCOffsetMode(&stub, testLogger, device.DirectionTX, 0, true)
+	require.NotNil(t, err)
+	assert.Equal(t, "the device does not support automatic DC offset correction", err.Error())
+}
+
+func TestSetDCOffsetMode_Error(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Errors: map[string]error{"SetDCOffsetMode": errors.New("could not set DC offset mode")}}
+	err := sdr.SetDCOffsetMode(&stub, testLogger, device.DirectionRX, 0, true)
+	require.NotNil(t, err)
+	assert.Equal(t, "could not set DC offset mode", err.Error())
+	testLogger.Close()
+	assert.Contains(t, log.String(), "[Error]: Could not set automatic DC offset correction to true: could not set DC offset mode\n")
+}
+
+func TestDCOffset(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.True(t, sdr.HasDCOffset(&stub, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetDCOffset(&stub, testLogger, device.DirectionRX, 0, 0.1, -0.2))
+	offsetI, offsetQ, err := sdr.GetDCOffset(&stub, testLogger, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 0.1, offsetI)
+	assert.Equal(t, -0.2, offsetQ)
+
+	err = sdr.SetDCOffset(&stub, testLogger, device.DirectionRX, 0, 0.1, -1.5)
+	require.NotNil(t, err)
+	assert.Equal(t, "requested DC offset = I: 0.100, Q: -1.500, but each must be between -1.0 and 1.0", err.Error())
+	_, offsetQ, _ = sdr.GetDCOffset(&stub, testLogger, device.DirectionRX, 0)
+	assert.Equal(t, -0.2, offsetQ)
+
+	_, _, err = sdr.GetDCOffset(&stub, testLogger, device.DirectionTX, 0)
+	assert.NotNil(t, err)
+	assert.NotNil(t, sdr.SetDCOffset(&stub, testLogger, device.DirectionTX, 0, 0., 0.))
+}
+
+func TestIQBalance(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.True(t, sdr.HasIQBalance(&stub, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetIQBalance(&stub, testLogger, device.DirectionRX, 0, -0.05, 0.02))
+	balanceI, balanceQ, err := sdr.GetIQBalance(&stub, testLogger, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, -0.05, balanceI)
+	assert.Equal(t, 0.02, balanceQ)
+
+	err = sdr.SetIQBalance(&stub, testLogger, device.DirectionRX, 0, 1.01, 0.)
+	require.NotNil(t, err)
+	assert.Equal(t, "requested IQ balance = I: 1.010, Q: 0.000, but each must be between -1.0 and 1.0", err.Error())
+
+	assert.False(t, sdr.HasIQBalance(&stub, testLogger, device.DirectionTX, 0))
+	assert.NotNil(t, sdr.SetIQBalance(&stub, testLogger, device.DirectionTX, 0, 0., 0.))
+}
+
+func TestFrequencyCorrection(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.True(t, sdr.HasFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, 0., sdr.GetFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0, -42.5))
+	assert.Equal(t, -42.5, sdr.GetFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0, sdr.MaxFrequencyCorrection))
+
+	err := sdr.SetFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0, -200.1)
+	require.NotNil(t, err)
+	assert.Equal(t, "requested frequency correction = -200.1 PPM, but must be between -200.0 and 200.0 PPM", err.Error())
+	assert.Equal(t, sdr.MaxFrequencyCorrection, sdr.GetFrequencyCorrection(&stub, testLogger, device.DirectionRX, 0))
+
+	assert.False(t, sdr.HasFrequencyCorrection(&stub, testLogger, device.DirectionTX, 0))
+	assert.Equal(t, 0., sdr.GetFrequencyCorrection(&stub, testLogger, device.DirectionTX, 0))
+	err = sdr.SetFrequencyCorrection(&stub, testLogger, device.DirectionTX, 0, 1.)
+	require.NotNil(t, err)
+	assert.Equal(t, "the device does not support frequency correction", err.Error())
+}
@@ -1,10 +1,12 @@
 package sdr_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/jimorc/jsdr/internal/logger"
 	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,49 +14,49 @@ import (
 func TestSupportsAGC(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
-	supportsAGC := sdr.SupportsAGC(&stub, testLogger)
+	supportsAGC := sdr.SupportsAGC(&stub, testLogger, device.DirectionRX, 0)
 	assert.True(t, supportsAGC)
 }
 
 func TestAgcIsEnabled(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	agcEnabled := sdr.AgcIsEnabled(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	assert.Nil(t, stub.EnableAgc(device.DirectionRX, 0, true))
+	agcEnabled := sdr.AgcIsEnabled(&stub, testLogger, device.DirectionRX, 0)
 	assert.True(t, agcEnabled)
 }
 
 func TestAgcIsNotEnabled(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	agcEnabled := sdr.AgcIsEnabled(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	agcEnabled := sdr.AgcIsEnabled(&stub, testLogger, device.DirectionRX, 0)
 	assert.False(t, agcEnabled)
 }
 
 func TestEnableAgc_Error(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	// serial number of "1" will return an error
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	err := sdr.EnableAgc(&stub, testLogger, true)
+	stub := sdr.StubDevice{Errors: map[string]error{"EnableAgc": errors.New("could not enable Agc")}}
+	err := sdr.EnableAgc(&stub, testLogger, device.DirectionRX, 0, true)
 	assert.Equal(t, "could not enable Agc", err.Error())
+	assert.False(t, sdr.AgcIsEnabled(&stub, testLogger, device.DirectionRX, 0))
 }
 
 func TestEnableAgc(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	// serial number of "2" will enable Agc
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	err := sdr.EnableAgc(&stub, testLogger, true)
+	stub := sdr.StubDevice{}
+	err := sdr.EnableAgc(&stub, testLogger, device.DirectionRX, 0, true)
 	assert.Nil(t, err)
-	assert.True(t, sdr.AgcIsEnabled(&stub, testLogger))
+	assert.True(t, sdr.AgcIsEnabled(&stub, testLogger, device.DirectionRX, 0))
 
-	err = sdr.EnableAgc(&stub, testLogger, false)
+	err = sdr.EnableAgc(&stub, testLogger, device.DirectionRX, 0, false)
 	assert.Nil(t, err)
-	assert.False(t, sdr.AgcIsEnabled(&stub, testLogger))
+	assert.False(t, sdr.AgcIsEnabled(&stub, testLogger, device.DirectionRX, 0))
 }
 
 func TestGetGainElementNames(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	elements := sdr.GetGainElementNames(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	elements := sdr.GetGainElementNames(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, 2, len(elements))
 
 	assert.Contains(t, elements, "RF")
@@ -63,25 +65,25 @@ func TestGetGainElementNames(t *testing.T) {
 
 func TestGetOverallGain(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	gain := sdr.GetOverallGain(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	gain := sdr.GetOverallGain(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, 50., gain)
 }
 
 func TestSetOverallGain_TooLarge(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
+	stub := sdr.StubDevice{}
 	// attempting to set gain to 50.1 dB but stub only allows values up to 50.0 dB.
-	err := sdr.SetOverallGain(&stub, testLogger, 50.1)
+	err := sdr.SetOverallGain(&stub, testLogger, device.DirectionRX, 0, 50.1)
 	assert.NotNil(t, err)
 	assert.Equal(t, "requested overall gain = 50.1 dB, but must be between 0.0 and 50.0 dB", err.Error())
 }
 
 func TestSetOverallGain_Negative(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
+	stub := sdr.StubDevice{}
 	// attempting to set overall gain to a negative value.
-	err := sdr.SetOverallGain(&stub, testLogger, -2.0)
+	err := sdr.SetOverallGain(&stub, testLogger, device.DirectionRX, 0, -2.0)
 	assert.NotNil(t, err)
 	assert.Equal(t, "requested overall gain = -2.0 dB, but must be between 0.0 and 50.0 dB", err.Error())
 
@@ -89,25 +91,25 @@ func TestSetOverallGain_Negative(t *testing.T) {
 
 func TestSetOverallGain(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	err := sdr.SetOverallGain(&stub, testLogger, 40.)
+	stub := sdr.StubDevice{}
+	err := sdr.SetOverallGain(&stub, testLogger, device.DirectionRX, 0, 40.)
 	assert.Nil(t, err)
-	gain := sdr.GetOverallGain(&stub, testLogger)
+	gain := sdr.GetOverallGain(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, 40.0, gain)
 }
 
 func TestGetElementGain(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	gain, err := sdr.GetElementGain(&stub, testLogger, "RF")
+	stub := sdr.StubDevice{}
+	gain, err := sdr.GetElementGain(&stub, testLogger, device.DirectionRX, 0, "RF")
 	assert.Nil(t, err)
 	assert.Equal(t, 25., gain)
 }
 
 func TestGetElementGain_InvalidElement(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	gain, err := sdr.GetElementGain(&stub, testLogger, "Audio")
+	stub := sdr.StubDevice{}
+	gain, err := sdr.GetElementGain(&stub, testLogger, device.DirectionRX, 0, "Audio")
 	assert.NotNil(t, err)
 	assert.Equal(t, "gain element 'Audio' is invalid", err.Error())
 	assert.Equal(t, 0.0, gain)
@@ -115,8 +117,8 @@ func TestGetElementGain_InvalidElement(t *testing.T) {
 
 func TestGetGainElementRange(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	rfRange, err := sdr.GetElementGainRange(&stub, testLogger, "RF")
+	stub := sdr.StubDevice{}
+	rfRange, err := sdr.GetElementGainRange(&stub, testLogger, device.DirectionRX, 0, "RF")
 	assert.Nil(t, err)
 	assert.Equal(t, 0.0, rfRange.Minimum)
 	assert.Equal(t, 25.0, rfRange.Maximum)
@@ -125,39 +127,39 @@ func TestGetGainElementRange(t *testing.T) {
 
 func TestGetGainElementRange_BadElement(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	_, err := sdr.GetElementGainRange(&stub, testLogger, "Audio")
+	stub := sdr.StubDevice{}
+	_, err := sdr.GetElementGainRange(&stub, testLogger, device.DirectionRX, 0, "Audio")
 	assert.NotNil(t, err)
 	assert.Equal(t, "Gain element name: Audio is invalid\n", err.Error())
 }
 
 func TestSetElementGain(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	err := sdr.SetElementGain(&stub, testLogger, "RF", 22.0)
+	stub := sdr.StubDevice{}
+	err := sdr.SetElementGain(&stub, testLogger, device.DirectionRX, 0, "RF", 22.0)
 	assert.Nil(t, err)
-	gain, _ := sdr.GetElementGain(&stub, testLogger, "RF")
+	gain, _ := sdr.GetElementGain(&stub, testLogger, device.DirectionRX, 0, "RF")
 	assert.Equal(t, 22.0, gain)
 
 }
 
 func TestSetElementGain_InvalidElement(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	err := sdr.SetElementGain(&stub, testLogger, "Audio", 22.0)
+	stub := sdr.StubDevice{}
+	err := sdr.SetElementGain(&stub, testLogger, device.DirectionRX, 0, "Audio", 22.0)
 	assert.NotNil(t, err)
 	assert.Equal(t, "cannot set gain for non-existent gain element: Audio", err.Error())
 }
 
 func TestSetElementGain_InvalidValue(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	err := sdr.SetElementGain(&stub, testLogger, "RF", -1.0)
+	stub := sdr.StubDevice{}
+	err := sdr.SetElementGain(&stub, testLogger, device.DirectionRX, 0, "RF", -1.0)
 	assert.NotNil(t, err)
 	assert.Equal(t, "cannot set gain for element: RF to -1.0. Requested gain is outside the allowable range: 0.0 to 25.0",
 		err.Error())
 
-	err = sdr.SetElementGain(&stub, testLogger, "RF", 25.1)
+	err = sdr.SetElementGain(&stub, testLogger, device.DirectionRX, 0, "RF", 25.1)
 	assert.NotNil(t, err)
 	assert.Equal(t, "cannot set gain for element: RF to 25.1. Requested gain is outside the allowable range: 0.0 to 25.0",
 		err.Error())
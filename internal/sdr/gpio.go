@@ -0,0 +1,141 @@
+package sdr
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jimorc/jsdr/internal/logger"
+)
+
+// GPIOAllPins is the mask that selects all of the pins of a GPIO bank.
+const GPIOAllPins uint32 = 0xffffffff
+
+// GPIO interface specifies the methods for reading and writing an SDR's general purpose input/output banks. Each bit
+// of a bank's value and direction is one pin. A direction bit of 1 makes the pin an output, and 0 makes it an input.
+type GPIO interface {
+	GetGPIOBanks() []string
+	WriteGPIO(string, uint32) error
+	WriteGPIOMasked(string, uint32, uint32) error
+	ReadGPIO(string) (uint32, error)
+	WriteGPIODir(string, uint32) error
+	WriteGPIODirMasked(string, uint32, uint32) error
+	ReadGPIODir(string) (uint32, error)
+}
+
+// GetGPIOBanks returns the names of the device's GPIO banks. Devices without GPIO return no banks.
+func GetGPIOBanks(sdrD GPIO, log *logger.Logger) []string {
+	banks := sdrD.GetGPIOBanks()
+	log.Logf(logger.Debug, "GPIO banks: %v\n", banks)
+	return banks
+}
+
+// ReadGPIO reads the value of the pins of the named GPIO bank.
+//
+// Returns an error if the device does not have the bank, or the bank could not be read.
+func ReadGPIO(sdrD GPIO, log *logger.Logger, bank string) (uint32, error) {
+	if err := validateGPIOBank(sdrD, log, bank); err != nil {
+		return 0, err
+	}
+	value, err := sdrD.ReadGPIO(bank)
+	if err != nil {
+		log.Logf(logger.Error, "Could not read GPIO bank %s: %s\n", bank, err.Error())
+		return 0, err
+	}
+	log.Logf(logger.Debug, "GPIO bank %s: %#08x\n", bank, value)
+	return value, nil
+}
+
+// WriteGPIO writes value to the pins of the named GPIO bank that are selected by mask. The other pins are not
+// changed. Pass GPIOAllPins to write all of the pins.
+//
+// Returns an error if the device does not have the bank, or the bank could not be written.
+func WriteGPIO(sdrD GPIO, log *logger.Logger, bank string, value uint32, mask uint32) error {
+	if err := validateGPIOBank(sdrD, log, bank); err != nil {
+		return err
+	}
+	var err error
+	if mask == GPIOAllPins {
+		err = sdrD.WriteGPIO(bank, value)
+	} else {
+		err = sdrD.WriteGPIOMasked(bank, value, mask)
+	}
+	if err != nil {
+		log.Logf(logger.Error, "Could not write %#08x with mask %#08x to GPIO bank %s: %s\n", value, mask, bank,
+			err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have written %#08x with mask %#08x to GPIO bank %s\n", value, mask, bank)
+	return nil
+}
+
+// ReadGPIODirection reads the direction of the pins of the named GPIO bank. Bits that are 1 are outputs.
+//
+// Returns an error if the device does not have the bank, or the bank's direction could not be read.
+func ReadGPIODirection(sdrD GPIO, log *logger.Logger, bank string) (uint32, error) {
+	if err := validateGPIOBank(sdrD, log, bank); err != nil {
+		return 0, err
+	}
+	dir, err := sdrD.ReadGPIODir(bank)
+	if err != nil {
+		log.Logf(logger.Error, "Could not read the direction of GPIO bank %s: %s\n", bank, err.Error())
+		return 0, err
+	}
+	log.Logf(logger.Debug, "GPIO bank %s direction: %#08x\n", bank, dir)
+	return dir, nil
+}
+
+// WriteGPIODirection sets the direction of the pins of the named GPIO bank that are selected by mask. Bits of dir
+// that are 1 make the pins outputs, and bits that are 0 make them inputs. Pass GPIOAllPins to set the direction of
+// all of the pins.
+//
+// Returns an error if the device does not have the bank, or the bank's direction could not be written.
+func WriteGPIODirection(sdrD GPIO, log *logger.Logger, bank string, dir uint32, mask uint32) error {
+	if err := validateGPIOBank(sdrD, log, bank); err != nil {
+		return err
+	}
+	var err error
+	if mask == GPIOAllPins {
+		err = sdrD.WriteGPIODir(bank, dir)
+	} else {
+		err = sdrD.WriteGPIODirMasked(bank, dir, mask)
+	}
+	if err != nil {
+		log.Logf(logger.Error, "Could not write direction %#08x with mask %#08x to GPIO bank %s: %s\n", dir, mask,
+			bank, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have written direction %#08x with mask %#08x to GPIO bank %s\n", dir, mask, bank)
+	return nil
+}
+
+// SetGPIOOutput makes a pin of the named GPIO bank an output, and drives it high or low, for example to switch an
+// external LNA or filter. The other pins are not changed.
+//
+// Returns an error if the device does not have the bank, pin is not between 0 and 31, or the bank could not be
+// written.
+func SetGPIOOutput(sdrD GPIO, log *logger.Logger, bank string, pin uint, high bool) error {
+	if pin > 31 {
+		log.Logf(logger.Error, "Attempting to set GPIO pin %d, but GPIO pins are numbered 0 to 31\n", pin)
+		return fmt.Errorf("GPIO pin %d is invalid", pin)
+	}
+	mask := uint32(1) << pin
+	if err := WriteGPIODirection(sdrD, log, bank, mask, mask); err != nil {
+		return err
+	}
+	var value uint32
+	if high {
+		value = mask
+	}
+	return WriteGPIO(sdrD, log, bank, value, mask)
+}
+
+// validateGPIOBank returns an error if the device does not have the named GPIO bank.
+func validateGPIOBank(sdrD GPIO, log *logger.Logger, bank string) error {
+	banks := sdrD.GetGPIOBanks()
+	if !slices.Contains(banks, bank) {
+		log.Logf(logger.Error, "Attempting to access GPIO bank: %s, but that bank does not exist.\n"+
+			"GPIO banks are: %v\n", bank, banks)
+		return fmt.Errorf("GPIO bank '%s' does not exist", bank)
+	}
+	return nil
+}
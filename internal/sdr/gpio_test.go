@@ -0,0 +1,100 @@
+package sdr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ sdr.GPIO = &sdr.SoapyDevice{}
+	_ sdr.GPIO = &sdr.StubDevice{}
+)
+
+func TestGetGPIOBanks(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, []string{"MAIN", "AUX"}, sdr.GetGPIOBanks(&stub, testLogger))
+}
+
+func TestWriteGPIO(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.WriteGPIODirection(&stub, testLogger, "MAIN", 0x0f, sdr.GPIOAllPins))
+	require.Nil(t, sdr.WriteGPIO(&stub, testLogger, "MAIN", 0xff, sdr.GPIOAllPins))
+	// Only the output pins read the values that were written.
+	value, err := sdr.ReadGPIO(&stub, testLogger, "MAIN")
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0x0f), value)
+
+	require.Nil(t, sdr.WriteGPIODirection(&stub, testLogger, "MAIN", 0xf0, 0x30))
+	dir, err := sdr.ReadGPIODirection(&stub, testLogger, "MAIN")
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0x3f), dir)
+	require.Nil(t, sdr.WriteGPIO(&stub, testLogger, "MAIN", 0x00, 0x05))
+	value, err = sdr.ReadGPIO(&stub, testLogger, "MAIN")
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0x3a), value)
+	assert.Equal(t, 1, stub.CallCount("WriteGPIO"))
+	assert.Equal(t, 1, stub.CallCount("WriteGPIOMasked"))
+	assert.Equal(t, 1, stub.CallCount("WriteGPIODir"))
+	assert.Equal(t, 1, stub.CallCount("WriteGPIODirMasked"))
+
+	// Other banks are not changed.
+	value, err = sdr.ReadGPIO(&stub, testLogger, "AUX")
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0), value)
+}
+
+func TestGPIO_InvalidBank(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	_, err := sdr.ReadGPIO(&stub, testLogger, "GPIO9")
+	require.NotNil(t, err)
+	assert.Equal(t, "GPIO bank 'GPIO9' does not exist", err.Error())
+	assert.NotNil(t, sdr.WriteGPIO(&stub, testLogger, "GPIO9", 1, 1))
+	_, err = sdr.ReadGPIODirection(&stub, testLogger, "GPIO9")
+	assert.NotNil(t, err)
+	assert.NotNil(t, sdr.WriteGPIODirection(&stub, testLogger, "GPIO9", 1, 1))
+	assert.NotNil(t, sdr.SetGPIOOutput(&stub, testLogger, "GPIO9", 0, true))
+	// Invalid banks are not passed to the device.
+	assert.Equal(t, 0, stub.CallCount("ReadGPIO")+stub.CallCount("WriteGPIOMasked")+
+		stub.CallCount("ReadGPIODir")+stub.CallCount("WriteGPIODirMasked"))
+}
+
+func TestGPIO_Errors(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{Errors: map[string]error{
+		"ReadGPIO":        errors.New("bank is busy"),
+		"WriteGPIOMasked": errors.New("bank is read only"),
+	}}
+	_, err := sdr.ReadGPIO(&stub, testLogger, "MAIN")
+	require.NotNil(t, err)
+	assert.Equal(t, "bank is busy", err.Error())
+	err = sdr.SetGPIOOutput(&stub, testLogger, "MAIN", 3, true)
+	require.NotNil(t, err)
+	assert.Equal(t, "bank is read only", err.Error())
+}
+
+func TestSetGPIOOutput(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.SetGPIOOutput(&stub, testLogger, "AUX", 2, true))
+	require.Nil(t, sdr.SetGPIOOutput(&stub, testLogger, "AUX", 5, true))
+	require.Nil(t, sdr.SetGPIOOutput(&stub, testLogger, "AUX", 2, false))
+	dir, err := sdr.ReadGPIODirection(&stub, testLogger, "AUX")
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0x24), dir)
+	value, err := sdr.ReadGPIO(&stub, testLogger, "AUX")
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0x20), value)
+
+	err = sdr.SetGPIOOutput(&stub, testLogger, "AUX", 32, true)
+	require.NotNil(t, err)
+	assert.Equal(t, "GPIO pin 32 is invalid", err.Error())
+}
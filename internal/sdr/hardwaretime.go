@@ -0,0 +1,98 @@
+package sdr
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jimorc/jsdr/internal/logger"
+)
+
+// The hardware times that may be passed to the HardwareTime methods.
+const (
+	// TimeNow is the device's current hardware time.
+	TimeNow = ""
+	// TimeNextPPS sets the hardware time at the next pulse on the device's PPS (pulse per second) input, so that the
+	// time is aligned with the PPS signal. It is only passed to SetHardwareTime.
+	TimeNextPPS = "PPS"
+)
+
+// HardwareTime interface specifies the methods for an SDR's time source and hardware time.
+type HardwareTime interface {
+	GetTimeSources() []string
+	GetTimeSource() string
+	SetTimeSource(string) error
+	HasHardwareTime(string) bool
+	GetHardwareTime(string) uint
+	SetHardwareTime(uint, string) error
+}
+
+// GetTimeSources returns the names of the device's time sources, for example "internal" and "gpsdo".
+func GetTimeSources(sdrD HardwareTime, log *logger.Logger) []string {
+	sources := sdrD.GetTimeSources()
+	log.Logf(logger.Debug, "Time sources: %v\n", sources)
+	return sources
+}
+
+// GetTimeSource returns the name of the device's current time source.
+func GetTimeSource(sdrD HardwareTime, log *logger.Logger) string {
+	source := sdrD.GetTimeSource()
+	log.Logf(logger.Debug, "Time source: %s\n", source)
+	return source
+}
+
+// SetTimeSource selects the device's time source.
+//
+// Returns an error if the device does not have the named time source, or the time source could not be selected.
+func SetTimeSource(sdrD HardwareTime, log *logger.Logger, source string) error {
+	sources := sdrD.GetTimeSources()
+	if !slices.Contains(sources, source) {
+		log.Logf(logger.Error, "Attempting to select time source: %s, but that time source does not exist.\n"+
+			"Time sources are: %v\n", source, sources)
+		return fmt.Errorf("cannot select non-existent time source: %s", source)
+	}
+	if err := sdrD.SetTimeSource(source); err != nil {
+		log.Logf(logger.Error, "Could not select time source %s: %s\n", source, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have selected time source %s\n", source)
+	return nil
+}
+
+// HasHardwareTime returns whether the device has the specified hardware time. Pass TimeNow for the device's
+// main hardware time.
+func HasHardwareTime(sdrD HardwareTime, log *logger.Logger, what string) bool {
+	hasTime := sdrD.HasHardwareTime(what)
+	log.Logf(logger.Debug, "Device has hardware time '%s': %v\n", what, hasTime)
+	return hasTime
+}
+
+// GetHardwareTime returns the specified hardware time of the device in nanoseconds. Pass TimeNow for the device's
+// current hardware time.
+//
+// Returns an error if the device does not have the hardware time.
+func GetHardwareTime(sdrD HardwareTime, log *logger.Logger, what string) (uint, error) {
+	if !sdrD.HasHardwareTime(what) {
+		log.Logf(logger.Error, "Attempting to get hardware time '%s', but the device does not have that time\n", what)
+		return 0, fmt.Errorf("the device does not have hardware time '%s'", what)
+	}
+	timeNs := sdrD.GetHardwareTime(what)
+	log.Logf(logger.Debug, "Hardware time '%s': %d ns\n", what, timeNs)
+	return timeNs, nil
+}
+
+// SetHardwareTime sets the specified hardware time of the device in nanoseconds. Pass TimeNow to set the time
+// immediately, or TimeNextPPS to set the time at the next PPS pulse.
+//
+// Returns an error if the device does not have the hardware time, or the time could not be set.
+func SetHardwareTime(sdrD HardwareTime, log *logger.Logger, timeNs uint, what string) error {
+	if !sdrD.HasHardwareTime(what) {
+		log.Logf(logger.Error, "Attempting to set hardware time '%s', but the device does not have that time\n", what)
+		return fmt.Errorf("the device does not have hardware time '%s'", what)
+	}
+	if err := sdrD.SetHardwareTime(timeNs, what); err != nil {
+		log.Logf(logger.Error, "Could not set hardware time '%s' to %d ns: %s\n", what, timeNs, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have set hardware time '%s' to %d ns\n", what, timeNs)
+	return nil
+}
@@ -0,0 +1,50 @@
+package sdr_test
+
+import (
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeSource(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, []string{"internal", "gpsdo"}, sdr.GetTimeSources(&stub, testLogger))
+	assert.Equal(t, "internal", sdr.GetTimeSource(&stub, testLogger))
+	require.Nil(t, sdr.SetTimeSource(&stub, testLogger, "gpsdo"))
+	assert.Equal(t, "gpsdo", sdr.GetTimeSource(&stub, testLogger))
+
+	err := sdr.SetTimeSource(&stub, testLogger, "external")
+	require.NotNil(t, err)
+	assert.Equal(t, "cannot select non-existent time source: external", err.Error())
+}
+
+func TestHardwareTime(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.True(t, sdr.HasHardwareTime(&stub, testLogger, sdr.TimeNow))
+	timeNs, err := sdr.GetHardwareTime(&stub, testLogger, sdr.TimeNow)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), timeNs)
+
+	require.Nil(t, sdr.SetHardwareTime(&stub, testLogger, 3000000000, sdr.TimeNextPPS))
+	timeNs, err = sdr.GetHardwareTime(&stub, testLogger, sdr.TimeNow)
+	require.Nil(t, err)
+	// The stub's hardware time advances in real time once it has been set.
+	assert.GreaterOrEqual(t, timeNs, uint(3000000000))
+	assert.Less(t, timeNs, uint(4000000000))
+}
+
+func TestHardwareTime_NotSupported(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.False(t, sdr.HasHardwareTime(&stub, testLogger, "RX"))
+	_, err := sdr.GetHardwareTime(&stub, testLogger, "RX")
+	require.NotNil(t, err)
+	assert.Equal(t, "the device does not have hardware time 'RX'", err.Error())
+	assert.NotNil(t, sdr.SetHardwareTime(&stub, testLogger, 0, "RX"))
+}
@@ -0,0 +1,6 @@
+//go:build !race
+
+package sdr_test
+
+// raceEnabled is true if the tests were built with the race detector.
+const raceEnabled = false
@@ -0,0 +1,306 @@
+package sdr
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// defaultReceiverTimeoutUs is the read timeout used by a Receiver.
+const defaultReceiverTimeoutUs = 100000
+
+// receiverBlockBufferSize is the number of blocks that may be queued on a Receiver's block channel.
+const receiverBlockBufferSize = 16
+
+// Block is a block of samples that has been read by a Receiver.
+type Block struct {
+	// Samples contains the samples for each of the receiver's channels.
+	Samples [][]complex64
+	// TimeNs is the hardware timestamp of the first sample, valid only if Flags contains StreamFlagHasTime.
+	TimeNs uint
+	// Flags are the stream flags returned when the block was read.
+	Flags int
+	// Sequence is the sequence number of the block, starting at 0.
+	Sequence uint64
+	// Discontinuity is true if samples were lost between the previous block and this one.
+	Discontinuity bool
+	// Tags are the device settings that the block was received with. Tags are only set if the receiver has a
+	// StreamTagger.
+	Tags StreamTags
+	// Retuned is true if the settings in Tags took effect with this block, for example because the device was tuned
+	// to a new frequency. Consumers such as spectrum averaging should discard what they have accumulated from
+	// earlier blocks.
+	Retuned bool
+
+	buffer *Buffer[complex64]
+	pool   *BufferPool[complex64]
+	// generation is the buffer's generation when the block was read. The buffer has been released by another copy
+	// of the block if its generation has changed.
+	generation uint64
+}
+
+// Release returns the block's sample buffers to the receiver so that they can be reused for later blocks.
+//
+// Release should be called once the block's samples are no longer needed. Copies of a Block share its sample
+// buffers, so the block is released by calling Release on any one of the copies; calling Release again, on the same
+// block or on another copy, has no effect. The samples of the block and of all of its copies must not be used after
+// Release has been called. Blocks that are not released are garbage collected as normal.
+func (block *Block) Release() {
+	if block.pool != nil {
+		if block.buffer.generation.CompareAndSwap(block.generation, block.generation+1) {
+			block.pool.Put(block.buffer)
+		}
+		block.buffer, block.pool, block.Samples = nil, nil, nil
+	}
+}
+
+// ReceiverStats contains the counts that a Receiver accumulates while it is running.
+type ReceiverStats struct {
+	Blocks        uint64
+	Samples       uint64
+	Overflows     uint64
+	TimestampGaps uint64
+	Timeouts      uint64
+	Retunes       uint64
+}
+
+// Receiver continuously reads blocks of samples from a SampleSource on its own go routine.
+//
+// Blocks are passed to a callback, or published on the channel returned by Blocks if there is no callback.
+// Block sample buffers come from a BufferPool, so a receiver whose blocks are released does not allocate memory
+// once it is running.
+type Receiver struct {
+	source     SampleSource
+	log        *logger.Logger
+	sampleRate float64
+	callback   func(Block)
+	monitor    *StreamMonitor
+	tagger     *StreamTagger
+	blocks     chan Block
+	done       chan struct{}
+
+	mutex sync.Mutex
+	stats ReceiverStats
+	err   error
+}
+
+// NewReceiver creates a Receiver that reads from source.
+//
+// Params:
+//   - source: the sample source to read from. The Receiver takes ownership of source and closes it when the
+//     Receiver stops.
+//   - sampleRate: the sample rate in samples per second. This is used to detect gaps between block timestamps.
+//     Pass 0.0 to disable timestamp gap detection.
+//   - callback: called on the receiver's go routine for each block that is read. If callback is nil, blocks
+//     are published on the channel returned by Blocks. In either case, call Block.Release once the block's
+//     samples are no longer needed.
+//
+// If source does not have a StreamMonitor, the Receiver attaches one for sampleRate, so that the statistics of the
+// stream can be retrieved with StreamStats.
+func NewReceiver(source SampleSource, log *logger.Logger, sampleRate float64, callback func(Block)) *Receiver {
+	if source.Monitor() == nil {
+		source.SetMonitor(NewStreamMonitor(sampleRate))
+	}
+	receiver := &Receiver{source: source, log: log, sampleRate: sampleRate, callback: callback,
+		monitor: source.Monitor(), done: make(chan struct{})}
+	if callback == nil {
+		receiver.blocks = make(chan Block, receiverBlockBufferSize)
+	}
+	return receiver
+}
+
+// SetStreamTagger sets the tagger that holds the device settings that blocks are tagged with. The tagger's sample
+// rate replaces the one that was passed to NewReceiver. When the tagger's sample rate changes, the receiver uses the
+// new rate to detect timestamp gaps, and its StreamMonitor is reset. SetStreamTagger must be called before Start.
+func (r *Receiver) SetStreamTagger(tagger *StreamTagger) {
+	r.tagger = tagger
+	if tagger == nil {
+		return
+	}
+	if rate := tagger.Tags().SampleRate; rate > 0 && rate != r.sampleRate {
+		r.sampleRate = rate
+		r.monitor.SetSampleRate(rate)
+	}
+}
+
+// Start activates the sample source and starts reading blocks on a new go routine.
+//
+// The receiver stops when ctx is cancelled or a read error other than a timeout or an overflow occurs.
+// When the receiver stops, the source is deactivated and closed, and the blocks channel is closed.
+func (r *Receiver) Start(ctx context.Context) error {
+	err := r.source.Activate(r.log, 0, 0, 0)
+	if err != nil {
+		r.log.Logf(logger.Error, "Receiver could not activate its sample source: %s\n", err.Error())
+		r.source.Close(r.log)
+		close(r.done)
+		if r.blocks != nil {
+			close(r.blocks)
+		}
+		return err
+	}
+	r.log.Log(logger.Debug, "Receiver started.\n")
+	go r.run(ctx)
+	return nil
+}
+
+// Blocks returns the channel that blocks are published on. Blocks returns nil if the receiver has a callback.
+func (r *Receiver) Blocks() <-chan Block {
+	return r.blocks
+}
+
+// Done returns a channel that is closed when the receiver has stopped.
+func (r *Receiver) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait waits for the receiver to stop.
+//
+// Returns the read error that stopped the receiver, or nil if the receiver was stopped by its context.
+func (r *Receiver) Wait() error {
+	<-r.done
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// Stats returns a copy of the receiver's current statistics.
+func (r *Receiver) Stats() ReceiverStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.stats
+}
+
+// StreamStats returns the statistics that the source's StreamMonitor has accumulated. The BufferFill is the fill of
+// the blocks channel when the last block was published, and is always 0.0 if the receiver has a callback.
+func (r *Receiver) StreamStats() StreamStats {
+	return r.monitor.Stats()
+}
+
+// Monitor returns the StreamMonitor of the receiver's source, for example to publish its statistics.
+func (r *Receiver) Monitor() *StreamMonitor {
+	return r.monitor
+}
+
+// run is the receiver's read loop.
+func (r *Receiver) run(ctx context.Context) {
+	defer r.shutdown()
+	mtu := r.source.GetMTU(r.log)
+	pool := NewBufferPool[complex64](len(r.source.Channels()), int(mtu))
+	var sequence uint64
+	var lastTimeNs, lastNumSamples uint
+	haveLastTime := false
+	discontinuity := false
+	var tags StreamTags
+	if r.tagger != nil {
+		tags = r.tagger.Tags()
+	}
+	var flags [1]int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		buffer := pool.Get()
+		samples := buffer.Data
+		flags[0] = 0
+		timeNs, numRead, err := r.source.ReadSamples(r.log, samples, mtu, &flags, defaultReceiverTimeoutUs)
+		if err != nil {
+			pool.Put(buffer)
+		}
+		switch {
+		case errors.Is(err, ErrStreamTimeout):
+			r.count(func(stats *ReceiverStats) { stats.Timeouts++ })
+			continue
+		case errors.Is(err, ErrStreamOverflow):
+			r.count(func(stats *ReceiverStats) { stats.Overflows++ })
+			discontinuity = true
+			haveLastTime = false
+			continue
+		case err != nil:
+			r.mutex.Lock()
+			r.err = err
+			r.mutex.Unlock()
+			return
+		}
+		if flags[0]&int(device.StreamFlagEndAbrupt) != 0 {
+			r.count(func(stats *ReceiverStats) { stats.Overflows++ })
+			discontinuity = true
+		}
+		hasTime := flags[0]&int(device.StreamFlagHasTime) != 0
+		retuned := false
+		if r.tagger != nil {
+			latest := r.tagger.Tags()
+			if latest.Generation != tags.Generation && !latest.receivedBefore(timeNs, hasTime, numRead, r.sampleRate) {
+				retuned = true
+				if latest.SampleRate > 0 && latest.SampleRate != r.sampleRate {
+					r.sampleRate = latest.SampleRate
+					r.monitor.SetSampleRate(latest.SampleRate)
+					// The previous block's timestamp cannot be compared with this one's at the new rate.
+					haveLastTime = false
+				}
+				tags = latest
+				r.count(func(stats *ReceiverStats) { stats.Retunes++ })
+			}
+		}
+		if hasTime && haveLastTime && timestampGap(r.sampleRate, lastTimeNs, lastNumSamples, timeNs) {
+			r.count(func(stats *ReceiverStats) { stats.TimestampGaps++ })
+			discontinuity = true
+		}
+		haveLastTime = hasTime
+		lastTimeNs, lastNumSamples = timeNs, numRead
+
+		for ch := range samples {
+			samples[ch] = samples[ch][:numRead]
+		}
+		block := Block{Samples: samples, TimeNs: timeNs, Flags: flags[0], Sequence: sequence,
+			Discontinuity: discontinuity, Tags: tags, Retuned: retuned, buffer: buffer, pool: pool,
+			generation: buffer.generation.Load()}
+		sequence++
+		discontinuity = false
+		r.count(func(stats *ReceiverStats) {
+			stats.Blocks++
+			stats.Samples += uint64(numRead)
+		})
+		if r.callback != nil {
+			r.callback(block)
+		} else {
+			select {
+			case r.blocks <- block:
+				r.monitor.recordBufferFill(len(r.blocks), cap(r.blocks))
+			case <-ctx.Done():
+				block.Release()
+				return
+			}
+		}
+	}
+}
+
+// count updates the receiver's statistics while holding the receiver's mutex.
+func (r *Receiver) count(update func(*ReceiverStats)) {
+	r.mutex.Lock()
+	update(&r.stats)
+	r.mutex.Unlock()
+}
+
+// shutdown deactivates and closes the sample source, and signals that the receiver has stopped.
+func (r *Receiver) shutdown() {
+	if err := r.source.Deactivate(r.log, 0, 0); err != nil {
+		r.log.Logf(logger.Error, "Receiver could not deactivate its sample source: %s\n", err.Error())
+	}
+	if err := r.source.Close(r.log); err != nil {
+		r.log.Logf(logger.Error, "Receiver could not close its sample source: %s\n", err.Error())
+	}
+	stats := r.Stats()
+	r.log.Logf(logger.Debug, "Receiver stopped. Blocks: %d, samples: %d, overflows: %d, timestamp gaps: %d\n",
+		stats.Blocks, stats.Samples, stats.Overflows, stats.TimestampGaps)
+	r.log.Logf(logger.Debug, "Receiver stream statistics: %s\n", r.StreamStats().String())
+	if r.blocks != nil {
+		close(r.blocks)
+	}
+	close(r.done)
+}
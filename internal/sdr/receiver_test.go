@@ -0,0 +1,212 @@
+package sdr_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiver_Blocks(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Errors: map[string]error{"DeactivateCS8Stream": errors.New("bad device")}}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0, 1})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	assert.True(t, source.IsActive())
+
+	for i := 0; i < 3; i++ {
+		block := <-receiver.Blocks()
+		assert.Equal(t, uint64(i), block.Sequence)
+		require.Equal(t, 2, len(block.Samples))
+		assert.Equal(t, 10000, len(block.Samples[0]))
+		assert.Equal(t, complex64(complex(-2./128., 0)), block.Samples[0][0])
+		assert.Equal(t, complex64(complex(-4./128., 0)), block.Samples[1][0])
+		assert.False(t, block.Discontinuity)
+	}
+	cancel()
+	for range receiver.Blocks() {
+	}
+	assert.Nil(t, receiver.Wait())
+	stats := receiver.Stats()
+	assert.GreaterOrEqual(t, stats.Blocks, uint64(3))
+	assert.Equal(t, stats.Blocks*10000, stats.Samples)
+	assert.Equal(t, uint64(0), stats.Overflows)
+	testLogger.Close()
+	assert.Contains(t, log.String(), "Receiver could not deactivate its sample source: bad device")
+}
+
+func TestReceiver_Callback(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var blocks []sdr.Block
+	receiver := sdr.NewReceiver(source, testLogger, 0., func(block sdr.Block) {
+		blocks = append(blocks, block)
+		if len(blocks) == 3 {
+			cancel()
+		}
+	})
+	assert.Nil(t, receiver.Blocks())
+	require.Nil(t, receiver.Start(ctx))
+	assert.Nil(t, receiver.Wait())
+	require.Equal(t, 3, len(blocks))
+	assert.Equal(t, uint64(2), blocks[2].Sequence)
+	assert.Equal(t, uint64(3), receiver.Stats().Blocks)
+}
+
+func TestReceiver_Overflow(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{{Err: sdr.ErrStreamOverflow, Flags: device.StreamFlagEndAbrupt}},
+		LoopReads: true}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	assert.Eventually(t, func() bool { return receiver.Stats().Overflows >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	<-receiver.Done()
+	// Overflows are counted; they do not stop the receiver.
+	assert.Nil(t, receiver.Wait())
+	_, open := <-receiver.Blocks()
+	assert.False(t, open)
+	assert.Equal(t, uint64(0), receiver.Stats().Blocks)
+}
+
+func TestReceiver_TimestampGaps(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	// Every fourth read follows a 1 ms gap.
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{{}, {}, {}, {GapNs: 1000000}}, LoopReads: true}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 2e6, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	var blocks []sdr.Block
+	for i := 0; i < 9; i++ {
+		blocks = append(blocks, <-receiver.Blocks())
+	}
+	cancel()
+	assert.Nil(t, receiver.Wait())
+
+	discontinuities := 0
+	for _, block := range blocks {
+		assert.NotZero(t, block.Flags&int(device.StreamFlagHasTime))
+		if block.Discontinuity {
+			discontinuities++
+		}
+	}
+	assert.Equal(t, 2, discontinuities)
+	assert.GreaterOrEqual(t, receiver.Stats().TimestampGaps, uint64(2))
+	assert.Equal(t, uint64(0), receiver.Stats().Overflows)
+}
+
+func TestReceiver_CancelledBeforeStart(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Nil(t, receiver.Start(ctx))
+	assert.Nil(t, receiver.Wait())
+	assert.False(t, source.IsActive())
+	assert.Equal(t, uint64(0), receiver.Stats().Blocks)
+}
+
+func TestReceiver_ReleasedBlocksAreReused(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	block := <-receiver.Blocks()
+	require.Equal(t, 10000, len(block.Samples[0]))
+	block.Release()
+	assert.Nil(t, block.Samples)
+	// Releasing a block more than once has no effect.
+	block.Release()
+	cancel()
+	for block := range receiver.Blocks() {
+		block.Release()
+	}
+	assert.Nil(t, receiver.Wait())
+}
+
+func TestReceiver_ReleaseCopiedBlock(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	block := <-receiver.Blocks()
+	blockCopy := block
+	block.Release()
+	// The copy shares the released buffer, so releasing it must not return the buffer to the pool again.
+	blockCopy.Release()
+	assert.Nil(t, blockCopy.Samples)
+	// If the buffer had been returned twice, two of the blocks that are held here would share it.
+	held := make(map[*complex64]uint64)
+	for range 40 {
+		block := <-receiver.Blocks()
+		first := &block.Samples[0][0]
+		if sequence, ok := held[first]; ok {
+			assert.Failf(t, "blocks share a buffer", "blocks %d and %d", sequence, block.Sequence)
+		}
+		held[first] = block.Sequence
+	}
+	cancel()
+	for block := range receiver.Blocks() {
+		block.Release()
+	}
+	assert.Nil(t, receiver.Wait())
+}
+
+func BenchmarkReceiver(b *testing.B) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(b, err)
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(b, receiver.Start(ctx))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		block := <-receiver.Blocks()
+		block.Release()
+	}
+	b.StopTimer()
+	cancel()
+	for block := range receiver.Blocks() {
+		block.Release()
+	}
+	receiver.Wait()
+}
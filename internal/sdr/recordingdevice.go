@@ -0,0 +1,538 @@
+package sdr
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// RecordingDevice sits in front of a device, such as a SoapyDevice, and records every call that is made to it, with
+// its arguments and results, in a Session. Save the session to capture how a particular SDR responds, so that tests
+// can be run against it with a ReplayDevice.
+//
+// Each of the SessionDevice methods calls the same method of the recorded device, records the call, and returns the
+// recorded device's results.
+//
+// Use NewRecordingDevice to create a RecordingDevice. A RecordingDevice may be used concurrently on multiple go
+// routines if the device that it records may be.
+type RecordingDevice struct {
+	sdrD SessionDevice
+
+	mutex sync.Mutex
+	calls []SessionCall
+}
+
+// NewRecordingDevice creates a RecordingDevice that records the calls that are made to sdrD.
+func NewRecordingDevice(sdrD SessionDevice) *RecordingDevice {
+	return &RecordingDevice{sdrD: sdrD}
+}
+
+// Session returns the calls that have been recorded.
+func (dev *RecordingDevice) Session() *Session {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return &Session{Calls: slices.Clone(dev.calls)}
+}
+
+// Enumerate returns the devices that the recorded device enumerates.
+func (dev *RecordingDevice) Enumerate(args map[string]string) []map[string]string {
+	devices := dev.sdrD.Enumerate(args)
+	dev.record("Enumerate", []any{args}, nil, devices)
+	return devices
+}
+
+// Make makes the recorded device.
+func (dev *RecordingDevice) Make(args map[string]string) error {
+	err := dev.sdrD.Make(args)
+	dev.record("Make", []any{args}, err)
+	return err
+}
+
+// Unmake unmakes the recorded device.
+func (dev *RecordingDevice) Unmake() error {
+	err := dev.sdrD.Unmake()
+	dev.record("Unmake", nil, err)
+	return err
+}
+
+// GetHardwareKey retrieves the hardware key of the recorded device.
+func (dev *RecordingDevice) GetHardwareKey() string {
+	key := dev.sdrD.GetHardwareKey()
+	dev.record("GetHardwareKey", nil, nil, key)
+	return key
+}
+
+// GetNumChannels retrieves the number of channels for the specified direction.
+func (dev *RecordingDevice) GetNumChannels(direction device.Direction) uint {
+	numChannels := dev.sdrD.GetNumChannels(direction)
+	dev.record("GetNumChannels", []any{direction}, nil, numChannels)
+	return numChannels
+}
+
+// GetFrequencyRanges retrieves the frequency ranges.
+func (dev *RecordingDevice) GetFrequencyRanges(direction device.Direction, channel uint) []device.SDRRange {
+	ranges := dev.sdrD.GetFrequencyRanges(direction, channel)
+	dev.record("GetFrequencyRanges", []any{direction, channel}, nil, ranges)
+	return ranges
+}
+
+// GetOverallCenterFrequency retrieves the overall center frequency.
+func (dev *RecordingDevice) GetOverallCenterFrequency(direction device.Direction, channel uint) float64 {
+	freq := dev.sdrD.GetOverallCenterFrequency(direction, channel)
+	dev.record("GetOverallCenterFrequency", []any{direction, channel}, nil, freq)
+	return freq
+}
+
+// SetOverallCenterFrequency sets the overall center frequency.
+func (dev *RecordingDevice) SetOverallCenterFrequency(direction device.Direction, channel uint, freq float64,
+	args map[string]string) error {
+	err := dev.sdrD.SetOverallCenterFrequency(direction, channel, freq, args)
+	dev.record("SetOverallCenterFrequency", []any{direction, channel, freq, args}, err)
+	return err
+}
+
+// GetTunableElementNames retrieves the names of the tunable elements.
+func (dev *RecordingDevice) GetTunableElementNames(direction device.Direction, channel uint) []string {
+	names := dev.sdrD.GetTunableElementNames(direction, channel)
+	dev.record("GetTunableElementNames", []any{direction, channel}, nil, names)
+	return names
+}
+
+// GetTunableElementFrequencyRanges retrieves the frequency ranges of the named tunable element.
+func (dev *RecordingDevice) GetTunableElementFrequencyRanges(direction device.Direction, channel uint,
+	name string) []device.SDRRange {
+	ranges := dev.sdrD.GetTunableElementFrequencyRanges(direction, channel, name)
+	dev.record("GetTunableElementFrequencyRanges", []any{direction, channel, name}, nil, ranges)
+	return ranges
+}
+
+// GetTunableElementFrequency retrieves the frequency of the named tunable element.
+func (dev *RecordingDevice) GetTunableElementFrequency(direction device.Direction, channel uint, name string) float64 {
+	freq := dev.sdrD.GetTunableElementFrequency(direction, channel, name)
+	dev.record("GetTunableElementFrequency", []any{direction, channel, name}, nil, freq)
+	return freq
+}
+
+// SetTunableElementFrequency sets the frequency of the named tunable element.
+func (dev *RecordingDevice) SetTunableElementFrequency(direction device.Direction, channel uint, name string,
+	freq float64) error {
+	err := dev.sdrD.SetTunableElementFrequency(direction, channel, name, freq)
+	dev.record("SetTunableElementFrequency", []any{direction, channel, name, freq}, err)
+	return err
+}
+
+// SupportsAGC retrieves whether automatic gain control is supported.
+func (dev *RecordingDevice) SupportsAGC(direction device.Direction, channel uint) bool {
+	supported := dev.sdrD.SupportsAGC(direction, channel)
+	dev.record("SupportsAGC", []any{direction, channel}, nil, supported)
+	return supported
+}
+
+// AgcIsEnabled retrieves whether automatic gain control is enabled.
+func (dev *RecordingDevice) AgcIsEnabled(direction device.Direction, channel uint) bool {
+	enabled := dev.sdrD.AgcIsEnabled(direction, channel)
+	dev.record("AgcIsEnabled", []any{direction, channel}, nil, enabled)
+	return enabled
+}
+
+// EnableAgc enables or disables automatic gain control.
+func (dev *RecordingDevice) EnableAgc(direction device.Direction, channel uint, enable bool) error {
+	err := dev.sdrD.EnableAgc(direction, channel, enable)
+	dev.record("EnableAgc", []any{direction, channel, enable}, err)
+	return err
+}
+
+// GetGainElementNames retrieves the names of the gain elements.
+func (dev *RecordingDevice) GetGainElementNames(direction device.Direction, channel uint) []string {
+	names := dev.sdrD.GetGainElementNames(direction, channel)
+	dev.record("GetGainElementNames", []any{direction, channel}, nil, names)
+	return names
+}
+
+// GetElementGain retrieves the gain of the named element.
+func (dev *RecordingDevice) GetElementGain(direction device.Direction, channel uint, name string) (float64, error) {
+	gain, err := dev.sdrD.GetElementGain(direction, channel, name)
+	dev.record("GetElementGain", []any{direction, channel, name}, err, gain)
+	return gain, err
+}
+
+// SetElementGain sets the gain of the named element.
+func (dev *RecordingDevice) SetElementGain(direction device.Direction, channel uint, name string, gain float64) error {
+	err := dev.sdrD.SetElementGain(direction, channel, name, gain)
+	dev.record("SetElementGain", []any{direction, channel, name, gain}, err)
+	return err
+}
+
+// GetElementGainRange retrieves the gain range of the named element.
+func (dev *RecordingDevice) GetElementGainRange(direction device.Direction, channel uint, name string) device.SDRRange {
+	gainRange := dev.sdrD.GetElementGainRange(direction, channel, name)
+	dev.record("GetElementGainRange", []any{direction, channel, name}, nil, gainRange)
+	return gainRange
+}
+
+// GetOverallGain retrieves the overall gain.
+func (dev *RecordingDevice) GetOverallGain(direction device.Direction, channel uint) float64 {
+	gain := dev.sdrD.GetOverallGain(direction, channel)
+	dev.record("GetOverallGain", []any{direction, channel}, nil, gain)
+	return gain
+}
+
+// SetOverallGain sets the overall gain.
+func (dev *RecordingDevice) SetOverallGain(direction device.Direction, channel uint, gain float64) error {
+	err := dev.sdrD.SetOverallGain(direction, channel, gain)
+	dev.record("SetOverallGain", []any{direction, channel, gain}, err)
+	return err
+}
+
+// GetSampleRateRange retrieves the sample rate ranges.
+func (dev *RecordingDevice) GetSampleRateRange(direction device.Direction, channel uint) []device.SDRRange {
+	ranges := dev.sdrD.GetSampleRateRange(direction, channel)
+	dev.record("GetSampleRateRange", []any{direction, channel}, nil, ranges)
+	return ranges
+}
+
+// GetSampleRate retrieves the sample rate.
+func (dev *RecordingDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
+	rate := dev.sdrD.GetSampleRate(direction, channel)
+	dev.record("GetSampleRate", []any{direction, channel}, nil, rate)
+	return rate
+}
+
+// SetSampleRate sets the sample rate.
+func (dev *RecordingDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
+	err := dev.sdrD.SetSampleRate(direction, channel, rate)
+	dev.record("SetSampleRate", []any{direction, channel, rate}, err)
+	return err
+}
+
+// GetBandwidthRanges retrieves the bandwidth ranges.
+func (dev *RecordingDevice) GetBandwidthRanges(direction device.Direction, channel uint) []device.SDRRange {
+	ranges := dev.sdrD.GetBandwidthRanges(direction, channel)
+	dev.record("GetBandwidthRanges", []any{direction, channel}, nil, ranges)
+	return ranges
+}
+
+// GetBandwidth retrieves the bandwidth.
+func (dev *RecordingDevice) GetBandwidth(direction device.Direction, channel uint) float64 {
+	bw := dev.sdrD.GetBandwidth(direction, channel)
+	dev.record("GetBandwidth", []any{direction, channel}, nil, bw)
+	return bw
+}
+
+// SetBandwidth sets the bandwidth.
+func (dev *RecordingDevice) SetBandwidth(direction device.Direction, channel uint, bw float64) error {
+	err := dev.sdrD.SetBandwidth(direction, channel, bw)
+	dev.record("SetBandwidth", []any{direction, channel, bw}, err)
+	return err
+}
+
+// GetAntennaNames retrieves the antenna names for the specified direction and channel.
+func (dev *RecordingDevice) GetAntennaNames(direction device.Direction, channel uint) []string {
+	names := dev.sdrD.GetAntennaNames(direction, channel)
+	dev.record("GetAntennaNames", []any{direction, channel}, nil, names)
+	return names
+}
+
+// GetCurrentAntenna retrieves the selected antenna for the specified direction and channel.
+func (dev *RecordingDevice) GetCurrentAntenna(direction device.Direction, channel uint) string {
+	name := dev.sdrD.GetCurrentAntenna(direction, channel)
+	dev.record("GetCurrentAntenna", []any{direction, channel}, nil, name)
+	return name
+}
+
+// SetAntenna selects the antenna for the specified direction and channel.
+func (dev *RecordingDevice) SetAntenna(direction device.Direction, channel uint, name string) error {
+	err := dev.sdrD.SetAntenna(direction, channel, name)
+	dev.record("SetAntenna", []any{direction, channel, name}, err)
+	return err
+}
+
+// GetStreamFormats retrieves the stream formats.
+func (dev *RecordingDevice) GetStreamFormats(direction device.Direction, channel uint) []string {
+	formats := dev.sdrD.GetStreamFormats(direction, channel)
+	dev.record("GetStreamFormats", []any{direction, channel}, nil, formats)
+	return formats
+}
+
+// GetNativeStreamFormat retrieves the native stream format and its full scale.
+func (dev *RecordingDevice) GetNativeStreamFormat(direction device.Direction, channel uint) (string, float64) {
+	format, fullScale := dev.sdrD.GetNativeStreamFormat(direction, channel)
+	dev.record("GetNativeStreamFormat", []any{direction, channel}, nil, format, fullScale)
+	return format, fullScale
+}
+
+// HasDCOffsetMode retrieves whether automatic DC offset correction is supported.
+func (dev *RecordingDevice) HasDCOffsetMode(direction device.Direction, channel uint) bool {
+	has := dev.sdrD.HasDCOffsetMode(direction, channel)
+	dev.record("HasDCOffsetMode", []any{direction, channel}, nil, has)
+	return has
+}
+
+// GetDCOffsetMode retrieves whether automatic DC offset correction is enabled.
+func (dev *RecordingDevice) GetDCOffsetMode(direction device.Direction, channel uint) bool {
+	automatic := dev.sdrD.GetDCOffsetMode(direction, channel)
+	dev.record("GetDCOffsetMode", []any{direction, channel}, nil, automatic)
+	return automatic
+}
+
+// SetDCOffsetMode enables or disables automatic DC offset correction.
+func (dev *RecordingDevice) SetDCOffsetMode(direction device.Direction, channel uint, automatic bool) error {
+	err := dev.sdrD.SetDCOffsetMode(direction, channel, automatic)
+	dev.record("SetDCOffsetMode", []any{direction, channel, automatic}, err)
+	return err
+}
+
+// HasDCOffset retrieves whether DC offset correction is supported.
+func (dev *RecordingDevice) HasDCOffset(direction device.Direction, channel uint) bool {
+	has := dev.sdrD.HasDCOffset(direction, channel)
+	dev.record("HasDCOffset", []any{direction, channel}, nil, has)
+	return has
+}
+
+// GetDCOffset retrieves the I and Q DC offset corrections.
+func (dev *RecordingDevice) GetDCOffset(direction device.Direction, channel uint) (float64, float64, error) {
+	offsetI, offsetQ, err := dev.sdrD.GetDCOffset(direction, channel)
+	dev.record("GetDCOffset", []any{direction, channel}, err, offsetI, offsetQ)
+	return offsetI, offsetQ, err
+}
+
+// SetDCOffset sets the I and Q DC offset corrections.
+func (dev *RecordingDevice) SetDCOffset(direction device.Direction, channel uint, offsetI float64,
+	offsetQ float64) error {
+	err := dev.sdrD.SetDCOffset(direction, channel, offsetI, offsetQ)
+	dev.record("SetDCOffset", []any{direction, channel, offsetI, offsetQ}, err)
+	return err
+}
+
+// HasIQBalance retrieves whether IQ balance correction is supported.
+func (dev *RecordingDevice) HasIQBalance(direction device.Direction, channel uint) bool {
+	has := dev.sdrD.HasIQBalance(direction, channel)
+	dev.record("HasIQBalance", []any{direction, channel}, nil, has)
+	return has
+}
+
+// GetIQBalance retrieves the I and Q balance corrections.
+func (dev *RecordingDevice) GetIQBalance(direction device.Direction, channel uint) (float64, float64, error) {
+	balanceI, balanceQ, err := dev.sdrD.GetIQBalance(direction, channel)
+	dev.record("GetIQBalance", []any{direction, channel}, err, balanceI, balanceQ)
+	return balanceI, balanceQ, err
+}
+
+// SetIQBalance sets the I and Q balance corrections.
+func (dev *RecordingDevice) SetIQBalance(direction device.Direction, channel uint, balanceI float64,
+	balanceQ float64) error {
+	err := dev.sdrD.SetIQBalance(direction, channel, balanceI, balanceQ)
+	dev.record("SetIQBalance", []any{direction, channel, balanceI, balanceQ}, err)
+	return err
+}
+
+// HasFrequencyCorrection retrieves whether frequency correction is supported.
+func (dev *RecordingDevice) HasFrequencyCorrection(direction device.Direction, channel uint) bool {
+	has := dev.sdrD.HasFrequencyCorrection(direction, channel)
+	dev.record("HasFrequencyCorrection", []any{direction, channel}, nil, has)
+	return has
+}
+
+// GetFrequencyCorrection retrieves the frequency correction in parts per million.
+func (dev *RecordingDevice) GetFrequencyCorrection(direction device.Direction, channel uint) float64 {
+	ppm := dev.sdrD.GetFrequencyCorrection(direction, channel)
+	dev.record("GetFrequencyCorrection", []any{direction, channel}, nil, ppm)
+	return ppm
+}
+
+// SetFrequencyCorrection sets the frequency correction in parts per million.
+func (dev *RecordingDevice) SetFrequencyCorrection(direction device.Direction, channel uint, ppm float64) error {
+	err := dev.sdrD.SetFrequencyCorrection(direction, channel, ppm)
+	dev.record("SetFrequencyCorrection", []any{direction, channel, ppm}, err)
+	return err
+}
+
+// GetMasterClockRate retrieves the master clock rate.
+func (dev *RecordingDevice) GetMasterClockRate() float64 {
+	rate := dev.sdrD.GetMasterClockRate()
+	dev.record("GetMasterClockRate", nil, nil, rate)
+	return rate
+}
+
+// SetMasterClockRate sets the master clock rate.
+func (dev *RecordingDevice) SetMasterClockRate(rate float64) error {
+	err := dev.sdrD.SetMasterClockRate(rate)
+	dev.record("SetMasterClockRate", []any{rate}, err)
+	return err
+}
+
+// GetMasterClockRates retrieves the ranges of master clock rates.
+func (dev *RecordingDevice) GetMasterClockRates() []device.SDRRange {
+	ranges := dev.sdrD.GetMasterClockRates()
+	dev.record("GetMasterClockRates", nil, nil, ranges)
+	return ranges
+}
+
+// GetClockSources retrieves the clock sources.
+func (dev *RecordingDevice) GetClockSources() []string {
+	sources := dev.sdrD.GetClockSources()
+	dev.record("GetClockSources", nil, nil, sources)
+	return sources
+}
+
+// GetClockSource retrieves the selected clock source.
+func (dev *RecordingDevice) GetClockSource() string {
+	source := dev.sdrD.GetClockSource()
+	dev.record("GetClockSource", nil, nil, source)
+	return source
+}
+
+// SetClockSource selects the clock source.
+func (dev *RecordingDevice) SetClockSource(source string) error {
+	err := dev.sdrD.SetClockSource(source)
+	dev.record("SetClockSource", []any{source}, err)
+	return err
+}
+
+// GetTimeSources retrieves the time sources.
+func (dev *RecordingDevice) GetTimeSources() []string {
+	sources := dev.sdrD.GetTimeSources()
+	dev.record("GetTimeSources", nil, nil, sources)
+	return sources
+}
+
+// GetTimeSource retrieves the selected time source.
+func (dev *RecordingDevice) GetTimeSource() string {
+	source := dev.sdrD.GetTimeSource()
+	dev.record("GetTimeSource", nil, nil, source)
+	return source
+}
+
+// SetTimeSource selects the time source.
+func (dev *RecordingDevice) SetTimeSource(source string) error {
+	err := dev.sdrD.SetTimeSource(source)
+	dev.record("SetTimeSource", []any{source}, err)
+	return err
+}
+
+// HasHardwareTime retrieves whether the specified hardware time is supported.
+func (dev *RecordingDevice) HasHardwareTime(what string) bool {
+	has := dev.sdrD.HasHardwareTime(what)
+	dev.record("HasHardwareTime", []any{what}, nil, has)
+	return has
+}
+
+// GetHardwareTime retrieves the hardware time.
+func (dev *RecordingDevice) GetHardwareTime(what string) uint {
+	timeNs := dev.sdrD.GetHardwareTime(what)
+	dev.record("GetHardwareTime", []any{what}, nil, timeNs)
+	return timeNs
+}
+
+// SetHardwareTime sets the hardware time.
+func (dev *RecordingDevice) SetHardwareTime(timeNs uint, what string) error {
+	err := dev.sdrD.SetHardwareTime(timeNs, what)
+	dev.record("SetHardwareTime", []any{timeNs, what}, err)
+	return err
+}
+
+// GetSensorNames retrieves the keys of the device sensors.
+func (dev *RecordingDevice) GetSensorNames() []string {
+	names := dev.sdrD.GetSensorNames()
+	dev.record("GetSensorNames", nil, nil, names)
+	return names
+}
+
+// GetSensorInfo retrieves the info for the specified device sensor.
+func (dev *RecordingDevice) GetSensorInfo(key string) device.SDRArgInfo {
+	info := dev.sdrD.GetSensorInfo(key)
+	dev.record("GetSensorInfo", []any{key}, nil, info)
+	return info
+}
+
+// ReadSensor reads the specified device sensor.
+func (dev *RecordingDevice) ReadSensor(key string) (string, error) {
+	value, err := dev.sdrD.ReadSensor(key)
+	dev.record("ReadSensor", []any{key}, err, value)
+	return value, err
+}
+
+// GetChannelSensorNames retrieves the keys of the channel sensors.
+func (dev *RecordingDevice) GetChannelSensorNames(direction device.Direction, channel uint) []string {
+	names := dev.sdrD.GetChannelSensorNames(direction, channel)
+	dev.record("GetChannelSensorNames", []any{direction, channel}, nil, names)
+	return names
+}
+
+// GetChannelSensorInfo retrieves the info for the specified channel sensor.
+func (dev *RecordingDevice) GetChannelSensorInfo(direction device.Direction, channel uint,
+	key string) device.SDRArgInfo {
+	info := dev.sdrD.GetChannelSensorInfo(direction, channel, key)
+	dev.record("GetChannelSensorInfo", []any{direction, channel, key}, nil, info)
+	return info
+}
+
+// ReadChannelSensor reads the specified channel sensor.
+func (dev *RecordingDevice) ReadChannelSensor(direction device.Direction, channel uint, key string) (string, error) {
+	value, err := dev.sdrD.ReadChannelSensor(direction, channel, key)
+	dev.record("ReadChannelSensor", []any{direction, channel, key}, err, value)
+	return value, err
+}
+
+// GetSettingInfo retrieves the info for the device settings.
+func (dev *RecordingDevice) GetSettingInfo() []device.SDRArgInfo {
+	infos := dev.sdrD.GetSettingInfo()
+	dev.record("GetSettingInfo", nil, nil, infos)
+	return infos
+}
+
+// ReadSetting reads the specified device setting.
+func (dev *RecordingDevice) ReadSetting(key string) string {
+	value := dev.sdrD.ReadSetting(key)
+	dev.record("ReadSetting", []any{key}, nil, value)
+	return value
+}
+
+// WriteSetting writes a value to the specified device setting.
+func (dev *RecordingDevice) WriteSetting(key string, value string) error {
+	err := dev.sdrD.WriteSetting(key, value)
+	dev.record("WriteSetting", []any{key, value}, err)
+	return err
+}
+
+// GetChannelSettingInfo retrieves the info for the channel settings.
+func (dev *RecordingDevice) GetChannelSettingInfo(direction device.Direction, channel uint) []device.SDRArgInfo {
+	infos := dev.sdrD.GetChannelSettingInfo(direction, channel)
+	dev.record("GetChannelSettingInfo", []any{direction, channel}, nil, infos)
+	return infos
+}
+
+// ReadChannelSetting reads the specified channel setting.
+func (dev *RecordingDevice) ReadChannelSetting(direction device.Direction, channel uint, key string) string {
+	value := dev.sdrD.ReadChannelSetting(direction, channel, key)
+	dev.record("ReadChannelSetting", []any{direction, channel, key}, nil, value)
+	return value
+}
+
+// WriteChannelSetting writes a value to the specified channel setting.
+func (dev *RecordingDevice) WriteChannelSetting(direction device.Direction, channel uint, key string,
+	value string) error {
+	err := dev.sdrD.WriteChannelSetting(direction, channel, key, value)
+	dev.record("WriteChannelSetting", []any{direction, channel, key, value}, err)
+	return err
+}
+
+// record appends a call to method to the session. If err is or wraps one of the sentinelErrors, the sentinel's name is
+// recorded with the error, so that the replayed error matches the sentinel.
+func (dev *RecordingDevice) record(method string, args []any, err error, results ...any) {
+	if args == nil {
+		args = []any{}
+	}
+	call := SessionCall{Method: method, Args: marshalSessionValue(args)}
+	for _, result := range results {
+		call.Results = append(call.Results, marshalSessionValue(result))
+	}
+	if err != nil {
+		call.Error = err.Error()
+		call.Sentinel = sessionSentinel(err)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.calls = append(dev.calls, call)
+}
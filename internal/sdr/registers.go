@@ -0,0 +1,110 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jimorc/jsdr/internal/logger"
+)
+
+// Registers interface specifies the methods for reading and writing the registers of an SDR's named register
+// interfaces, such as those of an FPGA or an RF IC.
+type Registers interface {
+	GetRegisterInterfaces() []string
+	WriteRegister(string, uint32, uint32) error
+	ReadRegister(string, uint32) (uint32, error)
+	WriteRegisters(string, uint32, []uint32) error
+	ReadRegisters(string, uint32, uint) ([]uint32, error)
+}
+
+// GetRegisterInterfaces returns the names of the device's register interfaces. Devices without register access
+// return no interfaces.
+func GetRegisterInterfaces(sdrD Registers, log *logger.Logger) []string {
+	names := sdrD.GetRegisterInterfaces()
+	log.Logf(logger.Debug, "Register interfaces: %v\n", names)
+	return names
+}
+
+// ReadRegister reads the register at addr of the named register interface.
+//
+// Returns an error if the device does not have the register interface, or the register could not be read.
+func ReadRegister(sdrD Registers, log *logger.Logger, name string, addr uint32) (uint32, error) {
+	if err := validateRegisterInterface(sdrD, log, name); err != nil {
+		return 0, err
+	}
+	value, err := sdrD.ReadRegister(name, addr)
+	if err != nil {
+		log.Logf(logger.Error, "Could not read register %s %#x: %s\n", name, addr, err.Error())
+		return 0, err
+	}
+	log.Logf(logger.Debug, "Register %s %#x: %#08x\n", name, addr, value)
+	return value, nil
+}
+
+// WriteRegister writes value to the register at addr of the named register interface.
+//
+// Returns an error if the device does not have the register interface, or the register could not be written.
+func WriteRegister(sdrD Registers, log *logger.Logger, name string, addr uint32, value uint32) error {
+	if err := validateRegisterInterface(sdrD, log, name); err != nil {
+		return err
+	}
+	if err := sdrD.WriteRegister(name, addr, value); err != nil {
+		log.Logf(logger.Error, "Could not write %#08x to register %s %#x: %s\n", value, name, addr, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have written %#08x to register %s %#x\n", value, name, addr)
+	return nil
+}
+
+// ReadRegisters reads length consecutive registers, starting at addr, of the named register interface.
+//
+// Returns an error if the device does not have the register interface, or the registers could not all be read.
+func ReadRegisters(sdrD Registers, log *logger.Logger, name string, addr uint32, length uint) ([]uint32, error) {
+	if err := validateRegisterInterface(sdrD, log, name); err != nil {
+		return nil, err
+	}
+	values, err := sdrD.ReadRegisters(name, addr, length)
+	if err != nil {
+		log.Logf(logger.Error, "Could not read %d registers from %s %#x: %s\n", length, name, addr, err.Error())
+		return nil, err
+	}
+	if uint(len(values)) != length {
+		log.Logf(logger.Error, "Read %d registers from %s %#x, but %d were requested\n", len(values), name, addr,
+			length)
+		return nil, fmt.Errorf("read %d registers from %s, but %d were requested", len(values), name, length)
+	}
+	log.Logf(logger.Debug, "Registers %s %#x: %#08x\n", name, addr, values)
+	return values, nil
+}
+
+// WriteRegisters writes values to consecutive registers, starting at addr, of the named register interface.
+//
+// Returns an error if values is empty, the device does not have the register interface, or the registers could not
+// be written.
+func WriteRegisters(sdrD Registers, log *logger.Logger, name string, addr uint32, values []uint32) error {
+	if len(values) == 0 {
+		log.Logf(logger.Error, "Attempting to write no values to register %s %#x\n", name, addr)
+		return errors.New("cannot write an empty block of registers")
+	}
+	if err := validateRegisterInterface(sdrD, log, name); err != nil {
+		return err
+	}
+	if err := sdrD.WriteRegisters(name, addr, values); err != nil {
+		log.Logf(logger.Error, "Could not write %d registers to %s %#x: %s\n", len(values), name, addr, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have written %#08x to registers %s %#x\n", values, name, addr)
+	return nil
+}
+
+// validateRegisterInterface returns an error if the device does not have the named register interface.
+func validateRegisterInterface(sdrD Registers, log *logger.Logger, name string) error {
+	names := sdrD.GetRegisterInterfaces()
+	if !slices.Contains(names, name) {
+		log.Logf(logger.Error, "Attempting to access register interface: %s, but that interface does not exist.\n"+
+			"Register interfaces are: %v\n", name, names)
+		return fmt.Errorf("register interface '%s' does not exist", name)
+	}
+	return nil
+}
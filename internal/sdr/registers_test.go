@@ -0,0 +1,77 @@
+package sdr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ sdr.Registers = &sdr.SoapyDevice{}
+	_ sdr.Registers = &sdr.StubDevice{}
+)
+
+func TestGetRegisterInterfaces(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, []string{"FPGA", "RFIC"}, sdr.GetRegisterInterfaces(&stub, testLogger))
+}
+
+func TestWriteRegister(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.WriteRegister(&stub, testLogger, "RFIC", 0x10, 0xbeef))
+	value, err := sdr.ReadRegister(&stub, testLogger, "RFIC", 0x10)
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0xbeef), value)
+	value, err = sdr.ReadRegister(&stub, testLogger, "FPGA", 0x10)
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0), value)
+}
+
+func TestWriteRegisters(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.WriteRegisters(&stub, testLogger, "FPGA", 0x100, []uint32{1, 2, 3}))
+	values, err := sdr.ReadRegisters(&stub, testLogger, "FPGA", 0xff, 5)
+	require.Nil(t, err)
+	assert.Equal(t, []uint32{0, 1, 2, 3, 0}, values)
+
+	err = sdr.WriteRegisters(&stub, testLogger, "FPGA", 0x100, []uint32{})
+	require.NotNil(t, err)
+	assert.Equal(t, "cannot write an empty block of registers", err.Error())
+	assert.Equal(t, 1, stub.CallCount("WriteRegisters"))
+}
+
+func TestRegisters_InvalidInterface(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	_, err := sdr.ReadRegister(&stub, testLogger, "LMS7", 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "register interface 'LMS7' does not exist", err.Error())
+	assert.NotNil(t, sdr.WriteRegister(&stub, testLogger, "LMS7", 0, 1))
+	_, err = sdr.ReadRegisters(&stub, testLogger, "LMS7", 0, 2)
+	assert.NotNil(t, err)
+	assert.NotNil(t, sdr.WriteRegisters(&stub, testLogger, "LMS7", 0, []uint32{1}))
+	assert.Equal(t, 0, stub.CallCount("ReadRegister")+stub.CallCount("WriteRegister")+
+		stub.CallCount("ReadRegisters")+stub.CallCount("WriteRegisters"))
+}
+
+func TestRegisters_Errors(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{Errors: map[string]error{
+		"WriteRegister": errors.New("bus error"),
+		"ReadRegisters": errors.New("bus error"),
+	}}
+	err := sdr.WriteRegister(&stub, testLogger, "RFIC", 0, 1)
+	require.NotNil(t, err)
+	assert.Equal(t, "bus error", err.Error())
+	_, err = sdr.ReadRegisters(&stub, testLogger, "RFIC", 0, 2)
+	require.NotNil(t, err)
+	assert.Equal(t, "bus error", err.Error())
+}
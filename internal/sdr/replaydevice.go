@@ -0,0 +1,548 @@
+package sdr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// ErrNotRecorded is returned by the ReplayDevice methods that return errors when the session does not hold a call to
+// the method with the same arguments.
+var ErrNotRecorded = errors.New("the call was not recorded")
+
+// ReplayDevice replays a Session that was recorded by a RecordingDevice, so that tests can be run against the device
+// that was recorded without the hardware.
+//
+// Each call returns the results of a recorded call to the same method with the same arguments. When the same call was
+// recorded several times, such as reading the center frequency before and after tuning, the results are returned in
+// the order that they were recorded, and the last results are returned once they have all been used. Calls that were
+// not recorded return zero values, or ErrNotRecorded, and are listed by Unrecorded.
+//
+// A recorded error is replayed as an error with the same message. If the recorded error was, or wrapped, one of the
+// package's sentinel errors, such as ErrStreamNotSupported, the replayed error also wraps that sentinel, so that it
+// can be matched with errors.Is. Other error types and sentinels are not preserved.
+//
+// Use NewReplayDevice to create a ReplayDevice. A ReplayDevice may be used concurrently on multiple go routines.
+// Make and Unmake set Device while holding the device's mutex, but Device itself is read without it, so it should
+// not be read while Make or Unmake may be running.
+type ReplayDevice struct {
+	Device *Sdr
+
+	mutex sync.Mutex
+	// responses holds the recorded calls, indexed by method and arguments.
+	responses map[string][]SessionCall
+	// next holds the index in responses of the next call to replay, indexed by method and arguments.
+	next       map[string]int
+	unrecorded []string
+}
+
+// NewReplayDevice creates a ReplayDevice that replays session.
+func NewReplayDevice(session *Session) *ReplayDevice {
+	dev := &ReplayDevice{responses: make(map[string][]SessionCall), next: make(map[string]int)}
+	for _, call := range session.Calls {
+		key := sessionKey(call.Method, call.Args)
+		dev.responses[key] = append(dev.responses[key], call)
+	}
+	return dev
+}
+
+// Unrecorded returns the calls that have been made to the device that were not recorded in the session.
+func (dev *ReplayDevice) Unrecorded() []string {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return slices.Clone(dev.unrecorded)
+}
+
+// Enumerate enumerates the devices, as recorded in the session.
+func (dev *ReplayDevice) Enumerate(args map[string]string) []map[string]string {
+	var devices []map[string]string
+	dev.replay("Enumerate", []any{args}, &devices)
+	return devices
+}
+
+// Make replays a recorded call that makes the device. If the call succeeded, Device is set to an Sdr with args as
+// its properties.
+func (dev *ReplayDevice) Make(args map[string]string) error {
+	if err := dev.replay("Make", []any{args}); err != nil {
+		return err
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.Device = &Sdr{DeviceProperties: args}
+	return nil
+}
+
+// Unmake replays a recorded call that unmakes the device. If the call succeeded, Device is set to nil.
+func (dev *ReplayDevice) Unmake() error {
+	if err := dev.replay("Unmake", nil); err != nil {
+		return err
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.Device = nil
+	return nil
+}
+
+// GetHardwareKey retrieves the hardware key, as recorded in the session.
+func (dev *ReplayDevice) GetHardwareKey() string {
+	var key string
+	dev.replay("GetHardwareKey", nil, &key)
+	return key
+}
+
+// GetNumChannels retrieves the number of channels for the specified direction, as recorded in the session.
+func (dev *ReplayDevice) GetNumChannels(direction device.Direction) uint {
+	var numChannels uint
+	dev.replay("GetNumChannels", []any{direction}, &numChannels)
+	return numChannels
+}
+
+// GetFrequencyRanges retrieves the frequency ranges, as recorded in the session.
+func (dev *ReplayDevice) GetFrequencyRanges(direction device.Direction, channel uint) []device.SDRRange {
+	var ranges []device.SDRRange
+	dev.replay("GetFrequencyRanges", []any{direction, channel}, &ranges)
+	return ranges
+}
+
+// GetOverallCenterFrequency retrieves the overall center frequency, as recorded in the session.
+func (dev *ReplayDevice) GetOverallCenterFrequency(direction device.Direction, channel uint) float64 {
+	var freq float64
+	dev.replay("GetOverallCenterFrequency", []any{direction, channel}, &freq)
+	return freq
+}
+
+// SetOverallCenterFrequency sets the overall center frequency, as recorded in the session.
+func (dev *ReplayDevice) SetOverallCenterFrequency(direction device.Direction, channel uint, freq float64,
+	args map[string]string) error {
+	return dev.replay("SetOverallCenterFrequency", []any{direction, channel, freq, args})
+}
+
+// GetTunableElementNames retrieves the names of the tunable elements, as recorded in the session.
+func (dev *ReplayDevice) GetTunableElementNames(direction device.Direction, channel uint) []string {
+	var names []string
+	dev.replay("GetTunableElementNames", []any{direction, channel}, &names)
+	return names
+}
+
+// GetTunableElementFrequencyRanges retrieves the frequency ranges of the named tunable element, as recorded in the
+// session.
+func (dev *ReplayDevice) GetTunableElementFrequencyRanges(direction device.Direction, channel uint,
+	name string) []device.SDRRange {
+	var ranges []device.SDRRange
+	dev.replay("GetTunableElementFrequencyRanges", []any{direction, channel, name}, &ranges)
+	return ranges
+}
+
+// GetTunableElementFrequency retrieves the frequency of the named tunable element, as recorded in the session.
+func (dev *ReplayDevice) GetTunableElementFrequency(direction device.Direction, channel uint, name string) float64 {
+	var freq float64
+	dev.replay("GetTunableElementFrequency", []any{direction, channel, name}, &freq)
+	return freq
+}
+
+// SetTunableElementFrequency sets the frequency of the named tunable element, as recorded in the session.
+func (dev *ReplayDevice) SetTunableElementFrequency(direction device.Direction, channel uint, name string,
+	freq float64) error {
+	return dev.replay("SetTunableElementFrequency", []any{direction, channel, name, freq})
+}
+
+// SupportsAGC retrieves whether automatic gain control is supported, as recorded in the session.
+func (dev *ReplayDevice) SupportsAGC(direction device.Direction, channel uint) bool {
+	var supported bool
+	dev.replay("SupportsAGC", []any{direction, channel}, &supported)
+	return supported
+}
+
+// AgcIsEnabled retrieves whether automatic gain control is enabled, as recorded in the session.
+func (dev *ReplayDevice) AgcIsEnabled(direction device.Direction, channel uint) bool {
+	var enabled bool
+	dev.replay("AgcIsEnabled", []any{direction, channel}, &enabled)
+	return enabled
+}
+
+// EnableAgc enables or disables automatic gain control, as recorded in the session.
+func (dev *ReplayDevice) EnableAgc(direction device.Direction, channel uint, enable bool) error {
+	return dev.replay("EnableAgc", []any{direction, channel, enable})
+}
+
+// GetGainElementNames retrieves the names of the gain elements, as recorded in the session.
+func (dev *ReplayDevice) GetGainElementNames(direction device.Direction, channel uint) []string {
+	var names []string
+	dev.replay("GetGainElementNames", []any{direction, channel}, &names)
+	return names
+}
+
+// GetElementGain retrieves the gain of the named element, as recorded in the session.
+func (dev *ReplayDevice) GetElementGain(direction device.Direction, channel uint, name string) (float64, error) {
+	var gain float64
+	err := dev.replay("GetElementGain", []any{direction, channel, name}, &gain)
+	return gain, err
+}
+
+// SetElementGain sets the gain of the named element, as recorded in the session.
+func (dev *ReplayDevice) SetElementGain(direction device.Direction, channel uint, name string, gain float64) error {
+	return dev.replay("SetElementGain", []any{direction, channel, name, gain})
+}
+
+// GetElementGainRange retrieves the gain range of the named element, as recorded in the session.
+func (dev *ReplayDevice) GetElementGainRange(direction device.Direction, channel uint, name string) device.SDRRange {
+	var gainRange device.SDRRange
+	dev.replay("GetElementGainRange", []any{direction, channel, name}, &gainRange)
+	return gainRange
+}
+
+// GetOverallGain retrieves the overall gain, as recorded in the session.
+func (dev *ReplayDevice) GetOverallGain(direction device.Direction, channel uint) float64 {
+	var gain float64
+	dev.replay("GetOverallGain", []any{direction, channel}, &gain)
+	return gain
+}
+
+// SetOverallGain sets the overall gain, as recorded in the session.
+func (dev *ReplayDevice) SetOverallGain(direction device.Direction, channel uint, gain float64) error {
+	return dev.replay("SetOverallGain", []any{direction, channel, gain})
+}
+
+// GetSampleRateRange retrieves the sample rate ranges, as recorded in the session.
+func (dev *ReplayDevice) GetSampleRateRange(direction device.Direction, channel uint) []device.SDRRange {
+	var ranges []device.SDRRange
+	dev.replay("GetSampleRateRange", []any{direction, channel}, &ranges)
+	return ranges
+}
+
+// GetSampleRate retrieves the sample rate, as recorded in the session.
+func (dev *ReplayDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
+	var rate float64
+	dev.replay("GetSampleRate", []any{direction, channel}, &rate)
+	return rate
+}
+
+// SetSampleRate sets the sample rate, as recorded in the session.
+func (dev *ReplayDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
+	return dev.replay("SetSampleRate", []any{direction, channel, rate})
+}
+
+// GetBandwidthRanges retrieves the bandwidth ranges, as recorded in the session.
+func (dev *ReplayDevice) GetBandwidthRanges(direction device.Direction, channel uint) []device.SDRRange {
+	var ranges []device.SDRRange
+	dev.replay("GetBandwidthRanges", []any{direction, channel}, &ranges)
+	return ranges
+}
+
+// GetBandwidth retrieves the bandwidth, as recorded in the session.
+func (dev *ReplayDevice) GetBandwidth(direction device.Direction, channel uint) float64 {
+	var bw float64
+	dev.replay("GetBandwidth", []any{direction, channel}, &bw)
+	return bw
+}
+
+// SetBandwidth sets the bandwidth, as recorded in the session.
+func (dev *ReplayDevice) SetBandwidth(direction device.Direction, channel uint, bw float64) error {
+	return dev.replay("SetBandwidth", []any{direction, channel, bw})
+}
+
+// GetAntennaNames retrieves the antenna names for the specified direction and channel, as recorded in the session.
+func (dev *ReplayDevice) GetAntennaNames(direction device.Direction, channel uint) []string {
+	var names []string
+	dev.replay("GetAntennaNames", []any{direction, channel}, &names)
+	return names
+}
+
+// GetCurrentAntenna retrieves the selected antenna for the specified direction and channel, as recorded in the session.
+func (dev *ReplayDevice) GetCurrentAntenna(direction device.Direction, channel uint) string {
+	var name string
+	dev.replay("GetCurrentAntenna", []any{direction, channel}, &name)
+	return name
+}
+
+// SetAntenna selects the antenna for the specified direction and channel, as recorded in the session.
+func (dev *ReplayDevice) SetAntenna(direction device.Direction, channel uint, name string) error {
+	return dev.replay("SetAntenna", []any{direction, channel, name})
+}
+
+// GetStreamFormats retrieves the stream formats, as recorded in the session.
+func (dev *ReplayDevice) GetStreamFormats(direction device.Direction, channel uint) []string {
+	var formats []string
+	dev.replay("GetStreamFormats", []any{direction, channel}, &formats)
+	return formats
+}
+
+// GetNativeStreamFormat retrieves the native stream format and its full scale, as recorded in the session.
+func (dev *ReplayDevice) GetNativeStreamFormat(direction device.Direction, channel uint) (string, float64) {
+	var format string
+	var fullScale float64
+	dev.replay("GetNativeStreamFormat", []any{direction, channel}, &format, &fullScale)
+	return format, fullScale
+}
+
+// HasDCOffsetMode retrieves whether automatic DC offset correction is supported, as recorded in the session.
+func (dev *ReplayDevice) HasDCOffsetMode(direction device.Direction, channel uint) bool {
+	var has bool
+	dev.replay("HasDCOffsetMode", []any{direction, channel}, &has)
+	return has
+}
+
+// GetDCOffsetMode retrieves whether automatic DC offset correction is enabled, as recorded in the session.
+func (dev *ReplayDevice) GetDCOffsetMode(direction device.Direction, channel uint) bool {
+	var automatic bool
+	dev.replay("GetDCOffsetMode", []any{direction, channel}, &automatic)
+	return automatic
+}
+
+// SetDCOffsetMode enables or disables automatic DC offset correction, as recorded in the session.
+func (dev *ReplayDevice) SetDCOffsetMode(direction device.Direction, channel uint, automatic bool) error {
+	return dev.replay("SetDCOffsetMode", []any{direction, channel, automatic})
+}
+
+// HasDCOffset retrieves whether DC offset correction is supported, as recorded in the session.
+func (dev *ReplayDevice) HasDCOffset(direction device.Direction, channel uint) bool {
+	var has bool
+	dev.replay("HasDCOffset", []any{direction, channel}, &has)
+	return has
+}
+
+// GetDCOffset retrieves the I and Q DC offset corrections, as recorded in the session.
+func (dev *ReplayDevice) GetDCOffset(direction device.Direction, channel uint) (float64, float64, error) {
+	var offsetI float64
+	var offsetQ float64
+	err := dev.replay("GetDCOffset", []any{direction, channel}, &offsetI, &offsetQ)
+	return offsetI, offsetQ, err
+}
+
+// SetDCOffset sets the I and Q DC offset corrections, as recorded in the session.
+func (dev *ReplayDevice) SetDCOffset(direction device.Direction, channel uint, offsetI float64, offsetQ float64) error {
+	return dev.replay("SetDCOffset", []any{direction, channel, offsetI, offsetQ})
+}
+
+// HasIQBalance retrieves whether IQ balance correction is supported, as recorded in the session.
+func (dev *ReplayDevice) HasIQBalance(direction device.Direction, channel uint) bool {
+	var has bool
+	dev.replay("HasIQBalance", []any{direction, channel}, &has)
+	return has
+}
+
+// GetIQBalance retrieves the I and Q balance corrections, as recorded in the session.
+func (dev *ReplayDevice) GetIQBalance(direction device.Direction, channel uint) (float64, float64, error) {
+	var balanceI float64
+	var balanceQ float64
+	err := dev.replay("GetIQBalance", []any{direction, channel}, &balanceI, &balanceQ)
+	return balanceI, balanceQ, err
+}
+
+// SetIQBalance sets the I and Q balance corrections, as recorded in the session.
+func (dev *ReplayDevice) SetIQBalance(direction device.Direction, channel uint, balanceI float64,
+	balanceQ float64) error {
+	return dev.replay("SetIQBalance", []any{direction, channel, balanceI, balanceQ})
+}
+
+// HasFrequencyCorrection retrieves whether frequency correction is supported, as recorded in the session.
+func (dev *ReplayDevice) HasFrequencyCorrection(direction device.Direction, channel uint) bool {
+	var has bool
+	dev.replay("HasFrequencyCorrection", []any{direction, channel}, &has)
+	return has
+}
+
+// GetFrequencyCorrection retrieves the frequency correction in parts per million, as recorded in the session.
+func (dev *ReplayDevice) GetFrequencyCorrection(direction device.Direction, channel uint) float64 {
+	var ppm float64
+	dev.replay("GetFrequencyCorrection", []any{direction, channel}, &ppm)
+	return ppm
+}
+
+// SetFrequencyCorrection sets the frequency correction in parts per million, as recorded in the session.
+func (dev *ReplayDevice) SetFrequencyCorrection(direction device.Direction, channel uint, ppm float64) error {
+	return dev.replay("SetFrequencyCorrection", []any{direction, channel, ppm})
+}
+
+// GetMasterClockRate retrieves the master clock rate, as recorded in the session.
+func (dev *ReplayDevice) GetMasterClockRate() float64 {
+	var rate float64
+	dev.replay("GetMasterClockRate", nil, &rate)
+	return rate
+}
+
+// SetMasterClockRate sets the master clock rate, as recorded in the session.
+func (dev *ReplayDevice) SetMasterClockRate(rate float64) error {
+	return dev.replay("SetMasterClockRate", []any{rate})
+}
+
+// GetMasterClockRates retrieves the ranges of master clock rates, as recorded in the session.
+func (dev *ReplayDevice) GetMasterClockRates() []device.SDRRange {
+	var ranges []device.SDRRange
+	dev.replay("GetMasterClockRates", nil, &ranges)
+	return ranges
+}
+
+// GetClockSources retrieves the clock sources, as recorded in the session.
+func (dev *ReplayDevice) GetClockSources() []string {
+	var sources []string
+	dev.replay("GetClockSources", nil, &sources)
+	return sources
+}
+
+// GetClockSource retrieves the selected clock source, as recorded in the session.
+func (dev *ReplayDevice) GetClockSource() string {
+	var source string
+	dev.replay("GetClockSource", nil, &source)
+	return source
+}
+
+// SetClockSource selects the clock source, as recorded in the session.
+func (dev *ReplayDevice) SetClockSource(source string) error {
+	return dev.replay("SetClockSource", []any{source})
+}
+
+// GetTimeSources retrieves the time sources, as recorded in the session.
+func (dev *ReplayDevice) GetTimeSources() []string {
+	var sources []string
+	dev.replay("GetTimeSources", nil, &sources)
+	return sources
+}
+
+// GetTimeSource retrieves the selected time source, as recorded in the session.
+func (dev *ReplayDevice) GetTimeSource() string {
+	var source string
+	dev.replay("GetTimeSource", nil, &source)
+	return source
+}
+
+// SetTimeSource selects the time source, as recorded in the session.
+func (dev *ReplayDevice) SetTimeSource(source string) error {
+	return dev.replay("SetTimeSource", []any{source})
+}
+
+// HasHardwareTime retrieves whether the specified hardware time is supported, as recorded in the session.
+func (dev *ReplayDevice) HasHardwareTime(what string) bool {
+	var has bool
+	dev.replay("HasHardwareTime", []any{what}, &has)
+	return has
+}
+
+// GetHardwareTime retrieves the hardware time, as recorded in the session.
+func (dev *ReplayDevice) GetHardwareTime(what string) uint {
+	var timeNs uint
+	dev.replay("GetHardwareTime", []any{what}, &timeNs)
+	return timeNs
+}
+
+// SetHardwareTime sets the hardware time, as recorded in the session.
+func (dev *ReplayDevice) SetHardwareTime(timeNs uint, what string) error {
+	return dev.replay("SetHardwareTime", []any{timeNs, what})
+}
+
+// GetSensorNames retrieves the keys of the device sensors, as recorded in the session.
+func (dev *ReplayDevice) GetSensorNames() []string {
+	var names []string
+	dev.replay("GetSensorNames", nil, &names)
+	return names
+}
+
+// GetSensorInfo retrieves the info for the specified device sensor, as recorded in the session.
+func (dev *ReplayDevice) GetSensorInfo(key string) device.SDRArgInfo {
+	var info device.SDRArgInfo
+	dev.replay("GetSensorInfo", []any{key}, &info)
+	return info
+}
+
+// ReadSensor reads the specified device sensor, as recorded in the session.
+func (dev *ReplayDevice) ReadSensor(key string) (string, error) {
+	var value string
+	err := dev.replay("ReadSensor", []any{key}, &value)
+	return value, err
+}
+
+// GetChannelSensorNames retrieves the keys of the channel sensors, as recorded in the session.
+func (dev *ReplayDevice) GetChannelSensorNames(direction device.Direction, channel uint) []string {
+	var names []string
+	dev.replay("GetChannelSensorNames", []any{direction, channel}, &names)
+	return names
+}
+
+// GetChannelSensorInfo retrieves the info for the specified channel sensor, as recorded in the session.
+func (dev *ReplayDevice) GetChannelSensorInfo(direction device.Direction, channel uint, key string) device.SDRArgInfo {
+	var info device.SDRArgInfo
+	dev.replay("GetChannelSensorInfo", []any{direction, channel, key}, &info)
+	return info
+}
+
+// ReadChannelSensor reads the specified channel sensor, as recorded in the session.
+func (dev *ReplayDevice) ReadChannelSensor(direction device.Direction, channel uint, key string) (string, error) {
+	var value string
+	err := dev.replay("ReadChannelSensor", []any{direction, channel, key}, &value)
+	return value, err
+}
+
+// GetSettingInfo retrieves the info for the device settings, as recorded in the session.
+func (dev *ReplayDevice) GetSettingInfo() []device.SDRArgInfo {
+	var infos []device.SDRArgInfo
+	dev.replay("GetSettingInfo", nil, &infos)
+	return infos
+}
+
+// ReadSetting reads the specified device setting, as recorded in the session.
+func (dev *ReplayDevice) ReadSetting(key string) string {
+	var value string
+	dev.replay("ReadSetting", []any{key}, &value)
+	return value
+}
+
+// WriteSetting writes a value to the specified device setting, as recorded in the session.
+func (dev *ReplayDevice) WriteSetting(key string, value string) error {
+	return dev.replay("WriteSetting", []any{key, value})
+}
+
+// GetChannelSettingInfo retrieves the info for the channel settings, as recorded in the session.
+func (dev *ReplayDevice) GetChannelSettingInfo(direction device.Direction, channel uint) []device.SDRArgInfo {
+	var infos []device.SDRArgInfo
+	dev.replay("GetChannelSettingInfo", []any{direction, channel}, &infos)
+	return infos
+}
+
+// ReadChannelSetting reads the specified channel setting, as recorded in the session.
+func (dev *ReplayDevice) ReadChannelSetting(direction device.Direction, channel uint, key string) string {
+	var value string
+	dev.replay("ReadChannelSetting", []any{direction, channel, key}, &value)
+	return value
+}
+
+// WriteChannelSetting writes a value to the specified channel setting, as recorded in the session.
+func (dev *ReplayDevice) WriteChannelSetting(direction device.Direction, channel uint, key string, value string) error {
+	return dev.replay("WriteChannelSetting", []any{direction, channel, key, value})
+}
+
+// replay sets results to the results of the next recorded call to method with args, and returns the call's error.
+func (dev *ReplayDevice) replay(method string, args []any, results ...any) error {
+	if args == nil {
+		args = []any{}
+	}
+	argsJSON := marshalSessionValue(args)
+	key := sessionKey(method, argsJSON)
+	dev.mutex.Lock()
+	calls := dev.responses[key]
+	if len(calls) == 0 {
+		dev.unrecorded = append(dev.unrecorded, method+string(argsJSON))
+		dev.mutex.Unlock()
+		return fmt.Errorf("%w: %s%s", ErrNotRecorded, method, argsJSON)
+	}
+	call := calls[min(dev.next[key], len(calls)-1)]
+	dev.next[key]++
+	dev.mutex.Unlock()
+
+	if len(call.Results) != len(results) {
+		return fmt.Errorf("cannot replay %s: %d results were recorded, but %d are returned", method,
+			len(call.Results), len(results))
+	}
+	for i, result := range results {
+		if err := json.Unmarshal(call.Results[i], result); err != nil {
+			return fmt.Errorf("cannot replay %s: %w", method, err)
+		}
+	}
+	if call.Error != "" {
+		return replayedError(call)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package sdr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// RtlTcpCommand is a command that an rtl_tcp client sends to the server.
+//
+// Each command is sent as 5 bytes: the command byte followed by a 32 bit big endian parameter.
+type RtlTcpCommand byte
+
+// The rtl_tcp commands that jsdr uses.
+const (
+	// RtlTcpSetFrequency sets the center frequency in Hz.
+	RtlTcpSetFrequency RtlTcpCommand = 0x01
+	// RtlTcpSetSampleRate sets the sample rate in samples per second.
+	RtlTcpSetSampleRate RtlTcpCommand = 0x02
+	// RtlTcpSetGainMode selects automatic (0) or manual (1) tuner gain.
+	RtlTcpSetGainMode RtlTcpCommand = 0x03
+	// RtlTcpSetGain sets the tuner gain in tenths of a dB.
+	RtlTcpSetGain RtlTcpCommand = 0x04
+	// RtlTcpSetFrequencyCorrection sets the frequency correction in parts per million.
+	RtlTcpSetFrequencyCorrection RtlTcpCommand = 0x05
+	// RtlTcpSetDirectSampling selects direct sampling of the I or Q branch (see RtlTcpDirectSampling).
+	RtlTcpSetDirectSampling RtlTcpCommand = 0x09
+)
+
+// rtlTcpCommandNames holds the names of the rtl_tcp commands that jsdr uses.
+var rtlTcpCommandNames = map[RtlTcpCommand]string{
+	RtlTcpSetFrequency:           "set frequency",
+	RtlTcpSetSampleRate:          "set sample rate",
+	RtlTcpSetGainMode:            "set gain mode",
+	RtlTcpSetGain:                "set gain",
+	RtlTcpSetFrequencyCorrection: "set frequency correction",
+	RtlTcpSetDirectSampling:      "set direct sampling",
+}
+
+// String returns the name of the command, or its number if jsdr does not use the command.
+func (command RtlTcpCommand) String() string {
+	if name, ok := rtlTcpCommandNames[command]; ok {
+		return name
+	}
+	return fmt.Sprintf("command 0x%02x", byte(command))
+}
+
+// RtlTcpDirectSampling specifies whether the tuner is bypassed and the ADC samples an input directly.
+type RtlTcpDirectSampling uint32
+
+// The direct sampling modes.
+const (
+	RtlTcpDirectSamplingOff RtlTcpDirectSampling = iota
+	RtlTcpDirectSamplingI
+	RtlTcpDirectSamplingQ
+)
+
+// RtlTcpTuner is the type of tuner in the dongle that an rtl_tcp server is serving.
+type RtlTcpTuner uint32
+
+// The tuner types that rtl_tcp reports.
+const (
+	RtlTcpTunerUnknown RtlTcpTuner = iota
+	RtlTcpTunerE4000
+	RtlTcpTunerFC0012
+	RtlTcpTunerFC0013
+	RtlTcpTunerFC2580
+	RtlTcpTunerR820T
+	RtlTcpTunerR828D
+)
+
+// rtlTcpTunerInfo holds the properties of a tuner type.
+type rtlTcpTunerInfo struct {
+	name           string
+	frequencyRange device.SDRRange
+	// gains are the gains in tenths of a dB that the tuner supports.
+	gains []int
+}
+
+// rtlTcpTuners holds the properties of each tuner type. The gain tables are those in librtlsdr.
+var rtlTcpTuners = map[RtlTcpTuner]rtlTcpTunerInfo{
+	RtlTcpTunerUnknown: {"Unknown", device.SDRRange{Minimum: 24e6, Maximum: 1.766e9}, []int{0}},
+	RtlTcpTunerE4000: {"E4000", device.SDRRange{Minimum: 52e6, Maximum: 2.2e9},
+		[]int{-10, 15, 40, 65, 90, 115, 140, 165, 190, 215, 240, 290, 340, 420}},
+	RtlTcpTunerFC0012: {"FC0012", device.SDRRange{Minimum: 22e6, Maximum: 948.6e6},
+		[]int{-99, -40, 71, 179, 192}},
+	RtlTcpTunerFC0013: {"FC0013", device.SDRRange{Minimum: 22e6, Maximum: 1.1e9},
+		[]int{-99, -73, -65, -63, -60, -58, -54, 58, 61, 63, 65, 67, 68, 70, 71, 179, 181, 182, 184, 186, 188, 191, 197}},
+	RtlTcpTunerFC2580: {"FC2580", device.SDRRange{Minimum: 146e6, Maximum: 924e6}, []int{0}},
+	RtlTcpTunerR820T: {"R820T", device.SDRRange{Minimum: 24e6, Maximum: 1.766e9},
+		[]int{0, 9, 14, 27, 37, 77, 87, 125, 144, 157, 166, 197, 207, 229, 254, 280, 297, 328, 338, 364, 372, 386,
+			402, 421, 434, 439, 445, 480, 496}},
+	RtlTcpTunerR828D: {"R828D", device.SDRRange{Minimum: 24e6, Maximum: 1.766e9},
+		[]int{0, 9, 14, 27, 37, 77, 87, 125, 144, 157, 166, 197, 207, 229, 254, 280, 297, 328, 338, 364, 372, 386,
+			402, 421, 434, 439, 445, 480, 496}},
+}
+
+// info returns the properties of the tuner. Tuners that are not known are treated as RtlTcpTunerUnknown.
+func (tuner RtlTcpTuner) info() rtlTcpTunerInfo {
+	info, ok := rtlTcpTuners[tuner]
+	if !ok {
+		return rtlTcpTuners[RtlTcpTunerUnknown]
+	}
+	return info
+}
+
+// String returns the name of the tuner.
+func (tuner RtlTcpTuner) String() string {
+	return tuner.info().name
+}
+
+// Gains returns the gains in tenths of a dB that the tuner supports, in increasing order.
+func (tuner RtlTcpTuner) Gains() []int {
+	return tuner.info().gains
+}
+
+// rtlTcpMagic is the first 4 bytes of the dongle info header.
+const rtlTcpMagic = "RTL0"
+
+// RtlTcpHeader is the 12 byte dongle info header that an rtl_tcp server sends when a client connects.
+type RtlTcpHeader struct {
+	Tuner    RtlTcpTuner
+	NumGains uint32
+}
+
+// ReadRtlTcpHeader reads the dongle info header from r.
+//
+// Returns an error if the header cannot be read, or r is not an rtl_tcp stream.
+func ReadRtlTcpHeader(r io.Reader) (RtlTcpHeader, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return RtlTcpHeader{}, fmt.Errorf("could not read the rtl_tcp header: %s", err.Error())
+	}
+	if string(header[0:4]) != rtlTcpMagic {
+		return RtlTcpHeader{}, fmt.Errorf("invalid rtl_tcp header: %q", header[0:4])
+	}
+	return RtlTcpHeader{
+		Tuner:    RtlTcpTuner(binary.BigEndian.Uint32(header[4:8])),
+		NumGains: binary.BigEndian.Uint32(header[8:12]),
+	}, nil
+}
+
+// WriteRtlTcpHeader writes the dongle info header to w.
+func WriteRtlTcpHeader(w io.Writer, header RtlTcpHeader) error {
+	var buff [12]byte
+	copy(buff[0:4], rtlTcpMagic)
+	binary.BigEndian.PutUint32(buff[4:8], uint32(header.Tuner))
+	binary.BigEndian.PutUint32(buff[8:12], header.NumGains)
+	_, err := w.Write(buff[:])
+	return err
+}
+
+// ReadRtlTcpCommand reads a command and its parameter from r.
+func ReadRtlTcpCommand(r io.Reader) (RtlTcpCommand, uint32, error) {
+	var buff [5]byte
+	if _, err := io.ReadFull(r, buff[:]); err != nil {
+		return 0, 0, err
+	}
+	return RtlTcpCommand(buff[0]), binary.BigEndian.Uint32(buff[1:5]), nil
+}
+
+// WriteRtlTcpCommand writes a command and its parameter to w. Signed parameters, such as gains and frequency
+// corrections, are sent as their two's complement value.
+func WriteRtlTcpCommand(w io.Writer, command RtlTcpCommand, param uint32) error {
+	var buff [5]byte
+	buff[0] = byte(command)
+	binary.BigEndian.PutUint32(buff[1:5], param)
+	_, err := w.Write(buff[:])
+	return err
+}
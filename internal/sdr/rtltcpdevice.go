@@ -0,0 +1,263 @@
+package sdr
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RtlTcpDevice is an RTL-SDR dongle that is served over the network by an rtl_tcp server.
+//
+// The rtl_tcp protocol is one way: the client sends commands to change the dongle's settings, but cannot read them
+// back, so RtlTcpDevice remembers the settings that it has sent. When the device is made, it sets the dongle to
+// 100 MHz with a sample rate of 2.048 MS/s and automatic gain, so that the remembered settings match the dongle's.
+//
+// The server streams CU8 samples continuously from the time that the client connects. Samples that are received
+// while no stream is active are discarded, and samples that are received faster than the stream is read are
+// dropped and reported as an overflow.
+//
+// A RtlTcpDevice may be used concurrently on multiple go routines, for example to change its frequency while a
+// stream is being read.
+type RtlTcpDevice struct {
+	Device *Sdr
+	Args   map[string]string
+	// Servers lists the addresses, in host:port form, of the rtl_tcp servers that Enumerate returns.
+	Servers []string
+
+	mutex               sync.Mutex
+	connection          *rtlTcpConnection
+	header              RtlTcpHeader
+	centerFrequency     float64
+	sampleRate          float64
+	gain                int
+	agcEnabled          bool
+	frequencyCorrection int
+	directSampling      RtlTcpDirectSampling
+	// pending is the block that stream reads take samples from, and pendingOffset is the offset in bytes of the
+	// next unread sample in the block.
+	pending       []byte
+	pendingOffset int
+	// streamTimeNs is the timestamp of the next sample that a stream read returns.
+	streamTimeNs float64
+	scratch      []complex64
+}
+
+// rtlTcpConnection is a connection to an rtl_tcp server, and the go routine that receives samples from it.
+type rtlTcpConnection struct {
+	conn net.Conn
+	// receiving is set while a stream is active. Blocks that are received while it is not set are discarded.
+	receiving atomic.Bool
+	blocks    chan rtlTcpBlock
+	free      chan []byte
+	done      chan struct{}
+	// err is the error that stopped the receive go routine. It is set before blocks is closed.
+	err error
+}
+
+// rtlTcpBlock is a block of CU8 samples received from an rtl_tcp server.
+type rtlTcpBlock struct {
+	data []byte
+	// dropped is the number of bytes that were dropped before this block because the stream was not read fast enough.
+	dropped uint64
+}
+
+// Settings and limits for RtlTcpDevice.
+const (
+	rtlTcpDefaultPort            = "1234"
+	rtlTcpTimeout                = 5 * time.Second
+	rtlTcpDefaultCenterFrequency = 100e6
+	rtlTcpDefaultSampleRate      = 2.048e6
+	rtlTcpMTU                    = 16384
+	// rtlTcpQueueLength is the number of blocks that may be waiting to be read before blocks are dropped.
+	rtlTcpQueueLength = 64
+)
+
+// Enumerate returns the args for each of the device's Servers, and for args["address"] if it is set.
+//
+// rtl_tcp servers cannot be discovered, and each server accepts only one client at a time, so Enumerate does not
+// connect to the servers to check that they are available.
+func (dev *RtlTcpDevice) Enumerate(args map[string]string) []map[string]string {
+	addresses := dev.Servers
+	if args["address"] != "" {
+		addresses = append(addresses[:len(addresses):len(addresses)], args["address"])
+	}
+	servers := []map[string]string{}
+	for _, address := range addresses {
+		servers = append(servers, map[string]string{
+			"driver":  "rtltcp",
+			"label":   "rtl_tcp :: " + address,
+			"serial":  address,
+			"address": address,
+		})
+	}
+	return servers
+}
+
+// Make connects to the rtl_tcp server at args["address"]. If the address does not include a port, the default
+// rtl_tcp port, 1234, is used.
+//
+// Returns an error if the server cannot be reached, or does not send an rtl_tcp header.
+func (dev *RtlTcpDevice) Make(args map[string]string) error {
+	address := args["address"]
+	if address == "" {
+		return errors.New("no rtl_tcp server address provided")
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, rtlTcpDefaultPort)
+	}
+	conn, err := net.DialTimeout("tcp", address, rtlTcpTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(rtlTcpTimeout))
+	header, err := ReadRtlTcpHeader(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	conn.SetReadDeadline(time.Time{})
+	if dev.connected() {
+		dev.Unmake()
+	}
+	connection := &rtlTcpConnection{
+		conn:   conn,
+		blocks: make(chan rtlTcpBlock, rtlTcpQueueLength),
+		free:   make(chan []byte, rtlTcpQueueLength+2),
+		done:   make(chan struct{}),
+	}
+	go connection.receive()
+
+	dev.mutex.Lock()
+	dev.connection = connection
+	dev.header = header
+	dev.pending = nil
+	dev.pendingOffset = 0
+	dev.streamTimeNs = 0
+	dev.Args = args
+	dev.Device = &Sdr{DeviceProperties: args}
+	err = errors.Join(
+		dev.sendCommand(RtlTcpSetSampleRate, uint32(rtlTcpDefaultSampleRate)),
+		dev.sendCommand(RtlTcpSetFrequency, uint32(rtlTcpDefaultCenterFrequency)),
+		dev.sendCommand(RtlTcpSetGainMode, 0),
+	)
+	dev.sampleRate = rtlTcpDefaultSampleRate
+	dev.centerFrequency = rtlTcpDefaultCenterFrequency
+	dev.agcEnabled = true
+	dev.gain = 0
+	dev.frequencyCorrection = 0
+	dev.directSampling = RtlTcpDirectSamplingOff
+	dev.mutex.Unlock()
+	if err != nil {
+		dev.Unmake()
+		return err
+	}
+	return nil
+}
+
+// Unmake disconnects from the rtl_tcp server.
+func (dev *RtlTcpDevice) Unmake() error {
+	dev.mutex.Lock()
+	connection := dev.connection
+	dev.connection = nil
+	dev.Device = nil
+	dev.Args = nil
+	dev.mutex.Unlock()
+	if connection == nil {
+		return errors.New("no device to unmake")
+	}
+	err := connection.conn.Close()
+	<-connection.done
+	return err
+}
+
+// GetHardwareKey returns the hardware key for the rtl_tcp device.
+func (dev *RtlTcpDevice) GetHardwareKey() string {
+	return "RtlTcpDevice"
+}
+
+// Tuner returns the type of tuner in the dongle, as reported by the server.
+func (dev *RtlTcpDevice) Tuner() RtlTcpTuner {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.header.Tuner
+}
+
+// DirectSampling returns the direct sampling mode.
+func (dev *RtlTcpDevice) DirectSampling() RtlTcpDirectSampling {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.directSampling
+}
+
+// SetDirectSampling sets the direct sampling mode. Direct sampling bypasses the tuner so that HF signals below
+// 28.8 MHz can be received by dongles that have been modified to feed the ADC directly.
+func (dev *RtlTcpDevice) SetDirectSampling(mode RtlTcpDirectSampling) error {
+	if mode > RtlTcpDirectSamplingQ {
+		return errors.New("invalid direct sampling mode")
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if err := dev.sendCommand(RtlTcpSetDirectSampling, uint32(mode)); err != nil {
+		return err
+	}
+	dev.directSampling = mode
+	return nil
+}
+
+// connected returns true if the device is connected to an rtl_tcp server.
+func (dev *RtlTcpDevice) connected() bool {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.connection != nil
+}
+
+// sendCommand sends a command to the rtl_tcp server. dev.mutex must be held by the caller.
+func (dev *RtlTcpDevice) sendCommand(command RtlTcpCommand, param uint32) error {
+	if dev.connection == nil {
+		return errors.New("not connected to an rtl_tcp server")
+	}
+	dev.connection.conn.SetWriteDeadline(time.Now().Add(rtlTcpTimeout))
+	return WriteRtlTcpCommand(dev.connection.conn, command, param)
+}
+
+// receive reads blocks of samples from the server until the connection is closed.
+func (connection *rtlTcpConnection) receive() {
+	defer close(connection.done)
+	var dropped uint64
+	for {
+		var data []byte
+		select {
+		case data = <-connection.free:
+		default:
+			data = make([]byte, 2*rtlTcpMTU)
+		}
+		if _, err := io.ReadFull(connection.conn, data); err != nil {
+			connection.err = err
+			close(connection.blocks)
+			return
+		}
+		if !connection.receiving.Load() {
+			dropped = 0
+			connection.recycle(data)
+			continue
+		}
+		select {
+		case connection.blocks <- rtlTcpBlock{data: data, dropped: dropped}:
+			dropped = 0
+		default:
+			dropped += uint64(len(data))
+			connection.recycle(data)
+		}
+	}
+}
+
+// recycle returns a block's buffer so that it can be reused.
+func (connection *rtlTcpConnection) recycle(data []byte) {
+	select {
+	case connection.free <- data:
+	default:
+	}
+}
@@ -0,0 +1,20 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetNumChannels returns the number of channels for the specified direction.
+//
+// rtl_tcp serves a single receive channel.
+func (dev *RtlTcpDevice) GetNumChannels(direction device.Direction) uint {
+	if direction == device.DirectionRX {
+		return 1
+	}
+	return 0
+}
+
+// isChannel returns true if the rtl_tcp device has the specified direction and channel.
+func (dev *RtlTcpDevice) isChannel(direction device.Direction, channel uint) bool {
+	return channel < dev.GetNumChannels(direction)
+}
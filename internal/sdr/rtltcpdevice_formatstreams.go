@@ -0,0 +1,22 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SetupFormatStream sets up a CU8, CS16, or CF32 stream for the rtl_tcp device. The samples that are received from
+// the server are converted to the stream's format as they are read, and samples that are received while the stream
+// is inactive are discarded. The connection to the server remains open when the stream is closed, until the device
+// is unmade.
+func (dev *RtlTcpDevice) SetupFormatStream(format string, direction device.Direction, channels []uint,
+	_ map[string]string) (formatStreamDevice, error) {
+	if err := dev.validateStreamChannels(direction, channels); err != nil {
+		return nil, err
+	}
+	return newConvertedFormatStream(format, sampleProducer{
+		mtu:         rtlTcpMTU,
+		activate:    dev.activate,
+		deactivate:  dev.deactivate,
+		readSamples: dev.readSamples,
+	})
+}
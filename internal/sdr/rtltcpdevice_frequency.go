@@ -0,0 +1,84 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// rtlTcpDirectSamplingRange is the frequency range when the tuner is bypassed and the ADC samples an input directly.
+var rtlTcpDirectSamplingRange = device.SDRRange{Minimum: 0, Maximum: 28.8e6, Step: 0}
+
+// GetFrequencyRanges returns the tuning range of the dongle's tuner, or the direct sampling range when direct
+// sampling is enabled.
+func (dev *RtlTcpDevice) GetFrequencyRanges(direction device.Direction, channel uint) []device.SDRRange {
+	if !dev.isChannel(direction, channel) {
+		return []device.SDRRange{}
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.directSampling != RtlTcpDirectSamplingOff {
+		return []device.SDRRange{rtlTcpDirectSamplingRange}
+	}
+	return []device.SDRRange{dev.header.Tuner.info().frequencyRange}
+}
+
+// GetOverallCenterFrequency returns the center frequency that was last sent to the server.
+func (dev *RtlTcpDevice) GetOverallCenterFrequency(_ device.Direction, _ uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.centerFrequency
+}
+
+// SetOverallCenterFrequency tunes the dongle to the specified frequency.
+//
+// Returns an error if the frequency is outside the tuning range, or the command could not be sent.
+func (dev *RtlTcpDevice) SetOverallCenterFrequency(direction device.Direction, channel uint, newFreq float64,
+	_ map[string]string) error {
+	return dev.SetTunableElementFrequency(direction, channel, "RF", newFreq)
+}
+
+// GetTunableElementNames returns the single tunable element of the dongle.
+func (dev *RtlTcpDevice) GetTunableElementNames(_ device.Direction, _ uint) []string {
+	return []string{"RF"}
+}
+
+// GetTunableElementFrequencyRanges returns the tuning range of the "RF" tunable element.
+func (dev *RtlTcpDevice) GetTunableElementFrequencyRanges(direction device.Direction, channel uint,
+	name string) []device.SDRRange {
+	if name != "RF" {
+		return []device.SDRRange{}
+	}
+	return dev.GetFrequencyRanges(direction, channel)
+}
+
+// GetTunableElementFrequency returns the frequency of the "RF" tunable element, which is the center frequency.
+func (dev *RtlTcpDevice) GetTunableElementFrequency(direction device.Direction, channel uint, name string) float64 {
+	if name != "RF" {
+		return 0.0
+	}
+	return dev.GetOverallCenterFrequency(direction, channel)
+}
+
+// SetTunableElementFrequency sets the frequency of the "RF" tunable element, which is the center frequency.
+func (dev *RtlTcpDevice) SetTunableElementFrequency(direction device.Direction, channel uint, name string,
+	newFreq float64) error {
+	if !dev.isChannel(direction, channel) {
+		return fmt.Errorf("RtlTcpDevice does not have %s channel %d", directionAsString(direction), channel)
+	}
+	if name != "RF" {
+		return fmt.Errorf("invalid tunable element: %s", name)
+	}
+	frequencyRange := dev.GetFrequencyRanges(direction, channel)[0]
+	if newFreq < frequencyRange.Minimum || newFreq > frequencyRange.Maximum {
+		return fmt.Errorf("frequency %.1f Hz is outside the range %.1f to %.1f Hz", newFreq,
+			frequencyRange.Minimum, frequencyRange.Maximum)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if err := dev.sendCommand(RtlTcpSetFrequency, uint32(newFreq)); err != nil {
+		return err
+	}
+	dev.centerFrequency = newFreq
+	return nil
+}
@@ -0,0 +1,84 @@
+package sdr
+
+import (
+	"errors"
+	"math"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// errNoRtlTcpCorrection is returned for the DC offset and IQ balance corrections, which rtl_tcp does not support.
+var errNoRtlTcpCorrection = errors.New("rtl_tcp does not support DC offset or IQ balance correction")
+
+// HasDCOffsetMode returns false because rtl_tcp does not support DC offset correction.
+func (dev *RtlTcpDevice) HasDCOffsetMode(_ device.Direction, _ uint) bool {
+	return false
+}
+
+// GetDCOffsetMode returns false because rtl_tcp does not support DC offset correction.
+func (dev *RtlTcpDevice) GetDCOffsetMode(_ device.Direction, _ uint) bool {
+	return false
+}
+
+// SetDCOffsetMode returns an error because rtl_tcp does not support DC offset correction.
+func (dev *RtlTcpDevice) SetDCOffsetMode(_ device.Direction, _ uint, _ bool) error {
+	return errNoRtlTcpCorrection
+}
+
+// HasDCOffset returns false because rtl_tcp does not support DC offset correction.
+func (dev *RtlTcpDevice) HasDCOffset(_ device.Direction, _ uint) bool {
+	return false
+}
+
+// GetDCOffset returns an error because rtl_tcp does not support DC offset correction.
+func (dev *RtlTcpDevice) GetDCOffset(_ device.Direction, _ uint) (float64, float64, error) {
+	return 0., 0., errNoRtlTcpCorrection
+}
+
+// SetDCOffset returns an error because rtl_tcp does not support DC offset correction.
+func (dev *RtlTcpDevice) SetDCOffset(_ device.Direction, _ uint, _, _ float64) error {
+	return errNoRtlTcpCorrection
+}
+
+// HasIQBalance returns false because rtl_tcp does not support IQ balance correction.
+func (dev *RtlTcpDevice) HasIQBalance(_ device.Direction, _ uint) bool {
+	return false
+}
+
+// GetIQBalance returns an error because rtl_tcp does not support IQ balance correction.
+func (dev *RtlTcpDevice) GetIQBalance(_ device.Direction, _ uint) (float64, float64, error) {
+	return 0., 0., errNoRtlTcpCorrection
+}
+
+// SetIQBalance returns an error because rtl_tcp does not support IQ balance correction.
+func (dev *RtlTcpDevice) SetIQBalance(_ device.Direction, _ uint, _, _ float64) error {
+	return errNoRtlTcpCorrection
+}
+
+// HasFrequencyCorrection returns true for the receive channel.
+func (dev *RtlTcpDevice) HasFrequencyCorrection(direction device.Direction, channel uint) bool {
+	return dev.isChannel(direction, channel)
+}
+
+// GetFrequencyCorrection returns the frequency correction in parts per million.
+func (dev *RtlTcpDevice) GetFrequencyCorrection(_ device.Direction, _ uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return float64(dev.frequencyCorrection)
+}
+
+// SetFrequencyCorrection sets the frequency correction, in parts per million, for the dongle's crystal. rtl_tcp only
+// accepts whole PPM values, so the correction is rounded to the nearest PPM.
+func (dev *RtlTcpDevice) SetFrequencyCorrection(direction device.Direction, channel uint, ppm float64) error {
+	if !dev.HasFrequencyCorrection(direction, channel) {
+		return errors.New("frequency correction is only supported on the receive channel")
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	correction := int(math.Round(ppm))
+	if err := dev.sendCommand(RtlTcpSetFrequencyCorrection, uint32(int32(correction))); err != nil {
+		return err
+	}
+	dev.frequencyCorrection = correction
+	return nil
+}
@@ -0,0 +1,123 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SupportsAGC returns true for the receive channel. AGC is the tuner's automatic gain mode.
+func (dev *RtlTcpDevice) SupportsAGC(direction device.Direction, channel uint) bool {
+	return dev.isChannel(direction, channel)
+}
+
+// AgcIsEnabled returns whether the tuner is in automatic gain mode.
+func (dev *RtlTcpDevice) AgcIsEnabled(_ device.Direction, _ uint) bool {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.agcEnabled
+}
+
+// EnableAgc selects automatic or manual tuner gain. When AGC is disabled, the last gain that was set is restored.
+func (dev *RtlTcpDevice) EnableAgc(direction device.Direction, channel uint, enable bool) error {
+	if !dev.SupportsAGC(direction, channel) {
+		return errors.New("AGC is only supported on the receive channel")
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if enable {
+		if err := dev.sendCommand(RtlTcpSetGainMode, 0); err != nil {
+			return err
+		}
+	} else if err := dev.setManualGain(dev.gain); err != nil {
+		return err
+	}
+	dev.agcEnabled = enable
+	return nil
+}
+
+// GetGainElementNames returns the single "TUNER" gain element.
+func (dev *RtlTcpDevice) GetGainElementNames(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	return []string{"TUNER"}
+}
+
+// GetElementGain returns the gain in dB of the "TUNER" element.
+func (dev *RtlTcpDevice) GetElementGain(direction device.Direction, channel uint, eltName string) (float64, error) {
+	if eltName != "TUNER" {
+		return 0.0, fmt.Errorf("gain element '%s' is invalid", eltName)
+	}
+	return dev.GetOverallGain(direction, channel), nil
+}
+
+// SetElementGain sets the gain of the "TUNER" element.
+//
+// Tuners support only a set of discrete gains, so the supported gain that is closest to the requested gain is set.
+// Setting the gain switches the tuner to manual gain mode.
+func (dev *RtlTcpDevice) SetElementGain(direction device.Direction, channel uint, eltName string, gain float64) error {
+	if eltName != "TUNER" || !dev.isChannel(direction, channel) {
+		return fmt.Errorf("gain element '%s' is invalid", eltName)
+	}
+	gainRange := dev.GetElementGainRange(direction, channel, eltName)
+	if gain < gainRange.Minimum || gain > gainRange.Maximum {
+		return fmt.Errorf("cannot set gain for element: %s to %.1f. Requested gain is outside the allowable range: %.1f to %.1f",
+			eltName, gain, gainRange.Minimum, gainRange.Maximum)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	tenths := closestRtlTcpGain(dev.header.Tuner.Gains(), gain)
+	if err := dev.setManualGain(tenths); err != nil {
+		return err
+	}
+	dev.gain = tenths
+	dev.agcEnabled = false
+	return nil
+}
+
+// GetElementGainRange returns the range of gains that the tuner supports.
+func (dev *RtlTcpDevice) GetElementGainRange(_ device.Direction, _ uint, eltName string) device.SDRRange {
+	if eltName != "TUNER" {
+		return device.SDRRange{}
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	gains := dev.header.Tuner.Gains()
+	return device.SDRRange{Minimum: float64(gains[0]) / 10, Maximum: float64(gains[len(gains)-1]) / 10, Step: 0}
+}
+
+// GetOverallGain returns the manual tuner gain in dB.
+func (dev *RtlTcpDevice) GetOverallGain(_ device.Direction, _ uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return float64(dev.gain) / 10
+}
+
+// SetOverallGain sets the tuner gain. The dongle has a single gain element, so this is the same as setting the
+// gain of the "TUNER" element.
+func (dev *RtlTcpDevice) SetOverallGain(direction device.Direction, channel uint, overallGain float64) error {
+	return dev.SetElementGain(direction, channel, "TUNER", overallGain)
+}
+
+// setManualGain switches the tuner to manual gain mode and sets the gain in tenths of a dB. dev.mutex must be held
+// by the caller.
+func (dev *RtlTcpDevice) setManualGain(tenths int) error {
+	if err := dev.sendCommand(RtlTcpSetGainMode, 1); err != nil {
+		return err
+	}
+	return dev.sendCommand(RtlTcpSetGain, uint32(int32(tenths)))
+}
+
+// closestRtlTcpGain returns the gain in tenths of a dB in gains that is closest to gain in dB.
+func closestRtlTcpGain(gains []int, gain float64) int {
+	closest := gains[0]
+	for _, g := range gains {
+		if math.Abs(float64(g)-gain*10) < math.Abs(float64(closest)-gain*10) {
+			closest = g
+		}
+	}
+	return closest
+}
@@ -0,0 +1,57 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// rtlTcpSampleRateRanges are the ranges of sample rates that the RTL2832 supports.
+var rtlTcpSampleRateRanges = []device.SDRRange{
+	{Minimum: 225001, Maximum: 300000, Step: 0},
+	{Minimum: 900001, Maximum: 3200000, Step: 0},
+}
+
+// GetSampleRateRange returns the ranges of sample rates that the dongle supports.
+func (dev *RtlTcpDevice) GetSampleRateRange(direction device.Direction, channel uint) []device.SDRRange {
+	if !dev.isChannel(direction, channel) {
+		return []device.SDRRange{}
+	}
+	return rtlTcpSampleRateRanges
+}
+
+// GetSampleRate returns the sample rate that was last sent to the server.
+func (dev *RtlTcpDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).SampleRate = dev.sampleRate
+	}
+	return dev.sampleRate
+}
+
+// SetSampleRate sets the sample rate.
+//
+// Returns an error if the rate is outside the supported ranges, or the command could not be sent.
+func (dev *RtlTcpDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
+	if !dev.isChannel(direction, channel) {
+		return fmt.Errorf("RtlTcpDevice does not have %s channel %d", directionAsString(direction), channel)
+	}
+	supported := false
+	for _, r := range rtlTcpSampleRateRanges {
+		supported = supported || (rate >= r.Minimum && rate <= r.Maximum)
+	}
+	if !supported {
+		return fmt.Errorf("sample rate %.1f is not supported by RTL-SDR dongles", rate)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if err := dev.sendCommand(RtlTcpSetSampleRate, uint32(rate)); err != nil {
+		return err
+	}
+	dev.sampleRate = rate
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).SampleRate = rate
+	}
+	return nil
+}
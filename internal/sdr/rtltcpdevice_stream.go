@@ -0,0 +1,161 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetStreamFormats returns the stream formats that the rtl_tcp device supports. Samples are converted from CU8 to
+// the stream's format as they are read.
+func (dev *RtlTcpDevice) GetStreamFormats(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	return []string{FormatCU8, FormatCS8, FormatCS16, FormatCF32}
+}
+
+// GetNativeStreamFormat returns CU8 with a full scale value of 128, which is the format that rtl_tcp sends.
+func (dev *RtlTcpDevice) GetNativeStreamFormat(_ device.Direction, _ uint) (string, float64) {
+	return FormatCU8, defaultFullScales[FormatCU8]
+}
+
+// validateStreamChannels returns an error if the device is not connected, or any of the channels does not exist.
+func (dev *RtlTcpDevice) validateStreamChannels(direction device.Direction, channels []uint) error {
+	if !dev.connected() {
+		return errors.New("not connected to an rtl_tcp server")
+	}
+	for _, channel := range channels {
+		if !dev.isChannel(direction, channel) {
+			return fmt.Errorf("RtlTcpDevice does not have %s channel %d", directionAsString(direction), channel)
+		}
+	}
+	return nil
+}
+
+// activate starts passing the samples that are received from the server to stream reads. Samples that were
+// received before the stream was activated are discarded.
+func (dev *RtlTcpDevice) activate() error {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.connection == nil {
+		return errors.New("not connected to an rtl_tcp server")
+	}
+	dev.discardPending()
+	dev.connection.receiving.Store(true)
+	return nil
+}
+
+// deactivate stops passing received samples to stream reads.
+func (dev *RtlTcpDevice) deactivate() {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.connection != nil {
+		dev.connection.receiving.Store(false)
+		dev.discardPending()
+	}
+}
+
+// discardPending discards the samples that have been received but not read. dev.mutex must be held by the caller.
+func (dev *RtlTcpDevice) discardPending() {
+	if dev.pending != nil {
+		dev.connection.recycle(dev.pending)
+		dev.pending = nil
+	}
+	for {
+		select {
+		case block, ok := <-dev.connection.blocks:
+			if !ok {
+				return
+			}
+			dev.connection.recycle(block.data)
+		default:
+			return
+		}
+	}
+}
+
+// readSamples reads up to numElems of the samples that have been received from the server and passes them to
+// store, which copies them into a stream buffer.
+//
+// If no samples have been received, readSamples waits up to timeoutUs for them to arrive. If samples were dropped
+// because the stream was not read fast enough, ErrStreamOverflow is returned and the next read continues with the
+// samples that were received after those that were dropped.
+//
+// Returns the timestamp of the first sample in nanoseconds, the number of samples read, and an error or nil.
+func (dev *RtlTcpDevice) readSamples(numElems uint, outputFlags *[1]int, timeoutUs uint,
+	store func([]complex64)) (uint, uint, error) {
+	outputFlags[0] = 0
+	dev.mutex.Lock()
+	connection := dev.connection
+	havePending := dev.pending != nil
+	dev.mutex.Unlock()
+	if connection == nil {
+		return 0, 0, errors.New("not connected to an rtl_tcp server")
+	}
+	if !havePending {
+		block, err := connection.nextBlock(timeoutUs)
+		if err != nil {
+			return 0, 0, err
+		}
+		dev.mutex.Lock()
+		dev.pending = block.data
+		dev.pendingOffset = 0
+		if block.dropped > 0 {
+			dev.streamTimeNs += float64(block.dropped/2) * 1e9 / dev.sampleRate
+			dev.mutex.Unlock()
+			return 0, 0, ErrStreamOverflow
+		}
+		dev.mutex.Unlock()
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if dev.pending == nil {
+		// The stream was deactivated while waiting for samples.
+		return 0, 0, ErrStreamTimeout
+	}
+	numElems = min(numElems, uint(len(dev.pending)-dev.pendingOffset)/2)
+	if uint(cap(dev.scratch)) < numElems {
+		dev.scratch = make([]complex64, numElems)
+	}
+	samples := dev.scratch[:numElems]
+	decodeSamples(FormatCU8, samples, dev.pending[dev.pendingOffset:])
+	store(samples)
+	timeNs := uint(dev.streamTimeNs)
+	dev.streamTimeNs += float64(numElems) * 1e9 / dev.sampleRate
+	dev.pendingOffset += 2 * int(numElems)
+	if dev.pendingOffset == len(dev.pending) {
+		connection.recycle(dev.pending)
+		dev.pending = nil
+	}
+	outputFlags[0] = int(device.StreamFlagHasTime)
+	return timeNs, numElems, nil
+}
+
+// nextBlock returns the next block of samples that was received from the server, waiting up to timeoutUs for one to
+// arrive.
+func (connection *rtlTcpConnection) nextBlock(timeoutUs uint) (rtlTcpBlock, error) {
+	select {
+	case block, ok := <-connection.blocks:
+		return connection.received(block, ok)
+	default:
+	}
+	timer := time.NewTimer(time.Duration(timeoutUs) * time.Microsecond)
+	defer timer.Stop()
+	select {
+	case block, ok := <-connection.blocks:
+		return connection.received(block, ok)
+	case <-timer.C:
+		return rtlTcpBlock{}, ErrStreamTimeout
+	}
+}
+
+// received returns block, or the error that closed the connection if ok is false.
+func (connection *rtlTcpConnection) received(block rtlTcpBlock, ok bool) (rtlTcpBlock, error) {
+	if !ok {
+		return rtlTcpBlock{}, fmt.Errorf("the connection to the rtl_tcp server was lost: %s", connection.err.Error())
+	}
+	return block, nil
+}
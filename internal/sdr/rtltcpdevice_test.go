@@ -0,0 +1,378 @@
+package sdr_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ sdr.Enumerate = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.MakeDevice = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.Channels = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.Frequency = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.Gain = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.Agc = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.Antenna = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.SampleRates = (*sdr.RtlTcpDevice)(nil)
+var _ sdr.SampleStreams = (*sdr.RtlTcpDevice)(nil)
+
+// rtlTcpCommand is a command received by fakeRtlTcpServer.
+type rtlTcpCommand struct {
+	command sdr.RtlTcpCommand
+	param   uint32
+}
+
+// fakeRtlTcpServer is an in-process rtl_tcp server. It records the commands that it receives, and streams CU8
+// samples whose I values count up and whose Q values count down.
+type fakeRtlTcpServer struct {
+	listener net.Listener
+	// samplesPerMs is the rate at which samples are streamed.
+	samplesPerMs int
+
+	mutex    sync.Mutex
+	commands []rtlTcpCommand
+	conns    []net.Conn
+}
+
+// newFakeRtlTcpServer starts a fake rtl_tcp server on a loopback port. The server is closed when the test ends.
+func newFakeRtlTcpServer(t *testing.T, samplesPerMs int) *fakeRtlTcpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	server := &fakeRtlTcpServer{listener: listener, samplesPerMs: samplesPerMs}
+	go server.serve()
+	t.Cleanup(server.close)
+	return server
+}
+
+func (server *fakeRtlTcpServer) address() string {
+	return server.listener.Addr().String()
+}
+
+func (server *fakeRtlTcpServer) serve() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		server.mutex.Lock()
+		server.conns = append(server.conns, conn)
+		server.mutex.Unlock()
+		sdr.WriteRtlTcpHeader(conn, sdr.RtlTcpHeader{Tuner: sdr.RtlTcpTunerR820T, NumGains: 29})
+		go server.stream(conn)
+		go func() {
+			for {
+				command, param, err := sdr.ReadRtlTcpCommand(conn)
+				if err != nil {
+					return
+				}
+				server.mutex.Lock()
+				server.commands = append(server.commands, rtlTcpCommand{command, param})
+				server.mutex.Unlock()
+			}
+		}()
+	}
+}
+
+func (server *fakeRtlTcpServer) stream(conn net.Conn) {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	data := make([]byte, 2*server.samplesPerMs)
+	var count byte
+	for range ticker.C {
+		for i := 0; i < len(data); i += 2 {
+			data[i] = count
+			data[i+1] = 255 - count
+			count++
+		}
+		if _, err := conn.Write(data); err != nil {
+			return
+		}
+	}
+}
+
+// closeConnections closes the connections to the clients, as happens when the server is stopped.
+func (server *fakeRtlTcpServer) closeConnections() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for _, conn := range server.conns {
+		conn.Close()
+	}
+}
+
+func (server *fakeRtlTcpServer) close() {
+	server.listener.Close()
+	server.closeConnections()
+}
+
+// receivedCommands waits for the server to receive numCommands commands, then returns the commands and clears them.
+func (server *fakeRtlTcpServer) receivedCommands(t *testing.T, numCommands int) []rtlTcpCommand {
+	require.Eventually(t, func() bool {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return len(server.commands) >= numCommands
+	}, time.Second, time.Millisecond)
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	commands := server.commands
+	server.commands = nil
+	return commands
+}
+
+// makeRtlTcpDevice makes a RtlTcpDevice that is connected to server, and discards the commands sent by Make.
+func makeRtlTcpDevice(t *testing.T, testLogger *logger.Logger, server *fakeRtlTcpServer) *sdr.RtlTcpDevice {
+	rtlTcp := &sdr.RtlTcpDevice{}
+	require.Nil(t, sdr.Make(rtlTcp, map[string]string{"address": server.address()}, testLogger))
+	t.Cleanup(func() { rtlTcp.Unmake() })
+	server.receivedCommands(t, 3)
+	return rtlTcp
+}
+
+func TestRtlTcpHeader(t *testing.T) {
+	var buff bytes.Buffer
+	require.Nil(t, sdr.WriteRtlTcpHeader(&buff, sdr.RtlTcpHeader{Tuner: sdr.RtlTcpTunerE4000, NumGains: 14}))
+	assert.Equal(t, []byte{'R', 'T', 'L', '0', 0, 0, 0, 1, 0, 0, 0, 14}, buff.Bytes())
+	header, err := sdr.ReadRtlTcpHeader(&buff)
+	require.Nil(t, err)
+	assert.Equal(t, sdr.RtlTcpTunerE4000, header.Tuner)
+	assert.Equal(t, uint32(14), header.NumGains)
+	assert.Equal(t, "E4000", header.Tuner.String())
+	assert.Equal(t, 14, len(header.Tuner.Gains()))
+	assert.Equal(t, "Unknown", sdr.RtlTcpTuner(42).String())
+
+	_, err = sdr.ReadRtlTcpHeader(strings.NewReader("HTTP/1.1 200"))
+	assert.Equal(t, `invalid rtl_tcp header: "HTTP"`, err.Error())
+	_, err = sdr.ReadRtlTcpHeader(strings.NewReader("RTL0"))
+	assert.NotNil(t, err)
+
+	require.Nil(t, sdr.WriteRtlTcpCommand(&buff, sdr.RtlTcpSetFrequency, 100000000))
+	assert.Equal(t, []byte{0x01, 0x05, 0xf5, 0xe1, 0x00}, buff.Bytes())
+	command, param, err := sdr.ReadRtlTcpCommand(&buff)
+	require.Nil(t, err)
+	assert.Equal(t, sdr.RtlTcpSetFrequency, command)
+	assert.Equal(t, uint32(100000000), param)
+	assert.Equal(t, "set frequency", command.String())
+	assert.Equal(t, "command 0x0d", sdr.RtlTcpCommand(0x0d).String())
+}
+
+func TestRtlTcpDevice_Enumerate(t *testing.T) {
+	rtlTcp := &sdr.RtlTcpDevice{Servers: []string{"pi1:1234", "pi2:1234"}}
+	servers := rtlTcp.Enumerate(nil)
+	require.Equal(t, 2, len(servers))
+	assert.Equal(t, map[string]string{"driver": "rtltcp", "label": "rtl_tcp :: pi1:1234", "serial": "pi1:1234",
+		"address": "pi1:1234"}, servers[0])
+	servers = rtlTcp.Enumerate(map[string]string{"address": "pi3:1234"})
+	assert.Equal(t, 3, len(servers))
+	assert.Equal(t, 2, len(rtlTcp.Servers))
+	assert.Equal(t, 0, len((&sdr.RtlTcpDevice{}).Enumerate(nil)))
+}
+
+func TestRtlTcpDevice_Make(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	server := newFakeRtlTcpServer(t, 256)
+	rtlTcp := &sdr.RtlTcpDevice{}
+	require.Nil(t, sdr.Make(rtlTcp, map[string]string{"address": server.address()}, testLogger))
+	assert.Equal(t, sdr.RtlTcpTunerR820T, rtlTcp.Tuner())
+	assert.Equal(t, "RtlTcpDevice", rtlTcp.GetHardwareKey())
+	// Make sets the dongle to the settings that the device reports.
+	assert.Equal(t, []rtlTcpCommand{
+		{sdr.RtlTcpSetSampleRate, 2048000},
+		{sdr.RtlTcpSetFrequency, 100000000},
+		{sdr.RtlTcpSetGainMode, 0},
+	}, server.receivedCommands(t, 3))
+	assert.Equal(t, 100e6, rtlTcp.GetOverallCenterFrequency(device.DirectionRX, 0))
+	assert.Equal(t, 2.048e6, rtlTcp.GetSampleRate(device.DirectionRX, 0))
+	assert.True(t, rtlTcp.AgcIsEnabled(device.DirectionRX, 0))
+	assert.Nil(t, sdr.Unmake(rtlTcp, testLogger))
+	assert.NotNil(t, rtlTcp.Unmake())
+	assert.NotNil(t, rtlTcp.SetSampleRate(device.DirectionRX, 0, 1e6))
+
+	assert.NotNil(t, rtlTcp.Make(map[string]string{}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Write([]byte("SSH-2.0-OpenSSH"))
+			conn.Close()
+		}
+	}()
+	err = rtlTcp.Make(map[string]string{"address": listener.Addr().String()})
+	assert.Equal(t, `invalid rtl_tcp header: "SSH-"`, err.Error())
+	listener.Close()
+	assert.NotNil(t, rtlTcp.Make(map[string]string{"address": listener.Addr().String()}))
+}
+
+func TestRtlTcpDevice_Settings(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	server := newFakeRtlTcpServer(t, 256)
+	rtlTcp := makeRtlTcpDevice(t, testLogger, server)
+
+	require.Nil(t, sdr.SetOverallCenterFrequency(rtlTcp, testLogger, device.DirectionRX, 0, 433.92e6, nil))
+	require.Nil(t, rtlTcp.SetSampleRate(device.DirectionRX, 0, 250000))
+	assert.Equal(t, []rtlTcpCommand{{sdr.RtlTcpSetFrequency, 433920000}, {sdr.RtlTcpSetSampleRate, 250000}},
+		server.receivedCommands(t, 2))
+	assert.Equal(t, 433.92e6, rtlTcp.GetOverallCenterFrequency(device.DirectionRX, 0))
+	assert.Equal(t, 250000., rtlTcp.GetSampleRate(device.DirectionRX, 0))
+	assert.NotNil(t, rtlTcp.SetOverallCenterFrequency(device.DirectionRX, 0, 10e6, nil))
+	assert.NotNil(t, rtlTcp.SetSampleRate(device.DirectionRX, 0, 500000))
+	assert.NotNil(t, rtlTcp.SetSampleRate(device.DirectionTX, 0, 250000))
+
+	// The R820T does not support 20 dB, so the closest gain is set.
+	assert.Equal(t, device.SDRRange{Minimum: 0, Maximum: 49.6, Step: 0},
+		rtlTcp.GetElementGainRange(device.DirectionRX, 0, "TUNER"))
+	require.Nil(t, rtlTcp.SetOverallGain(device.DirectionRX, 0, 20))
+	assert.Equal(t, []rtlTcpCommand{{sdr.RtlTcpSetGainMode, 1}, {sdr.RtlTcpSetGain, 197}},
+		server.receivedCommands(t, 2))
+	assert.Equal(t, 19.7, rtlTcp.GetOverallGain(device.DirectionRX, 0))
+	assert.False(t, rtlTcp.AgcIsEnabled(device.DirectionRX, 0))
+	assert.NotNil(t, rtlTcp.SetElementGain(device.DirectionRX, 0, "TUNER", 50))
+	assert.NotNil(t, rtlTcp.SetElementGain(device.DirectionRX, 0, "IF", 10))
+	require.Nil(t, rtlTcp.EnableAgc(device.DirectionRX, 0, true))
+	require.Nil(t, rtlTcp.EnableAgc(device.DirectionRX, 0, false))
+	assert.Equal(t, []rtlTcpCommand{{sdr.RtlTcpSetGainMode, 0}, {sdr.RtlTcpSetGainMode, 1}, {sdr.RtlTcpSetGain, 197}},
+		server.receivedCommands(t, 3))
+
+	require.Nil(t, rtlTcp.SetFrequencyCorrection(device.DirectionRX, 0, -5.2))
+	assert.Equal(t, -5., rtlTcp.GetFrequencyCorrection(device.DirectionRX, 0))
+	assert.NotNil(t, rtlTcp.SetFrequencyCorrection(device.DirectionTX, 0, 1))
+	assert.False(t, rtlTcp.HasDCOffsetMode(device.DirectionRX, 0))
+	require.Nil(t, rtlTcp.SetDirectSampling(sdr.RtlTcpDirectSamplingQ))
+	assert.Equal(t, []device.SDRRange{{Minimum: 0, Maximum: 28.8e6, Step: 0}},
+		rtlTcp.GetFrequencyRanges(device.DirectionRX, 0))
+	require.Nil(t, rtlTcp.SetOverallCenterFrequency(device.DirectionRX, 0, 7.1e6, nil))
+	assert.Equal(t, []rtlTcpCommand{{sdr.RtlTcpSetFrequencyCorrection, 0xfffffffb},
+		{sdr.RtlTcpSetDirectSampling, 2}, {sdr.RtlTcpSetFrequency, 7100000}}, server.receivedCommands(t, 3))
+}
+
+func TestRtlTcpDevice_SampleRateWhileUnmaking(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	server := newFakeRtlTcpServer(t, 256)
+	rtlTcp := makeRtlTcpDevice(t, testLogger, server)
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		close(started)
+		for i := 0; i < 1000; i++ {
+			rtlTcp.GetSampleRate(device.DirectionRX, 0)
+		}
+	}()
+	<-started
+	// Run with -race: the sample rate must not be stored in Device while Unmake is clearing it.
+	rtlTcp.Unmake()
+	<-done
+	assert.Nil(t, rtlTcp.Device)
+}
+
+func TestRtlTcpDevice_Stream(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	server := newFakeRtlTcpServer(t, 256)
+	rtlTcp := makeRtlTcpDevice(t, testLogger, server)
+	stream, err := sdr.SetupFormatStream[uint8](rtlTcp, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+
+	// The samples are read in pieces that do not match the server's writes, but are continuous.
+	buffer := [][]uint8{make([]uint8, 2*1000)}
+	var outputFlags [1]int
+	var last uint8
+	for read := 0; read < 3; read++ {
+		timeNs, numRead, err := stream.ReadFromStream(testLogger, buffer, 1000, &outputFlags, 1000000)
+		require.Nil(t, err)
+		require.Equal(t, uint(1000), numRead)
+		assert.InDelta(t, float64(read)*1000*1e9/2.048e6, float64(timeNs), 1)
+		assert.Equal(t, int(device.StreamFlagHasTime), outputFlags[0])
+		for i := 0; i < 1000; i++ {
+			if read > 0 || i > 0 {
+				require.Equal(t, last+1, buffer[0][2*i])
+			}
+			last = buffer[0][2*i]
+			require.Equal(t, 255-last, buffer[0][2*i+1])
+		}
+	}
+}
+
+func TestRtlTcpDevice_SampleSource(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	server := newFakeRtlTcpServer(t, 256)
+	rtlTcp := makeRtlTcpDevice(t, testLogger, server)
+	source, err := sdr.NewSampleSource(rtlTcp, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	assert.Equal(t, sdr.FormatCU8, source.Format())
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	samples := [][]complex64{make([]complex64, 512)}
+	var outputFlags [1]int
+	_, _, err = source.ReadSamples(testLogger, samples, 512, &outputFlags, 1000000)
+	require.Nil(t, err)
+	for _, sample := range samples[0] {
+		// I and Q are symmetrical about the CU8 zero point of 127.5.
+		require.InDelta(t, -1./128, real(sample)+imag(sample), 1e-6)
+	}
+}
+
+func TestRtlTcpDevice_Overflow(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	server := newFakeRtlTcpServer(t, 16384)
+	rtlTcp := makeRtlTcpDevice(t, testLogger, server)
+	stream, err := sdr.SetupFormatStream[uint8](rtlTcp, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+
+	// The server sends samples faster than the stream's queue can hold them when the stream is not read.
+	time.Sleep(300 * time.Millisecond)
+	buffer := [][]uint8{make([]uint8, 2*16384)}
+	var outputFlags [1]int
+	overflowed := false
+	for read := 0; read < 100 && !overflowed; read++ {
+		_, _, err = stream.ReadFromStream(testLogger, buffer, 16384, &outputFlags, 1000000)
+		overflowed = errors.Is(err, sdr.ErrStreamOverflow)
+	}
+	assert.True(t, overflowed)
+	_, numRead, err := stream.ReadFromStream(testLogger, buffer, 16384, &outputFlags, 1000000)
+	require.Nil(t, err)
+	assert.Equal(t, uint(16384), numRead)
+}
+
+func TestRtlTcpDevice_ConnectionLost(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	server := newFakeRtlTcpServer(t, 256)
+	rtlTcp := makeRtlTcpDevice(t, testLogger, server)
+	source, err := sdr.NewSampleSource(rtlTcp, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, rtlTcp.GetSampleRate(device.DirectionRX, 0), nil)
+	require.Nil(t, receiver.Start(context.Background()))
+	block := <-receiver.Blocks()
+	assert.Equal(t, uint64(0), block.Sequence)
+	block.Release()
+	server.closeConnections()
+	for block := range receiver.Blocks() {
+		block.Release()
+	}
+	err = receiver.Wait()
+	require.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "the connection to the rtl_tcp server was lost"))
+}
@@ -1,9 +1,9 @@
 package sdr
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -19,89 +19,129 @@ type SampleRates interface {
 	SetSampleRate(device.Direction, uint, float64) error
 }
 
-// map of possible sample rates
-var sampleRatesMap = map[float64]string{
-	256000.0:   "0.256 MS/s",
-	512000.0:   "0.512 MS/s",
-	1024000.0:  "1.024 MS/s",
-	1600000.0:  "1.6 MS/s",
-	2048000.0:  "2.048 MS/s",
-	2400000.0:  "2.4 MS/s",
-	2800000.0:  "2.8 MS/s",
-	3200000.0:  "3.2 MS/s",
-	4000000.0:  "4.0 MS/s",
-	5000000.0:  "5.0 MS/s",
-	6000000.0:  "6.0 MS/s",
-	7000000.0:  "7.0 MS/s",
-	8000000.0:  "8.0 MS/s",
-	9000000.0:  "9.0 MS/s",
-	10000000.0: "10.0 MS/s",
+// commonSampleRates are the sample rates that GetSampleRates offers for continuous sample rate ranges. They cover
+// the rates that are commonly used by RTL-SDR dongles, HackRFs, Airspys, LimeSDRs, and USRPs.
+var commonSampleRates = []float64{
+	250e3, 256e3, 500e3, 512e3, 1e6, 1.024e6, 1.2e6, 1.6e6, 2e6, 2.048e6, 2.4e6, 2.56e6, 2.8e6, 3.2e6,
+	4e6, 5e6, 6e6, 7e6, 8e6, 9e6, 10e6, 12.5e6, 15.36e6, 16e6, 20e6, 25e6, 30.72e6, 40e6, 50e6, 56e6, 61.44e6,
 }
 
-// GetSampleRate returns the sample rate that most closely matches the current sample rate for the SDR.
-func GetSampleRate(sdrD SampleRates, log *logger.Logger) string {
-	sampleRate := sdrD.GetSampleRate(device.DirectionRX, 0)
-	log.Logf(logger.Debug, "Current sample rate: %f\n", sampleRate)
-	closestRate := closestSampleRate(sampleRate, log)
-	log.Logf(logger.Debug, "Closest sample rate: %f\n", closestRate)
-	log.Logf(logger.Debug, "GetSampleRate returning %s\n", sampleRatesMap[closestRate])
-	return sampleRatesMap[closestRate]
+// maxSteppedSampleRates is the largest number of sample rates in a stepped sample rate range for which every step
+// is offered by GetSampleRates. Only the common sample rates that fall on a step are offered for larger ranges.
+const maxSteppedSampleRates = 32
+
+// sampleRateTolerance is the largest difference in Hz between the requested sample rate and the sample rate that is
+// read back from the device for the rate to be considered set.
+const sampleRateTolerance = 1.0
+
+// GetSampleRate returns the current sample rate for the SDR in samples per second.
+func GetSampleRate(sdrD SampleRates, log *logger.Logger, direction device.Direction, channel uint) float64 {
+	sampleRate := sdrD.GetSampleRate(direction, channel)
+	log.Logf(logger.Debug, "Current sample rate: %.1f\n", sampleRate)
+	return sampleRate
 }
 
-// GetSampleRates retrieves a string slice of sample rates based on the sample rate ranges for the SDR.
-func GetSampleRates(sdrD SampleRates, log *logger.Logger) []string {
-	sampleRateRanges := sdrD.GetSampleRateRange(device.DirectionRX, 0)
-	var rMsg strings.Builder
+// GetSampleRates returns the sample rates that can be selected for the SDR, in ascending order. The rates are
+// derived from the device's sample rate ranges:
+//   - a range whose minimum and maximum are equal is a single discrete rate.
+//   - every rate in a stepped range is returned if there are no more than 32 of them.
+//   - otherwise, the common sample rates within the range are returned. Rates in a stepped range must fall on a
+//     step. The minimum and maximum of the range are returned if no common sample rate is within the range.
+func GetSampleRates(sdrD SampleRates, log *logger.Logger, direction device.Direction, channel uint) []float64 {
+	sampleRateRanges := sdrD.GetSampleRateRange(direction, channel)
 	if len(sampleRateRanges) == 0 {
-		rMsg.WriteString("There are no sample rate ranges for the specified SDR\n")
-	} else {
-		rMsg.WriteString("Sample Rate ranges:\n")
-		for _, srR := range sampleRateRanges {
-			rMsg.WriteString(fmt.Sprintf("         %v\n", srR))
+		log.Log(logger.Debug, "There are no sample rate ranges for the specified SDR\n")
+		return []float64{}
+	}
+	var rMsg strings.Builder
+	rMsg.WriteString("Sample Rate ranges:\n")
+	for _, srR := range sampleRateRanges {
+		rMsg.WriteString(fmt.Sprintf("         %v\n", srR))
+	}
+	log.Log(logger.Debug, rMsg.String())
+
+	var rates []float64
+	for _, srR := range sampleRateRanges {
+		rates = append(rates, sampleRateCandidates(srR)...)
+	}
+	slices.Sort(rates)
+	rates = slices.Compact(rates)
+
+	rMsg.Reset()
+	rMsg.WriteString("Sample Rates found:\n")
+	for _, rate := range rates {
+		rMsg.WriteString(fmt.Sprintf("         %s\n", FormatSampleRate(rate)))
+	}
+	log.Log(logger.Debug, rMsg.String())
+	return rates
+}
+
+// sampleRateCandidates returns the sample rates that are offered for a single sample rate range. See GetSampleRates.
+func sampleRateCandidates(srR device.SDRRange) []float64 {
+	if srR.Maximum <= srR.Minimum {
+		return []float64{srR.Minimum}
+	}
+	if srR.Step > 0 {
+		steps := int(math.Floor((srR.Maximum-srR.Minimum)/srR.Step+1e-9)) + 1
+		if steps <= maxSteppedSampleRates {
+			rates := make([]float64, 0, steps)
+			for i := range steps {
+				rates = append(rates, srR.Minimum+float64(i)*srR.Step)
+			}
+			return rates
 		}
-		log.Log(logger.Debug, rMsg.String())
 	}
-	return getSampleRatesAsStrings(sampleRateRanges, log)
+	var rates []float64
+	for _, rate := range commonSampleRates {
+		if rate < srR.Minimum || rate > srR.Maximum {
+			continue
+		}
+		if srR.Step > 0 {
+			steps := (rate - srR.Minimum) / srR.Step
+			if math.Abs(steps-math.Round(steps)) > 1e-6 {
+				continue
+			}
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		rates = []float64{srR.Minimum, srR.Maximum}
+	}
+	return rates
+}
+
+// FormatSampleRate returns a sample rate in MS/s for display, for example "2.048 MS/s" for 2048000.
+func FormatSampleRate(rate float64) string {
+	return strconv.FormatFloat(rate/1e6, 'f', -1, 64) + " MS/s"
 }
 
-// SetSampleRate sets the sample rate to the specified value.
+// SetSampleRate sets the sample rate to the specified value in samples per second. The rate does not need to be one
+// of the rates returned by GetSampleRates, but must be within one of the device's sample rate ranges. The rate is
+// read back from the device after it is set to verify that the device is using the requested rate.
 //
-// Returns error if an error occured while trying to set the requested sample rate.
-func SetSampleRate(sdrD SampleRates, log *logger.Logger, rate float64) error {
-	GetSampleRates(sdrD, log)
-	currentRate := GetSampleRate(sdrD, log)
-	re, err := regexp.Compile(`[0-9]+\.[0-9]+`)
-	if err != nil {
-		return err
-	}
-	match := re.FindString(currentRate)
-	sampleRate, err := strconv.ParseFloat(match, 64)
-	if err != nil {
-		log.Logf(logger.Error, "Error parsing sample rate: %s\n", err.Error())
+// Returns error if the rate is not within the device's sample rate ranges, the rate could not be set, or the device
+// is using a different rate after it is set.
+func SetSampleRate(sdrD SampleRates, log *logger.Logger, direction device.Direction, channel uint, rate float64) error {
+	sampleRateRanges := sdrD.GetSampleRateRange(direction, channel)
+	if !withinRanges(sampleRateRanges, rate) {
+		log.Logf(logger.Error, "Requested sample rate: %.1f is not within the sample rate ranges: %v\n",
+			rate, sampleRateRanges)
+		return fmt.Errorf("requested sample rate: %.1f is not within the sample rate ranges for this device", rate)
 	}
-	sampleRate *= 1e6
-	rate = closestSampleRate(rate, log)
-	if rate == sampleRate {
+	if GetSampleRate(sdrD, log, direction, channel) == rate {
 		log.Log(logger.Debug, "Requested rate is same as current sample rate.\n")
 		return nil
-	} else {
-		log.Logf(logger.Debug, "Setting sample rate to %f\n", rate)
-		err := sdrD.SetSampleRate(device.DirectionRX, 0, rate)
-		if err != nil {
-			log.Logf(logger.Error, "Error attempting to set sample rate: %s\n", err.Error())
-			return err
-		} else {
-			match = re.FindString(GetSampleRate(sdrD, log))
-			setRate, _ := strconv.ParseFloat(match, 64)
-			setRate *= 1e6
-			if setRate != rate {
-				errMsg := fmt.Sprintf("Attempt to set sample rate to %.1f failed. Sample rate is %.1f", rate, setRate)
-				log.Log(logger.Error, errMsg)
-				return errors.New(errMsg)
-			} else {
-				log.Logf(logger.Debug, "Sample rate has been set to %f\n", setRate)
-				return nil
-			}
-		}
 	}
+	log.Logf(logger.Debug, "Setting sample rate to %.1f\n", rate)
+	if err := sdrD.SetSampleRate(direction, channel, rate); err != nil {
+		log.Logf(logger.Error, "Error attempting to set sample rate: %s\n", err.Error())
+		return err
+	}
+	setRate := GetSampleRate(sdrD, log, direction, channel)
+	if math.Abs(setRate-rate) > sampleRateTolerance {
+		log.Logf(logger.Error, "Attempt to set sample rate to %.1f failed. Sample rate is %.1f\n", rate, setRate)
+		return fmt.Errorf("attempt to set sample rate to %.1f failed. Sample rate is %.1f", rate, setRate)
+	}
+	log.Logf(logger.Debug, "Sample rate has been set to %.1f\n", setRate)
+	return nil
 }
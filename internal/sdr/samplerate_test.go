@@ -1,13 +1,12 @@
 package sdr_test
 
 import (
-	"regexp"
-	"slices"
-	"strconv"
+	"errors"
 	"testing"
 
 	"github.com/jimorc/jsdr/internal/logger"
 	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,25 +23,63 @@ func TestGetSampleRates(t *testing.T) {
 		"serial":       "00000102",
 		"tuner":        "Rafael Micro R820T"}, testLogger)
 	require.Nil(t, err)
-	rates := sdr.GetSampleRates(&stub, testLogger)
-	require.Equal(t, 7, len(rates))
-	assert.True(t, slices.Index(rates, "0.256 MS/s") != -1)
-	assert.True(t, slices.Index(rates, "1.024 MS/s") != -1)
-	assert.True(t, slices.Index(rates, "1.6 MS/s") != -1)
-	assert.True(t, slices.Index(rates, "2.048 MS/s") != -1)
-	assert.True(t, slices.Index(rates, "2.4 MS/s") != -1)
-	assert.True(t, slices.Index(rates, "2.8 MS/s") != -1)
-	assert.True(t, slices.Index(rates, "3.2 MS/s") != -1)
-
+	rates := sdr.GetSampleRates(&stub, testLogger, device.DirectionRX, 0)
+	assert.Equal(t, []float64{250e3, 256e3, 1e6, 1.024e6, 1.2e6, 1.6e6, 2e6, 2.048e6, 2.4e6, 2.56e6, 2.8e6, 3.2e6},
+		rates)
 }
 
 func TestGetSampleRates_NoDevice(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
-	rates := sdr.GetSampleRates(&stub, testLogger)
+	rates := sdr.GetSampleRates(&stub, testLogger, device.DirectionRX, 0)
 	require.Equal(t, 0, len(rates))
 }
 
+// sampleRateRanges is a SampleRates device with the specified sample rate ranges.
+type sampleRateRanges []device.SDRRange
+
+func (r sampleRateRanges) GetSampleRateRange(_ device.Direction, _ uint) []device.SDRRange {
+	return r
+}
+
+func (r sampleRateRanges) GetSampleRate(_ device.Direction, _ uint) float64 {
+	return 0.0
+}
+
+func (r sampleRateRanges) SetSampleRate(_ device.Direction, _ uint, _ float64) error {
+	return nil
+}
+
+func TestGetSampleRates_Ranges(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	tests := []struct {
+		name   string
+		ranges sampleRateRanges
+		rates  []float64
+	}{
+		{"discrete", sampleRateRanges{{Minimum: 1e6, Maximum: 1e6}, {Minimum: 250e3, Maximum: 250e3}},
+			[]float64{250e3, 1e6}},
+		{"few steps", sampleRateRanges{{Minimum: 2e6, Maximum: 20e6, Step: 2e6}},
+			[]float64{2e6, 4e6, 6e6, 8e6, 10e6, 12e6, 14e6, 16e6, 18e6, 20e6}},
+		{"many steps", sampleRateRanges{{Minimum: 1e6, Maximum: 20e6, Step: 100e3}},
+			[]float64{1e6, 1.2e6, 1.6e6, 2e6, 2.4e6, 2.8e6, 3.2e6, 4e6, 5e6, 6e6, 7e6, 8e6, 9e6, 10e6, 12.5e6, 16e6,
+				20e6}},
+		{"continuous", sampleRateRanges{{Minimum: 20e6, Maximum: 61.44e6}},
+			[]float64{20e6, 25e6, 30.72e6, 40e6, 50e6, 56e6, 61.44e6}},
+		{"no common rates", sampleRateRanges{{Minimum: 100.1e6, Maximum: 125e6}},
+			[]float64{100.1e6, 125e6}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.rates, sdr.GetSampleRates(test.ranges, testLogger, device.DirectionRX, 0), test.name)
+	}
+}
+
+func TestFormatSampleRate(t *testing.T) {
+	assert.Equal(t, "2.048 MS/s", sdr.FormatSampleRate(2048000.))
+	assert.Equal(t, "0.25 MS/s", sdr.FormatSampleRate(250000.))
+	assert.Equal(t, "61.44 MS/s", sdr.FormatSampleRate(61.44e6))
+}
+
 func TestGetSampleRate(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
@@ -51,19 +88,28 @@ func TestGetSampleRate(t *testing.T) {
 		"label":        "Generic RTL2832U OEM :: 00000102",
 		"manufacturer": "Realtek",
 		"product":      "RTL2838UHIDIR",
-		"serial":       "1",
+		"serial":       "00000102",
 		"tuner":        "Rafael Micro R820T"}, testLogger)
 	require.Nil(t, err)
-	sampleRate := sdr.GetSampleRate(&stub, testLogger)
-	assert.Equal(t, "2.048 MS/s", sampleRate)
+	sampleRate := sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 0)
+	assert.Equal(t, 2e6, sampleRate)
 }
 
 func TestGetSampleRate_BadDevice(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
 	// stub.Make not called, so no sample rate returned by sdr.GetSampleRate.
-	sampleRate := sdr.GetSampleRate(&stub, testLogger)
-	assert.Equal(t, "", sampleRate)
+	sampleRate := sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 0)
+	assert.Equal(t, 0., sampleRate)
+}
+
+func TestSetSampleRate(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	// 2.5 MS/s is not one of the rates returned by GetSampleRates, but is within the sample rate ranges.
+	require.Nil(t, sdr.SetSampleRate(&stub, testLogger, device.DirectionRX, 0, 2.5e6))
+	assert.Equal(t, 2.5e6, sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 0))
 }
 
 func TestSetSampleRate_SameAsCurrentRate(t *testing.T) {
@@ -74,30 +120,49 @@ func TestSetSampleRate_SameAsCurrentRate(t *testing.T) {
 		"label":        "Generic RTL2832U OEM :: 00000102",
 		"manufacturer": "Realtek",
 		"product":      "RTL2838UHIDIR",
-		"serial":       "1",
+		"serial":       "00000102",
 		"tuner":        "Rafael Micro R820T"}, testLogger)
 	require.Nil(t, err)
-	re, err := regexp.Compile(`\d+\.\d+`)
-	require.Nil(t, err)
-	rate := re.FindString(sdr.GetSampleRate(&stub, testLogger))
-	sampleRate, _ := strconv.ParseFloat(rate, 64)
-	sampleRate *= 1e6
-	err = sdr.SetSampleRate(&stub, testLogger, sampleRate)
+	sampleRate := sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 0)
+	err = sdr.SetSampleRate(&stub, testLogger, device.DirectionRX, 0, sampleRate)
 	assert.Nil(t, err)
 }
 
-func TestSetSampleRate_Mismatch(t *testing.T) {
+func TestSetSampleRate_OutOfRange(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	err := sdr.SetSampleRate(&stub, testLogger, device.DirectionRX, 0, 20e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "requested sample rate: 20000000.0 is not within the sample rate ranges for this device",
+		err.Error())
+	assert.Equal(t, 2e6, sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 0))
+}
+
+func TestSetSampleRate_ReadbackMismatch(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
 	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.Make(&stub, map[string]string{"driver": "stub"}, testLogger))
+	// The stub rounds sample rates to the nearest kHz.
+	err := sdr.SetSampleRate(&stub, testLogger, device.DirectionRX, 0, 1024400.)
+	require.NotNil(t, err)
+	assert.Equal(t, "attempt to set sample rate to 1024400.0 failed. Sample rate is 1024000.0", err.Error())
+}
+
+func TestSetSampleRate_Error(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{Errors: map[string]error{"SetSampleRate": errors.New("could not set sample rate")}}
 	err := sdr.Make(&stub, map[string]string{
 		"driver":       "rtlsdr",
 		"label":        "Generic RTL2832U OEM :: 00000102",
 		"manufacturer": "Realtek",
 		"product":      "RTL2838UHIDIR",
-		"serial":       "0",
+		"serial":       "00000102",
 		"tuner":        "Rafael Micro R820T"}, testLogger)
 	require.Nil(t, err)
-	err = sdr.SetSampleRate(&stub, testLogger, 1.024*1e6)
-	assert.NotNil(t, err)
-	assert.Equal(t, "attempt to set sample rate to 1024000.0 failed. Sample rate is 2048000.0", err.Error())
+	err = sdr.SetSampleRate(&stub, testLogger, device.DirectionRX, 0, 1.024*1e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "could not set sample rate", err.Error())
+	assert.Equal(t, 2e6, sdr.GetSampleRate(&stub, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, 1, stub.CallCount("SetSampleRate"))
 }
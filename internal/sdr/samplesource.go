@@ -0,0 +1,199 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SampleStreams interface specifies the methods that a device must satisfy to be used as a SampleSource.
+type SampleStreams interface {
+	Stream
+	CS8Streams
+	FormatStreams
+}
+
+// SampleSource is a source of complex samples regardless of the format of the underlying stream.
+//
+// Samples are normalised so that the device's full scale value is ±1.0.
+type SampleSource interface {
+	Format() string
+	Direction() device.Direction
+	Channels() []uint
+	IsActive() bool
+	FullScale() float64
+	GetMTU(*logger.Logger) uint
+	Activate(*logger.Logger, device.StreamFlag, int, int) error
+	Deactivate(*logger.Logger, device.StreamFlag, int) error
+	Close(*logger.Logger) error
+	ReadSamples(*logger.Logger, [][]complex64, uint, *[1]int, uint) (uint, uint, error)
+	SetMonitor(*StreamMonitor)
+	Monitor() *StreamMonitor
+}
+
+// defaultFullScales holds the full scale values for each stream format. These are used when the format is not
+// the device's native format, or the device does not report a full scale value.
+var defaultFullScales = map[string]float64{
+	FormatCU8:  128.,
+	FormatCS8:  128.,
+	FormatCS16: 32768.,
+	FormatCF32: 1.,
+}
+
+// sampleStream specifies the stream methods that are common to all of the stream types.
+type sampleStream interface {
+	Format() string
+	Direction() device.Direction
+	Channels() []uint
+	IsActive() bool
+	GetMTU(*logger.Logger) uint
+	Activate(*logger.Logger, device.StreamFlag, int, int) error
+	Deactivate(*logger.Logger, device.StreamFlag, int) error
+	Close(*logger.Logger) error
+	SetMonitor(*StreamMonitor)
+	Monitor() *StreamMonitor
+}
+
+// streamSource is a SampleSource that reads from a stream of T values and converts them to complex64 samples.
+type streamSource[T any] struct {
+	sampleStream
+	fullScale     float64
+	valuesPerElem uint
+	read          func(*logger.Logger, [][]T, uint, *[1]int, uint) (uint, uint, error)
+	convert       func([]complex64, []T, float32)
+	raw           [][]T
+}
+
+// NewSampleSource sets up a stream for the specified direction and channels and returns a SampleSource for it.
+//
+// The stream format is selected with SelectStreamFormat for the first channel in channels. The full scale
+// value reported by GetNativeStreamFormat is used to normalise the samples when the selected format is the
+// native format; otherwise, the full scale value for the selected format is used.
+//
+// The returned SampleSource must be activated before samples are read, and closed when it is no longer needed.
+func NewSampleSource(sdrD SampleStreams, log *logger.Logger, direction device.Direction,
+	channels []uint) (SampleSource, error) {
+	if err := validateChannels(log, channels); err != nil {
+		return nil, err
+	}
+	format, err := SelectStreamFormat(sdrD, log, direction, channels[0])
+	if err != nil {
+		return nil, err
+	}
+	fullScale := defaultFullScales[format]
+	native, nativeFullScale := GetNativeStreamFormat(sdrD, log, direction, channels[0])
+	if native == format && nativeFullScale > 0 {
+		fullScale = nativeFullScale
+	}
+	log.Logf(logger.Debug, "Sample source format: %s, full scale: %.1f\n", format, fullScale)
+
+	switch format {
+	case FormatCU8:
+		return newFormatStreamSource(sdrD, log, direction, channels, fullScale, convertCU8)
+	case FormatCS8:
+		stream, err := SetupCS8Stream(sdrD, log, direction, channels)
+		if err != nil {
+			return nil, err
+		}
+		return &streamSource[int]{sampleStream: stream, fullScale: fullScale, valuesPerElem: 2,
+			read: stream.ReadCS8FromStream, convert: convertCS8}, nil
+	case FormatCS16:
+		return newFormatStreamSource(sdrD, log, direction, channels, fullScale, convertCS16)
+	case FormatCF32:
+		return newFormatStreamSource(sdrD, log, direction, channels, fullScale, convertCF32)
+	}
+	return nil, fmt.Errorf("unsupported stream format: %s", format)
+}
+
+// newFormatStreamSource sets up a FormatStream of T values and returns a SampleSource that converts its values to
+// samples with convert.
+func newFormatStreamSource[T StreamElement](sdrD FormatStreams, log *logger.Logger, direction device.Direction,
+	channels []uint, fullScale float64, convert func([]complex64, []T, float32)) (SampleSource, error) {
+	stream, err := SetupFormatStream[T](sdrD, log, direction, channels)
+	if err != nil {
+		return nil, err
+	}
+	_, valuesPerElem := streamElementFormat[T]()
+	return &streamSource[T]{sampleStream: stream, fullScale: fullScale, valuesPerElem: valuesPerElem,
+		read: stream.ReadFromStream, convert: convert}, nil
+}
+
+// FullScale returns the value that is normalised to 1.0.
+func (source *streamSource[T]) FullScale() float64 {
+	return source.fullScale
+}
+
+// ReadSamples reads numSamples samples from each of the source's channels and stores them in buff.
+//
+// buff must contain one buffer for each of the source's channels, and each buffer must hold at least numSamples
+// samples. outputFlags and timeoutUs are as for StreamCS8.ReadCS8FromStream.
+//
+// Returns the timestamp of the first sample in nanoseconds, the number of samples read, and an error or nil.
+func (source *streamSource[T]) ReadSamples(log *logger.Logger, buff [][]complex64, numSamples uint,
+	outputFlags *[1]int, timeoutUs uint) (timeNs uint, numSamplesRead uint, err error) {
+	if len(buff) != len(source.Channels()) {
+		log.Logf(logger.Error, "Sample buffer has %d channels, but the source has %d channels.\n",
+			len(buff), len(source.Channels()))
+		return 0, 0, fmt.Errorf("sample buffer must have %d channels", len(source.Channels()))
+	}
+	for _, chBuff := range buff {
+		if uint(len(chBuff)) < numSamples {
+			log.Logf(logger.Error, "Sample buffer is too small to hold %d samples.\n", numSamples)
+			return 0, 0, fmt.Errorf("sample buffer is too small to hold %d samples", numSamples)
+		}
+	}
+	source.resizeRaw(numSamples)
+	timeNs, numSamplesRead, err = source.read(log, source.raw, numSamples, outputFlags, timeoutUs)
+	scale := float32(1. / source.fullScale)
+	for ch := range buff {
+		source.convert(buff[ch][:numSamplesRead], source.raw[ch], scale)
+	}
+	return timeNs, numSamplesRead, err
+}
+
+// resizeRaw ensures that the buffers that stream data is read into can hold numSamples samples.
+func (source *streamSource[T]) resizeRaw(numSamples uint) {
+	size := source.valuesPerElem * numSamples
+	if len(source.raw) == len(source.Channels()) && uint(len(source.raw[0])) >= size {
+		return
+	}
+	source.raw = make([][]T, len(source.Channels()))
+	for ch := range source.raw {
+		source.raw[ch] = make([]T, size)
+	}
+}
+
+// convertCU8 converts interleaved CU8 values to complex64 samples, multiplying by scale.
+func convertCU8(dst []complex64, src []uint8, scale float32) {
+	for i := range dst {
+		dst[i] = complex((float32(src[2*i])-128)*scale, (float32(src[2*i+1])-128)*scale)
+	}
+}
+
+// convertCS8 converts interleaved CS8 values to complex64 samples, multiplying by scale.
+func convertCS8(dst []complex64, src []int, scale float32) {
+	for i := range dst {
+		dst[i] = complex(float32(src[2*i])*scale, float32(src[2*i+1])*scale)
+	}
+}
+
+// convertCS16 converts interleaved CS16 values to complex64 samples, multiplying by scale.
+func convertCS16(dst []complex64, src []int16, scale float32) {
+	for i := range dst {
+		dst[i] = complex(float32(src[2*i])*scale, float32(src[2*i+1])*scale)
+	}
+}
+
+// convertCF32 copies CF32 samples, multiplying by scale.
+func convertCF32(dst []complex64, src []complex64, scale float32) {
+	if scale == 1 {
+		copy(dst, src)
+		return
+	}
+	s := complex(scale, 0)
+	for i := range dst {
+		dst[i] = src[i] * s
+	}
+}
@@ -0,0 +1,149 @@
+package sdr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCS16Stub returns a StubDevice whose native stream format is 12 bit CS16, and that does not support CS8.
+func newCS16Stub() *sdr.StubDevice {
+	return &sdr.StubDevice{StreamFormats: []string{"CS16", "CF32"}, NativeStreamFormat: "CS16", NativeFullScale: 2048.0}
+}
+
+func TestNewSampleSource_CS8(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	assert.Equal(t, sdr.FormatCS8, source.Format())
+	// StubDevice reports a full scale of 0.0 for CS8, so the default CS8 full scale is used.
+	assert.Equal(t, 128., source.FullScale())
+
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	mtu := source.GetMTU(testLogger)
+	samples := [][]complex64{make([]complex64, mtu)}
+	var outputFlags [1]int
+	_, numRead, err := source.ReadSamples(testLogger, samples, mtu, &outputFlags, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, mtu, numRead)
+	assert.Equal(t, complex64(complex(-2./128., 0)), samples[0][0])
+	assert.Equal(t, complex64(complex(-1./128., -2./128.)), samples[0][mtu-1])
+}
+
+func TestNewSampleSource_CS16NativeFullScale(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := newCS16Stub()
+	source, err := sdr.NewSampleSource(stub, testLogger, device.DirectionRX, []uint{0, 1})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	assert.Equal(t, sdr.FormatCS16, source.Format())
+	assert.Equal(t, 2048., source.FullScale())
+
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	samples := [][]complex64{make([]complex64, 100), make([]complex64, 100)}
+	var outputFlags [1]int
+	_, numRead, err := source.ReadSamples(testLogger, samples, 100, &outputFlags, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(100), numRead)
+	assert.Equal(t, complex64(complex(-0.25, 0)), samples[0][0])
+	assert.Equal(t, complex64(complex(-0.125, -0.25)), samples[0][1])
+	assert.Equal(t, complex64(complex(-0.5, 0)), samples[1][0])
+}
+
+func TestNewSampleSource_CS16DefaultFullScale(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	// The native format, CF64, is not supported by jsdr.
+	stub := sdr.StubDevice{StreamFormats: []string{"CS16", "CF32", "CF64"}, NativeStreamFormat: "CF64",
+		NativeFullScale: 1.0}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	assert.Equal(t, sdr.FormatCS16, source.Format())
+	assert.Equal(t, 32768., source.FullScale())
+}
+
+func TestNewSampleSource_NoSupportedFormat(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{StreamFormats: []string{"CF64"}}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	assert.NotNil(t, err)
+	assert.Nil(t, source)
+}
+
+func TestReadSamples_WrongNumberOfBuffers(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	samples := [][]complex64{make([]complex64, 100), make([]complex64, 100)}
+	var outputFlags [1]int
+	_, _, err = source.ReadSamples(testLogger, samples, 100, &outputFlags, 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, "sample buffer must have 1 channels", err.Error())
+}
+
+func TestSampleSource_ReadSamplesNoAllocations(t *testing.T) {
+	skipAllocationTestWithRace(t)
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := newCS16Stub()
+	source, err := sdr.NewSampleSource(stub, testLogger, device.DirectionRX, []uint{0, 1})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	mtu := source.GetMTU(testLogger)
+	samples := [][]complex64{make([]complex64, mtu), make([]complex64, mtu)}
+	var outputFlags [1]int
+	// The first read allocates the source's raw buffers.
+	_, _, err = source.ReadSamples(testLogger, samples, mtu, &outputFlags, 0)
+	require.Nil(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		source.ReadSamples(testLogger, samples, mtu, &outputFlags, 0)
+	})
+	assert.Equal(t, 0., allocs)
+}
+
+func benchmarkReadSamples(b *testing.B, stub *sdr.StubDevice) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	source, err := sdr.NewSampleSource(stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(b, err)
+	defer source.Close(testLogger)
+	require.Nil(b, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	mtu := source.GetMTU(testLogger)
+	samples := [][]complex64{make([]complex64, mtu)}
+	var outputFlags [1]int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		source.ReadSamples(testLogger, samples, mtu, &outputFlags, 0)
+	}
+}
+
+func BenchmarkSampleSource_ReadSamplesCS8(b *testing.B) {
+	benchmarkReadSamples(b, &sdr.StubDevice{})
+}
+
+func BenchmarkSampleSource_ReadSamplesCS16(b *testing.B) {
+	benchmarkReadSamples(b, newCS16Stub())
+}
@@ -0,0 +1,112 @@
+package sdr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// quantize converts v to an integer value with the specified full scale, clipping it to the range minimum to
+// maximum in the same way as a real device's ADC.
+func quantize(v float32, fullScale float64, minimum float64, maximum float64) float64 {
+	return math.Max(minimum, math.Min(maximum, math.Round(float64(v)*fullScale)))
+}
+
+// storeCU8 stores normalised samples in each of buff's channels as interleaved CU8 values.
+func storeCU8(buff [][]uint8, samples []complex64) {
+	for ch := range buff {
+		for i, s := range samples {
+			buff[ch][2*i] = uint8(quantize(real(s), 128, -128, 127) + 128)
+			buff[ch][2*i+1] = uint8(quantize(imag(s), 128, -128, 127) + 128)
+		}
+	}
+}
+
+// storeCS8 stores normalised samples in each of buff's channels as interleaved CS8 values.
+func storeCS8(buff [][]int, samples []complex64) {
+	for ch := range buff {
+		for i, s := range samples {
+			buff[ch][2*i] = int(quantize(real(s), 128, -128, 127))
+			buff[ch][2*i+1] = int(quantize(imag(s), 128, -128, 127))
+		}
+	}
+}
+
+// storeCS16 stores normalised samples in each of buff's channels as interleaved CS16 values.
+func storeCS16(buff [][]int16, samples []complex64) {
+	for ch := range buff {
+		for i, s := range samples {
+			buff[ch][2*i] = int16(quantize(real(s), 32768, -32768, 32767))
+			buff[ch][2*i+1] = int16(quantize(imag(s), 32768, -32768, 32767))
+		}
+	}
+}
+
+// storeCF32 copies normalised samples into each of buff's channels. CF32 samples are not clipped.
+func storeCF32(buff [][]complex64, samples []complex64) {
+	for ch := range buff {
+		copy(buff[ch], samples)
+	}
+}
+
+// sampleProducer holds the operations of a device that produces normalised complex64 samples, which are converted
+// to the format of each stream as they are read.
+type sampleProducer struct {
+	mtu      int
+	activate func() error
+	// deactivate is nil if the device does nothing when a stream is deactivated.
+	deactivate func()
+	// readSamples reads up to numElems samples and passes them to store, which converts them into the stream's
+	// buffers. It returns the timestamp of the first sample in nanoseconds, the number of samples read, and an
+	// error or nil.
+	readSamples func(numElems uint, outputFlags *[1]int, timeoutUs uint, store func([]complex64)) (uint, uint, error)
+}
+
+// convertedFormatStream is the device side of a FormatStream whose samples are produced by a sampleProducer.
+type convertedFormatStream[T StreamElement] struct {
+	producer sampleProducer
+	store    func([][]T, []complex64)
+}
+
+// newConvertedFormatStream creates the device side of a stream of the specified format whose samples are produced
+// by producer.
+func newConvertedFormatStream(format string, producer sampleProducer) (formatStreamDevice, error) {
+	switch format {
+	case FormatCU8:
+		return &convertedFormatStream[uint8]{producer: producer, store: storeCU8}, nil
+	case FormatCS16:
+		return &convertedFormatStream[int16]{producer: producer, store: storeCS16}, nil
+	case FormatCF32:
+		return &convertedFormatStream[complex64]{producer: producer, store: storeCF32}, nil
+	}
+	return nil, fmt.Errorf("unsupported stream format: %s", format)
+}
+
+func (s *convertedFormatStream[T]) close() error {
+	return nil
+}
+
+func (s *convertedFormatStream[T]) mtu() int {
+	return s.producer.mtu
+}
+
+func (s *convertedFormatStream[T]) activate(_ device.StreamFlag, _ int, _ int) error {
+	return s.producer.activate()
+}
+
+func (s *convertedFormatStream[T]) deactivate(_ device.StreamFlag, _ int) error {
+	if s.producer.deactivate != nil {
+		s.producer.deactivate()
+	}
+	return nil
+}
+
+// read fills each of the stream's buffers with produced samples, converted to the stream's format.
+func (s *convertedFormatStream[T]) read(_ *FormatStream[T], buff [][]T, numElemsToRead uint, outputFlags *[1]int,
+	timeoutUs uint) (timeNs uint, numElemsRead uint, err error) {
+	return s.producer.readSamples(maxElements(buff, numElemsToRead), outputFlags, timeoutUs,
+		func(samples []complex64) {
+			s.store(buff, samples)
+		})
+}
@@ -6,6 +6,15 @@
 //
 //	StubDevice for testing of the various sdr functions.
 //
+//	SimDevice for a simulated receiver that generates signals, so that jsdr can be used without an SDR attached.
+//
+//	FileDevice for playing back IQ recordings as though they were being received.
+//
+//	RtlTcpDevice for RTL-SDR dongles that are served over the network by rtl_tcp.
+//
+//	RecordingDevice for recording the calls that are made to another device in a Session, and ReplayDevice for
+//	replaying a Session without the device.
+//
 // Many of the function and method names are changed from those provided in go-soapy-sdr.go.
 // I find many of the function and method names to be confusing in go-soapy-sdr.go For example:
 // device.SetAntennas sets a single antenna on a device, not multiple antennas.
@@ -39,25 +48,28 @@ type KeyValues interface {
 	GetHardwareKey() string
 }
 
+// ChannelState holds the state that is tracked for a single channel of the SDR device.
+type ChannelState struct {
+	SampleRate float64
+	Antennas   []string
+	Antenna    string
+}
+
 // Sdr represents the SDR device.
 type Sdr struct {
 	Device           *device.SDRDevice
 	DeviceProperties map[string]string
-	SampleRates      []string
-	SampleRate       float64
-	Antennas         []string
-	Antenna          string
+	channels         map[device.Direction]map[uint]*ChannelState
+	// capabilities is set by the first call to GetCapabilities.
+	capabilities *Capabilities
 }
 
 // EnumerateWithoutAudio returns a map of SDR devices, not including any audio device.
 func EnumerateWithoutAudio(sdrD Enumerate, log *logger.Logger) map[string]map[string]string {
 	var sdrs map[string]map[string]string = make(map[string]map[string]string, 0)
 
-	eSdrs := sdrD.Enumerate(nil)
-	for _, dev := range eSdrs {
-		if dev["driver"] != "audio" {
-			sdrs[dev["label"]] = dev
-		}
+	for _, dev := range enumerateWithoutAudio(sdrD) {
+		sdrs[dev["label"]] = dev
 	}
 	var sMsg strings.Builder
 	if len(sdrs) == 0 {
@@ -72,6 +84,17 @@ func EnumerateWithoutAudio(sdrD Enumerate, log *logger.Logger) map[string]map[st
 	return sdrs
 }
 
+// enumerateWithoutAudio returns the args of the devices that sdrD enumerates, not including any audio device.
+func enumerateWithoutAudio(sdrD Enumerate) []map[string]string {
+	var devs []map[string]string
+	for _, dev := range sdrD.Enumerate(nil) {
+		if dev["driver"] != "audio" {
+			devs = append(devs, dev)
+		}
+	}
+	return devs
+}
+
 // Make makes a new device given construction args.
 //
 // Construction args should be as explicit as possible (i.e. include all values retrieved by
@@ -103,3 +126,21 @@ func Unmake(sdrD MakeDevice, log *logger.Logger) error {
 func (sdr *Sdr) GetHardwareKey(sdrD KeyValues) string {
 	return sdrD.GetHardwareKey()
 }
+
+// Channel returns the state for the specified direction and channel of the SDR device.
+//
+// The state is created the first time that a direction and channel is requested.
+func (sdr *Sdr) Channel(direction device.Direction, channel uint) *ChannelState {
+	if sdr.channels == nil {
+		sdr.channels = make(map[device.Direction]map[uint]*ChannelState)
+	}
+	if sdr.channels[direction] == nil {
+		sdr.channels[direction] = make(map[uint]*ChannelState)
+	}
+	state, ok := sdr.channels[direction][channel]
+	if !ok {
+		state = &ChannelState{}
+		sdr.channels[direction][channel] = state
+	}
+	return state
+}
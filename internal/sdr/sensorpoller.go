@@ -0,0 +1,103 @@
+package sdr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+)
+
+// SensorPoller reads a set of sensors on an interval on its own go routine, and publishes the readings that have
+// changed.
+type SensorPoller struct {
+	sdrD     Sensors
+	log      *logger.Logger
+	sensors  []Sensor
+	interval time.Duration
+	callback func([]SensorValue)
+	done     chan struct{}
+
+	mutex  sync.Mutex
+	values map[Sensor]SensorValue
+}
+
+// NewSensorPoller creates a SensorPoller for the specified sensors of sdrD.
+//
+// Params:
+//   - sensors: the sensors to read, for example those returned by GetSensors.
+//   - interval: the time between readings.
+//   - callback: called on the poller's go routine with the readings that have changed since the previous readings.
+//     All of the readings are passed to the first call. Sensors that cannot be read are not passed to callback.
+func NewSensorPoller(sdrD Sensors, log *logger.Logger, sensors []Sensor, interval time.Duration,
+	callback func([]SensorValue)) *SensorPoller {
+	return &SensorPoller{sdrD: sdrD, log: log, sensors: sensors, interval: interval, callback: callback,
+		done: make(chan struct{}), values: make(map[Sensor]SensorValue)}
+}
+
+// Start reads the sensors, then continues to read them on a new go routine until ctx is cancelled.
+func (p *SensorPoller) Start(ctx context.Context) {
+	p.log.Logf(logger.Debug, "Polling %d sensors every %v\n", len(p.sensors), p.interval)
+	go p.run(ctx)
+}
+
+// Done returns a channel that is closed when the poller has stopped.
+func (p *SensorPoller) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait waits for the poller to stop.
+func (p *SensorPoller) Wait() {
+	<-p.done
+}
+
+// Values returns the latest readings of the sensors that have been read, in the order that the sensors were passed
+// to NewSensorPoller.
+func (p *SensorPoller) Values() []SensorValue {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var values []SensorValue
+	for _, sensor := range p.sensors {
+		if value, ok := p.values[sensor]; ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// run is the poller's read loop.
+func (p *SensorPoller) run(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.poll()
+		select {
+		case <-ctx.Done():
+			p.log.Log(logger.Debug, "Sensor poller stopped.\n")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads each of the sensors and passes the readings that have changed to the callback.
+func (p *SensorPoller) poll() {
+	var changed []SensorValue
+	for _, sensor := range p.sensors {
+		value, err := ReadSensor(p.sdrD, p.log, sensor)
+		if err != nil {
+			continue
+		}
+		p.mutex.Lock()
+		previous, ok := p.values[sensor]
+		p.values[sensor] = value
+		p.mutex.Unlock()
+		if !ok || previous != value {
+			changed = append(changed, value)
+		}
+	}
+	if len(changed) > 0 && p.callback != nil {
+		p.callback(changed)
+	}
+}
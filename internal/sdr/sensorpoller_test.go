@@ -0,0 +1,50 @@
+package sdr_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorPoller(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	sensors := []sdr.Sensor{
+		sdr.DeviceSensor("temperature"),
+		sdr.ChannelSensor(device.DirectionRX, 0, "lo_locked"),
+		sdr.DeviceSensor("missing"),
+	}
+	changes := make(chan []sdr.SensorValue, 10)
+	poller := sdr.NewSensorPoller(&stub, testLogger, sensors, time.Millisecond, func(values []sdr.SensorValue) {
+		select {
+		case changes <- values:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	poller.Start(ctx)
+
+	// All of the sensors that can be read are published first. Afterwards, only the temperature changes.
+	first := <-changes
+	require.Equal(t, 2, len(first))
+	assert.Equal(t, 40., first[0].Float)
+	assert.True(t, first[1].Bool)
+	second := <-changes
+	require.Equal(t, 1, len(second))
+	assert.Equal(t, sdr.DeviceSensor("temperature"), second[0].Sensor)
+	assert.Equal(t, 40.5, second[0].Float)
+
+	cancel()
+	poller.Wait()
+	values := poller.Values()
+	require.Equal(t, 2, len(values))
+	assert.Equal(t, sdr.DeviceSensor("temperature"), values[0].Sensor)
+	assert.Equal(t, sdr.ChannelSensor(device.DirectionRX, 0, "lo_locked"), values[1].Sensor)
+}
@@ -0,0 +1,164 @@
+package sdr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// Sensors interface specifies the methods for reading an SDR's device and channel sensors.
+type Sensors interface {
+	GetSensorNames() []string
+	GetSensorInfo(string) device.SDRArgInfo
+	ReadSensor(string) (string, error)
+	GetChannelSensorNames(device.Direction, uint) []string
+	GetChannelSensorInfo(device.Direction, uint, string) device.SDRArgInfo
+	ReadChannelSensor(device.Direction, uint, string) (string, error)
+}
+
+// Sensor identifies a device sensor, or a channel sensor if IsChannelSensor is true.
+type Sensor struct {
+	Key             string
+	IsChannelSensor bool
+	Direction       device.Direction
+	Channel         uint
+}
+
+// DeviceSensor returns the Sensor for the device sensor with the specified key.
+func DeviceSensor(key string) Sensor {
+	return Sensor{Key: key}
+}
+
+// ChannelSensor returns the Sensor for the channel sensor with the specified key.
+func ChannelSensor(direction device.Direction, channel uint, key string) Sensor {
+	return Sensor{Key: key, IsChannelSensor: true, Direction: direction, Channel: channel}
+}
+
+// String returns the sensor's key, prefixed by the direction and channel for a channel sensor.
+func (sensor Sensor) String() string {
+	if !sensor.IsChannelSensor {
+		return sensor.Key
+	}
+	direction := "RX"
+	if sensor.Direction == device.DirectionTX {
+		direction = "TX"
+	}
+	return fmt.Sprintf("%s%d %s", direction, sensor.Channel, sensor.Key)
+}
+
+// SensorValue is a reading of a sensor, converted to the type that the sensor's info specifies.
+//
+// Only the field that matches Type is set: Bool for device.ArgInfoBool, Int for device.ArgInfoInt, and Float for
+// device.ArgInfoFloat. Raw always contains the reading as it was returned by the device, and is the value of
+// device.ArgInfoString sensors.
+type SensorValue struct {
+	Sensor Sensor
+	// Name is the sensor's displayable name, or its key if the device does not provide a name.
+	Name  string
+	Unit  string
+	Type  device.SDRArgInfoType
+	Raw   string
+	Bool  bool
+	Int   int64
+	Float float64
+}
+
+// ParseSensorValue converts a raw sensor reading to the type specified by the sensor's info.
+//
+// Returns an error if the reading cannot be converted to the specified type.
+func ParseSensorValue(sensor Sensor, info device.SDRArgInfo, raw string) (SensorValue, error) {
+	value := SensorValue{Sensor: sensor, Name: info.Name, Unit: info.Unit, Type: info.Type, Raw: raw}
+	if value.Name == "" {
+		value.Name = sensor.Key
+	}
+	trimmed := strings.TrimSpace(raw)
+	var err error
+	switch info.Type {
+	case device.ArgInfoBool:
+		value.Bool, err = strconv.ParseBool(trimmed)
+	case device.ArgInfoInt:
+		value.Int, err = strconv.ParseInt(trimmed, 10, 64)
+	case device.ArgInfoFloat:
+		value.Float, err = strconv.ParseFloat(trimmed, 64)
+	}
+	if err != nil {
+		return value, fmt.Errorf("cannot convert the reading '%s' for sensor %s: %s", raw, sensor, err.Error())
+	}
+	return value, nil
+}
+
+// String returns the sensor's reading, followed by its unit if it has one.
+func (value SensorValue) String() string {
+	var reading string
+	switch value.Type {
+	case device.ArgInfoBool:
+		reading = strconv.FormatBool(value.Bool)
+	case device.ArgInfoInt:
+		reading = strconv.FormatInt(value.Int, 10)
+	case device.ArgInfoFloat:
+		reading = strconv.FormatFloat(value.Float, 'f', -1, 64)
+	default:
+		reading = value.Raw
+	}
+	if value.Unit == "" {
+		return reading
+	}
+	return reading + " " + value.Unit
+}
+
+// GetSensorNames returns the keys of the device's sensors.
+func GetSensorNames(sdrD Sensors, log *logger.Logger) []string {
+	names := sdrD.GetSensorNames()
+	log.Logf(logger.Debug, "Sensors: %v\n", names)
+	return names
+}
+
+// GetChannelSensorNames returns the keys of the sensors for the specified direction and channel.
+func GetChannelSensorNames(sdrD Sensors, log *logger.Logger, direction device.Direction, channel uint) []string {
+	names := sdrD.GetChannelSensorNames(direction, channel)
+	log.Logf(logger.Debug, "Channel sensors: %v\n", names)
+	return names
+}
+
+// GetSensors returns the device's sensors followed by the sensors for the specified direction and channel.
+func GetSensors(sdrD Sensors, log *logger.Logger, direction device.Direction, channel uint) []Sensor {
+	var sensors []Sensor
+	for _, key := range GetSensorNames(sdrD, log) {
+		sensors = append(sensors, DeviceSensor(key))
+	}
+	for _, key := range GetChannelSensorNames(sdrD, log, direction, channel) {
+		sensors = append(sensors, ChannelSensor(direction, channel, key))
+	}
+	return sensors
+}
+
+// ReadSensor reads a device or channel sensor and converts the reading to the type specified by the sensor's info.
+//
+// Returns an error if the reading could not be read or converted.
+func ReadSensor(sdrD Sensors, log *logger.Logger, sensor Sensor) (SensorValue, error) {
+	var info device.SDRArgInfo
+	var raw string
+	var err error
+	if sensor.IsChannelSensor {
+		info = sdrD.GetChannelSensorInfo(sensor.Direction, sensor.Channel, sensor.Key)
+		raw, err = sdrD.ReadChannelSensor(sensor.Direction, sensor.Channel, sensor.Key)
+	} else {
+		info = sdrD.GetSensorInfo(sensor.Key)
+		raw, err = sdrD.ReadSensor(sensor.Key)
+	}
+	if err != nil {
+		log.Logf(logger.Error, "Error reading sensor %s: %s\n", sensor, err.Error())
+		return SensorValue{Sensor: sensor}, err
+	}
+	value, err := ParseSensorValue(sensor, info, raw)
+	if err != nil {
+		log.Logf(logger.Error, "%s\n", err.Error())
+		return value, err
+	}
+	log.Logf(logger.Debug, "Sensor %s: %s\n", sensor, value)
+	return value, nil
+}
@@ -0,0 +1,87 @@
+package sdr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSensors(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	assert.Equal(t, []sdr.Sensor{
+		sdr.DeviceSensor("temperature"),
+		sdr.DeviceSensor("clock_source"),
+		sdr.ChannelSensor(device.DirectionRX, 0, "lo_locked"),
+		sdr.ChannelSensor(device.DirectionRX, 0, "rssi"),
+	}, sdr.GetSensors(&stub, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, []sdr.Sensor{sdr.DeviceSensor("temperature"), sdr.DeviceSensor("clock_source")},
+		sdr.GetSensors(&stub, testLogger, device.DirectionTX, 0))
+}
+
+func TestSensor_String(t *testing.T) {
+	assert.Equal(t, "temperature", sdr.DeviceSensor("temperature").String())
+	assert.Equal(t, "RX1 lo_locked", sdr.ChannelSensor(device.DirectionRX, 1, "lo_locked").String())
+	assert.Equal(t, "TX0 lo_locked", sdr.ChannelSensor(device.DirectionTX, 0, "lo_locked").String())
+}
+
+func TestReadSensor(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	temperature, err := sdr.ReadSensor(&stub, testLogger, sdr.DeviceSensor("temperature"))
+	require.Nil(t, err)
+	assert.Equal(t, "Temperature", temperature.Name)
+	assert.Equal(t, 40., temperature.Float)
+	assert.Equal(t, "40 C", temperature.String())
+
+	clock, err := sdr.ReadSensor(&stub, testLogger, sdr.DeviceSensor("clock_source"))
+	require.Nil(t, err)
+	// clock_source has no name, so its key is used.
+	assert.Equal(t, "clock_source", clock.Name)
+	assert.Equal(t, "internal", clock.String())
+
+	locked, err := sdr.ReadSensor(&stub, testLogger, sdr.ChannelSensor(device.DirectionRX, 0, "lo_locked"))
+	require.Nil(t, err)
+	assert.True(t, locked.Bool)
+	assert.Equal(t, "true", locked.String())
+
+	rssi, err := sdr.ReadSensor(&stub, testLogger, sdr.ChannelSensor(device.DirectionRX, 0, "rssi"))
+	require.Nil(t, err)
+	assert.Equal(t, int64(-42), rssi.Int)
+	assert.Equal(t, "-42 dBm", rssi.String())
+}
+
+func TestReadSensor_Error(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	_, err := sdr.ReadSensor(&stub, testLogger, sdr.ChannelSensor(device.DirectionTX, 0, "lo_locked"))
+	require.NotNil(t, err)
+	assert.Equal(t, "channel sensor 'lo_locked' does not exist", err.Error())
+	testLogger.Close()
+	assert.Contains(t, log.String(), "Error reading sensor TX0 lo_locked: channel sensor 'lo_locked' does not exist\n")
+}
+
+func TestParseSensorValue(t *testing.T) {
+	sensor := sdr.DeviceSensor("test")
+	value, err := sdr.ParseSensorValue(sensor, device.SDRArgInfo{Type: device.ArgInfoFloat, Unit: "dB"}, " -3.5 ")
+	require.Nil(t, err)
+	assert.Equal(t, -3.5, value.Float)
+	assert.Equal(t, "-3.5 dB", value.String())
+
+	value, err = sdr.ParseSensorValue(sensor, device.SDRArgInfo{Type: device.ArgInfoString, Unit: "V"}, "3.3")
+	require.Nil(t, err)
+	assert.Equal(t, "3.3 V", value.String())
+
+	_, err = sdr.ParseSensorValue(sensor, device.SDRArgInfo{Type: device.ArgInfoBool}, "locked")
+	assert.NotNil(t, err)
+	_, err = sdr.ParseSensorValue(sensor, device.SDRArgInfo{Type: device.ArgInfoInt}, "4.5")
+	require.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "cannot convert the reading '4.5' for sensor test: "))
+}
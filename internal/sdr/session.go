@@ -0,0 +1,144 @@
+package sdr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+)
+
+// SessionDevice is the set of interfaces whose calls are recorded by RecordingDevice and replayed by ReplayDevice.
+// Streams are not recorded; use a SampleStore recording and FileDevice to replay samples.
+type SessionDevice interface {
+	Enumerate
+	MakeDevice
+	Channels
+	Frequency
+	Agc
+	Gain
+	SampleRates
+	Bandwidth
+	Antenna
+	Stream
+	FrontendCorrections
+	Clock
+	HardwareTime
+	Sensors
+	Settings
+}
+
+// Session holds the calls that were made to a device, with their arguments and results, in the order that they were
+// made. A session is saved as JSON, so that the way that a particular SDR responds can be captured once, then replayed
+// by a ReplayDevice without the SDR.
+type Session struct {
+	Calls []SessionCall `json:"calls"`
+}
+
+// SessionCall is a call to a device method.
+type SessionCall struct {
+	Method string `json:"method"`
+	// Args is a JSON array of the call's arguments.
+	Args json.RawMessage `json:"args"`
+	// Results are the values that the call returned, other than its error.
+	Results []json.RawMessage `json:"results,omitempty"`
+	// Error is the message of the error that the call returned, or "" if it did not return an error.
+	Error string `json:"error,omitempty"`
+	// Sentinel is the name of the sentinel error that the call's error was or wrapped, such as
+	// "ErrStreamNotSupported", or "" if it was not one of the sentinelErrors.
+	Sentinel string `json:"sentinel,omitempty"`
+}
+
+// sentinelErrors are the errors whose identity is recorded in a session, indexed by name, so that a replayed error can
+// be matched with errors.Is.
+var sentinelErrors = map[string]error{
+	"ErrStreamTimeout":      ErrStreamTimeout,
+	"ErrStreamError":        ErrStreamError,
+	"ErrStreamCorruption":   ErrStreamCorruption,
+	"ErrStreamOverflow":     ErrStreamOverflow,
+	"ErrStreamNotSupported": ErrStreamNotSupported,
+	"ErrStreamTimeError":    ErrStreamTimeError,
+	"ErrStreamUnderflow":    ErrStreamUnderflow,
+	"ErrStreamEnd":          ErrStreamEnd,
+	"ErrNotRecorded":        ErrNotRecorded,
+}
+
+// sessionError is a replayed error that wraps the sentinel error that the recorded error was or wrapped.
+type sessionError struct {
+	message  string
+	sentinel error
+}
+
+func (err *sessionError) Error() string {
+	return err.message
+}
+
+func (err *sessionError) Unwrap() error {
+	return err.sentinel
+}
+
+// LoadSession reads a session from the JSON file with the specified name.
+func LoadSession(fileName string) (*Session, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("cannot read session file %s: %w", fileName, err)
+	}
+	return &session, nil
+}
+
+// Save writes the session to the JSON file with the specified name.
+func (session *Session) Save(fileName string) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, append(data, '\n'), 0644)
+}
+
+// sessionSentinel returns the name of the sentinel error that err is or wraps, or "" if err is not one of the
+// sentinelErrors.
+func sessionSentinel(err error) string {
+	for _, name := range slices.Sorted(maps.Keys(sentinelErrors)) {
+		if errors.Is(err, sentinelErrors[name]) {
+			return name
+		}
+	}
+	return ""
+}
+
+// replayedError returns the error that call returned. The error wraps the call's sentinel error, if it has one.
+func replayedError(call SessionCall) error {
+	sentinel, ok := sentinelErrors[call.Sentinel]
+	if !ok {
+		return errors.New(call.Error)
+	}
+	if call.Error == sentinel.Error() {
+		return sentinel
+	}
+	return &sessionError{message: call.Error, sentinel: sentinel}
+}
+
+// sessionKey returns the key that identifies calls to method with args, whatever the formatting of args.
+func sessionKey(method string, args json.RawMessage) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, args); err != nil {
+		return method + string(args)
+	}
+	return method + compact.String()
+}
+
+// marshalSessionValue returns the JSON for an argument or result of a call. Values that cannot be converted to JSON,
+// such as NaN, are recorded as null.
+func marshalSessionValue(value any) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
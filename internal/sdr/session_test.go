@@ -0,0 +1,155 @@
+package sdr_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// All of the device types that talk to hardware must be recordable.
+var (
+	_ sdr.SessionDevice = &sdr.SoapyDevice{}
+	_ sdr.SessionDevice = &sdr.StubDevice{}
+	_ sdr.SessionDevice = &sdr.RecordingDevice{}
+	_ sdr.SessionDevice = &sdr.ReplayDevice{}
+)
+
+// recordSession records a session with a StubDevice, and returns the capabilities that were retrieved.
+func recordSession(t *testing.T, testLogger *logger.Logger) (*sdr.Session, sdr.Capabilities) {
+	stub := &sdr.StubDevice{Errors: map[string]error{"SetBandwidth": errors.New("bandwidth is fixed"),
+		"SetHardwareTime": fmt.Errorf("cannot set the hardware time: %w", sdr.ErrStreamNotSupported)}}
+	recorder := sdr.NewRecordingDevice(stub)
+	require.Nil(t, sdr.Make(recorder, map[string]string{"label": "stub"}, testLogger))
+	caps := sdr.GetCapabilities(recorder, testLogger)
+	assert.Equal(t, 100e6, sdr.GetOverallCenterFrequency(recorder, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetOverallCenterFrequency(recorder, testLogger, device.DirectionRX, 0, 433.92e6,
+		map[string]string{}))
+	assert.Equal(t, 433.92e6, sdr.GetOverallCenterFrequency(recorder, testLogger, device.DirectionRX, 0))
+	assert.NotNil(t, sdr.SetBandwidth(recorder, testLogger, device.DirectionRX, 0, 1.5e6))
+	assert.ErrorIs(t, sdr.SetHardwareTime(recorder, testLogger, 0, sdr.TimeNow), sdr.ErrStreamNotSupported)
+	require.Nil(t, sdr.Unmake(recorder, testLogger))
+	return recorder.Session(), caps
+}
+
+func TestRecordingDevice(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	session, _ := recordSession(t, testLogger)
+	require.NotEmpty(t, session.Calls)
+	assert.Equal(t, "Make", session.Calls[0].Method)
+	assert.JSONEq(t, `[{"label": "stub"}]`, string(session.Calls[0].Args))
+	assert.Equal(t, "Unmake", session.Calls[len(session.Calls)-1].Method)
+
+	var setBandwidth *sdr.SessionCall
+	for i, call := range session.Calls {
+		if call.Method == "SetBandwidth" {
+			setBandwidth = &session.Calls[i]
+		}
+	}
+	require.NotNil(t, setBandwidth)
+	assert.JSONEq(t, `[1, 0, 1500000]`, string(setBandwidth.Args))
+	assert.Equal(t, "bandwidth is fixed", setBandwidth.Error)
+}
+
+func TestReplayDevice(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	recorded, caps := recordSession(t, testLogger)
+	fileName := filepath.Join(t.TempDir(), "stub.json")
+	require.Nil(t, recorded.Save(fileName))
+	session, err := sdr.LoadSession(fileName)
+	require.Nil(t, err)
+
+	replay := sdr.NewReplayDevice(session)
+	require.Nil(t, sdr.Make(replay, map[string]string{"label": "stub"}, testLogger))
+	require.NotNil(t, replay.Device)
+	assert.Equal(t, caps, sdr.GetCapabilities(replay, testLogger))
+	// The results of repeated calls are replayed in the order that they were recorded.
+	assert.Equal(t, 100e6, sdr.GetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0, 433.92e6,
+		map[string]string{}))
+	assert.Equal(t, 433.92e6, sdr.GetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0))
+	assert.Equal(t, 433.92e6, sdr.GetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0))
+
+	err = sdr.SetBandwidth(replay, testLogger, device.DirectionRX, 0, 1.5e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "bandwidth is fixed", err.Error())
+	// Recorded sentinel errors still match when they are replayed.
+	err = sdr.SetHardwareTime(replay, testLogger, 0, sdr.TimeNow)
+	assert.ErrorIs(t, err, sdr.ErrStreamNotSupported)
+	assert.Equal(t, "cannot set the hardware time: "+sdr.ErrStreamNotSupported.Error(), err.Error())
+	assert.Empty(t, replay.Unrecorded())
+
+	err = replay.SetOverallCenterFrequency(device.DirectionRX, 0, 145e6, map[string]string{})
+	assert.ErrorIs(t, err, sdr.ErrNotRecorded)
+	assert.Equal(t, []string{`SetOverallCenterFrequency[1,0,145000000,{}]`}, replay.Unrecorded())
+	require.Nil(t, sdr.Unmake(replay, testLogger))
+	assert.Nil(t, replay.Device)
+}
+
+// TestReplayDevice_ConcurrentMake makes and unmakes a ReplayDevice on several go routines, so that setting Device
+// without holding the mutex is reported by the race detector.
+func TestReplayDevice_ConcurrentMake(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	session, _ := recordSession(t, testLogger)
+	replay := sdr.NewReplayDevice(session)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				assert.Nil(t, replay.Make(map[string]string{"label": "stub"}))
+				assert.Nil(t, replay.Unmake())
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Nil(t, replay.Device)
+}
+
+// TestReplayDevice_RecordedSession replays the session in testdata, which was recorded by recordSession, so that a
+// change to the session file format is caught.
+func TestReplayDevice_RecordedSession(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	session, err := sdr.LoadSession(filepath.Join("testdata", "stub_session.json"))
+	require.Nil(t, err)
+
+	replay := sdr.NewReplayDevice(session)
+	require.Nil(t, sdr.Make(replay, map[string]string{"label": "stub"}, testLogger))
+	stub := &sdr.StubDevice{}
+	require.Nil(t, sdr.Make(stub, map[string]string{"label": "stub"}, testLogger))
+	assert.Equal(t, sdr.GetCapabilities(stub, testLogger), sdr.GetCapabilities(replay, testLogger))
+	assert.Equal(t, 100e6, sdr.GetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0))
+	require.Nil(t, sdr.SetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0, 433.92e6,
+		map[string]string{}))
+	assert.Equal(t, 433.92e6, sdr.GetOverallCenterFrequency(replay, testLogger, device.DirectionRX, 0))
+	err = sdr.SetBandwidth(replay, testLogger, device.DirectionRX, 0, 1.5e6)
+	require.NotNil(t, err)
+	assert.Equal(t, "bandwidth is fixed", err.Error())
+	assert.ErrorIs(t, sdr.SetHardwareTime(replay, testLogger, 0, sdr.TimeNow), sdr.ErrStreamNotSupported)
+	require.Nil(t, sdr.Unmake(replay, testLogger))
+	assert.Empty(t, replay.Unrecorded())
+}
+
+func TestLoadSession_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := sdr.LoadSession(filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, err)
+
+	fileName := filepath.Join(dir, "bad.json")
+	require.Nil(t, os.WriteFile(fileName, []byte("calls"), 0644))
+	_, err = sdr.LoadSession(fileName)
+	require.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "cannot read session file "+fileName))
+}
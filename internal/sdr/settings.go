@@ -0,0 +1,214 @@
+package sdr
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// Settings interface specifies the methods for reading and writing an SDR's device specific settings, such as
+// bias-tee, direct sampling, and offset tuning.
+type Settings interface {
+	GetSettingInfo() []device.SDRArgInfo
+	ReadSetting(string) string
+	WriteSetting(string, string) error
+	GetChannelSettingInfo(device.Direction, uint) []device.SDRArgInfo
+	ReadChannelSetting(device.Direction, uint, string) string
+	WriteChannelSetting(device.Direction, uint, string, string) error
+}
+
+// SettingKind specifies the type of a setting's values.
+type SettingKind int
+
+// The kinds of settings.
+const (
+	// SettingBool is a setting whose value is "true" or "false".
+	SettingBool SettingKind = iota
+	// SettingInt is an integer setting, which may be restricted to a range.
+	SettingInt
+	// SettingFloat is a floating point setting, which may be restricted to a range.
+	SettingFloat
+	// SettingEnum is a setting whose value must be one of a set of options.
+	SettingEnum
+	// SettingString is a setting whose value may be any string.
+	SettingString
+)
+
+// Setting describes a device setting, or a channel setting if IsChannelSetting is true.
+type Setting struct {
+	Key string
+	// Name is the setting's displayable name, or its key if the device does not provide a name.
+	Name        string
+	Description string
+	Unit        string
+	Kind        SettingKind
+	// Default is the value of the setting when it has not been written.
+	Default string
+	// Range is the range of values for SettingInt and SettingFloat settings. The values are only restricted to
+	// Range if HasRange is true.
+	Range    device.SDRRange
+	HasRange bool
+	// Options are the values of a SettingEnum setting, and OptionNames are their displayable names.
+	Options     []string
+	OptionNames []string
+
+	IsChannelSetting bool
+	Direction        device.Direction
+	Channel          uint
+}
+
+// newSetting converts the arg info that a device returns for a setting to a Setting.
+//
+// Settings that have options are SettingEnum settings, whatever the type of their values.
+func newSetting(info device.SDRArgInfo) Setting {
+	setting := Setting{Key: info.Key, Name: info.Name, Description: info.Description, Unit: info.Unit,
+		Default: info.Value, Range: info.Range, HasRange: info.Range.Maximum > info.Range.Minimum}
+	if setting.Name == "" {
+		setting.Name = info.Key
+	}
+	switch {
+	case len(info.Options) > 0:
+		setting.Kind = SettingEnum
+		setting.Options = info.Options
+		setting.OptionNames = make([]string, len(info.Options))
+		for i, option := range info.Options {
+			setting.OptionNames[i] = option
+			if i < len(info.OptionNames) && info.OptionNames[i] != "" {
+				setting.OptionNames[i] = info.OptionNames[i]
+			}
+		}
+	case info.Type == device.ArgInfoBool:
+		setting.Kind = SettingBool
+	case info.Type == device.ArgInfoInt:
+		setting.Kind = SettingInt
+	case info.Type == device.ArgInfoFloat:
+		setting.Kind = SettingFloat
+	default:
+		setting.Kind = SettingString
+	}
+	return setting
+}
+
+// Validate checks that value is a valid value for the setting.
+//
+// Returns the value in the form that is written to the device, or an error if the value is not valid.
+func (setting Setting) Validate(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	switch setting.Kind {
+	case SettingBool:
+		b, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return "", fmt.Errorf("setting %s must be true or false, not '%s'", setting.Key, value)
+		}
+		return strconv.FormatBool(b), nil
+	case SettingInt:
+		i, err := strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("setting %s must be an integer, not '%s'", setting.Key, value)
+		}
+		if err := setting.checkRange(float64(i)); err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(i, 10), nil
+	case SettingFloat:
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("setting %s must be a number, not '%s'", setting.Key, value)
+		}
+		if err := setting.checkRange(f); err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case SettingEnum:
+		if !slices.Contains(setting.Options, value) {
+			return "", fmt.Errorf("setting %s must be one of %v, not '%s'", setting.Key, setting.Options, value)
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+// checkRange returns an error if the setting has a range and value is outside of it.
+func (setting Setting) checkRange(value float64) error {
+	if setting.HasRange && (value < setting.Range.Minimum || value > setting.Range.Maximum) {
+		return fmt.Errorf("setting %s must be between %v and %v, not %v", setting.Key, setting.Range.Minimum,
+			setting.Range.Maximum, value)
+	}
+	return nil
+}
+
+// GetSettings returns the schema for the device's settings.
+func GetSettings(sdrD Settings, log *logger.Logger) []Setting {
+	var settings []Setting
+	for _, info := range sdrD.GetSettingInfo() {
+		settings = append(settings, newSetting(info))
+	}
+	logSettings("Settings", settings, log)
+	return settings
+}
+
+// GetChannelSettings returns the schema for the settings for the specified direction and channel.
+func GetChannelSettings(sdrD Settings, log *logger.Logger, direction device.Direction, channel uint) []Setting {
+	var settings []Setting
+	for _, info := range sdrD.GetChannelSettingInfo(direction, channel) {
+		setting := newSetting(info)
+		setting.IsChannelSetting, setting.Direction, setting.Channel = true, direction, channel
+		settings = append(settings, setting)
+	}
+	logSettings("Channel settings", settings, log)
+	return settings
+}
+
+// logSettings logs the keys and kinds of settings.
+func logSettings(title string, settings []Setting, log *logger.Logger) {
+	if len(settings) == 0 {
+		log.Logf(logger.Debug, "%s: none\n", title)
+		return
+	}
+	var sMsg strings.Builder
+	sMsg.WriteString(fmt.Sprintf("%s:\n", title))
+	for _, setting := range settings {
+		sMsg.WriteString(fmt.Sprintf("         %s: kind %d, default '%s'\n", setting.Key, setting.Kind, setting.Default))
+	}
+	log.Log(logger.Debug, sMsg.String())
+}
+
+// ReadSetting returns the current value of a device or channel setting.
+func ReadSetting(sdrD Settings, log *logger.Logger, setting Setting) string {
+	var value string
+	if setting.IsChannelSetting {
+		value = sdrD.ReadChannelSetting(setting.Direction, setting.Channel, setting.Key)
+	} else {
+		value = sdrD.ReadSetting(setting.Key)
+	}
+	log.Logf(logger.Debug, "Setting %s: %s\n", setting.Key, value)
+	return value
+}
+
+// WriteSetting validates value, then writes it to a device or channel setting.
+//
+// Returns an error if the value is not valid for the setting, or could not be written.
+func WriteSetting(sdrD Settings, log *logger.Logger, setting Setting, value string) error {
+	validated, err := setting.Validate(value)
+	if err != nil {
+		log.Logf(logger.Error, "Cannot write setting: %s\n", err.Error())
+		return err
+	}
+	if setting.IsChannelSetting {
+		err = sdrD.WriteChannelSetting(setting.Direction, setting.Channel, setting.Key, validated)
+	} else {
+		err = sdrD.WriteSetting(setting.Key, validated)
+	}
+	if err != nil {
+		log.Logf(logger.Error, "Could not write '%s' to setting %s: %s\n", validated, setting.Key, err.Error())
+		return err
+	}
+	log.Logf(logger.Debug, "Have written '%s' to setting %s\n", validated, setting.Key)
+	return nil
+}
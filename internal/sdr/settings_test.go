@@ -0,0 +1,110 @@
+package sdr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSettings(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	settings := sdr.GetSettings(&stub, testLogger)
+	require.Equal(t, 3, len(settings))
+	directSamp := settings[0]
+	assert.Equal(t, "direct_samp", directSamp.Key)
+	assert.Equal(t, "Direct Sampling", directSamp.Name)
+	assert.Equal(t, sdr.SettingEnum, directSamp.Kind)
+	assert.Equal(t, []string{"0", "1", "2"}, directSamp.Options)
+	assert.Equal(t, []string{"Off", "I-ADC", "Q-ADC"}, directSamp.OptionNames)
+	assert.False(t, directSamp.IsChannelSetting)
+	assert.Equal(t, sdr.SettingBool, settings[1].Kind)
+	assert.Equal(t, "false", settings[1].Default)
+}
+
+func TestGetChannelSettings(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	settings := sdr.GetChannelSettings(&stub, testLogger, device.DirectionRX, 0)
+	require.Equal(t, 2, len(settings))
+	ifGain := settings[0]
+	assert.Equal(t, sdr.SettingInt, ifGain.Kind)
+	assert.True(t, ifGain.HasRange)
+	assert.Equal(t, 30., ifGain.Range.Maximum)
+	assert.True(t, ifGain.IsChannelSetting)
+	assert.Equal(t, device.DirectionRX, ifGain.Direction)
+	xtal := settings[1]
+	assert.Equal(t, sdr.SettingFloat, xtal.Kind)
+	// xtal_ppm has no name, so its key is used.
+	assert.Equal(t, "xtal_ppm", xtal.Name)
+	assert.False(t, xtal.HasRange)
+
+	assert.Equal(t, 0, len(sdr.GetChannelSettings(&stub, testLogger, device.DirectionTX, 0)))
+}
+
+func TestSetting_Validate(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	settings := sdr.GetSettings(&stub, testLogger)
+	channelSettings := sdr.GetChannelSettings(&stub, testLogger, device.DirectionRX, 0)
+	for _, test := range []struct {
+		setting sdr.Setting
+		value   string
+		valid   string
+		err     string
+	}{
+		{settings[0], "2", "2", ""},
+		{settings[0], "Q-ADC", "", "setting direct_samp must be one of [0 1 2], not 'Q-ADC'"},
+		{settings[1], " 1", "true", ""},
+		{settings[1], "on", "", "setting offset_tune must be true or false, not 'on'"},
+		{channelSettings[0], "30", "30", ""},
+		{channelSettings[0], "31", "", "setting if_gain must be between 0 and 30, not 31"},
+		{channelSettings[0], "1.5", "", "setting if_gain must be an integer, not '1.5'"},
+		{channelSettings[1], "-1.25", "-1.25", ""},
+		{channelSettings[1], "NaN", "", "setting xtal_ppm must be a number, not 'NaN'"},
+	} {
+		valid, err := test.setting.Validate(test.value)
+		assert.Equal(t, test.valid, valid, "setting %s, value %s", test.setting.Key, test.value)
+		if test.err == "" {
+			assert.Nil(t, err)
+		} else if assert.NotNil(t, err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+func TestWriteSetting(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	settings := sdr.GetSettings(&stub, testLogger)
+	biasTee := settings[2]
+	assert.Equal(t, "false", sdr.ReadSetting(&stub, testLogger, biasTee))
+	require.Nil(t, sdr.WriteSetting(&stub, testLogger, biasTee, "TRUE"))
+	assert.Equal(t, "true", sdr.ReadSetting(&stub, testLogger, biasTee))
+
+	ifGain := sdr.GetChannelSettings(&stub, testLogger, device.DirectionRX, 0)[0]
+	assert.Equal(t, "10", sdr.ReadSetting(&stub, testLogger, ifGain))
+	require.Nil(t, sdr.WriteSetting(&stub, testLogger, ifGain, "20"))
+	assert.Equal(t, "20", sdr.ReadSetting(&stub, testLogger, ifGain))
+	assert.NotNil(t, sdr.WriteSetting(&stub, testLogger, ifGain, "-1"))
+	assert.Equal(t, "20", sdr.ReadSetting(&stub, testLogger, ifGain))
+}
+
+func TestWriteSetting_Error(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Errors: map[string]error{"WriteSetting": errors.New("could not write setting biastee")}}
+	biasTee := sdr.GetSettings(&stub, testLogger)[2]
+	err := sdr.WriteSetting(&stub, testLogger, biasTee, "true")
+	require.NotNil(t, err)
+	assert.Equal(t, "could not write setting biastee", err.Error())
+	testLogger.Close()
+	assert.Contains(t, log.String(), "Could not write 'true' to setting biastee: could not write setting biastee\n")
+}
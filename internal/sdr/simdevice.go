@@ -0,0 +1,237 @@
+package sdr
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SimModulation specifies how a SimSignal's carrier is modulated.
+type SimModulation int
+
+// The modulation types that SimDevice can generate.
+const (
+	// SimUnmodulated is an unmodulated carrier.
+	SimUnmodulated SimModulation = iota
+	// SimAM is a carrier that is amplitude modulated by a tone.
+	SimAM
+	// SimFM is a carrier that is frequency modulated by a tone.
+	SimFM
+)
+
+// SimSignal is a signal that is generated by SimDevice.
+type SimSignal struct {
+	// Frequency is the carrier frequency in Hz.
+	Frequency float64
+	// Power is the carrier power in dBFS when the device gain is 0 dB.
+	Power float64
+	// Modulation is the type of modulation applied to the carrier.
+	Modulation SimModulation
+	// ToneFrequency is the frequency in Hz of the modulating tone.
+	ToneFrequency float64
+	// Depth is the modulation depth (0.0 to 1.0) for SimAM, or the peak frequency deviation in Hz for SimFM.
+	Depth float64
+}
+
+// simSignalState holds the oscillator phases for a SimSignal so that signals are continuous between reads.
+type simSignalState struct {
+	carrierPhase float64
+	tonePhase    float64
+}
+
+// SimDevice is a simulated SDR that generates IQ samples from a set of signals, so that jsdr can be used and
+// developed without an SDR attached.
+//
+// SimDevice emulates a single channel receiver. Only signals that are within the sample rate bandwidth about the
+// center frequency appear in the samples, and the samples are scaled by the current gain, so the center frequency,
+// sample rate and gain settings affect the generated samples in the same way that they would for a real receiver.
+//
+// Use NewSimDevice to create a SimDevice. A SimDevice may be used concurrently on multiple go routines, for example
+// to change its frequency while a stream is being read.
+type SimDevice struct {
+	Device *Sdr
+	Args   map[string]string
+
+	mutex           sync.Mutex
+	signals         []SimSignal
+	signalStates    []simSignalState
+	noiseFloor      float64
+	realtime        bool
+	random          *rand.Rand
+	centerFrequency float64
+	sampleRate      float64
+	gains           map[string]float64
+	agcEnabled      bool
+	antenna         string
+	// samplesGenerated is the number of samples generated since the device was created. It is used to calculate
+	// stream timestamps.
+	samplesGenerated uint64
+	// activatedAt and activatedSample are used to pace reads when the device generates samples in real time.
+	activatedAt     time.Time
+	activatedSample uint64
+	// scratch holds the samples that are generated for each read.
+	scratch []complex64
+}
+
+// Default settings for a SimDevice.
+const (
+	simDefaultCenterFrequency = 100e6
+	simDefaultSampleRate      = 2.048e6
+	simDefaultNoiseFloor      = -60.
+	simMTU                    = 16384
+	// simAgcTarget is the power in dBFS that AGC sets the strongest signal in the passband to.
+	simAgcTarget = -10.
+)
+
+// simFrequencyRange is the tuning range of a SimDevice.
+var simFrequencyRange = device.SDRRange{Minimum: 24e6, Maximum: 1.766e9, Step: 0}
+
+// simSampleRateRange is the range of sample rates that a SimDevice supports.
+var simSampleRateRange = device.SDRRange{Minimum: 225001, Maximum: 10e6, Step: 0}
+
+// simGainRanges are the gain ranges for each of a SimDevice's gain elements.
+var simGainRanges = map[string]device.SDRRange{
+	"LNA": {Minimum: 0, Maximum: 30, Step: 1},
+	"VGA": {Minimum: 0, Maximum: 20, Step: 1},
+}
+
+// simGainElementNames lists the gain elements in the order that overall gain is distributed to them.
+var simGainElementNames = []string{"LNA", "VGA"}
+
+// simDeviceArgs are the device args that SimDevice.Enumerate returns.
+var simDeviceArgs = map[string]string{"driver": "sim", "label": "Simulated SDR :: sim0", "serial": "sim0"}
+
+// NewSimDevice creates a SimDevice that generates the specified signals on top of a noise floor of -60 dBFS.
+//
+// The device is tuned to 100 MHz with a sample rate of 2.048 MS/s and 0 dB of gain.
+func NewSimDevice(signals []SimSignal) *SimDevice {
+	return &SimDevice{
+		signals:         signals,
+		signalStates:    make([]simSignalState, len(signals)),
+		noiseFloor:      simDefaultNoiseFloor,
+		random:          rand.New(rand.NewSource(1)),
+		centerFrequency: simDefaultCenterFrequency,
+		sampleRate:      simDefaultSampleRate,
+		gains:           map[string]float64{"LNA": 0, "VGA": 0},
+		antenna:         "RX",
+	}
+}
+
+// SetSignals replaces the signals that the device generates.
+func (dev *SimDevice) SetSignals(signals []SimSignal) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.signals = signals
+	dev.signalStates = make([]simSignalState, len(signals))
+}
+
+// SetNoiseFloor sets the noise power in dBFS when the device gain is 0 dB.
+func (dev *SimDevice) SetNoiseFloor(noiseFloor float64) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.noiseFloor = noiseFloor
+}
+
+// SetRealtime sets whether stream reads are paced so that samples are returned at the sample rate.
+//
+// By default, reads return immediately, which is what tests require. Set realtime to true when the samples are
+// processed as they would be from a real receiver, for example in the UI.
+func (dev *SimDevice) SetRealtime(realtime bool) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.realtime = realtime
+}
+
+// Enumerate returns the args for the single simulated device.
+func (dev *SimDevice) Enumerate(_ map[string]string) []map[string]string {
+	args := make(map[string]string, len(simDeviceArgs))
+	for k, v := range simDeviceArgs {
+		args[k] = v
+	}
+	return []map[string]string{args}
+}
+
+// Make makes the simulated device. args must contain the "driver" value "sim".
+func (dev *SimDevice) Make(args map[string]string) error {
+	if args["driver"] != simDeviceArgs["driver"] {
+		return errors.New("SimDevice can only make devices with driver 'sim'")
+	}
+	dev.Args = args
+	dev.Device = &Sdr{DeviceProperties: args}
+	return nil
+}
+
+// Unmake returns nil if a previous call to Make was successful; otherwise, returns an error.
+func (dev *SimDevice) Unmake() error {
+	if dev.Device == nil {
+		return errors.New("no device to unmake")
+	}
+	dev.Device = nil
+	dev.Args = nil
+	return nil
+}
+
+// GetHardwareKey returns the hardware key for the simulated device.
+func (dev *SimDevice) GetHardwareKey() string {
+	return "SimDevice"
+}
+
+// generate fills samples with the next len(samples) samples of the simulated signal.
+//
+// Returns the timestamp of the first sample in nanoseconds. dev.mutex must be held by the caller.
+func (dev *SimDevice) generate(samples []complex64) uint {
+	timeNs := uint(float64(dev.samplesGenerated) * 1e9 / dev.sampleRate)
+	gain := dev.effectiveGain()
+	noiseAmplitude := math.Pow(10, (dev.noiseFloor+gain)/20) / math.Sqrt2
+	for i := range samples {
+		samples[i] = complex64(complex(dev.random.NormFloat64()*noiseAmplitude,
+			dev.random.NormFloat64()*noiseAmplitude))
+	}
+	for s, signal := range dev.signals {
+		offset := signal.Frequency - dev.centerFrequency
+		if math.Abs(offset) >= dev.sampleRate/2 {
+			continue
+		}
+		state := &dev.signalStates[s]
+		amplitude := math.Pow(10, (signal.Power+gain)/20)
+		carrierStep := 2 * math.Pi * offset / dev.sampleRate
+		toneStep := 2 * math.Pi * signal.ToneFrequency / dev.sampleRate
+		for i := range samples {
+			a := amplitude
+			switch signal.Modulation {
+			case SimAM:
+				a *= 1 + signal.Depth*math.Cos(state.tonePhase)
+			case SimFM:
+				state.carrierPhase += 2 * math.Pi * signal.Depth * math.Sin(state.tonePhase) / dev.sampleRate
+			}
+			sin, cos := math.Sincos(state.carrierPhase)
+			samples[i] += complex64(complex(a*cos, a*sin))
+			state.carrierPhase = math.Mod(state.carrierPhase+carrierStep, 2*math.Pi)
+			state.tonePhase = math.Mod(state.tonePhase+toneStep, 2*math.Pi)
+		}
+	}
+	dev.samplesGenerated += uint64(len(samples))
+	return timeNs
+}
+
+// effectiveGain returns the gain in dB that is applied to the generated signals.
+//
+// When AGC is enabled, the gain is set so that the strongest signal in the passband is at simAgcTarget dBFS.
+// dev.mutex must be held by the caller.
+func (dev *SimDevice) effectiveGain() float64 {
+	if !dev.agcEnabled {
+		return dev.gains["LNA"] + dev.gains["VGA"]
+	}
+	strongest := dev.noiseFloor
+	for _, signal := range dev.signals {
+		if math.Abs(signal.Frequency-dev.centerFrequency) < dev.sampleRate/2 && signal.Power > strongest {
+			strongest = signal.Power
+		}
+	}
+	maxGain := simGainRanges["LNA"].Maximum + simGainRanges["VGA"].Maximum
+	return math.Max(0, math.Min(maxGain, simAgcTarget-strongest))
+}
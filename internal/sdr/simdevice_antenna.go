@@ -0,0 +1,43 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetAntennaNames returns the single "RX" antenna of the simulated device.
+func (dev *SimDevice) GetAntennaNames(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	antennas := []string{"RX"}
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).Antennas = antennas
+	}
+	return antennas
+}
+
+// GetCurrentAntenna returns the currently selected antenna.
+func (dev *SimDevice) GetCurrentAntenna(direction device.Direction, channel uint) string {
+	if !dev.isChannel(direction, channel) {
+		return ""
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.antenna
+}
+
+// SetAntenna selects the specified antenna. Returns an error if the antenna is not "RX".
+func (dev *SimDevice) SetAntenna(direction device.Direction, channel uint, antenna string) error {
+	if !dev.isChannel(direction, channel) || antenna != "RX" {
+		return fmt.Errorf("invalid antenna: %s", antenna)
+	}
+	dev.mutex.Lock()
+	dev.antenna = antenna
+	dev.mutex.Unlock()
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).Antenna = antenna
+	}
+	return nil
+}
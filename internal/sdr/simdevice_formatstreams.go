@@ -0,0 +1,30 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SetupFormatStream sets up a CU8, CS16, or CF32 stream for the specified channels of the simulated device. The
+// generated samples are clipped to the range of the stream's format, except for CF32 samples, which are not
+// clipped.
+func (dev *SimDevice) SetupFormatStream(format string, direction device.Direction, channels []uint,
+	_ map[string]string) (formatStreamDevice, error) {
+	if err := dev.validateStreamChannels(direction, channels); err != nil {
+		return nil, err
+	}
+	return newConvertedFormatStream(format, sampleProducer{
+		mtu: simMTU,
+		activate: func() error {
+			dev.activate()
+			return nil
+		},
+		readSamples: func(numElems uint, outputFlags *[1]int, timeoutUs uint,
+			store func([]complex64)) (uint, uint, error) {
+			timeNs, err := dev.readSamples(numElems, outputFlags, timeoutUs, store)
+			if err != nil {
+				return 0, 0, err
+			}
+			return timeNs, numElems, nil
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetFrequencyRanges returns the tuning range of the simulated device.
+func (dev *SimDevice) GetFrequencyRanges(direction device.Direction, channel uint) []device.SDRRange {
+	if !dev.isChannel(direction, channel) {
+		return []device.SDRRange{}
+	}
+	return []device.SDRRange{simFrequencyRange}
+}
+
+// GetOverallCenterFrequency returns the center frequency of the simulated device.
+func (dev *SimDevice) GetOverallCenterFrequency(_ device.Direction, _ uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.centerFrequency
+}
+
+// SetOverallCenterFrequency tunes the simulated device to the specified frequency.
+//
+// Returns an error if the frequency is outside the device's tuning range.
+func (dev *SimDevice) SetOverallCenterFrequency(direction device.Direction, channel uint, newFreq float64,
+	_ map[string]string) error {
+	return dev.SetTunableElementFrequency(direction, channel, "RF", newFreq)
+}
+
+// GetTunableElementNames returns the single tunable element of the simulated device.
+func (dev *SimDevice) GetTunableElementNames(_ device.Direction, _ uint) []string {
+	return []string{"RF"}
+}
+
+// GetTunableElementFrequencyRanges returns the tuning range of the "RF" tunable element.
+func (dev *SimDevice) GetTunableElementFrequencyRanges(direction device.Direction, channel uint,
+	name string) []device.SDRRange {
+	if name != "RF" {
+		return []device.SDRRange{}
+	}
+	return dev.GetFrequencyRanges(direction, channel)
+}
+
+// GetTunableElementFrequency returns the frequency of the "RF" tunable element, which is the center frequency.
+func (dev *SimDevice) GetTunableElementFrequency(direction device.Direction, channel uint, name string) float64 {
+	if name != "RF" {
+		return 0.0
+	}
+	return dev.GetOverallCenterFrequency(direction, channel)
+}
+
+// SetTunableElementFrequency sets the frequency of the "RF" tunable element, which is the center frequency.
+func (dev *SimDevice) SetTunableElementFrequency(direction device.Direction, channel uint, name string,
+	newFreq float64) error {
+	if !dev.isChannel(direction, channel) {
+		return fmt.Errorf("SimDevice does not have %s channel %d", directionAsString(direction), channel)
+	}
+	if name != "RF" {
+		return fmt.Errorf("invalid tunable element: %s", name)
+	}
+	if newFreq < simFrequencyRange.Minimum || newFreq > simFrequencyRange.Maximum {
+		return fmt.Errorf("frequency %.1f Hz is outside the range %.1f to %.1f Hz", newFreq,
+			simFrequencyRange.Minimum, simFrequencyRange.Maximum)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.centerFrequency = newFreq
+	return nil
+}
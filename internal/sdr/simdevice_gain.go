@@ -0,0 +1,100 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SupportsAGC returns true for the receive channel of the simulated device.
+func (dev *SimDevice) SupportsAGC(direction device.Direction, channel uint) bool {
+	return dev.isChannel(direction, channel)
+}
+
+// AgcIsEnabled returns whether AGC is currently enabled.
+func (dev *SimDevice) AgcIsEnabled(_ device.Direction, _ uint) bool {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.agcEnabled
+}
+
+// EnableAgc enables or disables AGC.
+//
+// When AGC is enabled, the gain element settings are ignored and the gain is set so that the strongest signal in
+// the passband is at -10 dBFS.
+func (dev *SimDevice) EnableAgc(direction device.Direction, channel uint, enable bool) error {
+	if !dev.SupportsAGC(direction, channel) {
+		return errors.New("AGC is only supported on the receive channel")
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.agcEnabled = enable
+	return nil
+}
+
+// GetGainElementNames returns the names of the simulated device's gain elements.
+func (dev *SimDevice) GetGainElementNames(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	return simGainElementNames
+}
+
+// GetElementGain returns the gain in dB of the specified element.
+func (dev *SimDevice) GetElementGain(_ device.Direction, _ uint, eltName string) (float64, error) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	gain, ok := dev.gains[eltName]
+	if !ok {
+		return 0.0, fmt.Errorf("gain element '%s' is invalid", eltName)
+	}
+	return gain, nil
+}
+
+// SetElementGain sets the gain of the specified element.
+//
+// Returns an error if the element does not exist or the gain is outside the element's range.
+func (dev *SimDevice) SetElementGain(direction device.Direction, channel uint, eltName string, gain float64) error {
+	gainRange, ok := simGainRanges[eltName]
+	if !ok || !dev.isChannel(direction, channel) {
+		return fmt.Errorf("gain element '%s' is invalid", eltName)
+	}
+	if gain < gainRange.Minimum || gain > gainRange.Maximum {
+		return fmt.Errorf("cannot set gain for element: %s to %.1f. Requested gain is outside the allowable range: %.1f to %.1f",
+			eltName, gain, gainRange.Minimum, gainRange.Maximum)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.gains[eltName] = gain
+	return nil
+}
+
+// GetElementGainRange returns the gain range of the specified element.
+func (dev *SimDevice) GetElementGainRange(_ device.Direction, _ uint, eltName string) device.SDRRange {
+	return simGainRanges[eltName]
+}
+
+// GetOverallGain returns the sum of the element gains.
+func (dev *SimDevice) GetOverallGain(_ device.Direction, _ uint) float64 {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.gains["LNA"] + dev.gains["VGA"]
+}
+
+// SetOverallGain sets the overall gain.
+//
+// As with most receivers, the gain is applied to the LNA first, and any remaining gain is applied to the VGA.
+func (dev *SimDevice) SetOverallGain(direction device.Direction, channel uint, overallGain float64) error {
+	maxGain := simGainRanges["LNA"].Maximum + simGainRanges["VGA"].Maximum
+	if !dev.isChannel(direction, channel) || overallGain < 0. || overallGain > maxGain {
+		return fmt.Errorf("requested overall gain = %.1f dB, but must be between 0.0 and %.1f dB", overallGain, maxGain)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	lna := math.Min(overallGain, simGainRanges["LNA"].Maximum)
+	dev.gains["LNA"] = lna
+	dev.gains["VGA"] = overallGain - lna
+	return nil
+}
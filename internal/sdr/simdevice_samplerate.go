@@ -0,0 +1,44 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetSampleRateRange returns the range of sample rates that the simulated device supports.
+func (dev *SimDevice) GetSampleRateRange(direction device.Direction, channel uint) []device.SDRRange {
+	if !dev.isChannel(direction, channel) {
+		return []device.SDRRange{}
+	}
+	return []device.SDRRange{simSampleRateRange}
+}
+
+// GetSampleRate returns the current sample rate.
+func (dev *SimDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
+	dev.mutex.Lock()
+	rate := dev.sampleRate
+	dev.mutex.Unlock()
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).SampleRate = rate
+	}
+	return rate
+}
+
+// SetSampleRate sets the sample rate. Returns an error if the rate is outside the supported range.
+func (dev *SimDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
+	if !dev.isChannel(direction, channel) {
+		return fmt.Errorf("SimDevice does not have %s channel %d", directionAsString(direction), channel)
+	}
+	if rate < simSampleRateRange.Minimum || rate > simSampleRateRange.Maximum {
+		return fmt.Errorf("sample rate %.1f is outside the range %.1f to %.1f", rate,
+			simSampleRateRange.Minimum, simSampleRateRange.Maximum)
+	}
+	dev.mutex.Lock()
+	dev.sampleRate = rate
+	dev.mutex.Unlock()
+	if dev.Device != nil {
+		dev.Device.Channel(direction, channel).SampleRate = rate
+	}
+	return nil
+}
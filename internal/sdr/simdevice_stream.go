@@ -0,0 +1,81 @@
+package sdr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+	"github.com/pothosware/go-soapy-sdr/pkg/sdrerror"
+)
+
+// GetStreamFormats returns the stream formats that the simulated device supports.
+func (dev *SimDevice) GetStreamFormats(direction device.Direction, channel uint) []string {
+	if !dev.isChannel(direction, channel) {
+		return []string{}
+	}
+	return []string{FormatCS8, FormatCU8, FormatCS16, FormatCF32}
+}
+
+// GetNativeStreamFormat returns CF32 with a full scale value of 1.0, because the simulated device generates
+// floating point samples.
+func (dev *SimDevice) GetNativeStreamFormat(_ device.Direction, _ uint) (string, float64) {
+	return FormatCF32, 1.0
+}
+
+// validateStreamChannels returns an error if any of the channels does not exist on the simulated device.
+func (dev *SimDevice) validateStreamChannels(direction device.Direction, channels []uint) error {
+	for _, channel := range channels {
+		if !dev.isChannel(direction, channel) {
+			return fmt.Errorf("SimDevice does not have %s channel %d", directionAsString(direction), channel)
+		}
+	}
+	return nil
+}
+
+// activate restarts the real time pacing of stream reads.
+func (dev *SimDevice) activate() {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.activatedAt = time.Now()
+	dev.activatedSample = dev.samplesGenerated
+}
+
+// readSamples generates numElems samples and passes them to store, which copies them into a stream buffer.
+//
+// When the device is generating samples in real time, readSamples waits until the samples would have been
+// received by a real device, and returns a timeout error if that is longer than timeoutUs.
+//
+// Returns the timestamp of the first sample in nanoseconds, or an error.
+func (dev *SimDevice) readSamples(numElems uint, outputFlags *[1]int, timeoutUs uint,
+	store func([]complex64)) (uint, error) {
+	outputFlags[0] = 0
+	if wait := dev.pacingDelay(numElems); wait > 0 {
+		if timeout := time.Duration(timeoutUs) * time.Microsecond; wait > timeout {
+			time.Sleep(timeout)
+			return 0, streamError(&sdrerror.Timeout{})
+		}
+		time.Sleep(wait)
+	}
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if uint(cap(dev.scratch)) < numElems {
+		dev.scratch = make([]complex64, numElems)
+	}
+	samples := dev.scratch[:numElems]
+	timeNs := dev.generate(samples)
+	store(samples)
+	outputFlags[0] = int(device.StreamFlagHasTime)
+	return timeNs, nil
+}
+
+// pacingDelay returns how long to wait before numElems more samples are due when generating samples in real time.
+func (dev *SimDevice) pacingDelay(numElems uint) time.Duration {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	if !dev.realtime || dev.activatedAt.IsZero() {
+		return 0
+	}
+	samples := float64(dev.samplesGenerated - dev.activatedSample + uint64(numElems))
+	due := dev.activatedAt.Add(time.Duration(samples / dev.sampleRate * float64(time.Second)))
+	return time.Until(due)
+}
@@ -0,0 +1,64 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// SetupCS8Stream sets up a CS8 stream for the specified channels of the simulated device.
+func (dev *SimDevice) SetupCS8Stream(direction device.Direction, channels []uint,
+	_ map[string]string) (*StreamCS8, error) {
+	if err := dev.validateStreamChannels(direction, channels); err != nil {
+		return nil, err
+	}
+	return &StreamCS8{streamState: newStreamState(FormatCS8, direction, channels), device: dev}, nil
+}
+
+// CloseCS8Stream closes the specified stream. The simulated device has no stream resources to free.
+func (dev *SimDevice) CloseCS8Stream(_ *StreamCS8) error {
+	return nil
+}
+
+// GetCS8MTU returns the stream's maximum transmission unit in number of elements.
+func (dev *SimDevice) GetCS8MTU(_ *StreamCS8) int {
+	return simMTU
+}
+
+// ActivateCS8Stream activates the specified stream.
+func (dev *SimDevice) ActivateCS8Stream(_ *StreamCS8, _ device.StreamFlag, _ int, _ int) error {
+	dev.activate()
+	return nil
+}
+
+// DeactivateCS8Stream deactivates the specified stream.
+func (dev *SimDevice) DeactivateCS8Stream(_ *StreamCS8, _ device.StreamFlag, _ int) error {
+	return nil
+}
+
+// ReadCS8Stream fills each of the stream's buffers with generated samples.
+//
+// Samples are clipped to the range of the CS8 format.
+func (dev *SimDevice) ReadCS8Stream(_ *StreamCS8, buff [][]int, numElemsToRead uint, outputFlags *[1]int,
+	timeoutUs uint) (timeNs uint, numElemsRead uint, err error) {
+	for _, chBuff := range buff {
+		if maxElems := uint(len(chBuff) / 2); numElemsToRead > maxElems {
+			numElemsToRead = maxElems
+		}
+	}
+	timeNs, err = dev.readSamples(numElemsToRead, outputFlags, timeoutUs, func(samples []complex64) {
+		storeCS8(buff, samples)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return timeNs, numElemsToRead, nil
+}
+
+// WriteCS8Stream returns ErrStreamNotSupported because SimDevice only simulates a receiver.
+func (dev *SimDevice) WriteCS8Stream(_ *StreamCS8, _ [][]int, _ uint, _ device.StreamFlag, _ uint, _ uint) (uint, error) {
+	return 0, ErrStreamNotSupported
+}
+
+// ReadCS8StreamStatus returns ErrStreamNotSupported because SimDevice does not have transmit streams.
+func (dev *SimDevice) ReadCS8StreamStatus(_ *StreamCS8, _ *[1]int, _ uint) (uint, error) {
+	return 0, ErrStreamNotSupported
+}
@@ -0,0 +1,244 @@
+package sdr_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/cmplx"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ sdr.Enumerate = (*sdr.SimDevice)(nil)
+var _ sdr.MakeDevice = (*sdr.SimDevice)(nil)
+var _ sdr.Channels = (*sdr.SimDevice)(nil)
+var _ sdr.Frequency = (*sdr.SimDevice)(nil)
+var _ sdr.Gain = (*sdr.SimDevice)(nil)
+var _ sdr.Agc = (*sdr.SimDevice)(nil)
+var _ sdr.Antenna = (*sdr.SimDevice)(nil)
+var _ sdr.SampleRates = (*sdr.SimDevice)(nil)
+var _ sdr.SampleStreams = (*sdr.SimDevice)(nil)
+
+// readSimSamples reads numSamples normalised samples from channel 0 of sim.
+func readSimSamples(t *testing.T, sim *sdr.SimDevice, numSamples uint) []complex64 {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	source, err := sdr.NewSampleSource(sim, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer source.Close(testLogger)
+	require.Nil(t, source.Activate(testLogger, 0, 0, 0))
+	defer source.Deactivate(testLogger, 0, 0)
+	samples := [][]complex64{make([]complex64, numSamples)}
+	var outputFlags [1]int
+	_, numRead, err := source.ReadSamples(testLogger, samples, numSamples, &outputFlags, 100000)
+	require.Nil(t, err)
+	require.Equal(t, numSamples, numRead)
+	return samples[0]
+}
+
+// rms returns the root mean square magnitude of samples.
+func rms(samples []complex64) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		sum += float64(real(s)*real(s) + imag(s)*imag(s))
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// instantaneousFrequencies returns the frequency in Hz between each pair of adjacent samples.
+func instantaneousFrequencies(samples []complex64, sampleRate float64) []float64 {
+	frequencies := make([]float64, len(samples)-1)
+	for i := range frequencies {
+		delta := complex128(samples[i+1]) * cmplx.Conj(complex128(samples[i]))
+		frequencies[i] = cmplx.Phase(delta) * sampleRate / (2 * math.Pi)
+	}
+	return frequencies
+}
+
+func TestSimDevice_EnumerateAndMake(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice(nil)
+	sdrs := sdr.EnumerateWithoutAudio(sim, testLogger)
+	require.Equal(t, 1, len(sdrs))
+	args, ok := sdrs["Simulated SDR :: sim0"]
+	require.True(t, ok)
+	assert.Nil(t, sdr.Make(sim, args, testLogger))
+	assert.Equal(t, "SimDevice", sim.GetHardwareKey())
+	assert.Nil(t, sdr.Unmake(sim, testLogger))
+	assert.NotNil(t, sdr.Unmake(sim, testLogger))
+	assert.NotNil(t, sim.Make(map[string]string{"driver": "rtlsdr"}))
+}
+
+func TestSimDevice_Settings(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice(nil)
+	assert.Equal(t, uint(1), sdr.GetNumChannels(sim, testLogger, device.DirectionRX))
+	assert.Equal(t, uint(0), sdr.GetNumChannels(sim, testLogger, device.DirectionTX))
+
+	assert.Nil(t, sdr.SetOverallCenterFrequency(sim, testLogger, device.DirectionRX, 0, 433.92e6, nil))
+	assert.Equal(t, 433.92e6, sdr.GetOverallCenterFrequency(sim, testLogger, device.DirectionRX, 0))
+	assert.NotNil(t, sdr.SetOverallCenterFrequency(sim, testLogger, device.DirectionRX, 0, 10e6, nil))
+
+	assert.Nil(t, sim.SetSampleRate(device.DirectionRX, 0, 1e6))
+	assert.Equal(t, 1e6, sim.GetSampleRate(device.DirectionRX, 0))
+	assert.NotNil(t, sim.SetSampleRate(device.DirectionRX, 0, 20e6))
+
+	assert.Nil(t, sdr.SetOverallGain(sim, testLogger, device.DirectionRX, 0, 40))
+	lna, err := sim.GetElementGain(device.DirectionRX, 0, "LNA")
+	assert.Nil(t, err)
+	assert.Equal(t, 30., lna)
+	vga, err := sim.GetElementGain(device.DirectionRX, 0, "VGA")
+	assert.Nil(t, err)
+	assert.Equal(t, 10., vga)
+	assert.NotNil(t, sim.SetElementGain(device.DirectionRX, 0, "VGA", 25))
+	assert.NotNil(t, sim.SetElementGain(device.DirectionRX, 0, "IF", 5))
+
+	assert.Equal(t, []string{"RX"}, sdr.GetAntennaNames(sim, testLogger, device.DirectionRX, 0))
+	assert.NotNil(t, sdr.SetAntenna(sim, testLogger, device.DirectionRX, 0, "TX"))
+
+	_, err = sdr.SetupCS8Stream(sim, testLogger, device.DirectionRX, []uint{1})
+	assert.Equal(t, "SimDevice does not have RX channel 1", err.Error())
+}
+
+func TestSimDevice_Carrier(t *testing.T) {
+	sim := sdr.NewSimDevice([]sdr.SimSignal{{Frequency: 100.1e6, Power: -20}})
+	sim.SetNoiseFloor(-140)
+	samples := readSimSamples(t, sim, 10000)
+	assert.InDelta(t, 0.1, rms(samples), 0.001)
+	for _, frequency := range instantaneousFrequencies(samples, 2.048e6) {
+		require.InDelta(t, 100e3, frequency, 100)
+	}
+}
+
+func TestSimDevice_GainScalesOutput(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice([]sdr.SimSignal{{Frequency: 100.1e6, Power: -40}})
+	sim.SetNoiseFloor(-100)
+	assert.InDelta(t, 0.01, rms(readSimSamples(t, sim, 10000)), 0.0001)
+	require.Nil(t, sdr.SetOverallGain(sim, testLogger, device.DirectionRX, 0, 20))
+	assert.InDelta(t, 0.1, rms(readSimSamples(t, sim, 10000)), 0.001)
+
+	require.Nil(t, sdr.EnableAgc(sim, testLogger, device.DirectionRX, 0, true))
+	// AGC sets the gain so that the carrier is at -10 dBFS.
+	assert.InDelta(t, math.Pow(10, -0.5), rms(readSimSamples(t, sim, 10000)), 0.003)
+}
+
+func TestSimDevice_RetuneRemovesSignal(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice([]sdr.SimSignal{{Frequency: 100.1e6, Power: -20}})
+	assert.Greater(t, rms(readSimSamples(t, sim, 10000)), 0.09)
+	require.Nil(t, sdr.SetOverallCenterFrequency(sim, testLogger, device.DirectionRX, 0, 105e6, nil))
+	// Only the -60 dBFS noise floor remains.
+	assert.InDelta(t, 0.001, rms(readSimSamples(t, sim, 10000)), 0.0001)
+}
+
+func TestSimDevice_AM(t *testing.T) {
+	sim := sdr.NewSimDevice([]sdr.SimSignal{
+		{Frequency: 100e6, Power: -20, Modulation: sdr.SimAM, ToneFrequency: 1000, Depth: 0.5}})
+	sim.SetNoiseFloor(-100)
+	samples := readSimSamples(t, sim, 10000)
+	minimum, maximum := math.MaxFloat64, 0.
+	for _, s := range samples {
+		magnitude := cmplx.Abs(complex128(s))
+		minimum = math.Min(minimum, magnitude)
+		maximum = math.Max(maximum, magnitude)
+	}
+	assert.InDelta(t, 0.05, minimum, 0.001)
+	assert.InDelta(t, 0.15, maximum, 0.001)
+}
+
+func TestSimDevice_FM(t *testing.T) {
+	sim := sdr.NewSimDevice([]sdr.SimSignal{
+		{Frequency: 100.2e6, Power: -20, Modulation: sdr.SimFM, ToneFrequency: 1000, Depth: 5000}})
+	sim.SetNoiseFloor(-140)
+	samples := readSimSamples(t, sim, 10000)
+	minimum, maximum := math.MaxFloat64, -math.MaxFloat64
+	for _, frequency := range instantaneousFrequencies(samples, 2.048e6) {
+		minimum = math.Min(minimum, frequency)
+		maximum = math.Max(maximum, frequency)
+	}
+	assert.InDelta(t, 195e3, minimum, 100)
+	assert.InDelta(t, 205e3, maximum, 100)
+	// The amplitude of an FM signal is constant.
+	assert.InDelta(t, 0.1, rms(samples), 0.001)
+}
+
+func TestSimDevice_CS8Clipping(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice([]sdr.SimSignal{{Frequency: 100.1e6, Power: 6}})
+	stream, err := sdr.SetupCS8Stream(sim, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := [][]int{make([]int, 2*mtu)}
+	var outputFlags [1]int
+	_, numRead, err := stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, mtu, numRead)
+	assert.Equal(t, int(device.StreamFlagHasTime), outputFlags[0])
+	assert.Equal(t, 127, slices.Max(buffer[0]))
+	assert.Equal(t, -128, slices.Min(buffer[0]))
+}
+
+func TestSimDevice_Receiver(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice([]sdr.SimSignal{{Frequency: 100.1e6, Power: -20}})
+	source, err := sdr.NewSampleSource(sim, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	assert.Equal(t, sdr.FormatCF32, source.Format())
+	receiver := sdr.NewReceiver(source, testLogger, sim.GetSampleRate(device.DirectionRX, 0), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	for i := 0; i < 5; i++ {
+		block := <-receiver.Blocks()
+		assert.Equal(t, 16384, len(block.Samples[0]))
+		assert.False(t, block.Discontinuity)
+		block.Release()
+	}
+	cancel()
+	for block := range receiver.Blocks() {
+		block.Release()
+	}
+	assert.Nil(t, receiver.Wait())
+	assert.Equal(t, uint64(0), receiver.Stats().TimestampGaps)
+}
+
+func TestSimDevice_Realtime(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	sim := sdr.NewSimDevice(nil)
+	sim.SetRealtime(true)
+	require.Nil(t, sim.SetSampleRate(device.DirectionRX, 0, 1e6))
+	stream, err := sdr.SetupFormatStream[complex64](sim, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]complex64{make([]complex64, 20000)}
+	var outputFlags [1]int
+	start := time.Now()
+	_, _, err = stream.ReadFromStream(testLogger, buffer, 20000, &outputFlags, 100000)
+	require.Nil(t, err)
+	// 20000 samples at 1 MS/s take 20 ms.
+	assert.GreaterOrEqual(t, time.Since(start), 19*time.Millisecond)
+
+	_, _, err = stream.ReadFromStream(testLogger, buffer, 20000, &outputFlags, 1000)
+	assert.True(t, errors.Is(err, sdr.ErrStreamTimeout))
+}
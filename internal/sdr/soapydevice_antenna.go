@@ -4,16 +4,26 @@ import (
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
+// GetAntennaNames returns the list of antennas for the specified direction and channel number.
 func (sD *SoapyDevice) GetAntennaNames(direction device.Direction, channel uint) []string {
-	return sD.Device.Device.ListAntennas(direction, channel)
+	antennas := sD.Device.Device.ListAntennas(direction, channel)
+	sD.Device.Channel(direction, channel).Antennas = antennas
+	return antennas
 }
 
 // GetCurrentAntenna returns the currently selected antenna for the specified direction and channel number.
 func (sD *SoapyDevice) GetCurrentAntenna(direction device.Direction, channel uint) string {
-	return sD.Device.Device.GetAntennas(direction, channel)
+	antenna := sD.Device.Device.GetAntennas(direction, channel)
+	sD.Device.Channel(direction, channel).Antenna = antenna
+	return antenna
 }
 
 // SetAntenna sets the current antenna for the specified direction and channel number
 func (sD *SoapyDevice) SetAntenna(direction device.Direction, channel uint, antennaName string) error {
-	return sD.Device.Device.SetAntennas(direction, channel, antennaName)
+	err := sD.Device.Device.SetAntennas(direction, channel, antennaName)
+	if err != nil {
+		return err
+	}
+	sD.Device.Channel(direction, channel).Antenna = antennaName
+	return nil
 }
@@ -0,0 +1,20 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetBandwidthRanges returns the analog filter bandwidth ranges for the specified direction and channel.
+func (sD *SoapyDevice) GetBandwidthRanges(direction device.Direction, channel uint) []device.SDRRange {
+	return sD.Device.Device.GetBandwidthRanges(direction, channel)
+}
+
+// GetBandwidth returns the analog filter bandwidth in Hz for the specified direction and channel.
+func (sD *SoapyDevice) GetBandwidth(direction device.Direction, channel uint) float64 {
+	return sD.Device.Device.GetBandwidth(direction, channel)
+}
+
+// SetBandwidth sets the analog filter bandwidth in Hz for the specified direction and channel.
+func (sD *SoapyDevice) SetBandwidth(direction device.Direction, channel uint, bw float64) error {
+	return sD.Device.Device.SetBandwidth(direction, channel, bw)
+}
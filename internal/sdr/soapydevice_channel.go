@@ -0,0 +1,10 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetNumChannels returns the number of channels that the device supports in the specified direction.
+func (sD *SoapyDevice) GetNumChannels(direction device.Direction) uint {
+	return sD.Device.Device.GetNumChannels(direction)
+}
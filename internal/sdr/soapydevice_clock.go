@@ -0,0 +1,35 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetMasterClockRate returns the master clock rate of the device in Hz.
+func (sD *SoapyDevice) GetMasterClockRate() float64 {
+	return sD.Device.Device.GetMasterClockRate()
+}
+
+// SetMasterClockRate sets the master clock rate of the device in Hz.
+func (sD *SoapyDevice) SetMasterClockRate(rate float64) error {
+	return sD.Device.Device.SetMasterClockRate(rate)
+}
+
+// GetMasterClockRates returns the ranges of master clock rates that the device supports.
+func (sD *SoapyDevice) GetMasterClockRates() []device.SDRRange {
+	return sD.Device.Device.GetMasterClockRates()
+}
+
+// GetClockSources returns the names of the device's clock sources.
+func (sD *SoapyDevice) GetClockSources() []string {
+	return sD.Device.Device.ListClockSources()
+}
+
+// GetClockSource returns the name of the current clock source.
+func (sD *SoapyDevice) GetClockSource() string {
+	return sD.Device.Device.GetClockSource()
+}
+
+// SetClockSource selects the clock source.
+func (sD *SoapyDevice) SetClockSource(source string) error {
+	return sD.Device.Device.SetClockSource(source)
+}
@@ -0,0 +1,86 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+	"github.com/pothosware/go-soapy-sdr/pkg/sdrerror"
+)
+
+// soapyStream specifies the methods of the go-soapy-sdr stream of T values, for example device.SDRStreamCU8 for
+// uint8 values.
+type soapyStream[T StreamElement] interface {
+	Close() sdrerror.SDRError
+	GetMTU() int
+	Activate(device.StreamFlag, int, int) sdrerror.SDRError
+	Deactivate(device.StreamFlag, int) sdrerror.SDRError
+	Read([][]T, uint, []int, uint) (uint, uint, error)
+}
+
+// soapyFormatStream is the device side of a FormatStream that is read from a go-soapy-sdr stream.
+type soapyFormatStream[T StreamElement] struct {
+	stream soapyStream[T]
+}
+
+// SetupFormatStream initializes a stream of the specified format for the specified direction, channels, and args.
+func (sD *SoapyDevice) SetupFormatStream(format string, direction device.Direction, channels []uint,
+	args map[string]string) (formatStreamDevice, error) {
+	switch format {
+	case FormatCU8:
+		stream, err := sD.Device.Device.SetupSDRStreamCU8(direction, channels, args)
+		if err != nil {
+			return nil, err
+		}
+		return &soapyFormatStream[uint8]{stream: stream}, nil
+	case FormatCS16:
+		stream, err := sD.Device.Device.SetupSDRStreamCS16(direction, channels, args)
+		if err != nil {
+			return nil, err
+		}
+		return &soapyFormatStream[int16]{stream: stream}, nil
+	case FormatCF32:
+		stream, err := sD.Device.Device.SetupSDRStreamCF32(direction, channels, args)
+		if err != nil {
+			return nil, err
+		}
+		return &soapyFormatStream[complex64]{stream: stream}, nil
+	}
+	return nil, fmt.Errorf("unsupported stream format: %s", format)
+}
+
+func (s *soapyFormatStream[T]) close() error {
+	err := s.stream.Close()
+	s.stream = nil
+	return err
+}
+
+func (s *soapyFormatStream[T]) mtu() int {
+	return s.stream.GetMTU()
+}
+
+func (s *soapyFormatStream[T]) activate(flag device.StreamFlag, timeNs int, numElems int) error {
+	return s.stream.Activate(flag, timeNs, numElems)
+}
+
+func (s *soapyFormatStream[T]) deactivate(flag device.StreamFlag, timeNs int) error {
+	return s.stream.Deactivate(flag, timeNs)
+}
+
+// read reads up to numElemsToRead elements from the stream directly into buff.
+//
+// Each channel's buffer must hold at least numElemsToRead elements. SoapySDR stream errors are returned
+// as one of the ErrStream... errors.
+func (s *soapyFormatStream[T]) read(stream *FormatStream[T], buff [][]T, numElemsToRead uint, outputFlags *[1]int,
+	timeoutUs uint) (timeNs uint, numElemsRead uint, err error) {
+	if s.stream == nil {
+		return 0, 0, errors.New("attempting to read from a closed stream")
+	}
+	flags := stream.soapyFlags()
+	timeNs, numElemsRead, err = s.stream.Read(buff, numElemsToRead, flags, timeoutUs)
+	outputFlags[0] = flags[0]
+	if err != nil {
+		return timeNs, 0, streamError(err)
+	}
+	return timeNs, numElemsRead, nil
+}
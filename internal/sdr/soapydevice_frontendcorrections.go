@@ -0,0 +1,67 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// HasDCOffsetMode returns whether the device supports automatic DC offset correction.
+func (sD *SoapyDevice) HasDCOffsetMode(direction device.Direction, channel uint) bool {
+	return sD.Device.Device.HasDCOffsetMode(direction, channel)
+}
+
+// GetDCOffsetMode returns whether automatic DC offset correction is enabled.
+func (sD *SoapyDevice) GetDCOffsetMode(direction device.Direction, channel uint) bool {
+	return sD.Device.Device.GetDCOffsetMode(direction, channel)
+}
+
+// SetDCOffsetMode enables or disables automatic DC offset correction.
+func (sD *SoapyDevice) SetDCOffsetMode(direction device.Direction, channel uint, automatic bool) error {
+	return sD.Device.Device.SetDCOffsetMode(direction, channel, automatic)
+}
+
+// HasDCOffset returns whether the device supports manual DC offset correction.
+func (sD *SoapyDevice) HasDCOffset(direction device.Direction, channel uint) bool {
+	return sD.Device.Device.HasDCOffset(direction, channel)
+}
+
+// GetDCOffset returns the I and Q DC offset corrections.
+func (sD *SoapyDevice) GetDCOffset(direction device.Direction, channel uint) (float64, float64, error) {
+	offsetI, offsetQ, err := sD.Device.Device.GetDCOffset(direction, channel)
+	return offsetI, offsetQ, err
+}
+
+// SetDCOffset sets the I and Q DC offset corrections.
+func (sD *SoapyDevice) SetDCOffset(direction device.Direction, channel uint, offsetI, offsetQ float64) error {
+	return sD.Device.Device.SetDCOffset(direction, channel, offsetI, offsetQ)
+}
+
+// HasIQBalance returns whether the device supports IQ balance correction.
+func (sD *SoapyDevice) HasIQBalance(direction device.Direction, channel uint) bool {
+	return sD.Device.Device.HasIQBalance(direction, channel)
+}
+
+// GetIQBalance returns the I and Q balance corrections.
+func (sD *SoapyDevice) GetIQBalance(direction device.Direction, channel uint) (float64, float64, error) {
+	balanceI, balanceQ, err := sD.Device.Device.GetIQBalance(direction, channel)
+	return balanceI, balanceQ, err
+}
+
+// SetIQBalance sets the I and Q balance corrections.
+func (sD *SoapyDevice) SetIQBalance(direction device.Direction, channel uint, balanceI, balanceQ float64) error {
+	return sD.Device.Device.SetIQBalance(direction, channel, balanceI, balanceQ)
+}
+
+// HasFrequencyCorrection returns whether the device supports frequency correction.
+func (sD *SoapyDevice) HasFrequencyCorrection(direction device.Direction, channel uint) bool {
+	return sD.Device.Device.HasFrequencyCorrection(direction, channel)
+}
+
+// GetFrequencyCorrection returns the frequency correction in parts per million.
+func (sD *SoapyDevice) GetFrequencyCorrection(direction device.Direction, channel uint) float64 {
+	return sD.Device.Device.GetFrequencyCorrection(direction, channel)
+}
+
+// SetFrequencyCorrection sets the frequency correction in parts per million.
+func (sD *SoapyDevice) SetFrequencyCorrection(direction device.Direction, channel uint, ppm float64) error {
+	return sD.Device.Device.SetFrequencyCorrection(direction, channel, ppm)
+}
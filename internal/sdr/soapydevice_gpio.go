@@ -0,0 +1,40 @@
+package sdr
+
+// GetGPIOBanks returns the names of the device's GPIO banks.
+func (sD *SoapyDevice) GetGPIOBanks() []string {
+	return sD.Device.Device.ListGPIOBanks()
+}
+
+// WriteGPIO writes the value of all of the pins of a GPIO bank.
+func (sD *SoapyDevice) WriteGPIO(bank string, value uint32) error {
+	return sD.Device.Device.WriteGPIO(bank, value)
+}
+
+// WriteGPIOMasked writes the value of the pins of a GPIO bank that are selected by mask.
+func (sD *SoapyDevice) WriteGPIOMasked(bank string, value uint32, mask uint32) error {
+	return sD.Device.Device.WriteGPIOMasked(bank, value, mask)
+}
+
+// ReadGPIO reads the value of the pins of a GPIO bank.
+//
+// The returned error is always nil.
+func (sD *SoapyDevice) ReadGPIO(bank string) (uint32, error) {
+	return sD.Device.Device.ReadGPIO(bank), nil
+}
+
+// WriteGPIODir writes the direction of all of the pins of a GPIO bank.
+func (sD *SoapyDevice) WriteGPIODir(bank string, dir uint32) error {
+	return sD.Device.Device.WriteGPIODir(bank, dir)
+}
+
+// WriteGPIODirMasked writes the direction of the pins of a GPIO bank that are selected by mask.
+func (sD *SoapyDevice) WriteGPIODirMasked(bank string, dir uint32, mask uint32) error {
+	return sD.Device.Device.WriteGPIODirMasked(bank, dir, mask)
+}
+
+// ReadGPIODir reads the direction of the pins of a GPIO bank.
+//
+// The returned error is always nil.
+func (sD *SoapyDevice) ReadGPIODir(bank string) (uint32, error) {
+	return sD.Device.Device.ReadGPIODir(bank), nil
+}
@@ -0,0 +1,31 @@
+package sdr
+
+// GetTimeSources returns the names of the device's time sources.
+func (sD *SoapyDevice) GetTimeSources() []string {
+	return sD.Device.Device.ListTimeSources()
+}
+
+// GetTimeSource returns the name of the current time source.
+func (sD *SoapyDevice) GetTimeSource() string {
+	return sD.Device.Device.GetTimeSource()
+}
+
+// SetTimeSource selects the time source.
+func (sD *SoapyDevice) SetTimeSource(source string) error {
+	return sD.Device.Device.SetTimeSource(source)
+}
+
+// HasHardwareTime returns whether the device has the specified hardware time.
+func (sD *SoapyDevice) HasHardwareTime(what string) bool {
+	return sD.Device.Device.HasHardwareTime(what)
+}
+
+// GetHardwareTime returns the specified hardware time in nanoseconds.
+func (sD *SoapyDevice) GetHardwareTime(what string) uint {
+	return sD.Device.Device.GetHardwareTime(what)
+}
+
+// SetHardwareTime sets the specified hardware time in nanoseconds.
+func (sD *SoapyDevice) SetHardwareTime(timeNs uint, what string) error {
+	return sD.Device.Device.SetHardwareTime(timeNs, what)
+}
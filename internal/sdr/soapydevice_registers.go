@@ -0,0 +1,30 @@
+package sdr
+
+// GetRegisterInterfaces returns the names of the device's register interfaces.
+func (sD *SoapyDevice) GetRegisterInterfaces() []string {
+	return sD.Device.Device.ListRegisterInterfaces()
+}
+
+// WriteRegister writes a register of a register interface.
+func (sD *SoapyDevice) WriteRegister(name string, addr uint32, value uint32) error {
+	return sD.Device.Device.WriteRegister(name, addr, value)
+}
+
+// ReadRegister reads a register of a register interface.
+//
+// The returned error is always nil.
+func (sD *SoapyDevice) ReadRegister(name string, addr uint32) (uint32, error) {
+	return sD.Device.Device.ReadRegister(name, addr), nil
+}
+
+// WriteRegisters writes consecutive registers of a register interface. values must not be empty.
+func (sD *SoapyDevice) WriteRegisters(name string, addr uint32, values []uint32) error {
+	return sD.Device.Device.WriteRegisters(name, addr, values)
+}
+
+// ReadRegisters reads consecutive registers of a register interface.
+//
+// The returned error is always nil. Fewer than length values are returned if the device could not read them all.
+func (sD *SoapyDevice) ReadRegisters(name string, addr uint32, length uint) ([]uint32, error) {
+	return sD.Device.Device.ReadRegisters(name, addr, length), nil
+}
@@ -12,10 +12,17 @@ func (sD *SoapyDevice) GetSampleRateRange(direction device.Direction, channel ui
 // GetSampleRate returns the currently set sample rate for the device.
 // If SetSampleRate has not been called, this is probably the device's default value.
 func (sD *SoapyDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
-	sD.Device.SampleRate = sD.Device.Device.GetSampleRate(direction, channel)
-	return sD.Device.SampleRate
+	state := sD.Device.Channel(direction, channel)
+	state.SampleRate = sD.Device.Device.GetSampleRate(direction, channel)
+	return state.SampleRate
 }
 
+// SetSampleRate sets the sample rate for the specified direction and channel.
 func (sD *SoapyDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
-	return sD.Device.Device.SetSampleRate(direction, channel, rate)
+	err := sD.Device.Device.SetSampleRate(direction, channel, rate)
+	if err != nil {
+		return err
+	}
+	sD.Device.Channel(direction, channel).SampleRate = rate
+	return nil
 }
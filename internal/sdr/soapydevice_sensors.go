@@ -0,0 +1,39 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetSensorNames returns the keys of the device's sensors.
+func (sD *SoapyDevice) GetSensorNames() []string {
+	return sD.Device.Device.ListSensors()
+}
+
+// GetSensorInfo returns the info for the device sensor with the specified key.
+func (sD *SoapyDevice) GetSensorInfo(key string) device.SDRArgInfo {
+	return sD.Device.Device.GetSensorInfo(key)
+}
+
+// ReadSensor reads the device sensor with the specified key.
+//
+// The returned error is always nil.
+func (sD *SoapyDevice) ReadSensor(key string) (string, error) {
+	return sD.Device.Device.ReadSensor(key), nil
+}
+
+// GetChannelSensorNames returns the keys of the sensors for the specified direction and channel.
+func (sD *SoapyDevice) GetChannelSensorNames(direction device.Direction, channel uint) []string {
+	return sD.Device.Device.ListChannelSensors(direction, channel)
+}
+
+// GetChannelSensorInfo returns the info for the channel sensor with the specified key.
+func (sD *SoapyDevice) GetChannelSensorInfo(direction device.Direction, channel uint, key string) device.SDRArgInfo {
+	return sD.Device.Device.GetChannelSensorInfo(direction, channel, key)
+}
+
+// ReadChannelSensor reads the channel sensor with the specified key.
+//
+// The returned error is always nil.
+func (sD *SoapyDevice) ReadChannelSensor(direction device.Direction, channel uint, key string) (string, error) {
+	return sD.Device.Device.ReadChannelSensor(direction, channel, key), nil
+}
@@ -0,0 +1,35 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetSettingInfo returns the arg info for each of the device's settings.
+func (sD *SoapyDevice) GetSettingInfo() []device.SDRArgInfo {
+	return sD.Device.Device.GetSettingInfo()
+}
+
+// ReadSetting returns the value of the device setting with the specified key.
+func (sD *SoapyDevice) ReadSetting(key string) string {
+	return sD.Device.Device.ReadSetting(key)
+}
+
+// WriteSetting writes a value to the device setting with the specified key.
+func (sD *SoapyDevice) WriteSetting(key string, value string) error {
+	return sD.Device.Device.WriteSetting(key, value)
+}
+
+// GetChannelSettingInfo returns the arg info for each of the settings for the specified direction and channel.
+func (sD *SoapyDevice) GetChannelSettingInfo(direction device.Direction, channel uint) []device.SDRArgInfo {
+	return sD.Device.Device.GetChannelSettingInfo(direction, channel)
+}
+
+// ReadChannelSetting returns the value of the channel setting with the specified key.
+func (sD *SoapyDevice) ReadChannelSetting(direction device.Direction, channel uint, key string) string {
+	return sD.Device.Device.ReadChannelSetting(direction, channel, key)
+}
+
+// WriteChannelSetting writes a value to the channel setting with the specified key.
+func (sD *SoapyDevice) WriteChannelSetting(direction device.Direction, channel uint, key string, value string) error {
+	return sD.Device.Device.WriteChannelSetting(direction, channel, key, value)
+}
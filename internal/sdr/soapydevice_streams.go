@@ -1,6 +1,11 @@
 package sdr
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
@@ -9,7 +14,7 @@ func (sD *SoapyDevice) SetupCS8Stream(direction device.Direction,
 	channels []uint,
 	args map[string]string) (*StreamCS8, error) {
 	stream, err := sD.Device.Device.SetupSDRStreamCS8(direction, channels, args)
-	return &StreamCS8{stream: stream, device: sD}, err
+	return &StreamCS8{streamState: newStreamState(FormatCS8, direction, channels), stream: stream, device: sD}, err
 }
 
 // Close closes the specified stream.
@@ -24,14 +29,14 @@ func (sD *SoapyDevice) GetCS8MTU(stream *StreamCS8) int {
 	return stream.stream.GetMTU()
 }
 
-// Activate activates the CS8 stream.
-func (sD *SoapyDevice) Activate(stream *StreamCS8, flag device.StreamFlag,
+// ActivateCS8Stream activates the CS8 stream.
+func (sD *SoapyDevice) ActivateCS8Stream(stream *StreamCS8, flag device.StreamFlag,
 	timeNs int, numElems int) error {
 	return stream.stream.Activate(flag, timeNs, numElems)
 }
 
-// Deactivate deactivates the active stream.
-func (sD *SoapyDevice) Deactivate(stream *StreamCS8, flag device.StreamFlag,
+// DeactivateCS8Stream deactivates the active stream.
+func (sD *SoapyDevice) DeactivateCS8Stream(stream *StreamCS8, flag device.StreamFlag,
 	timeNS int) error {
 	return stream.stream.Deactivate(flag, timeNS)
 }
@@ -39,12 +44,13 @@ func (sD *SoapyDevice) Deactivate(stream *StreamCS8, flag device.StreamFlag,
 // ReadCS8Stream reads numElemsToRead elements from the stream.
 //
 // Params:
-//   - buff: the buffer that stream data from a single channel is stored in. This buffer must be
+//   - buff: the buffers that stream data is stored in, one for each of the stream's channels. Each buffer must be
 //
-// initialized to [1][2 * MTU] in size before Read is called.
+// initialized to [2 * MTU] in size before Read is called.
 //   - numElemsToRead: The number of elements to read. Because the stream is complex, the actual
 //
-// number of integers read is 2 * MTU.
+// number of integers read is 2 * numElemsToRead. numElemsToRead is reduced if buff is too small to hold
+// that many elements, and an error is returned if buff cannot hold any elements.
 //   - outputFlags: The flag indicators of the result of the read operation.
 //   - timeoutUs: the timeout in microseconds.
 //
@@ -52,11 +58,166 @@ func (sD *SoapyDevice) Deactivate(stream *StreamCS8, flag device.StreamFlag,
 //   - timeNs: the timestamp for the data in buff.
 //   - numElemsRead: the number of elements read. Since this stream is complex, the total number
 //
-// of integers returned in buff is 2 times this number.
-//   - err: the error if the read is not successful, or nil if the read is successful. On error, the
+// of integers returned in each buffer is 2 times this number.
+//   - err: the error if the read is not successful, or nil if the read is successful. SoapySDR stream errors
 //
-// contents of buff, timeNs, and numElemsRead may not be valid.
-func (sD *SoapyDevice) ReadCS8Stream(stream *StreamCS8, buff [][]int, numElemsToRead uint, outputFlags *[1]int, timeoutNs uint) (
+// are returned as one of the ErrStream... errors, for example ErrStreamTimeout or ErrStreamOverflow. On error,
+// the contents of buff, timeNs, and numElemsRead may not be valid.
+func (sD *SoapyDevice) ReadCS8Stream(stream *StreamCS8, buff [][]int, numElemsToRead uint, outputFlags *[1]int, timeoutUs uint) (
 	timeNs uint, numElemsRead uint, err error) {
-	return 0, 0, nil
+	if stream.stream == nil {
+		return 0, 0, errors.New("attempting to read from a closed stream")
+	}
+	if len(buff) != len(stream.channels) {
+		return 0, 0, fmt.Errorf("read buffer must have %d channels", len(stream.channels))
+	}
+	for _, chBuff := range buff {
+		if maxElems := uint(len(chBuff) / 2); numElemsToRead > maxElems {
+			numElemsToRead = maxElems
+		}
+	}
+	if numElemsToRead == 0 {
+		return 0, 0, errors.New("read buffer is too small to hold any elements")
+	}
+	cs8Buff := stream.soapyBuffers(numElemsToRead)
+	flags := stream.soapyFlags()
+	timeNs, numElemsRead, err = stream.stream.Read(cs8Buff, numElemsToRead, flags, timeoutUs)
+	outputFlags[0] = flags[0]
+	if err != nil {
+		return timeNs, 0, streamError(err)
+	}
+	for ch := range buff {
+		for i := uint(0); i < 2*numElemsRead; i++ {
+			buff[ch][i] = int(cs8Buff[ch][i])
+		}
+	}
+	return timeNs, numElemsRead, nil
+}
+
+// WriteCS8Stream writes numElems elements from buff to the stream.
+//
+// Params:
+//   - buff: the buffers that hold the data to write, one for each of the stream's channels. Each value must be
+//
+// between -128 and 127.
+//   - numElems: The number of elements to write. Because the stream is complex, the actual number of integers
+//
+// written from each buffer is 2 * numElems. numElems is reduced to the number of elements that the smallest buffer
+// holds.
+//   - flags: the flag indicators for the write, for example StreamFlagHasTime and StreamFlagEndBurst.
+//   - timeNs: the time at which to transmit the data. Only valid if flags has StreamFlagHasTime.
+//   - timeoutUs: the timeout in microseconds.
+//
+// Returns the number of elements written, which may be less than numElems, and an error or nil. An error is returned
+// if the buffers cannot hold any elements. SoapySDR stream errors are returned as one of the ErrStream... errors.
+func (sD *SoapyDevice) WriteCS8Stream(stream *StreamCS8, buff [][]int, numElems uint, flags device.StreamFlag,
+	timeNs uint, timeoutUs uint) (uint, error) {
+	if stream.stream == nil {
+		return 0, errors.New("attempting to write to a closed stream")
+	}
+	if len(buff) != len(stream.channels) {
+		return 0, fmt.Errorf("write buffer must have %d channels", len(stream.channels))
+	}
+	for _, chBuff := range buff {
+		if maxElems := uint(len(chBuff) / 2); numElems > maxElems {
+			numElems = maxElems
+		}
+	}
+	if numElems == 0 {
+		return 0, errors.New("write buffer is too small to hold any elements")
+	}
+	cs8Buff := stream.soapyBuffers(numElems)
+	for ch := range buff {
+		for i := uint(0); i < 2*numElems; i++ {
+			cs8Buff[ch][i] = int8(buff[ch][i])
+		}
+	}
+	if err := setSoapyWriteBuffers(stream.stream, cs8Buff); err != nil {
+		return 0, err
+	}
+	writeFlags := stream.soapyFlags()
+	writeFlags[0] = int(flags)
+	numElemsWritten, err := stream.stream.Write(cs8Buff, numElems, writeFlags, timeNs, timeoutUs)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	return numElemsWritten, nil
+}
+
+// ReadCS8StreamStatus waits up to timeoutUs microseconds for a status event on the stream.
+//
+// Returns the time of the event in nanoseconds, and an error or nil. The event's flags are returned in outputFlags.
+// SoapySDR stream errors, such as underflows, are returned as one of the ErrStream... errors.
+func (sD *SoapyDevice) ReadCS8StreamStatus(stream *StreamCS8, outputFlags *[1]int, timeoutUs uint) (uint, error) {
+	if stream.stream == nil {
+		return 0, errors.New("attempting to read the status of a closed stream")
+	}
+	// chanMask receives the mask of the channels that the status applies to.
+	chanMask := make([]uint, len(stream.channels))
+	flags := stream.soapyFlags()
+	timeNs, err := stream.stream.ReadStreamStatus(chanMask, flags, timeoutUs)
+	outputFlags[0] = flags[0]
+	if err != nil {
+		return timeNs, streamError(err)
+	}
+	return timeNs, nil
+}
+
+// soapyBuffers returns the int8 buffers that go-soapy-sdr reads CS8 data into and writes CS8 data from.
+//
+// The buffers are kept with the stream so that they are only allocated again if a larger read or write is requested.
+func (stream *StreamCS8) soapyBuffers(numElems uint) [][]int8 {
+	if len(stream.int8Buff) != len(stream.channels) || uint(len(stream.int8Buff[0])) < 2*numElems {
+		stream.int8Buff = make([][]int8, len(stream.channels))
+		for ch := range stream.int8Buff {
+			stream.int8Buff[ch] = make([]int8, 2*numElems)
+		}
+	}
+	return stream.int8Buff
+}
+
+// soapyWriteBufferField is the name of the unexported field of device.SDRStreamCS8 that holds the array of buffer
+// pointers that go-soapy-sdr passes to SoapySDRDevice_writeStream. See setSoapyWriteBuffers.
+const soapyWriteBufferField = "writeBuffer"
+
+// setSoapyWriteBuffers stores pointers to buffs in the array of buffer pointers that go-soapy-sdr passes to
+// SoapySDRDevice_writeStream.
+//
+// This works around a bug in SDRStreamCS8.Write in pkg/device/streams.go of github.com/pothosware/go-soapy-sdr
+// v0.7.4: Write stores the pointers to the buffers that it is passed in the stream's readBuffer array, but passes the
+// stream's writeBuffer array, which is never filled, to SoapySDRDevice_writeStream, so nothing is transmitted. This
+// function fills the writeBuffer array through reflect and unsafe, so it depends on the layout of SDRStreamCS8 in
+// v0.7.4. TestSoapyWriteBufferLayout fails if go-soapy-sdr is updated, so that the work around is checked against the
+// new version, and removed if the bug has been fixed.
+//
+// Returns an error if the stream does not have a write buffer array, rather than letting Write silently transmit
+// nothing.
+func setSoapyWriteBuffers(stream *device.SDRStreamCS8, buffs [][]int8) error {
+	if err := checkSoapyWriteBufferLayout(); err != nil {
+		return err
+	}
+	field := reflect.ValueOf(stream).Elem().FieldByName(soapyWriteBufferField)
+	if field.IsNil() {
+		return errors.New("go-soapy-sdr CS8 stream's write buffer array has not been allocated; cannot write to the " +
+			"stream")
+	}
+	ptrs := unsafe.Slice((*unsafe.Pointer)(field.UnsafePointer()), len(buffs))
+	for ch := range buffs {
+		ptrs[ch] = unsafe.Pointer(&buffs[ch][0])
+	}
+	return nil
+}
+
+// checkSoapyWriteBufferLayout returns an error if device.SDRStreamCS8 does not have the write buffer array that
+// setSoapyWriteBuffers fills: a pointer to an array of pointers.
+func checkSoapyWriteBufferLayout() error {
+	field, ok := reflect.TypeOf(device.SDRStreamCS8{}).FieldByName(soapyWriteBufferField)
+	if !ok {
+		return errors.New("go-soapy-sdr CS8 stream does not have a write buffer array; cannot write to the stream")
+	}
+	if field.Type.Kind() != reflect.Pointer || field.Type.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("go-soapy-sdr CS8 stream's write buffer array has type %v, not a pointer to an array of "+
+			"pointers; cannot write to the stream", field.Type)
+	}
+	return nil
 }
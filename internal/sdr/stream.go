@@ -1,8 +1,8 @@
 package sdr
 
 import (
-	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/jimorc/jsdr/internal/logger"
@@ -10,20 +10,32 @@ import (
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
+// Stream formats that jsdr can read.
+const (
+	FormatCU8  = "CU8"
+	FormatCS8  = "CS8"
+	FormatCS16 = "CS16"
+	FormatCF32 = "CF32"
+)
+
+// preferredStreamFormats lists the stream formats that jsdr supports, in order of preference when the
+// device's native format is not one of them.
+var preferredStreamFormats = []string{FormatCS16, FormatCF32, FormatCS8, FormatCU8}
+
 // Stream interface specifies stream related functionality.
 type Stream interface {
 	GetStreamFormats(device.Direction, uint) []string
 	GetNativeStreamFormat(device.Direction, uint) (string, float64)
 }
 
-// GetStreamFormats retrieves the stream formats for RX channel 0.
+// GetStreamFormats retrieves the stream formats for the specified direction and channel.
 //
-// Returns an error if there are no formats for RX channel 0.
-func GetStreamFormats(sdrD Stream, log *logger.Logger) ([]string, error) {
-	formats := sdrD.GetStreamFormats(device.DirectionRX, 0)
+// Returns an error if there are no formats for the channel.
+func GetStreamFormats(sdrD Stream, log *logger.Logger, direction device.Direction, channel uint) ([]string, error) {
+	formats := sdrD.GetStreamFormats(direction, channel)
 	if len(formats) == 0 {
-		log.Log(logger.Error, "Channel 0 has no stream formats\n")
-		err := errors.New("no stream formats retrieved for channel 0")
+		log.Logf(logger.Error, "%s channel %d has no stream formats\n", directionAsString(direction), channel)
+		err := fmt.Errorf("no stream formats retrieved for channel %d", channel)
 		return formats, err
 	}
 	var formatStr strings.Builder
@@ -35,7 +47,34 @@ func GetStreamFormats(sdrD Stream, log *logger.Logger) ([]string, error) {
 	return formats, nil
 }
 
-// GetNativeStreamFormat retrieves the native format for RX channel 0 and its full scale value.
-func GetNativeStreamFormat(sdrD Stream, log *logger.Logger) (string, float64) {
-	return sdrD.GetNativeStreamFormat(device.DirectionRX, 0)
+// GetNativeStreamFormat retrieves the native format for the specified direction and channel and its full scale value.
+func GetNativeStreamFormat(sdrD Stream, log *logger.Logger, direction device.Direction, channel uint) (string, float64) {
+	return sdrD.GetNativeStreamFormat(direction, channel)
+}
+
+// SelectStreamFormat selects the best stream format for the specified direction and channel.
+//
+// The device's native format is selected if jsdr supports it, because no conversion is then done by the driver.
+// Otherwise, the first of CS16, CF32, CS8, and CU8 that the device supports is selected.
+//
+// Returns an error if the device has no stream formats or none of its formats are supported by jsdr.
+func SelectStreamFormat(sdrD Stream, log *logger.Logger, direction device.Direction, channel uint) (string, error) {
+	formats, err := GetStreamFormats(sdrD, log, direction, channel)
+	if err != nil {
+		return "", err
+	}
+	native, _ := sdrD.GetNativeStreamFormat(direction, channel)
+	if slices.Contains(formats, native) && slices.Contains(preferredStreamFormats, native) {
+		log.Logf(logger.Debug, "Selected native stream format: %s\n", native)
+		return native, nil
+	}
+	for _, format := range preferredStreamFormats {
+		if slices.Contains(formats, format) {
+			log.Logf(logger.Debug, "Native stream format %s is not supported. Selected stream format: %s\n",
+				native, format)
+			return format, nil
+		}
+	}
+	log.Logf(logger.Error, "None of the stream formats %v are supported.\n", formats)
+	return "", fmt.Errorf("none of the stream formats %v are supported", formats)
 }
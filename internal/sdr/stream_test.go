@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 
 	"github.com/jimorc/jsdr/internal/logger"
 	"github.com/stretchr/testify/assert"
@@ -12,8 +13,8 @@ import (
 
 func TestGetStreamFormats(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	formats, err := sdr.GetStreamFormats(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	formats, err := sdr.GetStreamFormats(&stub, testLogger, device.DirectionRX, 0)
 	assert.Nil(t, err)
 	assert.Equal(t, 3, len(formats))
 	assert.True(t, slices.Contains(formats, "CS8"))
@@ -23,8 +24,8 @@ func TestGetStreamFormats(t *testing.T) {
 
 func TestGetStreamFormats_NoFormats(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	formats, err := sdr.GetStreamFormats(&stub, testLogger)
+	stub := sdr.StubDevice{StreamFormats: []string{}}
+	formats, err := sdr.GetStreamFormats(&stub, testLogger, device.DirectionRX, 0)
 	assert.NotNil(t, err)
 	assert.Equal(t, "no stream formats retrieved for channel 0", err.Error())
 	assert.Equal(t, 0, len(formats))
@@ -32,8 +33,42 @@ func TestGetStreamFormats_NoFormats(t *testing.T) {
 
 func TestGetNativeStreamFormat(t *testing.T) {
 	testLogger, _ := logger.NewFileLogger("stdout")
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	format, fullScale := sdr.GetNativeStreamFormat(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	format, fullScale := sdr.GetNativeStreamFormat(&stub, testLogger, device.DirectionRX, 0)
 	assert.Equal(t, "CS8", format)
-	assert.Equal(t, 0.0, fullScale)
+	assert.Equal(t, 128.0, fullScale)
+}
+
+func TestSelectStreamFormat_Native(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{}
+	format, err := sdr.SelectStreamFormat(&stub, testLogger, device.DirectionRX, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, sdr.FormatCS8, format)
+}
+
+func TestSelectStreamFormat_NativeNotSupported(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{StreamFormats: []string{"CS16", "CF32", "CF64"}, NativeStreamFormat: "CF64",
+		NativeFullScale: 1.0}
+	format, err := sdr.SelectStreamFormat(&stub, testLogger, device.DirectionRX, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, sdr.FormatCS16, format)
+}
+
+func TestSelectStreamFormat_NoSupportedFormats(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{StreamFormats: []string{"CF64"}}
+	format, err := sdr.SelectStreamFormat(&stub, testLogger, device.DirectionRX, 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, "none of the stream formats [CF64] are supported", err.Error())
+	assert.Equal(t, "", format)
+}
+
+func TestSelectStreamFormat_NoFormats(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{StreamFormats: []string{}}
+	_, err := sdr.SelectStreamFormat(&stub, testLogger, device.DirectionRX, 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, "no stream formats retrieved for channel 0", err.Error())
 }
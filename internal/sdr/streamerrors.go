@@ -0,0 +1,52 @@
+package sdr
+
+import (
+	"errors"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/sdrerror"
+)
+
+// Errors returned by stream operations. These correspond to the SoapySDR stream error codes, so that
+// callers can use errors.Is to test for a specific condition without depending on go-soapy-sdr.
+var (
+	ErrStreamTimeout      = errors.New("timeout error during stream operation")
+	ErrStreamError        = errors.New("non-specific stream error")
+	ErrStreamCorruption   = errors.New("data corruption during read operation")
+	ErrStreamOverflow     = errors.New("overflow during read operation")
+	ErrStreamNotSupported = errors.New("requested operation or flag setting is not supported")
+	ErrStreamTimeError    = errors.New("device encountered a stream time that expired or was too early")
+	ErrStreamUnderflow    = errors.New("write operation caused an underflow condition")
+)
+
+// ErrStreamEnd is returned by devices that play back recordings when the end of the recording has been reached.
+// There is no corresponding SoapySDR error.
+var ErrStreamEnd = errors.New("end of stream")
+
+// streamError converts an error returned by a go-soapy-sdr stream operation into one of the
+// ErrStream... errors.
+//
+// Errors that are not SoapySDR stream errors are returned unchanged.
+func streamError(err error) error {
+	var sdrErr sdrerror.SDRError
+	if !errors.As(err, &sdrErr) {
+		return err
+	}
+	switch sdrErr.(type) {
+	case *sdrerror.Timeout:
+		return ErrStreamTimeout
+	case *sdrerror.StreamError:
+		return ErrStreamError
+	case *sdrerror.Corruption:
+		return ErrStreamCorruption
+	case *sdrerror.Overflow:
+		return ErrStreamOverflow
+	case *sdrerror.NotSupported:
+		return ErrStreamNotSupported
+	case *sdrerror.TimeError:
+		return ErrStreamTimeError
+	case *sdrerror.Underflow:
+		return ErrStreamUnderflow
+	default:
+		return err
+	}
+}
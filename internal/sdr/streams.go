@@ -2,6 +2,7 @@ package sdr
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jimorc/jsdr/internal/logger"
@@ -14,35 +15,49 @@ type CS8Streams interface {
 	SetupCS8Stream(device.Direction, []uint, map[string]string) (*StreamCS8, error)
 	CloseCS8Stream(*StreamCS8) error
 	GetCS8MTU(*StreamCS8) int
-	Activate(*StreamCS8, device.StreamFlag, int, int) error
-	Deactivate(*StreamCS8, device.StreamFlag, int) error
+	ActivateCS8Stream(*StreamCS8, device.StreamFlag, int, int) error
+	DeactivateCS8Stream(*StreamCS8, device.StreamFlag, int) error
 	ReadCS8Stream(*StreamCS8, [][]int, uint, *[1]int, uint) (uint, uint, error)
+	WriteCS8Stream(*StreamCS8, [][]int, uint, device.StreamFlag, uint, uint) (uint, error)
+	ReadCS8StreamStatus(*StreamCS8, *[1]int, uint) (uint, error)
 }
 
 // StreamCS8 is the stream for CS8 data.
 type StreamCS8 struct {
+	streamState
 	stream *device.SDRStreamCS8
 	device CS8Streams
-	active bool
+	// int8Buff is used by SoapyDevice to read from and write to the go-soapy-sdr stream.
+	int8Buff [][]int8
+	// cs8Pool provides the buffers that ReadStreamAsCF64Data reads CS8 data into.
+	cs8Pool *BufferPool[int]
+	// cs8Flags holds the flags returned by reads made by ReadStreamAsCF64Data.
+	cs8Flags [1]int
 }
 
-// SetupCS8Stream initializes a stream for RX channel 0.
+// SetupCS8Stream initializes a stream for the specified direction and channels.
+//
+// Each channel in channels is a channel number of the device. Data for each of the channels
+// is returned in a separate buffer when the stream is read.
 //
 // All stream API calls should be usable with the new stream object
 // after SetupSDRStreamCU8() is complete, regardless of the activity state.
 //
 // Returns a stream pointer and an error. The returned stream may not be used
 // concurrently on multiple go routines.
-func SetupCS8Stream(sdrD CS8Streams, log *logger.Logger) (*StreamCS8, error) {
+func SetupCS8Stream(sdrD CS8Streams, log *logger.Logger, direction device.Direction, channels []uint) (*StreamCS8, error) {
+	if err := validateChannels(log, channels); err != nil {
+		return nil, err
+	}
 	// TODO: Determine what the "WIRE" value should be. The SoapySDR documentation does not
 	// give any specific values, just says 'format of the samples between device and host.
 	// I am guessing that means "CS8" here.
-	stream, err := sdrD.SetupCS8Stream(device.DirectionRX, []uint{0}, map[string]string{"WIRE": "CS8"})
+	stream, err := sdrD.SetupCS8Stream(direction, channels, map[string]string{"WIRE": "CS8"})
 	if err != nil {
 		log.Logf(logger.Error, "Could not set up stream: %s\n", err.Error())
 		return nil, err
 	}
-	log.Log(logger.Debug, "CS8 stream setup complete.\n")
+	log.Logf(logger.Debug, "CS8 stream setup complete for %s channels %v.\n", directionAsString(direction), channels)
 	return stream, err
 }
 
@@ -65,7 +80,9 @@ func (stream *StreamCS8) Close(log *logger.Logger) error {
 // Return the MTU in number of stream elements (never zero)
 func (stream *StreamCS8) GetMTU(log *logger.Logger) uint {
 	mtu := stream.device.GetCS8MTU(stream)
-	log.Logf(logger.Debug, "CS8 stream MTU is %d\n", mtu)
+	if log.Enabled(logger.Debug) {
+		log.Logf(logger.Debug, "CS8 stream MTU is %d\n", mtu)
+	}
 	return uint(mtu)
 }
 
@@ -83,14 +100,21 @@ func (stream *StreamCS8) GetMTU(log *logger.Logger) uint {
 //
 // Return an error or nil in case of success
 func (stream *StreamCS8) Activate(log *logger.Logger, flag device.StreamFlag, timeNs int, numElems int) error {
-	err := stream.device.Activate(stream, flag, timeNs, numElems)
-	if err != nil {
-		log.Logf(logger.Error, "Error attempting to activate CS8 stream: %s\n", err.Error())
-		stream.active = false
-		return err
-	}
-	stream.active = true
-	return nil
+	return activateStream(log, &stream.streamState, flag, timeNs, func() error {
+		return stream.device.ActivateCS8Stream(stream, flag, timeNs, numElems)
+	})
+}
+
+// ActivateAt activates a stream at the specified hardware time, so that the first sample that is read was received
+// at timeNs. Use ActivateAt with HardwareTime to align captures with other devices or with a PPS signal.
+//
+// Params:
+//   - timeNs: the hardware time in nanoseconds at which to start streaming.
+//   - numElems: optional element count for burst control.
+//
+// Returns an error if the device does not support timed activation, or nil in case of success.
+func (stream *StreamCS8) ActivateAt(log *logger.Logger, timeNs uint, numElems int) error {
+	return stream.Activate(log, device.StreamFlagHasTime, int(timeNs), numElems)
 }
 
 // Deactivate deactivates a stream.
@@ -104,83 +128,108 @@ func (stream *StreamCS8) Activate(log *logger.Logger, flag device.StreamFlag, ti
 //
 // Returns an error or nil in case of success
 func (stream *StreamCS8) Deactivate(log *logger.Logger, flags device.StreamFlag, timeNs int) error {
-	if !stream.active {
-		log.Log(logger.Error, "Attempting to deactivate a stream that is not active.\n")
-		return errors.New("attempting to deactivate a stream that is not active")
-	}
-	err := stream.device.Deactivate(stream, flags, timeNs)
-	if err != nil {
-		log.Logf(logger.Error, "Error encountered deactivating a stream: %s\n", err.Error())
-		return err
-	}
-	stream.active = false
-	return nil
+	return deactivateStream(log, &stream.streamState, func() error {
+		return stream.device.DeactivateCS8Stream(stream, flags, timeNs)
+	})
 }
 
 // ReadCS8FromStream reads MTU items from the stream. Since the format is CS8, 2 * MTU integers values are read.
 //
-// Data is read only from RX channel 0.
+// Data is read from each of the stream's channels.
 //
 // Params:
-//   - buff: an array of buffers that will hold the data that is read. buff must be sized to [1][2*mtu] in the
+//   - buff: an array of buffers that will hold the data that is read. buff must be sized to
 //
-// code that calls ReadCS8FromStream.
+// [number of stream channels][2*mtu] in the code that calls ReadCS8FromStream.
 //
-//   - outputFlags: The flag indicators of the result. Since data is read only from RX channel 0, this array is
+//   - outputFlags: The flag indicators of the result. The flags apply to all of the stream's channels, so this
 //
-// one element in size.
+// array is one element in size.
 //
 //   - timeoutUs: the timeout time in microseconds.
 //
 // Returns:
 //   - timeNs: the buffer's timestamp in nanoseconds.
 //   - numElemsRead: the number of elements read. This should match the stream's MTU.
-//   - err: error, or nil if the call is successful. On error, buff, numElemsRead, and timeNs may not be valid.
+//   - err: error, or nil if the call is successful. SoapySDR stream errors are returned as one of the
+//
+// ErrStream... errors, for example ErrStreamTimeout. On error, buff, numElemsRead, and timeNs may not be valid.
 func (stream *StreamCS8) ReadCS8FromStream(log *logger.Logger, buff [][]int, elementsToRead uint, outputFlags *[1]int, timeoutUs uint) (
 	timeNs uint, numElemsRead uint, err error) {
-	if !stream.active {
-		log.Log(logger.Error, "Attempting to read from an inactive stream.\n")
-		return 0, 0, errors.New("attempting to read from an inactive stream")
+	return readStream(log, &stream.streamState, buff, 2, elementsToRead, outputFlags,
+		func(readBuff [][]int, numElems uint) (uint, uint, error) {
+			return stream.device.ReadCS8Stream(stream, readBuff, numElems, outputFlags, timeoutUs)
+		})
+}
+
+// Write writes numElems elements from buff to a transmit stream. Since the format is CS8, 2 * numElems integer
+// values are written from each channel's buffer. The values must be between -128 and 127.
+//
+// Params:
+//   - buff: an array of buffers, one for each of the stream's channels, that hold the data to transmit.
+//   - numElems: the number of elements to write. Write makes as many device writes as are needed to write them all.
+//   - flags: StreamFlagHasTime to transmit the first element at timeNs, and StreamFlagEndBurst to end the burst with
+//
+// the last element.
+//
+//   - timeNs: the hardware time in nanoseconds at which to transmit. timeNs is only valid when the flags have
+//
+// StreamFlagHasTime.
+//
+//   - timeoutUs: the timeout time in microseconds for each device write.
+//
+// Returns:
+//   - numElemsWritten: the number of elements written. This is numElems unless an error occurs.
+//   - err: error, or nil if the call is successful. SoapySDR stream errors are returned as one of the
+//
+// ErrStream... errors, for example ErrStreamTimeError if timeNs has already passed.
+func (stream *StreamCS8) Write(log *logger.Logger, buff [][]int, numElems uint, flags device.StreamFlag, timeNs uint,
+	timeoutUs uint) (numElemsWritten uint, err error) {
+	return writeStream(log, &stream.streamState, buff, 2, numElems, flags, timeNs,
+		func(writeBuff [][]int, elems uint, writeFlags device.StreamFlag, writeTimeNs uint) (uint, error) {
+			return stream.device.WriteCS8Stream(stream, writeBuff, elems, writeFlags, writeTimeNs, timeoutUs)
+		})
+}
+
+// ReadStreamStatus waits up to timeoutUs microseconds for a status event on a transmit stream, such as the end of a
+// burst or an underflow.
+//
+// Returns:
+//   - timeNs: the hardware time of the event in nanoseconds. This is only valid if flags has StreamFlagHasTime.
+//   - flags: the flag indicators of the event, for example StreamFlagEndBurst when a burst has been transmitted.
+//   - err: error, or nil if the call is successful. Underflows are returned as ErrStreamUnderflow and late timed
+//
+// transmissions as ErrStreamTimeError. ErrStreamTimeout is returned if there was no event before the timeout, and
+// ErrStreamNotSupported if the device does not report stream status.
+func (stream *StreamCS8) ReadStreamStatus(log *logger.Logger, timeoutUs uint) (timeNs uint, flags int, err error) {
+	if stream.direction != device.DirectionTX {
+		log.Log(logger.Error, "Attempting to read the status of a receive stream.\n")
+		return 0, 0, errors.New("attempting to read the status of a receive stream")
 	}
-	var elemsRead uint
-	mtu := stream.GetMTU(log)
-	cs8Buff := make([][]int, 1)
-	cs8Buff[0] = make([]int, 2*mtu)
-	start := time.Now()
-	for {
-		if numElemsRead < elementsToRead {
-			timeNs, elemsRead, err = stream.device.ReadCS8Stream(stream, cs8Buff, elementsToRead, outputFlags, timeoutUs)
-			if err != nil {
-				log.Logf(logger.Error, "Error encountered while reading CS8 data: %s\n", err.Error())
-				return timeNs, numElemsRead, err
-			}
-			if outputFlags[0] != 0 {
-				log.Logf(logger.Debug, "Flags = %d\n", outputFlags[0])
-			}
-			log.Logf(logger.Debug, "Elements Read: %d\n", elemsRead)
-			// for loop used to transfer data because it is 20x to 25x as fast as append.
-			for i := uint(0); i < 2*elemsRead; i++ {
-				buff[0][i+2*numElemsRead] = cs8Buff[0][i]
-			}
-			numElemsRead += elemsRead
-		} else {
-			end := time.Now()
-			diff := end.Sub(start).Microseconds()
-			log.Logf(logger.Debug, "Time to read CS8 data: %d μs\n", diff)
-			return timeNs, numElemsRead, nil
-		}
+	var statusFlags [1]int
+	timeNs, err = stream.device.ReadCS8StreamStatus(stream, &statusFlags, timeoutUs)
+	if stream.monitor != nil {
+		stream.monitor.recordStatus(err)
 	}
+	switch {
+	case errors.Is(err, ErrStreamTimeout):
+		log.Log(logger.Debug, "No stream status before timeout.\n")
+		return 0, 0, err
+	case err != nil:
+		log.Logf(logger.Error, "Stream status: %s\n", err.Error())
+		return timeNs, statusFlags[0], err
+	}
+	log.Logf(logger.Debug, "Stream status flags = %d, time = %d ns\n", statusFlags[0], timeNs)
+	return timeNs, statusFlags[0], nil
 }
 
 // ReadStreamAsCF64Data reads MTU CS8 items from the stream. Since the stream format is CS8, 2 * MTU integers values are read.
-// These are then converted to an array of float64 values.
-//
-// Data is read only from RX channel 0.
+// These are then converted to an array of float64 values for each of the stream's channels.
 //
 // Params:
-//   - cf64: an array that will hold the data that is read. cf64 must be sized to [2*mtu] in
+//   - cf64: an array of buffers that will hold the data that is read. cf64 must be sized to
 //
-// the code that calls ReadSttreamAsCF64Data.
+// [number of stream channels][2*mtu] in the code that calls ReadSttreamAsCF64Data.
 //
 //   - elemsToRead: This should match the stream's MTU.
 //
@@ -197,25 +246,38 @@ func (stream *StreamCS8) ReadCS8FromStream(log *logger.Logger, buff [][]int, ele
 //   - err: error, or nil if the call is successful. On error, cf64, numElemsRead, and timeNs are not valid.
 //
 // The error has already been logged, so it is not necessary to do so again.
-func (stream *StreamCS8) ReadStreamAsCF64Data(log *logger.Logger, cf64 []float64, elementsToRead uint,
+//
+// Deprecated: Use NewSampleSource and SampleSource.ReadSamples, which return normalised complex64 samples for
+// any stream format.
+func (stream *StreamCS8) ReadStreamAsCF64Data(log *logger.Logger, cf64 [][]float64, elementsToRead uint,
 	outputFlags *int, timeoutUs uint) (
 	timeNs uint, numElemsRead uint, err error) {
-	mtu := stream.GetMTU(log)
-	cs8 := make([][]int, 1)
-	cs8[0] = make([]int, 2*mtu)
-	var flags [1]int
-	timeNs, numElemsRead, err = stream.ReadCS8FromStream(log, cs8, elementsToRead, &flags, timeoutUs)
+	if len(cf64) != len(stream.channels) {
+		log.Logf(logger.Error, "CF64 buffer has %d channels, but the stream has %d channels.\n",
+			len(cf64), len(stream.channels))
+		return 0, 0, fmt.Errorf("read buffer must have %d channels", len(stream.channels))
+	}
+	mtu := int(stream.GetMTU(log))
+	if stream.cs8Pool == nil || stream.cs8Pool.Size() != 2*mtu {
+		stream.cs8Pool = NewBufferPool[int](len(stream.channels), 2*mtu)
+	}
+	cs8 := stream.cs8Pool.Get()
+	timeNs, numElemsRead, err = stream.ReadCS8FromStream(log, cs8.Data, elementsToRead, &stream.cs8Flags, timeoutUs)
 	if err != nil {
+		stream.cs8Pool.Put(cs8)
 		return 0, 0, err
 	}
-	*outputFlags = flags[0]
+	*outputFlags = stream.cs8Flags[0]
 	start := time.Now()
-	size := len(cs8[0])
-	//	cf64 = make([]float64, size)
-	for i := 0; i < size; i++ {
-		cf64[i] = float64(cs8[0][i])
+	for ch := range cs8.Data {
+		size := len(cs8.Data[ch])
+		for i := 0; i < size; i++ {
+			cf64[ch][i] = float64(cs8.Data[ch][i])
+		}
+	}
+	stream.cs8Pool.Put(cs8)
+	if log.Enabled(logger.Debug) {
+		log.Logf(logger.Debug, "Time to convert CS8 data to CF128 data: %d μs\n", time.Since(start).Microseconds())
 	}
-	end := time.Now()
-	log.Logf(logger.Debug, "Time to convert CS8 data to CF128 data: %d μs\n", end.Sub(start).Microseconds())
 	return timeNs, numElemsRead, err
 }
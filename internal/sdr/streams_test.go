@@ -1,12 +1,15 @@
 package sdr_test
 
 import (
+	"errors"
+	"runtime/debug"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jimorc/jsdr/internal/logger"
 )
@@ -14,8 +17,8 @@ import (
 func TestSetupCS8Stream(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	assert.NotNil(t, stream)
 }
@@ -23,8 +26,8 @@ func TestSetupCS8Stream(t *testing.T) {
 func TestSetupCS8Stream_Error(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "1"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{Errors: map[string]error{"SetupCS8Stream": errors.New("bad args passed to SetupCS8Stream")}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.NotNil(t, err)
 	assert.Equal(t, "bad args passed to SetupCS8Stream", err.Error())
 	assert.Nil(t, stream)
@@ -33,8 +36,8 @@ func TestSetupCS8Stream_Error(t *testing.T) {
 func TestCS8StreamClose(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	assert.NotNil(t, stream)
 	err = stream.Close(testLogger)
@@ -44,8 +47,8 @@ func TestCS8StreamClose(t *testing.T) {
 func TestGetMTU(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	assert.NotNil(t, stream)
 	defer stream.Close(testLogger)
@@ -56,8 +59,8 @@ func TestGetMTU(t *testing.T) {
 func TestActivateCS8Stream(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	defer stream.Close(testLogger)
 	err = stream.Activate(testLogger, 0, 0, 0)
@@ -65,11 +68,49 @@ func TestActivateCS8Stream(t *testing.T) {
 	defer stream.Deactivate(testLogger, 0, 0)
 }
 
+func TestActivateCS8Stream_Timed(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.SetHardwareTime(&stub, testLogger, 1000000000, sdr.TimeNextPPS))
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.ActivateAt(testLogger, 2000000000, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]int{make([]int, 200)}
+	var outputFlags [1]int
+	timeNs, numElemsRead, err := stream.ReadCS8FromStream(testLogger, buffer, 100, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(100), numElemsRead)
+	assert.Equal(t, uint(2000000000), timeNs)
+	assert.NotZero(t, outputFlags[0]&int(device.StreamFlagHasTime))
+}
+
+func TestActivateCS8Stream_TimePassed(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	require.Nil(t, sdr.SetHardwareTime(&stub, testLogger, 5000000000, sdr.TimeNow))
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	err = stream.ActivateAt(testLogger, 1000000000, 0)
+	require.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "activation time 1000000000 ns has passed."))
+	assert.False(t, stream.IsActive())
+
+	err = stream.Activate(testLogger, device.StreamFlagHasTime, -1, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "cannot activate a stream at a negative time: -1 ns", err.Error())
+	assert.False(t, stream.IsActive())
+}
+
 func TestDectivateCS8Stream(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "3"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	defer stream.Close(testLogger)
 	err = stream.Activate(testLogger, 0, 0, 0)
@@ -81,8 +122,8 @@ func TestDectivateCS8Stream(t *testing.T) {
 func TestDeactivateCS8Stream_NotActive(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	defer stream.Close(testLogger)
 	err = stream.Deactivate(testLogger, 0, 0)
@@ -93,8 +134,8 @@ func TestDeactivateCS8Stream_NotActive(t *testing.T) {
 func TestDeactivateCS8Stream_Error(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "2"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{Errors: map[string]error{"DeactivateCS8Stream": errors.New("bad device")}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	defer stream.Close(testLogger)
 	err = stream.Activate(testLogger, 0, 0, 0)
@@ -108,27 +149,28 @@ func TestReadCS8Stream(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
 	testLogger.SetMaxLevel(logger.Debug)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "3"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
-	defer stream.Close(testLogger)
 	err = stream.Activate(testLogger, 0, 0, 0)
 	assert.Nil(t, err)
-	defer stream.Deactivate(testLogger, 0, 0)
 	mtu := stream.GetMTU(testLogger)
 	buffer := make([][]int, 1)
 	buffer[0] = make([]int, 2*mtu)
 	var outputFlags [1]int
 	outputFlags[0] = 0
 	timeNs, numElemsRead, _ := stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
-	assert.True(t, timeNs > 0)
+	// The hardware time is 0 until it is set, so the first element that is read has a timestamp of 0.
+	assert.Equal(t, uint(0), timeNs)
 	assert.Equal(t, mtu, numElemsRead)
 	assert.Equal(t, -2, buffer[0][0])
 	assert.Equal(t, 0, buffer[0][1])
 	assert.Equal(t, -1, buffer[0][19998])
 	assert.Equal(t, -2, buffer[0][19999])
-	// Wait for log to update.
-	time.Sleep(100 * time.Millisecond)
+	stream.Deactivate(testLogger, 0, 0)
+	stream.Close(testLogger)
+	// Closing the logger waits for the messages to be written.
+	testLogger.Close()
 	assert.True(t, strings.Contains(log.String(), "Flags ="))
 	assert.True(t, strings.Contains(log.String(), "Elements Read: 10000"))
 }
@@ -137,26 +179,26 @@ func TestReadCS8Stream_PartialReads(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
 	testLogger.SetMaxLevel(logger.Debug)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "4"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{{NumElems: 5000}, {NumElems: 3000}, {NumElems: 2000}}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
-	defer stream.Close(testLogger)
 	err = stream.Activate(testLogger, 0, 0, 0)
 	assert.Nil(t, err)
-	defer stream.Deactivate(testLogger, 0, 0)
 	mtu := stream.GetMTU(testLogger)
 	buffer := make([][]int, 1)
 	buffer[0] = make([]int, 2*mtu)
 	var outputFlags [1]int
 	timeNs, numElemsRead, _ := stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
-	assert.True(t, timeNs > 0)
+	assert.Equal(t, uint(0), timeNs)
 	assert.Equal(t, mtu, numElemsRead)
 	assert.Equal(t, -2, buffer[0][0])
 	assert.Equal(t, 0, buffer[0][1])
 	assert.Equal(t, -1, buffer[0][19998])
 	assert.Equal(t, -2, buffer[0][19999])
-	// Wait for log to update
-	time.Sleep(100 * time.Millisecond)
+	stream.Deactivate(testLogger, 0, 0)
+	stream.Close(testLogger)
+	// Closing the logger waits for the messages to be written.
+	testLogger.Close()
 	assert.True(t, strings.Contains(log.String(), "Elements Read: 5000"))
 	assert.True(t, strings.Contains(log.String(), "Elements Read: 3000"))
 	assert.True(t, strings.Contains(log.String(), "Elements Read: 2000"))
@@ -167,10 +209,9 @@ func TestReadCS8tream_NotActivated(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
 	testLogger.SetMaxLevel(logger.Debug)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "3"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
-	defer stream.Close(testLogger)
 	mtu := stream.GetMTU(testLogger)
 	buffer := make([][]int, 1)
 	buffer[0] = make([]int, 2*mtu)
@@ -182,9 +223,9 @@ func TestReadCS8tream_NotActivated(t *testing.T) {
 	// The following tests are provided simply because this is what the test stream sets them to.
 	assert.Equal(t, uint(0), timeNs)
 	assert.Equal(t, uint(0), numElemsRead)
-	// Wait for log to update
-	time.Sleep(100 * time.Millisecond)
-
+	stream.Close(testLogger)
+	// Closing the logger waits for the messages to be written.
+	testLogger.Close()
 	assert.True(t, strings.Contains(log.String(), "Attempting to read from an inactive stream"))
 }
 
@@ -192,8 +233,8 @@ func TestReadStreamAsCF64Data(t *testing.T) {
 	var log strings.Builder
 	testLogger := logger.New(&log)
 	testLogger.SetMaxLevel(logger.Debug)
-	stub := sdr.StubDevice{Args: map[string]string{"serial": "3"}}
-	stream, err := sdr.SetupCS8Stream(&stub, testLogger)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
 	assert.Nil(t, err)
 	defer stream.Close(testLogger)
 	err = stream.Activate(testLogger, 0, 0, 0)
@@ -201,14 +242,312 @@ func TestReadStreamAsCF64Data(t *testing.T) {
 	defer stream.Deactivate(testLogger, 0, 0)
 	mtu := stream.GetMTU(testLogger)
 	var outputFlags int
-	cf64 := make([]float64, 2*mtu)
+	cf64 := make([][]float64, 1)
+	cf64[0] = make([]float64, 2*mtu)
 	timeNs, numElemsRead, err := stream.ReadStreamAsCF64Data(testLogger, cf64, mtu,
 		&outputFlags, 0)
 	assert.Nil(t, err)
 	assert.Equal(t, mtu, numElemsRead)
-	assert.True(t, timeNs > 0)
-	assert.Equal(t, -2.0, cf64[0])
-	assert.Equal(t, 0.0, cf64[1])
-	assert.Equal(t, -1.0, cf64[2*mtu-2])
-	assert.Equal(t, -2.0, cf64[2*mtu-1])
+	assert.Equal(t, uint(0), timeNs)
+	assert.Equal(t, -2.0, cf64[0][0])
+	assert.Equal(t, 0.0, cf64[0][1])
+	assert.Equal(t, -1.0, cf64[0][2*mtu-2])
+	assert.Equal(t, -2.0, cf64[0][2*mtu-1])
+}
+
+func TestSetupCS8Stream_NoChannels(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{})
+	assert.NotNil(t, err)
+	assert.Equal(t, "cannot set up a stream without any channels", err.Error())
+	assert.Nil(t, stream)
+}
+
+func TestReadCS8Stream_TwoChannels(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0, 1})
+	assert.Nil(t, err)
+	defer stream.Close(testLogger)
+	assert.Equal(t, device.DirectionRX, stream.Direction())
+	assert.Equal(t, []uint{0, 1}, stream.Channels())
+	err = stream.Activate(testLogger, 0, 0, 0)
+	assert.Nil(t, err)
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := make([][]int, 2)
+	buffer[0] = make([]int, 2*mtu)
+	buffer[1] = make([]int, 2*mtu)
+	var outputFlags [1]int
+	_, numElemsRead, err := stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, mtu, numElemsRead)
+	assert.Equal(t, -2, buffer[0][0])
+	assert.Equal(t, -1, buffer[0][19998])
+	assert.Equal(t, -4, buffer[1][0])
+	assert.Equal(t, -2, buffer[1][19998])
+}
+
+func TestReadCS8Stream_WrongNumberOfBuffers(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0, 1})
+	assert.Nil(t, err)
+	defer stream.Close(testLogger)
+	err = stream.Activate(testLogger, 0, 0, 0)
+	assert.Nil(t, err)
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := make([][]int, 1)
+	buffer[0] = make([]int, 2*mtu)
+	var outputFlags [1]int
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, "read buffer must have 2 channels", err.Error())
+}
+
+func TestReadCS8Stream_Timeout(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Errors: map[string]error{"ReadCS8Stream": sdr.ErrStreamTimeout}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	assert.Nil(t, err)
+	defer stream.Close(testLogger)
+	err = stream.Activate(testLogger, 0, 0, 0)
+	assert.Nil(t, err)
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := make([][]int, 1)
+	buffer[0] = make([]int, 2*mtu)
+	var outputFlags [1]int
+	_, numElemsRead, err := stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 1000)
+	assert.True(t, errors.Is(err, sdr.ErrStreamTimeout))
+	assert.Equal(t, uint(0), numElemsRead)
+}
+
+func TestReadCS8Stream_Overflow(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{{Err: sdr.ErrStreamOverflow, Flags: device.StreamFlagEndAbrupt}}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	assert.Nil(t, err)
+	defer stream.Close(testLogger)
+	err = stream.Activate(testLogger, 0, 0, 0)
+	assert.Nil(t, err)
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := make([][]int, 1)
+	buffer[0] = make([]int, 2*mtu)
+	var outputFlags [1]int
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 1000)
+	assert.True(t, errors.Is(err, sdr.ErrStreamOverflow))
+	assert.Equal(t, "overflow during read operation", err.Error())
+	assert.Equal(t, int(device.StreamFlagEndAbrupt), outputFlags[0])
+}
+
+func TestReadCS8FromStream_NoAllocations(t *testing.T) {
+	skipAllocationTestWithRace(t)
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0, 1})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := [][]int{make([]int, 2*mtu), make([]int, 2*mtu)}
+	var outputFlags [1]int
+	allocs := testing.AllocsPerRun(100, func() {
+		stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
+	})
+	assert.Equal(t, 0., allocs)
+}
+
+func TestReadStreamAsCF64Data_NoAllocations(t *testing.T) {
+	skipAllocationTestWithRace(t)
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	cf64 := [][]float64{make([]float64, 2*mtu)}
+	var outputFlags int
+	// The first read creates the stream's buffer pool.
+	_, _, err = stream.ReadStreamAsCF64Data(testLogger, cf64, mtu, &outputFlags, 0)
+	require.Nil(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		stream.ReadStreamAsCF64Data(testLogger, cf64, mtu, &outputFlags, 0)
+	})
+	assert.Equal(t, 0., allocs)
+}
+
+func TestWriteCS8Stream(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionTX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	_, _, err = stream.ReadStreamStatus(testLogger, 0)
+	assert.True(t, errors.Is(err, sdr.ErrStreamTimeout))
+
+	// StubDevice accepts at most 4000 elements per write, so this burst takes three writes.
+	buffer := [][]int{make([]int, 20000)}
+	numElemsWritten, err := stream.Write(testLogger, buffer, 10000,
+		device.StreamFlagHasTime|device.StreamFlagEndBurst, 1000000000, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(10000), numElemsWritten)
+	timeNs, flags, err := stream.ReadStreamStatus(testLogger, 0)
+	require.Nil(t, err)
+	assert.Equal(t, int(device.StreamFlagHasTime|device.StreamFlagEndBurst), flags)
+	// The burst ends 10000 elements at 2 MS/s after it starts.
+	assert.Equal(t, uint(1005000000), timeNs)
+	_, _, err = stream.ReadStreamStatus(testLogger, 0)
+	assert.True(t, errors.Is(err, sdr.ErrStreamTimeout))
+}
+
+func TestWriteCS8Stream_Errors(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionTX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	buffer := [][]int{make([]int, 200)}
+	_, err = stream.Write(testLogger, buffer, 100, 0, 0, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "attempting to write to an inactive stream", err.Error())
+
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	_, err = stream.Write(testLogger, buffer, 101, 0, 0, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "write buffer does not hold 101 elements", err.Error())
+	_, err = stream.Write(testLogger, [][]int{buffer[0], buffer[0]}, 100, 0, 0, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "write buffer must have 1 channels", err.Error())
+	var outputFlags [1]int
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 100, &outputFlags, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "attempting to read from a transmit stream", err.Error())
+
+	require.Nil(t, sdr.SetHardwareTime(&stub, testLogger, 2000000000, sdr.TimeNow))
+	numElemsWritten, err := stream.Write(testLogger, buffer, 100, device.StreamFlagHasTime, 1000000000, 0)
+	assert.True(t, errors.Is(err, sdr.ErrStreamTimeError))
+	assert.Equal(t, uint(0), numElemsWritten)
+}
+
+func TestWriteCS8Stream_ReceiveStream(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	_, err = stream.Write(testLogger, [][]int{make([]int, 200)}, 100, 0, 0, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "attempting to write to a receive stream", err.Error())
+	_, _, err = stream.ReadStreamStatus(testLogger, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "attempting to read the status of a receive stream", err.Error())
+}
+
+func TestReadStreamStatus_Underflow(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Errors: map[string]error{"ReadCS8StreamStatus": sdr.ErrStreamUnderflow}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionTX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	_, _, err = stream.ReadStreamStatus(testLogger, 0)
+	assert.True(t, errors.Is(err, sdr.ErrStreamUnderflow))
+	testLogger.Close()
+	assert.Contains(t, log.String(), "Stream status: write operation caused an underflow condition\n")
+}
+
+func BenchmarkReadCS8FromStream(b *testing.B) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(b, err)
+	defer stream.Close(testLogger)
+	require.Nil(b, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	buffer := [][]int{make([]int, 2*mtu)}
+	var outputFlags [1]int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stream.ReadCS8FromStream(testLogger, buffer, mtu, &outputFlags, 0)
+	}
+}
+
+func BenchmarkReadStreamAsCF64Data(b *testing.B) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(b, err)
+	defer stream.Close(testLogger)
+	require.Nil(b, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	mtu := stream.GetMTU(testLogger)
+	cf64 := [][]float64{make([]float64, 2*mtu)}
+	var outputFlags int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stream.ReadStreamAsCF64Data(testLogger, cf64, mtu, &outputFlags, 0)
+	}
+}
+
+func TestSoapyDevice_WriteCS8Stream_BufferTooSmall(t *testing.T) {
+	soapy := &sdr.SoapyDevice{}
+	stream := sdr.NewUnallocatedSoapyStreamCS8(soapy, device.DirectionTX, []uint{0})
+	_, err := soapy.WriteCS8Stream(stream, [][]int{make([]int, 1)}, 10, 0, 0, 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "write buffer is too small to hold any elements", err.Error())
+
+	// numElems is reduced to the size of the buffer, rather than reading beyond its end. The write then fails because
+	// SoapySDR has not set up the stream.
+	assert.NotPanics(t, func() {
+		_, err = soapy.WriteCS8Stream(stream, [][]int{make([]int, 20)}, 100, 0, 0, 0)
+	})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "write buffer array has not been allocated")
+}
+
+// TestSoapyWriteBufferLayout fails if go-soapy-sdr is updated, because setSoapyWriteBuffers depends on the layout of
+// device.SDRStreamCS8 in v0.7.4. Check whether go-soapy-sdr still passes its unfilled writeBuffer array to
+// SoapySDRDevice_writeStream, then update setSoapyWriteBuffers and this test.
+func TestSoapyWriteBufferLayout(t *testing.T) {
+	require.Nil(t, sdr.CheckSoapyWriteBufferLayout(),
+		"the layout of go-soapy-sdr's SDRStreamCS8 has changed; see setSoapyWriteBuffers")
+	info, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/pothosware/go-soapy-sdr" {
+			assert.Equal(t, "v0.7.4", dep.Version,
+				"setSoapyWriteBuffers works around a bug in go-soapy-sdr v0.7.4; check it against %s", dep.Version)
+			return
+		}
+	}
+	t.Fatal("go-soapy-sdr is not a dependency of the tests")
 }
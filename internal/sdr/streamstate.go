@@ -0,0 +1,257 @@
+package sdr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// streamState holds the state that is common to all of the stream types.
+type streamState struct {
+	format    string
+	direction device.Direction
+	channels  []uint
+	active    bool
+	// readFlags is used by SoapyDevice to pass flags to and receive flags from go-soapy-sdr stream operations.
+	readFlags []int
+	monitor   *StreamMonitor
+}
+
+// newStreamState creates the state for a stream of the specified format, direction, and channels.
+func newStreamState(format string, direction device.Direction, channels []uint) streamState {
+	return streamState{format: format, direction: direction, channels: channels}
+}
+
+// Format returns the stream format, for example "CS8".
+func (state *streamState) Format() string {
+	return state.format
+}
+
+// Direction returns the direction of the stream.
+func (state *streamState) Direction() device.Direction {
+	return state.direction
+}
+
+// Channels returns the device channel numbers that the stream was set up for.
+func (state *streamState) Channels() []uint {
+	return state.channels
+}
+
+// IsActive returns whether the stream is currently active.
+func (state *streamState) IsActive() bool {
+	return state.active
+}
+
+// SetMonitor sets the monitor that accumulates the statistics of the stream's reads, or removes it if monitor is nil.
+// SetMonitor must not be called while the stream is being read.
+func (state *streamState) SetMonitor(monitor *StreamMonitor) {
+	state.monitor = monitor
+}
+
+// Monitor returns the stream's monitor, or nil if it does not have one.
+func (state *streamState) Monitor() *StreamMonitor {
+	return state.monitor
+}
+
+// soapyFlags returns the zeroed flags slice that go-soapy-sdr stream operations require. go-soapy-sdr requires one
+// flag per channel, but only the first flag is ever set.
+func (state *streamState) soapyFlags() []int {
+	if len(state.readFlags) != len(state.channels) {
+		state.readFlags = make([]int, len(state.channels))
+	}
+	for i := range state.readFlags {
+		state.readFlags[i] = 0
+	}
+	return state.readFlags
+}
+
+// validateChannels returns an error if no channels are specified for a stream that is being set up.
+func validateChannels(log *logger.Logger, channels []uint) error {
+	if len(channels) == 0 {
+		log.Log(logger.Error, "Could not set up stream: no channels specified.\n")
+		return errors.New("cannot set up a stream without any channels")
+	}
+	return nil
+}
+
+// activateStream calls activate and marks the stream as active on success.
+//
+// Returns an error without calling activate if flag contains StreamFlagHasTime and timeNs is negative.
+func activateStream(log *logger.Logger, state *streamState, flag device.StreamFlag, timeNs int, activate func() error) error {
+	if flag&device.StreamFlagHasTime != 0 {
+		if timeNs < 0 {
+			log.Logf(logger.Error, "Cannot activate %s stream at a negative time: %d ns\n", state.format, timeNs)
+			return fmt.Errorf("cannot activate a stream at a negative time: %d ns", timeNs)
+		}
+		log.Logf(logger.Debug, "Activating %s stream at %d ns\n", state.format, timeNs)
+	}
+	err := activate()
+	if err != nil {
+		log.Logf(logger.Error, "Error attempting to activate %s stream: %s\n", state.format, err.Error())
+		state.active = false
+		return err
+	}
+	state.active = true
+	return nil
+}
+
+// deactivateStream calls deactivate if the stream is active, and marks the stream as inactive on success.
+func deactivateStream(log *logger.Logger, state *streamState, deactivate func() error) error {
+	if !state.active {
+		log.Log(logger.Error, "Attempting to deactivate a stream that is not active.\n")
+		return errors.New("attempting to deactivate a stream that is not active")
+	}
+	err := deactivate()
+	if err != nil {
+		log.Logf(logger.Error, "Error encountered deactivating a stream: %s\n", err.Error())
+		return err
+	}
+	state.active = false
+	return nil
+}
+
+// readStream calls read until elementsToRead elements have been read into buff, a read returns the
+// StreamFlagEndBurst flag, or an error occurs.
+//
+// Each call to read is passed the portion of each channel's buffer that has not yet been filled.
+// valuesPerElem is the number of buff values that make up one element, for example 2 for CS8 data.
+//
+// Returns the timestamp of the first read, the number of elements read, and an error or nil.
+func readStream[T any](log *logger.Logger, state *streamState, buff [][]T, valuesPerElem uint, elementsToRead uint,
+	outputFlags *[1]int, read func([][]T, uint) (uint, uint, error)) (timeNs uint, numElemsRead uint, err error) {
+	if !state.active {
+		log.Log(logger.Error, "Attempting to read from an inactive stream.\n")
+		return 0, 0, errors.New("attempting to read from an inactive stream")
+	}
+	if state.direction != device.DirectionRX {
+		log.Log(logger.Error, "Attempting to read from a transmit stream.\n")
+		return 0, 0, errors.New("attempting to read from a transmit stream")
+	}
+	if len(buff) != len(state.channels) {
+		log.Logf(logger.Error, "Read buffer has %d channels, but the stream has %d channels.\n",
+			len(buff), len(state.channels))
+		return 0, 0, fmt.Errorf("read buffer must have %d channels", len(state.channels))
+	}
+	for _, chBuff := range buff {
+		if uint(len(chBuff)) < valuesPerElem*elementsToRead {
+			log.Logf(logger.Error, "Read buffer is too small to hold %d elements.\n", elementsToRead)
+			return 0, 0, fmt.Errorf("read buffer is too small to hold %d elements", elementsToRead)
+		}
+	}
+	// buff is passed directly to the first read so that reads that return all of the requested elements
+	// do not allocate memory.
+	readBuff := buff
+	var partialBuff [][]T
+	start := time.Now()
+	for numElemsRead < elementsToRead {
+		if numElemsRead > 0 {
+			if partialBuff == nil {
+				partialBuff = make([][]T, len(buff))
+			}
+			for ch := range buff {
+				partialBuff[ch] = buff[ch][valuesPerElem*numElemsRead:]
+			}
+			readBuff = partialBuff
+		}
+		readStart := time.Now()
+		readTimeNs, elemsRead, err := read(readBuff, elementsToRead-numElemsRead)
+		if state.monitor != nil {
+			state.monitor.recordRead(time.Since(readStart), elemsRead, outputFlags[0], readTimeNs, err)
+		}
+		if err != nil {
+			log.Logf(logger.Error, "Error encountered while reading %s data: %s\n", state.format, err.Error())
+			return timeNs, numElemsRead, err
+		}
+		if numElemsRead == 0 {
+			timeNs = readTimeNs
+		}
+		if log.Enabled(logger.Debug) {
+			if outputFlags[0] != 0 {
+				log.Logf(logger.Debug, "Flags = %d\n", outputFlags[0])
+			}
+			log.Logf(logger.Debug, "Elements Read: %d\n", elemsRead)
+		}
+		numElemsRead += elemsRead
+		// A finite burst ends with the read that returns the StreamFlagEndBurst flag, so there are no more
+		// elements to wait for.
+		if outputFlags[0]&int(device.StreamFlagEndBurst) != 0 {
+			break
+		}
+	}
+	if log.Enabled(logger.Debug) {
+		log.Logf(logger.Debug, "Time to read %s data: %d μs\n", state.format, time.Since(start).Microseconds())
+	}
+	return timeNs, numElemsRead, nil
+}
+
+// writeStream calls write until numElems elements from buff have been written, or an error occurs.
+//
+// Each call to write is passed the portion of each channel's buffer that has not yet been written.
+// valuesPerElem is the number of buff values that make up one element, for example 2 for CS8 data.
+// StreamFlagHasTime and timeNs are only passed to the first write, because the elements of later writes follow
+// on from those that have already been written. StreamFlagEndBurst is passed to every write, so that the burst
+// ends with the last element in buff.
+//
+// Returns the number of elements written, and an error or nil.
+func writeStream[T any](log *logger.Logger, state *streamState, buff [][]T, valuesPerElem uint, numElems uint,
+	flags device.StreamFlag, timeNs uint, write func([][]T, uint, device.StreamFlag, uint) (uint, error)) (
+	numElemsWritten uint, err error) {
+	if !state.active {
+		log.Log(logger.Error, "Attempting to write to an inactive stream.\n")
+		return 0, errors.New("attempting to write to an inactive stream")
+	}
+	if state.direction != device.DirectionTX {
+		log.Log(logger.Error, "Attempting to write to a receive stream.\n")
+		return 0, errors.New("attempting to write to a receive stream")
+	}
+	if len(buff) != len(state.channels) {
+		log.Logf(logger.Error, "Write buffer has %d channels, but the stream has %d channels.\n",
+			len(buff), len(state.channels))
+		return 0, fmt.Errorf("write buffer must have %d channels", len(state.channels))
+	}
+	for _, chBuff := range buff {
+		if uint(len(chBuff)) < valuesPerElem*numElems {
+			log.Logf(logger.Error, "Write buffer does not hold %d elements.\n", numElems)
+			return 0, fmt.Errorf("write buffer does not hold %d elements", numElems)
+		}
+	}
+	if flags&device.StreamFlagHasTime != 0 {
+		log.Logf(logger.Debug, "Writing %s burst at %d ns\n", state.format, timeNs)
+	}
+	writeBuff := buff
+	var partialBuff [][]T
+	start := time.Now()
+	for numElemsWritten < numElems {
+		if numElemsWritten > 0 {
+			if partialBuff == nil {
+				partialBuff = make([][]T, len(buff))
+			}
+			for ch := range buff {
+				partialBuff[ch] = buff[ch][valuesPerElem*numElemsWritten:]
+			}
+			writeBuff = partialBuff
+			flags &^= device.StreamFlagHasTime
+		}
+		elemsWritten, err := write(writeBuff, numElems-numElemsWritten, flags, timeNs)
+		if err != nil {
+			log.Logf(logger.Error, "Error encountered while writing %s data: %s\n", state.format, err.Error())
+			return numElemsWritten, err
+		}
+		if elemsWritten == 0 {
+			log.Logf(logger.Error, "No %s data was written.\n", state.format)
+			return numElemsWritten, ErrStreamTimeout
+		}
+		if log.Enabled(logger.Debug) {
+			log.Logf(logger.Debug, "Elements Written: %d\n", elemsWritten)
+		}
+		numElemsWritten += elemsWritten
+	}
+	if log.Enabled(logger.Debug) {
+		log.Logf(logger.Debug, "Time to write %s data: %d μs\n", state.format, time.Since(start).Microseconds())
+	}
+	return numElemsWritten, nil
+}
@@ -0,0 +1,233 @@
+package sdr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// ReadLatencyBuckets holds the upper bounds of the buckets of the StreamStats read latency histogram. Reads that take
+// longer than the last bound are counted in an extra, final bucket.
+var ReadLatencyBuckets = [...]time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// healthySampleRateRatio is the lowest ratio of the effective sample rate to the configured sample rate of a
+// healthy stream.
+const healthySampleRateRatio = 0.95
+
+// StreamStats is a snapshot of the statistics that a StreamMonitor has accumulated for a stream.
+type StreamStats struct {
+	// ConfiguredSampleRate is the sample rate that the device was set to, or 0.0 if it is not known.
+	ConfiguredSampleRate float64
+	// EffectiveSampleRate is the rate at which samples have been read since the first read.
+	EffectiveSampleRate float64
+	// Elapsed is the time since the first read.
+	Elapsed time.Duration
+	// Reads is the number of device reads, including those that returned an error.
+	Reads   uint64
+	Samples uint64
+	// Overflows counts the reads that returned ErrStreamOverflow or the StreamFlagEndAbrupt flag.
+	Overflows uint64
+	Timeouts  uint64
+	// Underflows counts the transmit stream status events that reported an underflow.
+	Underflows uint64
+	// Discontinuities counts the reads whose timestamp is later than expected from the previous read.
+	Discontinuities uint64
+	// ReadLatency is a histogram of the time taken by each device read. ReadLatency[i] counts the reads that took
+	// up to ReadLatencyBuckets[i], and the last element counts those that took longer.
+	ReadLatency    [len(ReadLatencyBuckets) + 1]uint64
+	MaxReadLatency time.Duration
+	// BufferFill is the fraction, between 0.0 and 1.0, of the buffer between the stream and its consumer that was in
+	// use when it was last measured.
+	BufferFill float64
+}
+
+// Healthy returns false if samples have been lost, or the effective sample rate is well below the configured sample
+// rate. Either is a sign that the device cannot deliver samples as fast as the host is asking for them, for example
+// because there is not enough USB bandwidth.
+func (stats StreamStats) Healthy() bool {
+	if stats.Overflows > 0 || stats.Underflows > 0 || stats.Discontinuities > 0 {
+		return false
+	}
+	if stats.ConfiguredSampleRate > 0 && stats.Samples > 0 {
+		return stats.EffectiveSampleRate >= healthySampleRateRatio*stats.ConfiguredSampleRate
+	}
+	return true
+}
+
+// String returns a one line summary of the statistics that is suitable for a status bar.
+func (stats StreamStats) String() string {
+	rate := fmt.Sprintf("%.3f MS/s", stats.EffectiveSampleRate/1e6)
+	if stats.ConfiguredSampleRate > 0 {
+		rate = fmt.Sprintf("%.3f of %.3f MS/s", stats.EffectiveSampleRate/1e6, stats.ConfiguredSampleRate/1e6)
+	}
+	return fmt.Sprintf("%s, overflows: %d, timeouts: %d, underflows: %d, gaps: %d, max read: %v, buffer: %.0f%%",
+		rate, stats.Overflows, stats.Timeouts, stats.Underflows, stats.Discontinuities, stats.MaxReadLatency,
+		100*stats.BufferFill)
+}
+
+// StreamMonitor accumulates the statistics of a stream as it is read, so that the health of the stream can be
+// checked at any time.
+//
+// Attach a monitor to a stream or SampleSource with SetMonitor; a Receiver attaches one to its source. A
+// StreamMonitor may be used concurrently on multiple go routines.
+type StreamMonitor struct {
+	mutex sync.Mutex
+	stats StreamStats
+	start time.Time
+	// lastTimeNs and lastNumElems are the timestamp and size of the previous timed read, and are valid only if
+	// haveLastTime is true.
+	lastTimeNs   uint
+	lastNumElems uint
+	haveLastTime bool
+}
+
+// NewStreamMonitor creates a StreamMonitor for a stream whose device is set to sampleRate samples per second. Pass
+// 0.0 if the sample rate is not known; discontinuities are then not detected.
+func NewStreamMonitor(sampleRate float64) *StreamMonitor {
+	return &StreamMonitor{stats: StreamStats{ConfiguredSampleRate: sampleRate}}
+}
+
+// Stats returns a copy of the current statistics.
+func (monitor *StreamMonitor) Stats() StreamStats {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	stats := monitor.stats
+	if !monitor.start.IsZero() {
+		stats.Elapsed = time.Since(monitor.start)
+		if stats.Elapsed > 0 {
+			stats.EffectiveSampleRate = float64(stats.Samples) / stats.Elapsed.Seconds()
+		}
+	}
+	return stats
+}
+
+// SetSampleRate sets the configured sample rate, for example after the device's sample rate has been changed, and
+// resets the statistics.
+func (monitor *StreamMonitor) SetSampleRate(sampleRate float64) {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	monitor.reset(sampleRate)
+}
+
+// Reset clears the statistics, other than the configured sample rate.
+func (monitor *StreamMonitor) Reset() {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	monitor.reset(monitor.stats.ConfiguredSampleRate)
+}
+
+// Publish calls publish with the current statistics every interval on a new go routine until ctx is cancelled.
+//
+// Returns a channel that is closed when publishing has stopped.
+func (monitor *StreamMonitor) Publish(ctx context.Context, interval time.Duration,
+	publish func(StreamStats)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				publish(monitor.Stats())
+			}
+		}
+	}()
+	return done
+}
+
+// reset clears the statistics and sets the configured sample rate. The monitor's mutex must be held.
+func (monitor *StreamMonitor) reset(sampleRate float64) {
+	monitor.stats = StreamStats{ConfiguredSampleRate: sampleRate}
+	monitor.start = time.Time{}
+	monitor.haveLastTime = false
+}
+
+// recordRead records a device read that took latency and returned numElems elements, flags, and err. timeNs is the
+// timestamp of the first element, and is valid only if flags has StreamFlagHasTime.
+func (monitor *StreamMonitor) recordRead(latency time.Duration, numElems uint, flags int, timeNs uint, err error) {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	if monitor.start.IsZero() {
+		monitor.start = time.Now().Add(-latency)
+	}
+	stats := &monitor.stats
+	stats.Reads++
+	stats.ReadLatency[latencyBucket(latency)]++
+	stats.MaxReadLatency = max(stats.MaxReadLatency, latency)
+	switch {
+	case errors.Is(err, ErrStreamTimeout):
+		stats.Timeouts++
+		return
+	case errors.Is(err, ErrStreamOverflow):
+		stats.Overflows++
+		monitor.haveLastTime = false
+		return
+	case err != nil:
+		monitor.haveLastTime = false
+		return
+	}
+	stats.Samples += uint64(numElems)
+	if flags&int(device.StreamFlagEndAbrupt) != 0 {
+		stats.Overflows++
+	}
+	hasTime := flags&int(device.StreamFlagHasTime) != 0
+	if hasTime && monitor.haveLastTime &&
+		timestampGap(stats.ConfiguredSampleRate, monitor.lastTimeNs, monitor.lastNumElems, timeNs) {
+		stats.Discontinuities++
+	}
+	monitor.haveLastTime = hasTime
+	monitor.lastTimeNs, monitor.lastNumElems = timeNs, numElems
+}
+
+// recordStatus records a stream status event that returned err.
+func (monitor *StreamMonitor) recordStatus(err error) {
+	if errors.Is(err, ErrStreamUnderflow) {
+		monitor.mutex.Lock()
+		monitor.stats.Underflows++
+		monitor.mutex.Unlock()
+	}
+}
+
+// recordBufferFill records that used of the capacity elements of the buffer between the stream and its consumer are
+// in use.
+func (monitor *StreamMonitor) recordBufferFill(used int, capacity int) {
+	if capacity <= 0 {
+		return
+	}
+	monitor.mutex.Lock()
+	monitor.stats.BufferFill = float64(used) / float64(capacity)
+	monitor.mutex.Unlock()
+}
+
+// latencyBucket returns the index of the ReadLatency bucket that counts reads that take latency.
+func latencyBucket(latency time.Duration) int {
+	for i, bound := range ReadLatencyBuckets {
+		if latency <= bound {
+			return i
+		}
+	}
+	return len(ReadLatencyBuckets)
+}
+
+// timestampGap returns true if timeNs is later than expected given the previous timestamp and number of samples at
+// sampleRate. A gap of less than one sample period is ignored, as is every gap if sampleRate is not positive.
+func timestampGap(sampleRate float64, lastTimeNs uint, lastNumSamples uint, timeNs uint) bool {
+	if sampleRate <= 0 {
+		return false
+	}
+	samplePeriodNs := 1e9 / sampleRate
+	expectedNs := float64(lastTimeNs) + float64(lastNumSamples)*samplePeriodNs
+	return float64(timeNs)-expectedNs > samplePeriodNs
+}
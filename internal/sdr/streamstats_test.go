@@ -0,0 +1,187 @@
+package sdr_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamMonitor_Reads(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{
+		{NumElems: 100},
+		{GapNs: 1000000},
+		{Err: sdr.ErrStreamOverflow, Flags: device.StreamFlagEndAbrupt},
+		{Err: sdr.ErrStreamTimeout},
+	}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	monitor := sdr.NewStreamMonitor(2e6)
+	stream.SetMonitor(monitor)
+	assert.Equal(t, monitor, stream.Monitor())
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]int{make([]int, 2000)}
+	var outputFlags [1]int
+
+	// The first call is split into two device reads, with a gap between them.
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 1000, &outputFlags, 0)
+	require.Nil(t, err)
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 1000, &outputFlags, 0)
+	assert.ErrorIs(t, err, sdr.ErrStreamOverflow)
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 1000, &outputFlags, 0)
+	assert.ErrorIs(t, err, sdr.ErrStreamTimeout)
+	// The timestamp is not compared with the timestamps from before the overflow.
+	_, _, err = stream.ReadCS8FromStream(testLogger, buffer, 1000, &outputFlags, 0)
+	require.Nil(t, err)
+
+	stats := monitor.Stats()
+	assert.Equal(t, 2e6, stats.ConfiguredSampleRate)
+	assert.Equal(t, uint64(5), stats.Reads)
+	assert.Equal(t, uint64(2000), stats.Samples)
+	assert.Equal(t, uint64(1), stats.Overflows)
+	assert.Equal(t, uint64(1), stats.Timeouts)
+	assert.Equal(t, uint64(1), stats.Discontinuities)
+	assert.Equal(t, uint64(0), stats.Underflows)
+	var latencyCount uint64
+	for _, count := range stats.ReadLatency {
+		latencyCount += count
+	}
+	assert.Equal(t, stats.Reads, latencyCount)
+	assert.Greater(t, stats.EffectiveSampleRate, 0.)
+	assert.Greater(t, stats.Elapsed, time.Duration(0))
+	assert.False(t, stats.Healthy())
+
+	monitor.Reset()
+	stats = monitor.Stats()
+	assert.Equal(t, sdr.StreamStats{ConfiguredSampleRate: 2e6}, stats)
+	monitor.SetSampleRate(1.024e6)
+	assert.Equal(t, 1.024e6, monitor.Stats().ConfiguredSampleRate)
+}
+
+func TestStreamMonitor_ReadLatency(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Latency: map[string]time.Duration{"ReadCS16Stream": 2 * time.Millisecond}}
+	stream, err := sdr.SetupFormatStream[int16](&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	monitor := sdr.NewStreamMonitor(0.)
+	stream.SetMonitor(monitor)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	buffer := [][]int16{make([]int16, 200)}
+	var outputFlags [1]int
+	_, _, err = stream.ReadFromStream(testLogger, buffer, 100, &outputFlags, 0)
+	require.Nil(t, err)
+
+	stats := monitor.Stats()
+	assert.GreaterOrEqual(t, stats.MaxReadLatency, 2*time.Millisecond)
+	assert.Equal(t, uint64(0), stats.ReadLatency[0]+stats.ReadLatency[1])
+	assert.Equal(t, uint64(1), stats.ReadLatency[2]+stats.ReadLatency[3]+stats.ReadLatency[4])
+	assert.True(t, stats.Healthy())
+}
+
+func TestStreamMonitor_Underflow(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Errors: map[string]error{"ReadCS8StreamStatus": sdr.ErrStreamUnderflow}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionTX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	monitor := sdr.NewStreamMonitor(2e6)
+	stream.SetMonitor(monitor)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	defer stream.Deactivate(testLogger, 0, 0)
+	_, _, err = stream.ReadStreamStatus(testLogger, 0)
+	assert.ErrorIs(t, err, sdr.ErrStreamUnderflow)
+	assert.Equal(t, uint64(1), monitor.Stats().Underflows)
+	assert.False(t, monitor.Stats().Healthy())
+}
+
+func TestStreamMonitor_Publish(t *testing.T) {
+	monitor := sdr.NewStreamMonitor(2e6)
+	published := make(chan sdr.StreamStats, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := monitor.Publish(ctx, time.Millisecond, func(stats sdr.StreamStats) {
+		select {
+		case published <- stats:
+		default:
+		}
+	})
+	stats := <-published
+	assert.Equal(t, 2e6, stats.ConfiguredSampleRate)
+	cancel()
+	<-done
+}
+
+func TestStreamStats_Healthy(t *testing.T) {
+	tests := []struct {
+		name    string
+		stats   sdr.StreamStats
+		healthy bool
+	}{
+		{"no reads", sdr.StreamStats{ConfiguredSampleRate: 2e6}, true},
+		{"full rate", sdr.StreamStats{ConfiguredSampleRate: 2e6, EffectiveSampleRate: 1.99e6, Samples: 1}, true},
+		{"unknown rate", sdr.StreamStats{EffectiveSampleRate: 1e3, Samples: 1}, true},
+		{"slow", sdr.StreamStats{ConfiguredSampleRate: 2e6, EffectiveSampleRate: 1.5e6, Samples: 1}, false},
+		{"overflow", sdr.StreamStats{Overflows: 1}, false},
+		{"underflow", sdr.StreamStats{Underflows: 1}, false},
+		{"gap", sdr.StreamStats{Discontinuities: 1}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.healthy, test.stats.Healthy())
+		})
+	}
+}
+
+func TestStreamStats_String(t *testing.T) {
+	stats := sdr.StreamStats{ConfiguredSampleRate: 2.048e6, EffectiveSampleRate: 1.5e6, Overflows: 3, Timeouts: 1,
+		MaxReadLatency: 12 * time.Millisecond, BufferFill: 0.25}
+	assert.Equal(t, "1.500 of 2.048 MS/s, overflows: 3, timeouts: 1, underflows: 0, gaps: 0, max read: 12ms, "+
+		"buffer: 25%", stats.String())
+	stats.ConfiguredSampleRate = 0.
+	assert.True(t, strings.HasPrefix(stats.String(), "1.500 MS/s, "))
+}
+
+func TestReceiver_StreamStats(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	testLogger.SetMaxLevel(logger.Debug)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	receiver := sdr.NewReceiver(source, testLogger, 2e6, nil)
+	require.NotNil(t, receiver.Monitor())
+	assert.Equal(t, receiver.Monitor(), source.Monitor())
+	ctx, cancel := context.WithCancel(context.Background())
+	require.Nil(t, receiver.Start(ctx))
+	for range 3 {
+		block := <-receiver.Blocks()
+		block.Release()
+	}
+	cancel()
+	for range receiver.Blocks() {
+	}
+	assert.Nil(t, receiver.Wait())
+
+	stats := receiver.StreamStats()
+	assert.Equal(t, 2e6, stats.ConfiguredSampleRate)
+	assert.GreaterOrEqual(t, stats.Samples, receiver.Stats().Samples)
+	assert.Equal(t, uint64(0), stats.Discontinuities)
+	assert.GreaterOrEqual(t, stats.BufferFill, 0.)
+	assert.LessOrEqual(t, stats.BufferFill, 1.)
+	testLogger.Close()
+	assert.Contains(t, log.String(), "Receiver stream statistics: ")
+}
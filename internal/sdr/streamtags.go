@@ -0,0 +1,81 @@
+package sdr
+
+import (
+	"sync"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// StreamTags describe the device settings that a block of samples was received with.
+type StreamTags struct {
+	CenterFrequency float64
+	SampleRate      float64
+	Gain            float64
+	// Generation is incremented each time that the settings are changed.
+	Generation uint64
+	// ChangedAtNs is the hardware time in nanoseconds at which the settings were last changed, or 0 if the device
+	// does not have a hardware time.
+	ChangedAtNs uint
+}
+
+// StreamTagger holds the current settings of a device's direction and channel, so that a Receiver can tag the blocks
+// that it reads with the settings that they were received with, and mark the block where a retune took effect.
+//
+// A DeviceController updates the taggers that it returns from StreamTagger whenever it tunes the device, or sets
+// its gain or sample rate. Code that changes the settings in other ways should call Retuned. A StreamTagger may be
+// used concurrently on multiple go routines.
+type StreamTagger struct {
+	direction device.Direction
+	channel   uint
+
+	mutex sync.Mutex
+	tags  StreamTags
+}
+
+// NewStreamTagger creates a StreamTagger for the specified direction and channel, whose current settings are tags.
+func NewStreamTagger(direction device.Direction, channel uint, tags StreamTags) *StreamTagger {
+	return &StreamTagger{direction: direction, channel: channel, tags: tags}
+}
+
+// Direction returns the direction whose settings the tagger holds.
+func (tagger *StreamTagger) Direction() device.Direction {
+	return tagger.direction
+}
+
+// Channel returns the channel whose settings the tagger holds.
+func (tagger *StreamTagger) Channel() uint {
+	return tagger.channel
+}
+
+// Tags returns the current settings.
+func (tagger *StreamTagger) Tags() StreamTags {
+	tagger.mutex.Lock()
+	defer tagger.mutex.Unlock()
+	return tagger.tags
+}
+
+// Retuned records that update has changed the settings, and increments the Generation.
+//
+// changedAtNs is the hardware time at which the change took effect, or 0 if it is not known. Samples that were
+// received before changedAtNs keep the old settings; otherwise, the change takes effect from the next block that is
+// read.
+func (tagger *StreamTagger) Retuned(changedAtNs uint, update func(*StreamTags)) {
+	tagger.mutex.Lock()
+	defer tagger.mutex.Unlock()
+	update(&tagger.tags)
+	tagger.tags.Generation++
+	tagger.tags.ChangedAtNs = changedAtNs
+}
+
+// receivedBefore returns true if a block of numSamples samples at sampleRate whose first sample was received at
+// timeNs was received completely before the change described by tags took effect.
+func (tags StreamTags) receivedBefore(timeNs uint, hasTime bool, numSamples uint, sampleRate float64) bool {
+	if tags.ChangedAtNs == 0 || !hasTime {
+		return false
+	}
+	endNs := float64(timeNs)
+	if sampleRate > 0 {
+		endNs += float64(numSamples) * 1e9 / sampleRate
+	}
+	return endNs <= float64(tags.ChangedAtNs)
+}
@@ -0,0 +1,141 @@
+package sdr_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubNsPerSample is the time between the timestamps of StubDevice samples.
+const stubNsPerSample = 500
+
+func TestDeviceController_StreamTagger(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	controller, stub, _ := startController(t, testLogger)
+	ctx := context.Background()
+	tagger, err := controller.StreamTagger(ctx, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Equal(t, device.DirectionRX, tagger.Direction())
+	assert.Equal(t, uint(0), tagger.Channel())
+	assert.Equal(t, sdr.StreamTags{CenterFrequency: 100e6, SampleRate: 2e6, Gain: 50.}, tagger.Tags())
+	again, err := controller.StreamTagger(ctx, device.DirectionRX, 0)
+	require.Nil(t, err)
+	assert.Same(t, tagger, again)
+
+	require.Nil(t, controller.Tune(ctx, device.DirectionRX, 0, 433.92e6))
+	require.Nil(t, controller.SetGain(ctx, device.DirectionRX, 0, 30.))
+	require.Nil(t, controller.SetSampleRate(ctx, device.DirectionRX, 0, 1.024e6))
+	assert.Equal(t, sdr.StreamTags{CenterFrequency: 433.92e6, SampleRate: 1.024e6, Gain: 30., Generation: 3},
+		tagger.Tags())
+	// Other channels and failed changes do not change the tags.
+	require.Nil(t, controller.Tune(ctx, device.DirectionRX, 1, 145e6))
+	assert.NotNil(t, controller.Tune(ctx, device.DirectionRX, 0, 7e9))
+	assert.Equal(t, uint64(3), tagger.Tags().Generation)
+
+	// The hardware time at which the settings change is recorded if the device has a hardware time.
+	require.Nil(t, stub.SetHardwareTime(1000000000, sdr.TimeNow))
+	require.Nil(t, controller.Tune(ctx, device.DirectionRX, 0, 145e6))
+	assert.GreaterOrEqual(t, tagger.Tags().ChangedAtNs, uint(1000000000))
+}
+
+// receiveUntilRetuned returns the blocks that receiver publishes up to and including the first block that is
+// retuned. It fails the test if no block is retuned within maxBlocks blocks.
+func receiveUntilRetuned(t *testing.T, receiver *sdr.Receiver, maxBlocks int) []sdr.Block {
+	var blocks []sdr.Block
+	for range maxBlocks {
+		block := <-receiver.Blocks()
+		block.Release()
+		blocks = append(blocks, block)
+		if block.Retuned {
+			return blocks
+		}
+	}
+	require.Fail(t, "no block was retuned")
+	return nil
+}
+
+func TestReceiver_Retuned(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	tagger := sdr.NewStreamTagger(device.DirectionRX, 0, sdr.StreamTags{CenterFrequency: 100e6, SampleRate: 2e6})
+	receiver := sdr.NewReceiver(source, testLogger, 0., nil)
+	receiver.SetStreamTagger(tagger)
+	assert.Equal(t, 2e6, receiver.StreamStats().ConfiguredSampleRate)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.Nil(t, receiver.Start(ctx))
+
+	block := <-receiver.Blocks()
+	block.Release()
+	assert.Equal(t, 100e6, block.Tags.CenterFrequency)
+	assert.False(t, block.Retuned)
+
+	tagger.Retuned(0, func(tags *sdr.StreamTags) {
+		tags.CenterFrequency = 145e6
+		tags.SampleRate = 1.024e6
+	})
+	blocks := receiveUntilRetuned(t, receiver, 100)
+	for _, block := range blocks[:len(blocks)-1] {
+		assert.Equal(t, 100e6, block.Tags.CenterFrequency)
+	}
+	retuned := blocks[len(blocks)-1]
+	assert.Equal(t, sdr.StreamTags{CenterFrequency: 145e6, SampleRate: 1.024e6, Generation: 1}, retuned.Tags)
+	assert.False(t, retuned.Discontinuity)
+	block = <-receiver.Blocks()
+	block.Release()
+	assert.Equal(t, 145e6, block.Tags.CenterFrequency)
+	assert.False(t, block.Retuned)
+
+	cancel()
+	for range receiver.Blocks() {
+	}
+	assert.Nil(t, receiver.Wait())
+	assert.Equal(t, uint64(1), receiver.Stats().Retunes)
+	assert.Equal(t, 1.024e6, receiver.StreamStats().ConfiguredSampleRate)
+}
+
+func TestReceiver_RetunedAtHardwareTime(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{}
+	source, err := sdr.NewSampleSource(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	tagger := sdr.NewStreamTagger(device.DirectionRX, 0, sdr.StreamTags{CenterFrequency: 100e6, SampleRate: 2e6})
+	receiver := sdr.NewReceiver(source, testLogger, 2e6, nil)
+	receiver.SetStreamTagger(tagger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.Nil(t, receiver.Start(ctx))
+
+	block := <-receiver.Blocks()
+	blockNs := uint(len(block.Samples[0])) * stubNsPerSample
+	block.Release()
+	// The receiver may already have read the blocks that fill its channel, so the change takes effect well after
+	// them, part way through a block.
+	changedAtNs := block.TimeNs + 40*blockNs + blockNs/2
+	tagger.Retuned(changedAtNs, func(tags *sdr.StreamTags) { tags.CenterFrequency = 145e6 })
+	blocks := receiveUntilRetuned(t, receiver, 100)
+
+	retuned := blocks[len(blocks)-1]
+	assert.Equal(t, 145e6, retuned.Tags.CenterFrequency)
+	assert.Equal(t, changedAtNs, retuned.Tags.ChangedAtNs)
+	assert.LessOrEqual(t, retuned.TimeNs, changedAtNs)
+	assert.Greater(t, retuned.TimeNs+blockNs, changedAtNs)
+	for _, block := range blocks[:len(blocks)-1] {
+		assert.Equal(t, 100e6, block.Tags.CenterFrequency)
+	}
+	cancel()
+	for range receiver.Blocks() {
+	}
+	assert.Nil(t, receiver.Wait())
+}
@@ -2,6 +2,8 @@ package sdr
 
 import (
 	"errors"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
@@ -9,16 +11,73 @@ import (
 
 // StubDevice provides a stub interface for testing the soapySDR interface.
 // The fields in the struct allow loading of data for test purposes to allow simple method returns.
+//
+// Every StubDevice holds its own state, so tests do not depend on each other. The zero value emulates an RTL-SDR
+// dongle with two receive channels and one transmit channel. Failures are scripted with the exported fields:
+// errors and latency can be injected into any method, and the results of stream reads can be scripted. Every call
+// is recorded, so that tests can check the calls that were made.
 type StubDevice struct {
-	Device     *Sdr
-	Devices    []map[string]string
-	Args       map[string]string
+	Device  *Sdr
+	Devices []map[string]string
+	Args    map[string]string
+
+	// Errors holds the errors that are injected into methods, indexed by method name, for example "SetSampleRate".
+	// A method with an injected error returns the error without doing anything else. Methods that cannot return
+	// an error ignore their injected errors.
+	Errors map[string]error
+	// Latency holds the delays that are injected into methods, indexed by method name. A method sleeps for its
+	// delay before it does anything else.
+	Latency map[string]time.Duration
+	// Reads are the results of successive stream reads, in any stream format. Once the scripted reads have been
+	// used, reads return the number of elements that are requested. See StubRead.
+	Reads []StubRead
+	// LoopReads repeats Reads once they have all been used.
+	LoopReads bool
+
+	// FrequencyRanges are the frequency ranges of every channel. If FrequencyRanges is nil, the range is 0 to 6 GHz.
+	FrequencyRanges []device.SDRRange
+	// StreamFormats are the stream formats of every channel. If StreamFormats is nil, the formats are CS8, CS16,
+	// and CF32.
+	StreamFormats []string
+	// NativeStreamFormat and NativeFullScale are the native stream format of every channel and its full scale. If
+	// NativeStreamFormat is "", the native format is CS8 with a full scale of 128.
+	NativeStreamFormat string
+	NativeFullScale    float64
+
+	// mutex protects calls and the stream read state, which are used by the go routines that read streams.
+	mutex sync.Mutex
+	calls []StubCall
+	read  stubRead
+
 	sampleRate float64
+	bandwidth  float64
+	// temperatureReads is the number of times that the "temperature" sensor has been read.
+	temperatureReads int
+	// settings holds the values of the device and channel settings that have been written, indexed by key.
+	settings map[string]string
+	// clock holds the clock and time settings.
+	clock stubClock
+	// corrections holds the front end corrections for the receive channel.
+	corrections stubCorrections
+	// transmit holds the state of the burst that is being written to a transmit stream.
+	transmit stubTransmit
+	// tuning holds the frequencies that have been set.
+	tuning stubTuning
+	// gains holds the gain settings.
+	gains stubGains
+	// antennas holds the selected receive antenna for each channel, indexed by channel number.
+	antennas map[uint]string
+	// gpio holds the state of each GPIO bank, indexed by bank name.
+	gpio map[string]stubGPIOBank
+	// registers holds the values of the registers that have been written, indexed by register interface name and
+	// address.
+	registers map[string]map[uint32]uint32
 }
 
 // Enumerate returns a slice of map[string]string values representing the available devices. These
 // values must be preloaded into the Devices property of the StubDevice struct before Enumerate is called.
-func (dev StubDevice) Enumerate(args map[string]string) []map[string]string {
+func (dev *StubDevice) Enumerate(args map[string]string) []map[string]string {
+	dev.call("Enumerate", args)
 	return dev.Devices
 }
 
@@ -28,6 +87,9 @@ func (dev StubDevice) Enumerate(args map[string]string) []map[string]string {
 // If args are provided, then Make sets the Device.Device field to a fake device pointer. If no args are provided,
 // then an error is returned.
 func (dev *StubDevice) Make(args map[string]string) error {
+	if err := dev.call("Make", args); err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		return errors.New("no arguments provided")
 	} else {
@@ -42,6 +104,9 @@ func (dev *StubDevice) Make(args map[string]string) error {
 // Unmake returns nil if previous call to Make was successful; otherwise, returns an error.
 // Since StubDevice is used for testing, an actual SDR is never created.
 func (dev *StubDevice) Unmake() error {
+	if err := dev.call("Unmake"); err != nil {
+		return err
+	}
 	if dev.Device == nil {
 		return errors.New("no device to unmake")
 	} else {
@@ -52,6 +117,7 @@ func (dev *StubDevice) Unmake() error {
 }
 
 // GetHardwareKey returns a string containing a fake hardware key.
-func (dev StubDevice) GetHardwareKey() string {
+func (dev *StubDevice) GetHardwareKey() string {
+	dev.call("GetHardwareKey")
 	return "hardKey"
 }
@@ -2,32 +2,59 @@ package sdr
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
+// stubRxAntennas holds the RX antenna names for each channel.
+var stubRxAntennas = map[uint][]string{
+	0: {"RX"},
+	1: {"RX", "RX2"},
+}
+
 // GetAntennaNames retrieves a list of all antennas for the direction and channel
-func (dev *StubDevice) GetAntennaNames(direction device.Direction, _ uint) []string {
+func (dev *StubDevice) GetAntennaNames(direction device.Direction, channel uint) []string {
+	dev.call("GetAntennaNames", direction, channel)
 	if direction == device.DirectionRX {
-		return []string{"RX"}
+		antennas := stubRxAntennas[channel]
+		if dev.Device != nil {
+			dev.Device.Channel(direction, channel).Antennas = antennas
+		}
+		return antennas
 	} else {
 		return []string{}
 	}
 }
 
-// GetCurrentAntenna returns the currently selected antenna for the specified direction and channel number.
-func (dev *StubDevice) GetCurrentAntenna(direction device.Direction, _ uint) string {
+// GetCurrentAntenna returns the currently selected antenna for the specified direction and channel number. The
+// first antenna of a receive channel is selected until another antenna is selected.
+func (dev *StubDevice) GetCurrentAntenna(direction device.Direction, channel uint) string {
+	dev.call("GetCurrentAntenna", direction, channel)
 	if direction == device.DirectionRX {
-		return "RX"
+		if antenna, ok := dev.antennas[channel]; ok {
+			return antenna
+		}
+		return stubRxAntennas[channel][0]
 	} else {
 		return ""
 	}
 }
 
-// SetAntenna sets the RX antenna. Channel number is ignored here.
+// SetAntenna sets the RX antenna for the specified channel.
 // Returns nil on success, or error on failure.
-func (dev *StubDevice) SetAntenna(direction device.Direction, _ uint, antenna string) error {
-	if antenna == "RX" {
+func (dev *StubDevice) SetAntenna(direction device.Direction, channel uint, antenna string) error {
+	if err := dev.call("SetAntenna", direction, channel, antenna); err != nil {
+		return err
+	}
+	if direction == device.DirectionRX && slices.Contains(stubRxAntennas[channel], antenna) {
+		if dev.antennas == nil {
+			dev.antennas = make(map[uint]string)
+		}
+		dev.antennas[channel] = antenna
+		if dev.Device != nil {
+			dev.Device.Channel(direction, channel).Antenna = antenna
+		}
 		return nil
 	} else {
 		return fmt.Errorf("invalid antenna: %s", antenna)
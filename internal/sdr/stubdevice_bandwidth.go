@@ -0,0 +1,32 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetBandwidthRanges returns a narrow and a wide bandwidth range. The wide range has a step size so that the
+// stepping of bandwidths can be tested.
+// If Make has not been called for the device, then an empty slice is returned.
+func (dev *StubDevice) GetBandwidthRanges(direction device.Direction, channel uint) []device.SDRRange {
+	dev.call("GetBandwidthRanges", direction, channel)
+	if dev.Device == nil {
+		return []device.SDRRange{}
+	}
+	return []device.SDRRange{{Minimum: 200000, Maximum: 2000000, Step: 0},
+		{Minimum: 5000000, Maximum: 8000000, Step: 1000000}}
+}
+
+// GetBandwidth returns the bandwidth that was last set.
+func (dev *StubDevice) GetBandwidth(direction device.Direction, channel uint) float64 {
+	dev.call("GetBandwidth", direction, channel)
+	return dev.bandwidth
+}
+
+// SetBandwidth sets the bandwidth.
+func (dev *StubDevice) SetBandwidth(direction device.Direction, channel uint, bw float64) error {
+	if err := dev.call("SetBandwidth", direction, channel, bw); err != nil {
+		return err
+	}
+	dev.bandwidth = bw
+	return nil
+}
@@ -0,0 +1,16 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// GetNumChannels returns the number of channels for the specified direction.
+//
+// StubDevice emulates a dual-channel receiver with a single transmit channel.
+func (dev *StubDevice) GetNumChannels(direction device.Direction) uint {
+	dev.call("GetNumChannels", direction)
+	if direction == device.DirectionRX {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,68 @@
+package sdr
+
+import (
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// stubDefaultMasterClockRate is the master clock rate of a StubDevice until it is changed.
+const stubDefaultMasterClockRate = 28.8e6
+
+// stubClock holds the clock and time settings of a StubDevice. The zero value holds the default settings.
+type stubClock struct {
+	masterClockRate float64
+	clockSource     string
+	timeSource      string
+	// hardwareTimeNs is the hardware time that was set at hardwareTimeSetAt. The hardware time is 0 until it is set.
+	hardwareTimeNs    uint
+	hardwareTimeSetAt time.Time
+}
+
+// GetMasterClockRate returns the master clock rate.
+func (dev *StubDevice) GetMasterClockRate() float64 {
+	dev.call("GetMasterClockRate")
+	if dev.clock.masterClockRate == 0 {
+		return stubDefaultMasterClockRate
+	}
+	return dev.clock.masterClockRate
+}
+
+// SetMasterClockRate sets the master clock rate.
+func (dev *StubDevice) SetMasterClockRate(rate float64) error {
+	if err := dev.call("SetMasterClockRate", rate); err != nil {
+		return err
+	}
+	dev.clock.masterClockRate = rate
+	return nil
+}
+
+// GetMasterClockRates returns the range of master clock rates of an RTL-SDR dongle.
+func (dev *StubDevice) GetMasterClockRates() []device.SDRRange {
+	dev.call("GetMasterClockRates")
+	return []device.SDRRange{{Minimum: 24e6, Maximum: 32e6, Step: 0}}
+}
+
+// GetClockSources returns the clock sources.
+func (dev *StubDevice) GetClockSources() []string {
+	dev.call("GetClockSources")
+	return []string{"internal", "external"}
+}
+
+// GetClockSource returns the current clock source.
+func (dev *StubDevice) GetClockSource() string {
+	dev.call("GetClockSource")
+	if dev.clock.clockSource == "" {
+		return "internal"
+	}
+	return dev.clock.clockSource
+}
+
+// SetClockSource selects the clock source.
+func (dev *StubDevice) SetClockSource(source string) error {
+	if err := dev.call("SetClockSource", source); err != nil {
+		return err
+	}
+	dev.clock.clockSource = source
+	return nil
+}
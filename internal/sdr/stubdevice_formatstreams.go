@@ -0,0 +1,86 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// stubFormatStream is the device side of a fake FormatStream.
+//
+// Calls are recorded with the names of the per-format methods of a real device, for example "ReadCU8Stream" or
+// "GetCS16MTU", so that StubDevice.Errors and StubDevice.Latency can script the calls for each format.
+type stubFormatStream[T StreamElement] struct {
+	dev *StubDevice
+	// fill fills the start of each channel's buffer with the test data for the specified number of elements.
+	fill func([][]T, uint)
+	// The names that calls are recorded with are built when the stream is set up, so that reads do not allocate.
+	closeName      string
+	mtuName        string
+	activateName   string
+	deactivateName string
+	readName       string
+}
+
+// SetupFormatStream returns a fake CU8, CS16, or CF32 stream.
+func (dev *StubDevice) SetupFormatStream(format string, direction device.Direction, channels []uint,
+	args map[string]string) (formatStreamDevice, error) {
+	if err := dev.call("Setup"+format+"Stream", direction, channels, args); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatCU8:
+		return newStubFormatStream(dev, format, fillStubCU8Buffers), nil
+	case FormatCS16:
+		return newStubFormatStream(dev, format, func(buff [][]int16, numElems uint) {
+			fillStubBuffers(buff, numElems, cs16Pattern)
+		}), nil
+	case FormatCF32:
+		return newStubFormatStream(dev, format, fillStubCF32Buffers), nil
+	}
+	return nil, fmt.Errorf("unsupported stream format: %s", format)
+}
+
+// newStubFormatStream creates the device side of a fake stream of the specified format, whose reads are filled by
+// fill.
+func newStubFormatStream[T StreamElement](dev *StubDevice, format string, fill func([][]T, uint)) *stubFormatStream[T] {
+	return &stubFormatStream[T]{dev: dev, fill: fill, closeName: "Close" + format + "Stream",
+		mtuName: "Get" + format + "MTU", activateName: "Activate" + format + "Stream",
+		deactivateName: "Deactivate" + format + "Stream", readName: "Read" + format + "Stream"}
+}
+
+func (s *stubFormatStream[T]) close() error {
+	return s.dev.call(s.closeName)
+}
+
+// mtu returns 10000, the same MTU as the fake CS8 stream.
+func (s *stubFormatStream[T]) mtu() int {
+	s.dev.call(s.mtuName)
+	return 10000
+}
+
+// activate activates the fake stream. See activateStubStream.
+func (s *stubFormatStream[T]) activate(flag device.StreamFlag, timeNs int, numElems int) error {
+	if err := s.dev.call(s.activateName, flag, timeNs, numElems); err != nil {
+		return err
+	}
+	return s.dev.activateStubStream(flag, timeNs)
+}
+
+func (s *stubFormatStream[T]) deactivate(flag device.StreamFlag, timeNs int) error {
+	return s.dev.call(s.deactivateName, flag, timeNs)
+}
+
+// read fills buff with a fixed pattern of test data for each of the stream's channels.
+//
+// The number of elements read, the flags, and the errors are scripted by StubDevice.Reads.
+func (s *stubFormatStream[T]) read(_ *FormatStream[T], buff [][]T, numElemsToRead uint, outputFlags *[1]int,
+	_ uint) (timeNs uint, numElemsRead uint, err error) {
+	if err := s.dev.call(s.readName); err != nil {
+		outputFlags[0] = 0
+		return 0, 0, err
+	}
+	timeNs, numElemsRead, outputFlags[0], err = s.dev.nextRead(numElemsToRead)
+	s.fill(buff, numElemsRead)
+	return timeNs, numElemsRead, err
+}
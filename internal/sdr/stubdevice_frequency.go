@@ -1,66 +1,83 @@
 package sdr
 
 import (
-	"errors"
-
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
-var tunableFrequencies = map[string]float64{
-	"RF": 100000000.0,
-}
+// stubDefaultFrequency is the frequency of each channel and tunable element of a StubDevice until it is changed.
+const stubDefaultFrequency = 100000000.0
 
-var overallCenterFrequency = 100000000.
+// stubTuning holds the frequencies that have been set for a StubDevice.
+type stubTuning struct {
+	// centerFrequencies holds the overall center frequency of each channel, indexed by channel number.
+	centerFrequencies map[uint]float64
+	// elementFrequencies holds the frequency of each tunable element, indexed by element name.
+	elementFrequencies map[string]float64
+}
 
-// GetFrequencyRanges retrieves the frequency ranges supported by the device.
-func (dev *StubDevice) GetFrequencyRanges(_ device.Direction, _ uint) []device.SDRRange {
-	switch dev.Args["serial"] {
-	case "2", "4":
+// GetFrequencyRanges returns StubDevice.FrequencyRanges, or a range of 0 to 6 GHz if they have not been set.
+func (dev *StubDevice) GetFrequencyRanges(direction device.Direction, channel uint) []device.SDRRange {
+	dev.call("GetFrequencyRanges", direction, channel)
+	if dev.FrequencyRanges == nil {
 		return []device.SDRRange{{Minimum: 0.0, Maximum: 6e+09, Step: 0.0}}
-	case "3":
-		return []device.SDRRange{{Minimum: 0.0, Maximum: 6e+09, Step: 0.0},
-			{Minimum: 6.1e+09, Maximum: 1e+10, Step: 0.0}}
-	default:
-		return []device.SDRRange{}
 	}
+	return dev.FrequencyRanges
 }
 
 // GetTunableElementNames returns the list of tunable elements for this device.
-func (dev *StubDevice) GetTunableElementNames(_ device.Direction, _ uint) []string {
+func (dev *StubDevice) GetTunableElementNames(direction device.Direction, channel uint) []string {
+	dev.call("GetTunableElementNames", direction, channel)
 	return []string{"RF"}
 }
 
 // GetTunableElementFrequencyRanges retrieves a slice of frequency ranges for the specified tunable element.
-func (dev *StubDevice) GetTunableElementFrequencyRanges(_ device.Direction, _ uint, _ string) []device.SDRRange {
+func (dev *StubDevice) GetTunableElementFrequencyRanges(direction device.Direction, channel uint,
+	name string) []device.SDRRange {
+	dev.call("GetTunableElementFrequencyRanges", direction, channel, name)
 	return []device.SDRRange{{Minimum: 0, Maximum: 6e+09, Step: 0},
 		{Minimum: 6.1e+09, Maximum: 1e+10, Step: 0}}
 }
 
 // GetTunableElementFrequency retrieves the tuned frequency in Hz for the specified tunable element.
-func (dev *StubDevice) GetTunableElementFrequency(_ device.Direction, _ uint, name string) float64 {
-	return tunableFrequencies["RF"]
+func (dev *StubDevice) GetTunableElementFrequency(direction device.Direction, channel uint, name string) float64 {
+	dev.call("GetTunableElementFrequency", direction, channel, name)
+	if freq, ok := dev.tuning.elementFrequencies[name]; ok {
+		return freq
+	}
+	return stubDefaultFrequency
 }
 
 // SetTunableElementFrequency sets the frequency for the tunable element to the specified value.
-func (dev *StubDevice) SetTunableElementFrequency(_ device.Direction, _ uint, name string, newFreq float64) error {
-	tunableFrequencies[name] = newFreq
+func (dev *StubDevice) SetTunableElementFrequency(direction device.Direction, channel uint, name string,
+	newFreq float64) error {
+	if err := dev.call("SetTunableElementFrequency", direction, channel, name, newFreq); err != nil {
+		return err
+	}
+	if dev.tuning.elementFrequencies == nil {
+		dev.tuning.elementFrequencies = make(map[string]float64)
+	}
+	dev.tuning.elementFrequencies[name] = newFreq
 	return nil
 }
 
-// GetOverallCenterFrequency retrieves the overall center frequency for the device.
-func (dev *StubDevice) GetOverallCenterFrequency(_ device.Direction, _ uint) float64 {
-	return overallCenterFrequency
+// GetOverallCenterFrequency retrieves the overall center frequency for the specified channel.
+func (dev *StubDevice) GetOverallCenterFrequency(direction device.Direction, channel uint) float64 {
+	dev.call("GetOverallCenterFrequency", direction, channel)
+	if freq, ok := dev.tuning.centerFrequencies[channel]; ok {
+		return freq
+	}
+	return stubDefaultFrequency
 }
 
-// SetOverallCenterFrequency sets the overall center frequency for the device.
-//
-// Returns error if serial number for the device is "4". This is purely for testing purposes.
-func (dev *StubDevice) SetOverallCenterFrequency(_ device.Direction, _ uint, newFreq float64, _ map[string]string) error {
-	switch dev.Args["serial"] {
-	case "4":
-		return errors.New("serial # = 4")
-	default:
-		overallCenterFrequency = newFreq
-		return nil
+// SetOverallCenterFrequency sets the overall center frequency for the specified channel.
+func (dev *StubDevice) SetOverallCenterFrequency(direction device.Direction, channel uint, newFreq float64,
+	args map[string]string) error {
+	if err := dev.call("SetOverallCenterFrequency", direction, channel, newFreq, args); err != nil {
+		return err
 	}
+	if dev.tuning.centerFrequencies == nil {
+		dev.tuning.centerFrequencies = make(map[uint]float64)
+	}
+	dev.tuning.centerFrequencies[channel] = newFreq
+	return nil
 }
@@ -0,0 +1,103 @@
+package sdr
+
+import (
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// stubCorrections holds the front end corrections for a StubDevice.
+type stubCorrections struct {
+	dcOffsetAuto        bool
+	dcOffsetI           float64
+	dcOffsetQ           float64
+	iqBalanceI          float64
+	iqBalanceQ          float64
+	frequencyCorrection float64
+}
+
+// HasDCOffsetMode returns true for the receive direction.
+func (dev *StubDevice) HasDCOffsetMode(direction device.Direction, channel uint) bool {
+	dev.call("HasDCOffsetMode", direction, channel)
+	return direction == device.DirectionRX
+}
+
+// GetDCOffsetMode returns whether automatic DC offset correction is enabled.
+func (dev *StubDevice) GetDCOffsetMode(direction device.Direction, channel uint) bool {
+	dev.call("GetDCOffsetMode", direction, channel)
+	return dev.corrections.dcOffsetAuto
+}
+
+// SetDCOffsetMode enables or disables automatic DC offset correction.
+func (dev *StubDevice) SetDCOffsetMode(direction device.Direction, channel uint, automatic bool) error {
+	if err := dev.call("SetDCOffsetMode", direction, channel, automatic); err != nil {
+		return err
+	}
+	dev.corrections.dcOffsetAuto = automatic
+	return nil
+}
+
+// HasDCOffset returns true for the receive direction.
+func (dev *StubDevice) HasDCOffset(direction device.Direction, channel uint) bool {
+	dev.call("HasDCOffset", direction, channel)
+	return direction == device.DirectionRX
+}
+
+// GetDCOffset returns the I and Q DC offset corrections.
+func (dev *StubDevice) GetDCOffset(direction device.Direction, channel uint) (float64, float64, error) {
+	if err := dev.call("GetDCOffset", direction, channel); err != nil {
+		return 0.0, 0.0, err
+	}
+	return dev.corrections.dcOffsetI, dev.corrections.dcOffsetQ, nil
+}
+
+// SetDCOffset sets the I and Q DC offset corrections.
+func (dev *StubDevice) SetDCOffset(direction device.Direction, channel uint, offsetI, offsetQ float64) error {
+	if err := dev.call("SetDCOffset", direction, channel, offsetI, offsetQ); err != nil {
+		return err
+	}
+	dev.corrections.dcOffsetI, dev.corrections.dcOffsetQ = offsetI, offsetQ
+	return nil
+}
+
+// HasIQBalance returns true for the receive direction.
+func (dev *StubDevice) HasIQBalance(direction device.Direction, channel uint) bool {
+	dev.call("HasIQBalance", direction, channel)
+	return direction == device.DirectionRX
+}
+
+// GetIQBalance returns the I and Q balance corrections.
+func (dev *StubDevice) GetIQBalance(direction device.Direction, channel uint) (float64, float64, error) {
+	if err := dev.call("GetIQBalance", direction, channel); err != nil {
+		return 0.0, 0.0, err
+	}
+	return dev.corrections.iqBalanceI, dev.corrections.iqBalanceQ, nil
+}
+
+// SetIQBalance sets the I and Q balance corrections.
+func (dev *StubDevice) SetIQBalance(direction device.Direction, channel uint, balanceI, balanceQ float64) error {
+	if err := dev.call("SetIQBalance", direction, channel, balanceI, balanceQ); err != nil {
+		return err
+	}
+	dev.corrections.iqBalanceI, dev.corrections.iqBalanceQ = balanceI, balanceQ
+	return nil
+}
+
+// HasFrequencyCorrection returns true for the receive direction.
+func (dev *StubDevice) HasFrequencyCorrection(direction device.Direction, channel uint) bool {
+	dev.call("HasFrequencyCorrection", direction, channel)
+	return direction == device.DirectionRX
+}
+
+// GetFrequencyCorrection returns the frequency correction in parts per million.
+func (dev *StubDevice) GetFrequencyCorrection(direction device.Direction, channel uint) float64 {
+	dev.call("GetFrequencyCorrection", direction, channel)
+	return dev.corrections.frequencyCorrection
+}
+
+// SetFrequencyCorrection sets the frequency correction in parts per million.
+func (dev *StubDevice) SetFrequencyCorrection(direction device.Direction, channel uint, ppm float64) error {
+	if err := dev.call("SetFrequencyCorrection", direction, channel, ppm); err != nil {
+		return err
+	}
+	dev.corrections.frequencyCorrection = ppm
+	return nil
+}
@@ -1,93 +1,85 @@
 package sdr
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
-// agcEnabled stores current AGC enabled value.
-var agcEnabled bool
+// stubMaxOverallGain is the maximum overall gain of a StubDevice, which is the sum of the maximum gains of its gain
+// elements.
+const stubMaxOverallGain = 50.
 
-// overallGain stores the current overall gain value.
-var maxOverallGain float64 = 50.
+// stubGainElements are the names of a StubDevice's gain elements.
+var stubGainElements = []string{"RF", "IF"}
 
-type eltInfo struct {
-	gain      float64
-	gainRange device.SDRRange
+// stubGainRanges holds the gain range of each of a StubDevice's gain elements, indexed by element name.
+var stubGainRanges = map[string]device.SDRRange{
+	"RF": {Minimum: 0, Maximum: stubMaxOverallGain / 2, Step: 1},
+	"IF": {Minimum: 0, Maximum: stubMaxOverallGain / 2, Step: 0},
 }
 
-var eltGains = map[string]eltInfo{
-	"RF": {gain: maxOverallGain / 2, gainRange: device.SDRRange{Minimum: 0, Maximum: maxOverallGain / 2, Step: 1}},
-	"IF": {gain: maxOverallGain / 2, gainRange: device.SDRRange{Minimum: 0, Maximum: maxOverallGain / 2, Step: 0}},
+// stubGains holds the gain settings of a StubDevice.
+type stubGains struct {
+	agcEnabled bool
+	// elementGains holds the gains of the elements that have been set, indexed by element name. The gain of an
+	// element is the maximum of its range until it is set.
+	elementGains map[string]float64
 }
 
 // SupportsAGC returns whether the device supports AGC or not.
 //
 // Returns true if device supports automatic gain control.
-func (dev *StubDevice) SupportsAGC(direction device.Direction, _ uint) bool {
+func (dev *StubDevice) SupportsAGC(direction device.Direction, channel uint) bool {
+	dev.call("SupportsAGC", direction, channel)
 	return direction == device.DirectionRX
 }
 
 // AgcIsEnabled returns whether AGC is currently enabled or not.
-//
-// Value returned is based on the device's serial number to allow testing of sdr.AgcIsEnabled.
-func (dev *StubDevice) AgcIsEnabled(direction device.Direction, _ uint) bool {
-	switch dev.Args["serial"] {
-	case "1":
-		return true
-	case "2":
-		return agcEnabled
-	default:
-		return false
-	}
+func (dev *StubDevice) AgcIsEnabled(direction device.Direction, channel uint) bool {
+	dev.call("AgcIsEnabled", direction, channel)
+	return dev.gains.agcEnabled
 }
 
-func (dev *StubDevice) EnableAgc(_ device.Direction, _ uint, enable bool) error {
-	switch dev.Args["serial"] {
-	case "1":
-		return errors.New("could not enable Agc")
-	case "2":
-		agcEnabled = enable
-		return nil
+// EnableAgc enables or disables AGC.
+func (dev *StubDevice) EnableAgc(direction device.Direction, channel uint, enable bool) error {
+	if err := dev.call("EnableAgc", direction, channel, enable); err != nil {
+		return err
 	}
-	return errors.New("invalid serial number for StubDevice.EnableAgc")
+	dev.gains.agcEnabled = enable
+	return nil
 }
 
 // GetGainElementNames returns a list of names for the gain elements for the specified direction and channel.
-func (dev *StubDevice) GetGainElementNames(_ device.Direction, _ uint) []string {
-	var keys []string
-	for k := range eltGains {
-		keys = append(keys, k)
-	}
-	return keys
+func (dev *StubDevice) GetGainElementNames(direction device.Direction, channel uint) []string {
+	dev.call("GetGainElementNames", direction, channel)
+	return stubGainElements
 }
 
-// GetOverallGain returns the overall gain for the specified direction and channel.
-func (dev *StubDevice) GetOverallGain(_ device.Direction, _ uint) float64 {
-	switch dev.Args["serial"] {
-	case "1":
-		return 50.0
-	default:
-		gain := 0.0
-		for _, eltGain := range eltGains {
-			gain += eltGain.gain
-		}
-		return gain
+// GetOverallGain returns the overall gain for the specified direction and channel, which is the sum of the gains of
+// the elements.
+func (dev *StubDevice) GetOverallGain(direction device.Direction, channel uint) float64 {
+	dev.call("GetOverallGain", direction, channel)
+	gain := 0.0
+	for _, name := range stubGainElements {
+		gain += dev.elementGain(name)
 	}
+	return gain
 }
 
 // SetOverallGain sets the overall gain for the specified direction and channel.
 //
 // The overall gain is distributed automatically across the available elements.
-func (dev *StubDevice) SetOverallGain(_ device.Direction, _ uint, overallGain float64) error {
-	if overallGain < 0. || overallGain > maxOverallGain {
-		return fmt.Errorf("requested overall gain = %.1f dB, but must be between 0.0 and %.1f dB", overallGain, maxOverallGain)
+func (dev *StubDevice) SetOverallGain(direction device.Direction, channel uint, overallGain float64) error {
+	if err := dev.call("SetOverallGain", direction, channel, overallGain); err != nil {
+		return err
+	}
+	if overallGain < 0. || overallGain > stubMaxOverallGain {
+		return fmt.Errorf("requested overall gain = %.1f dB, but must be between 0.0 and %.1f dB", overallGain,
+			stubMaxOverallGain)
 	}
-	numElts := len(eltGains)
-	for k := range eltGains {
-		eltGains[k] = eltInfo{gain: overallGain / float64(numElts), gainRange: eltGains[k].gainRange}
+	for _, name := range stubGainElements {
+		dev.setElementGain(name, overallGain/float64(len(stubGainElements)))
 	}
 	return nil
 }
@@ -96,38 +88,54 @@ func (dev *StubDevice) SetOverallGain(_ device.Direction, _ uint, overallGain fl
 //
 // If an error occurs, such as the element name does not exist, then 0.0 and an error are returned. Note that 0.0 may also be a valid value,
 // so don't just check the gain value.
-func (dev *StubDevice) GetElementGain(_ device.Direction, _ uint, eltName string) (float64, error) {
-	switch dev.Args["serial"] {
-	case "2":
-		if gain, ok := eltGains[eltName]; ok {
-			return gain.gain, nil
-		} else {
-			return 0.0, fmt.Errorf("gain element '%s' is invalid", eltName)
-		}
-	default:
-		return 25.0, nil
+func (dev *StubDevice) GetElementGain(direction device.Direction, channel uint, eltName string) (float64, error) {
+	if err := dev.call("GetElementGain", direction, channel, eltName); err != nil {
+		return 0.0, err
 	}
+	if _, ok := stubGainRanges[eltName]; !ok {
+		return 0.0, fmt.Errorf("gain element '%s' is invalid", eltName)
+	}
+	return dev.elementGain(eltName), nil
 }
 
 // SetElementGain sets the gain for the specified element to the specified value.
 //
-// Returns an error if the requested gain is outside the allowable range.
+// Returns an error if the element does not exist, or the requested gain is outside the allowable range.
 func (dev *StubDevice) SetElementGain(direction device.Direction, channel uint, eltName string, gain float64) error {
-	gainRange := dev.GetElementGainRange(direction, channel, eltName)
+	if err := dev.call("SetElementGain", direction, channel, eltName, gain); err != nil {
+		return err
+	}
+	gainRange, ok := stubGainRanges[eltName]
+	if !ok {
+		return fmt.Errorf("gain element '%s' is invalid", eltName)
+	}
 	if gain < gainRange.Minimum || gain > gainRange.Maximum {
 		return fmt.Errorf("cannot set gain for element: %s to %.1f. Requested gain is outside the allowable range: %.1f to %.1f",
 			eltName, gain, gainRange.Minimum, gainRange.Maximum)
 	}
-	eltGains[eltName] = eltInfo{gain, eltGains[eltName].gainRange}
+	dev.setElementGain(eltName, gain)
 	return nil
 }
 
-// GetElementGainRange returns the SDRRange for the specified gain element.
-func (dev *StubDevice) GetElementGainRange(_ device.Direction, _ uint, eltName string) device.SDRRange {
-	switch dev.Args["serial"] {
-	case "2":
-		gain := eltGains[eltName]
-		return gain.gainRange
+// GetElementGainRange returns the SDRRange for the specified gain element, or an empty range if the element does
+// not exist.
+func (dev *StubDevice) GetElementGainRange(direction device.Direction, channel uint, eltName string) device.SDRRange {
+	dev.call("GetElementGainRange", direction, channel, eltName)
+	return stubGainRanges[eltName]
+}
+
+// elementGain returns the gain of the named element.
+func (dev *StubDevice) elementGain(name string) float64 {
+	if gain, ok := dev.gains.elementGains[name]; ok {
+		return gain
+	}
+	return stubGainRanges[name].Maximum
+}
+
+// setElementGain stores the gain of the named element.
+func (dev *StubDevice) setElementGain(name string, gain float64) {
+	if dev.gains.elementGains == nil {
+		dev.gains.elementGains = make(map[string]float64)
 	}
-	return device.SDRRange{Minimum: 0, Maximum: 0, Step: 0}
+	dev.gains.elementGains[name] = gain
 }
@@ -0,0 +1,92 @@
+package sdr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// stubGPIOBanks are the names of the StubDevice's GPIO banks.
+var stubGPIOBanks = []string{"MAIN", "AUX"}
+
+// stubGPIOBank holds the pin values and directions of one of a StubDevice's GPIO banks. The zero value has all pins
+// low and set as inputs.
+type stubGPIOBank struct {
+	value uint32
+	dir   uint32
+}
+
+// GetGPIOBanks returns the names of the GPIO banks.
+func (dev *StubDevice) GetGPIOBanks() []string {
+	dev.call("GetGPIOBanks")
+	return slices.Clone(stubGPIOBanks)
+}
+
+// WriteGPIO writes the value of all of the pins of a GPIO bank.
+func (dev *StubDevice) WriteGPIO(bank string, value uint32) error {
+	if err := dev.call("WriteGPIO", bank, value); err != nil {
+		return err
+	}
+	return dev.writeGPIO(bank, func(gpio *stubGPIOBank) { gpio.value = value })
+}
+
+// WriteGPIOMasked writes the value of the pins of a GPIO bank that are selected by mask.
+func (dev *StubDevice) WriteGPIOMasked(bank string, value uint32, mask uint32) error {
+	if err := dev.call("WriteGPIOMasked", bank, value, mask); err != nil {
+		return err
+	}
+	return dev.writeGPIO(bank, func(gpio *stubGPIOBank) { gpio.value = gpio.value&^mask | value&mask })
+}
+
+// ReadGPIO reads the value of the pins of a GPIO bank. Output pins read the value that was last written to them,
+// and input pins read low.
+func (dev *StubDevice) ReadGPIO(bank string) (uint32, error) {
+	if err := dev.call("ReadGPIO", bank); err != nil {
+		return 0, err
+	}
+	if !slices.Contains(stubGPIOBanks, bank) {
+		return 0, fmt.Errorf("GPIO bank '%s' is invalid", bank)
+	}
+	gpio := dev.gpio[bank]
+	return gpio.value & gpio.dir, nil
+}
+
+// WriteGPIODir writes the direction of all of the pins of a GPIO bank.
+func (dev *StubDevice) WriteGPIODir(bank string, dir uint32) error {
+	if err := dev.call("WriteGPIODir", bank, dir); err != nil {
+		return err
+	}
+	return dev.writeGPIO(bank, func(gpio *stubGPIOBank) { gpio.dir = dir })
+}
+
+// WriteGPIODirMasked writes the direction of the pins of a GPIO bank that are selected by mask.
+func (dev *StubDevice) WriteGPIODirMasked(bank string, dir uint32, mask uint32) error {
+	if err := dev.call("WriteGPIODirMasked", bank, dir, mask); err != nil {
+		return err
+	}
+	return dev.writeGPIO(bank, func(gpio *stubGPIOBank) { gpio.dir = gpio.dir&^mask | dir&mask })
+}
+
+// ReadGPIODir reads the direction of the pins of a GPIO bank.
+func (dev *StubDevice) ReadGPIODir(bank string) (uint32, error) {
+	if err := dev.call("ReadGPIODir", bank); err != nil {
+		return 0, err
+	}
+	if !slices.Contains(stubGPIOBanks, bank) {
+		return 0, fmt.Errorf("GPIO bank '%s' is invalid", bank)
+	}
+	return dev.gpio[bank].dir, nil
+}
+
+// writeGPIO applies update to the named GPIO bank.
+func (dev *StubDevice) writeGPIO(bank string, update func(*stubGPIOBank)) error {
+	if !slices.Contains(stubGPIOBanks, bank) {
+		return fmt.Errorf("GPIO bank '%s' is invalid", bank)
+	}
+	if dev.gpio == nil {
+		dev.gpio = make(map[string]stubGPIOBank)
+	}
+	gpio := dev.gpio[bank]
+	update(&gpio)
+	dev.gpio[bank] = gpio
+	return nil
+}
@@ -0,0 +1,61 @@
+package sdr
+
+import (
+	"time"
+)
+
+// GetTimeSources returns the time sources.
+func (dev *StubDevice) GetTimeSources() []string {
+	dev.call("GetTimeSources")
+	return []string{"internal", "gpsdo"}
+}
+
+// GetTimeSource returns the current time source.
+func (dev *StubDevice) GetTimeSource() string {
+	dev.call("GetTimeSource")
+	if dev.clock.timeSource == "" {
+		return "internal"
+	}
+	return dev.clock.timeSource
+}
+
+// SetTimeSource selects the time source.
+func (dev *StubDevice) SetTimeSource(source string) error {
+	if err := dev.call("SetTimeSource", source); err != nil {
+		return err
+	}
+	dev.clock.timeSource = source
+	return nil
+}
+
+// HasHardwareTime returns true for TimeNow and TimeNextPPS.
+func (dev *StubDevice) HasHardwareTime(what string) bool {
+	dev.call("HasHardwareTime", what)
+	return what == TimeNow || what == TimeNextPPS
+}
+
+// GetHardwareTime returns the current hardware time. The hardware time is 0 until it is set, then advances in real
+// time.
+func (dev *StubDevice) GetHardwareTime(what string) uint {
+	dev.call("GetHardwareTime", what)
+	return dev.hardwareTime()
+}
+
+// hardwareTime returns the current hardware time without recording a call.
+func (dev *StubDevice) hardwareTime() uint {
+	if dev.clock.hardwareTimeSetAt.IsZero() {
+		return 0
+	}
+	return dev.clock.hardwareTimeNs + uint(time.Since(dev.clock.hardwareTimeSetAt).Nanoseconds())
+}
+
+// SetHardwareTime sets the hardware time. StubDevice does not have a PPS input, so TimeNextPPS also sets the time
+// immediately.
+func (dev *StubDevice) SetHardwareTime(timeNs uint, what string) error {
+	if err := dev.call("SetHardwareTime", timeNs, what); err != nil {
+		return err
+	}
+	dev.clock.hardwareTimeNs = timeNs
+	dev.clock.hardwareTimeSetAt = time.Now()
+	return nil
+}
@@ -0,0 +1,74 @@
+package sdr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// stubRegisterInterfaces are the names of the StubDevice's register interfaces.
+var stubRegisterInterfaces = []string{"FPGA", "RFIC"}
+
+// GetRegisterInterfaces returns the names of the register interfaces.
+func (dev *StubDevice) GetRegisterInterfaces() []string {
+	dev.call("GetRegisterInterfaces")
+	return slices.Clone(stubRegisterInterfaces)
+}
+
+// WriteRegister writes a register of a register interface.
+func (dev *StubDevice) WriteRegister(name string, addr uint32, value uint32) error {
+	if err := dev.call("WriteRegister", name, addr, value); err != nil {
+		return err
+	}
+	return dev.writeRegisters(name, addr, []uint32{value})
+}
+
+// ReadRegister reads a register of a register interface. Registers that have not been written read 0.
+func (dev *StubDevice) ReadRegister(name string, addr uint32) (uint32, error) {
+	if err := dev.call("ReadRegister", name, addr); err != nil {
+		return 0, err
+	}
+	if !slices.Contains(stubRegisterInterfaces, name) {
+		return 0, fmt.Errorf("register interface '%s' is invalid", name)
+	}
+	return dev.registers[name][addr], nil
+}
+
+// WriteRegisters writes consecutive registers of a register interface.
+func (dev *StubDevice) WriteRegisters(name string, addr uint32, values []uint32) error {
+	if err := dev.call("WriteRegisters", name, addr, values); err != nil {
+		return err
+	}
+	return dev.writeRegisters(name, addr, values)
+}
+
+// writeRegisters stores values in consecutive registers of the named register interface.
+func (dev *StubDevice) writeRegisters(name string, addr uint32, values []uint32) error {
+	if !slices.Contains(stubRegisterInterfaces, name) {
+		return fmt.Errorf("register interface '%s' is invalid", name)
+	}
+	if dev.registers == nil {
+		dev.registers = make(map[string]map[uint32]uint32)
+	}
+	if dev.registers[name] == nil {
+		dev.registers[name] = make(map[uint32]uint32)
+	}
+	for i, value := range values {
+		dev.registers[name][addr+uint32(i)] = value
+	}
+	return nil
+}
+
+// ReadRegisters reads consecutive registers of a register interface. Registers that have not been written read 0.
+func (dev *StubDevice) ReadRegisters(name string, addr uint32, length uint) ([]uint32, error) {
+	if err := dev.call("ReadRegisters", name, addr, length); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(stubRegisterInterfaces, name) {
+		return nil, fmt.Errorf("register interface '%s' is invalid", name)
+	}
+	values := make([]uint32, length)
+	for i := range values {
+		values[i] = dev.registers[name][addr+uint32(i)]
+	}
+	return values, nil
+}
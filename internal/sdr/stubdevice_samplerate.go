@@ -1,14 +1,18 @@
 package sdr
 
 import (
-	"fmt"
+	"math"
 
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
+// stubDefaultSampleRate is the sample rate of a StubDevice until it is changed.
+const stubDefaultSampleRate = 2000000.0
+
 // GetSampleRateRange returns sample rate ranges that match an RTLSDR dongle.
 // If the Make has not been called for the device, then an empty slice is returned.
-func (dev *StubDevice) GetSampleRateRange(_ device.Direction, _ uint) []device.SDRRange {
+func (dev *StubDevice) GetSampleRateRange(direction device.Direction, channel uint) []device.SDRRange {
+	dev.call("GetSampleRateRange", direction, channel)
 	if dev.Device == nil {
 		return []device.SDRRange{}
 	}
@@ -16,34 +20,29 @@ func (dev *StubDevice) GetSampleRateRange(_ device.Direction, _ uint) []device.S
 		{Minimum: 900001, Maximum: 3200000}}
 }
 
-// GetSampleRate returns a sample rate.
-// If Make has not been called for the StubDevice, then 0.0 is returned. Otherwise, 2000000 is returned.
-func (dev *StubDevice) GetSampleRate(_ device.Direction, _ uint) float64 {
+// GetSampleRate returns the sample rate that was last set, or 2000000 if it has not been set.
+// If Make has not been called for the StubDevice, then 0.0 is returned.
+func (dev *StubDevice) GetSampleRate(direction device.Direction, channel uint) float64 {
+	dev.call("GetSampleRate", direction, channel)
 	if dev.Device == nil {
 		return 0.0
-	} else {
-		// returned sample rate is based on the device's serial number. This is for testing only!
-		switch dev.Args["serial"] {
-		case "1":
-			rate := 2000000.0
-			dev.Device.SampleRate = rate
-			return rate
-		default:
-			return 0.0
-		}
 	}
+	rate := dev.sampleRate
+	if rate == 0.0 {
+		rate = stubDefaultSampleRate
+	}
+	dev.Device.Channel(direction, channel).SampleRate = rate
+	return rate
 }
 
-func (dev *StubDevice) SetSampleRate(_ device.Direction, _ uint, rate float64) error {
-	// Err that is returned is based on the device's serial number. This is for testing only!
-	// Sets various conditions for testing sdr.GetSampleRate.
-	switch dev.Args["serial"] {
-	case "0":
-		dev.sampleRate = rate
-		return fmt.Errorf("attempt to set sample rate to %.1f failed. Sample rate is 2048000.0", rate)
-	case "1":
-		dev.sampleRate = rate
-		return nil
+// SetSampleRate sets the sample rate. Channel number is ignored here.
+//
+// Like a device whose sample rate is derived from a clock divider, the rate that is set is the requested rate
+// rounded to the nearest kHz.
+func (dev *StubDevice) SetSampleRate(direction device.Direction, channel uint, rate float64) error {
+	if err := dev.call("SetSampleRate", direction, channel, rate); err != nil {
+		return err
 	}
+	dev.sampleRate = math.Round(rate/1000.0) * 1000.0
 	return nil
 }
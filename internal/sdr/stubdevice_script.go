@@ -0,0 +1,106 @@
+package sdr
+
+import (
+	"slices"
+	"time"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// stubNsPerElement is the time between the timestamps of successive elements that are read from a StubDevice
+// stream, which is the time for a sample rate of 2 MS/s.
+const stubNsPerElement = 500
+
+// StubRead is the scripted result of one StubDevice stream read. See StubDevice.Reads.
+type StubRead struct {
+	// NumElems is the number of elements that are read, to simulate partial reads. If NumElems is 0, or is more
+	// than the number of elements that are requested, the requested number of elements are read.
+	NumElems uint
+	// Flags are returned together with StreamFlagHasTime, or on their own if Err is set.
+	Flags device.StreamFlag
+	// GapNs is added to the timestamp of the read, to simulate samples that have been lost.
+	GapNs uint
+	// Err is returned instead of reading any elements, for example ErrStreamTimeout or ErrStreamOverflow.
+	Err error
+}
+
+// StubCall is a call that has been made to a StubDevice method.
+type StubCall struct {
+	Method string
+	// Args are the arguments of the call, other than streams and buffers.
+	Args []any
+}
+
+// stubRead holds the state of the stream reads of a StubDevice.
+type stubRead struct {
+	// next is the index of the next scripted read in StubDevice.Reads.
+	next int
+	// timeNs is the timestamp of the next element to be read.
+	timeNs uint
+}
+
+// Calls returns the calls that have been made to the device's methods, in the order that they were made.
+func (dev *StubDevice) Calls() []StubCall {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return slices.Clone(dev.calls)
+}
+
+// CallCount returns the number of calls that have been made to the named method.
+func (dev *StubDevice) CallCount(method string) int {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	count := 0
+	for _, call := range dev.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// call records a call to the named method, sleeps for the method's injected latency, then returns the method's
+// injected error.
+func (dev *StubDevice) call(method string, args ...any) error {
+	dev.mutex.Lock()
+	dev.calls = append(dev.calls, StubCall{Method: method, Args: args})
+	latency, err := dev.Latency[method], dev.Errors[method]
+	dev.mutex.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	return err
+}
+
+// startReads sets the timestamp of the first element that is read after a stream is activated.
+func (dev *StubDevice) startReads(timeNs uint) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.read.timeNs = timeNs
+}
+
+// nextRead returns the result of a read of up to numElemsToRead elements: the timestamp of the first element, the
+// number of elements to read, and the flags and error to return.
+func (dev *StubDevice) nextRead(numElemsToRead uint) (timeNs uint, numElems uint, flags int, err error) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	var read StubRead
+	if dev.read.next < len(dev.Reads) {
+		read = dev.Reads[dev.read.next]
+		dev.read.next++
+		if dev.LoopReads && dev.read.next == len(dev.Reads) {
+			dev.read.next = 0
+		}
+	}
+	dev.read.timeNs += read.GapNs
+	if read.Err != nil {
+		return 0, 0, int(read.Flags), read.Err
+	}
+	numElems = numElemsToRead
+	if read.NumElems > 0 && read.NumElems < numElems {
+		numElems = read.NumElems
+	}
+	timeNs = dev.read.timeNs
+	dev.read.timeNs += numElems * stubNsPerElement
+	return timeNs, numElems, int(device.StreamFlagHasTime | read.Flags), nil
+}
@@ -0,0 +1,78 @@
+package sdr
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// stubSensors holds the info for the StubDevice's device sensors.
+var stubSensors = map[string]device.SDRArgInfo{
+	"temperature":  {Key: "temperature", Name: "Temperature", Unit: "C", Type: device.ArgInfoFloat},
+	"clock_source": {Key: "clock_source", Type: device.ArgInfoString, Value: "internal"},
+}
+
+// stubChannelSensors holds the info for the StubDevice's receive channel sensors.
+var stubChannelSensors = map[string]device.SDRArgInfo{
+	"lo_locked": {Key: "lo_locked", Name: "LO Locked", Type: device.ArgInfoBool, Value: "true"},
+	"rssi":      {Key: "rssi", Name: "RSSI", Unit: "dBm", Type: device.ArgInfoInt, Value: "-42"},
+}
+
+// GetSensorNames returns the keys of the device sensors.
+func (dev *StubDevice) GetSensorNames() []string {
+	dev.call("GetSensorNames")
+	return []string{"temperature", "clock_source"}
+}
+
+// GetSensorInfo returns the info for the specified device sensor.
+func (dev *StubDevice) GetSensorInfo(key string) device.SDRArgInfo {
+	dev.call("GetSensorInfo", key)
+	return stubSensors[key]
+}
+
+// ReadSensor reads the specified device sensor.
+//
+// The "temperature" sensor starts at 40 C and rises by 0.5 C each time that it is read, so that changes in sensor
+// readings can be tested.
+func (dev *StubDevice) ReadSensor(key string) (string, error) {
+	if err := dev.call("ReadSensor", key); err != nil {
+		return "", err
+	}
+	switch key {
+	case "temperature":
+		temperature := 40. + 0.5*float64(dev.temperatureReads)
+		dev.temperatureReads++
+		return strconv.FormatFloat(temperature, 'f', 1, 64), nil
+	case "clock_source":
+		return stubSensors[key].Value, nil
+	}
+	return "", fmt.Errorf("sensor '%s' does not exist", key)
+}
+
+// GetChannelSensorNames returns the keys of the channel sensors. Only the receive direction has sensors.
+func (dev *StubDevice) GetChannelSensorNames(direction device.Direction, channel uint) []string {
+	dev.call("GetChannelSensorNames", direction, channel)
+	if direction != device.DirectionRX {
+		return []string{}
+	}
+	return []string{"lo_locked", "rssi"}
+}
+
+// GetChannelSensorInfo returns the info for the specified channel sensor.
+func (dev *StubDevice) GetChannelSensorInfo(direction device.Direction, channel uint, key string) device.SDRArgInfo {
+	dev.call("GetChannelSensorInfo", direction, channel, key)
+	return stubChannelSensors[key]
+}
+
+// ReadChannelSensor reads the specified channel sensor.
+func (dev *StubDevice) ReadChannelSensor(direction device.Direction, channel uint, key string) (string, error) {
+	if err := dev.call("ReadChannelSensor", direction, channel, key); err != nil {
+		return "", err
+	}
+	info, ok := stubChannelSensors[key]
+	if !ok || direction != device.DirectionRX {
+		return "", fmt.Errorf("channel sensor '%s' does not exist", key)
+	}
+	return info.Value, nil
+}
@@ -0,0 +1,100 @@
+package sdr
+
+import (
+	"fmt"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// stubSettings holds the info for the StubDevice's device settings, which are those of an RTL-SDR dongle.
+var stubSettings = []device.SDRArgInfo{
+	{Key: "direct_samp", Name: "Direct Sampling", Type: device.ArgInfoString, Value: "0",
+		Options: []string{"0", "1", "2"}, OptionNames: []string{"Off", "I-ADC", "Q-ADC"}, NumOptions: 3},
+	{Key: "offset_tune", Name: "Offset Tune", Type: device.ArgInfoBool, Value: "false"},
+	{Key: "biastee", Name: "Bias Tee", Type: device.ArgInfoBool, Value: "false"},
+}
+
+// stubChannelSettings holds the info for the StubDevice's receive channel settings.
+var stubChannelSettings = []device.SDRArgInfo{
+	{Key: "if_gain", Name: "IF Gain", Unit: "dB", Type: device.ArgInfoInt, Value: "10",
+		Range: device.SDRRange{Minimum: 0, Maximum: 30, Step: 1}},
+	{Key: "xtal_ppm", Type: device.ArgInfoFloat, Value: "0"},
+}
+
+// GetSettingInfo returns the info for the device settings.
+func (dev *StubDevice) GetSettingInfo() []device.SDRArgInfo {
+	dev.call("GetSettingInfo")
+	return stubSettings
+}
+
+// ReadSetting returns the value of the specified device setting, or "" if the setting does not exist.
+func (dev *StubDevice) ReadSetting(key string) string {
+	dev.call("ReadSetting", key)
+	return dev.readSetting(stubSettings, key)
+}
+
+// WriteSetting writes a value to the specified device setting.
+//
+// Returns an error if the setting does not exist.
+func (dev *StubDevice) WriteSetting(key string, value string) error {
+	if err := dev.call("WriteSetting", key, value); err != nil {
+		return err
+	}
+	return dev.writeSetting(stubSettings, key, value)
+}
+
+// GetChannelSettingInfo returns the info for the channel settings. Only the receive direction has settings.
+func (dev *StubDevice) GetChannelSettingInfo(direction device.Direction, channel uint) []device.SDRArgInfo {
+	dev.call("GetChannelSettingInfo", direction, channel)
+	return stubChannelSettingInfo(direction)
+}
+
+// stubChannelSettingInfo returns the info for the channel settings of the specified direction.
+func stubChannelSettingInfo(direction device.Direction) []device.SDRArgInfo {
+	if direction != device.DirectionRX {
+		return []device.SDRArgInfo{}
+	}
+	return stubChannelSettings
+}
+
+// ReadChannelSetting returns the value of the specified channel setting, or "" if the setting does not exist.
+func (dev *StubDevice) ReadChannelSetting(direction device.Direction, channel uint, key string) string {
+	dev.call("ReadChannelSetting", direction, channel, key)
+	return dev.readSetting(stubChannelSettingInfo(direction), key)
+}
+
+// WriteChannelSetting writes a value to the specified channel setting.
+func (dev *StubDevice) WriteChannelSetting(direction device.Direction, channel uint, key string, value string) error {
+	if err := dev.call("WriteChannelSetting", direction, channel, key, value); err != nil {
+		return err
+	}
+	return dev.writeSetting(stubChannelSettingInfo(direction), key, value)
+}
+
+// readSetting returns the value that was written to the setting in infos with the specified key, or its default
+// value if no value has been written.
+func (dev *StubDevice) readSetting(infos []device.SDRArgInfo, key string) string {
+	for _, info := range infos {
+		if info.Key == key {
+			if value, ok := dev.settings[key]; ok {
+				return value
+			}
+			return info.Value
+		}
+	}
+	return ""
+}
+
+// writeSetting stores the value for the setting in infos with the specified key.
+func (dev *StubDevice) writeSetting(infos []device.SDRArgInfo, key string, value string) error {
+	for _, info := range infos {
+		if info.Key == key {
+			if dev.settings == nil {
+				dev.settings = make(map[string]string)
+			}
+			dev.settings[key] = value
+			return nil
+		}
+	}
+	return fmt.Errorf("setting '%s' does not exist", key)
+}
@@ -4,21 +4,22 @@ import (
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
-// GetStreamFormats returns a slice of stream formats.
-//
-// The formats returned either match those for RTL_SDR dongles, or no formats.
-func (dev *StubDevice) GetStreamFormats(_ device.Direction, _ uint) []string {
-	switch dev.Args["serial"] {
-	case "2":
+// GetStreamFormats returns StubDevice.StreamFormats, or the formats of an RTL-SDR dongle if they have not been
+// set.
+func (dev *StubDevice) GetStreamFormats(direction device.Direction, channel uint) []string {
+	dev.call("GetStreamFormats", direction, channel)
+	if dev.StreamFormats == nil {
 		return []string{"CS8", "CS16", "CF32"}
-	default:
-		return []string{}
 	}
+	return dev.StreamFormats
 }
 
-// GetNativeStreamFormat returns the native stream format and its full scale value.
-//
-// The returned values match those for an RTL_SDR device.
-func (dev *StubDevice) GetNativeStreamFormat(_ device.Direction, _ uint) (string, float64) {
-	return "CS8", 0.0
+// GetNativeStreamFormat returns StubDevice.NativeStreamFormat and StubDevice.NativeFullScale, or the native format
+// of an RTL-SDR dongle if they have not been set.
+func (dev *StubDevice) GetNativeStreamFormat(direction device.Direction, channel uint) (string, float64) {
+	dev.call("GetNativeStreamFormat", direction, channel)
+	if dev.NativeStreamFormat == "" {
+		return "CS8", 128.0
+	}
+	return dev.NativeStreamFormat, dev.NativeFullScale
 }
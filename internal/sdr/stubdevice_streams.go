@@ -1,34 +1,32 @@
 package sdr
 
 import (
-	"errors"
-	"time"
+	"fmt"
 
 	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
-var cs8EltsRead uint
-
+// SetupCS8Stream returns a fake CS8 stream.
 func (dev *StubDevice) SetupCS8Stream(direction device.Direction,
 	channels []uint,
 	args map[string]string) (*StreamCS8, error) {
-	// Device serial number is used to determine whether to return a valid
-	// StreamCS8 or an error.
-	switch dev.Args["serial"] {
-	case "1":
-		return nil, errors.New("bad args passed to SetupCS8Stream")
-	default:
-		// For test purposes, we are only interested that the stream exists, not
-		// it's specific values.
-		stream := &StreamCS8{stream: &device.SDRStreamCS8{}, device: dev}
-		return stream, nil
+	if err := dev.call("SetupCS8Stream", direction, channels, args); err != nil {
+		return nil, err
 	}
+	// For test purposes, we are only interested that the stream exists, not
+	// it's specific values.
+	stream := &StreamCS8{streamState: newStreamState(FormatCS8, direction, channels),
+		stream: &device.SDRStreamCS8{}, device: dev}
+	return stream, nil
 }
 
 // CloseCS8Stream closes the specified test stream.
 //
 // There is no actual stream, so just a bit of cleanup is done.
 func (dev *StubDevice) CloseCS8Stream(stream *StreamCS8) error {
+	if err := dev.call("CloseCS8Stream"); err != nil {
+		return err
+	}
 	stream.stream = nil
 	return nil
 }
@@ -38,72 +36,158 @@ func (dev *StubDevice) CloseCS8Stream(stream *StreamCS8) error {
 // As StubDevice is a test device, 10000 is returned. This matches the MTU value for
 // use in ReadCS8Stream, below
 func (dev *StubDevice) GetCS8MTU(stream *StreamCS8) int {
+	dev.call("GetCS8MTU")
 	return 10000
 }
 
-// Activate the specified stream. Since StubDevice is a test device, there
-// is not much activation to be done.
-func (dev *StubDevice) Activate(stream *StreamCS8, flag device.StreamFlag,
+// ActivateCS8Stream activates the specified stream. See activateStubStream.
+func (dev *StubDevice) ActivateCS8Stream(stream *StreamCS8, flag device.StreamFlag,
 	timeNs int, numElems int) error {
-	return nil
+	if err := dev.call("ActivateCS8Stream", flag, timeNs, numElems); err != nil {
+		return err
+	}
+	return dev.activateStubStream(flag, timeNs)
 }
 
-// Deactivate deactivates the specified stream. Since StubDevice is a test
+// DeactivateCS8Stream deactivates the specified stream. Since StubDevice is a test
 // device, there is not much to be done.
-func (dev *StubDevice) Deactivate(stream *StreamCS8, flag device.StreamFlag,
+func (dev *StubDevice) DeactivateCS8Stream(stream *StreamCS8, flag device.StreamFlag,
 	timeNs int) error {
-	switch dev.Args["serial"] {
-	case "2":
-		return errors.New("bad device")
-	default:
-		return nil
-	}
+	return dev.call("DeactivateCS8Stream", flag, timeNs)
 }
 
+// ReadCS8Stream fills buff with a fixed pattern of test data for each of the stream's channels.
+//
+// The values for each channel are scaled by the channel's index in buff plus 1, so that data for the
+// different channels can be distinguished. The number of elements read, the flags, and the errors are scripted
+// by StubDevice.Reads.
 func (dev *StubDevice) ReadCS8Stream(stream *StreamCS8, buff [][]int, numElemsToRead uint, outputFlags *[1]int, timeoutUs uint) (
 	timeNs uint, numElemsRead uint, err error) {
-	switch dev.Args["serial"] {
-	case "4":
-		switch cs8EltsRead {
-		case 0:
-			for i := 0; i < 5000/2; i++ {
-				buff[0][4*i] = -2
-				buff[0][4*i+1] = 0
-				buff[0][4*i+2] = -1
-				buff[0][4*i+3] = -2
-			}
-			cs8EltsRead = 5000
-			outputFlags[0] = 0
-			return uint(time.Now().UTC().Nanosecond()), 5000, nil
-		case 5000:
-			for i := 5000 / 2; i < 8000/2; i++ {
-				buff[0][4*i] = -2
-				buff[0][4*i+1] = 0
-				buff[0][4*i+2] = -1
-				buff[0][4*i+3] = -2
-			}
-			cs8EltsRead = 8000
-			outputFlags[0] = 0
-			return uint(time.Now().UTC().Nanosecond()), 3000, nil
-		case 8000:
-			for i := 8000 / 2; i < 10000/2; i++ {
-				buff[0][4*i] = -2
-				buff[0][4*i+1] = 0
-				buff[0][4*i+2] = -1
-				buff[0][4*i+3] = -2
-			}
-			cs8EltsRead = 0
-			outputFlags[0] = int(device.StreamFlagHasTime)
-			return uint(time.Now().UTC().Nanosecond()), 2000, nil
+	if err := dev.call("ReadCS8Stream"); err != nil {
+		outputFlags[0] = 0
+		return 0, 0, err
+	}
+	timeNs, numElemsRead, outputFlags[0], err = dev.nextRead(numElemsToRead)
+	fillStubBuffers(buff, numElemsRead, cs8Pattern)
+	return timeNs, numElemsRead, err
+}
+
+// activateStubStream starts the timestamps of the elements that are read at the activation time for a timed
+// activation, or at the hardware time otherwise.
+//
+// Timed activation returns an error if the activation time has already passed.
+func (dev *StubDevice) activateStubStream(flag device.StreamFlag, timeNs int) error {
+	now := dev.hardwareTime()
+	if flag&device.StreamFlagHasTime == 0 {
+		dev.startReads(now)
+		return nil
+	}
+	if uint(timeNs) < now {
+		return fmt.Errorf("activation time %d ns has passed. The hardware time is %d ns", timeNs, now)
+	}
+	dev.startReads(uint(timeNs))
+	return nil
+}
+
+// stubWriteSize is the maximum number of elements that StubDevice accepts in one write, so that larger writes
+// must be made in several parts.
+const stubWriteSize = 4000
+
+// stubTransmit holds the state of the burst that is being written to a StubDevice transmit stream.
+type stubTransmit struct {
+	// timeNs is the time of the first element of the burst.
+	timeNs uint
+	// elemsWritten is the number of elements of the burst that have been written.
+	elemsWritten uint
+	// burstEnded is true if the burst has ended, but its end has not yet been reported by ReadCS8StreamStatus.
+	burstEnded bool
+}
+
+// WriteCS8Stream accepts up to 4000 elements from each of the stream's buffers, and returns the number of elements
+// accepted.
+//
+// Timed writes return ErrStreamTimeError if the time has already passed.
+func (dev *StubDevice) WriteCS8Stream(stream *StreamCS8, buff [][]int, numElems uint, flags device.StreamFlag,
+	timeNs uint, timeoutUs uint) (uint, error) {
+	if err := dev.call("WriteCS8Stream", numElems, flags, timeNs); err != nil {
+		return 0, err
+	}
+	if flags&device.StreamFlagHasTime != 0 {
+		if timeNs < dev.hardwareTime() {
+			return 0, ErrStreamTimeError
 		}
-	default:
-		for i := 0; i < int(numElemsToRead/2); i++ {
-			buff[0][4*i] = -2
-			buff[0][4*i+1] = 0
-			buff[0][4*i+2] = -1
-			buff[0][4*i+3] = -2
+		dev.transmit = stubTransmit{timeNs: timeNs}
+	}
+	accepted := min(numElems, stubWriteSize)
+	dev.transmit.elemsWritten += accepted
+	// The burst ends with the last element, so only when all of the elements have been accepted.
+	if flags&device.StreamFlagEndBurst != 0 && accepted == numElems {
+		dev.transmit.burstEnded = true
+	}
+	return accepted, nil
+}
+
+// ReadCS8StreamStatus reports the end of a burst with the StreamFlagEndBurst and StreamFlagHasTime flags. The time
+// is that of the end of the burst, for a sample rate of 2 MS/s.
+//
+// ErrStreamTimeout is returned if no burst has ended since the last call.
+func (dev *StubDevice) ReadCS8StreamStatus(stream *StreamCS8, outputFlags *[1]int, timeoutUs uint) (uint, error) {
+	outputFlags[0] = 0
+	if err := dev.call("ReadCS8StreamStatus"); err != nil {
+		return 0, err
+	}
+	if !dev.transmit.burstEnded {
+		return 0, ErrStreamTimeout
+	}
+	outputFlags[0] = int(device.StreamFlagEndBurst | device.StreamFlagHasTime)
+	timeNs := dev.transmit.timeNs + dev.transmit.elemsWritten*500
+	dev.transmit = stubTransmit{}
+	return timeNs, nil
+}
+
+// cs8Pattern is the repeating pattern of CS8 values that StubDevice returns for channel index 0.
+var cs8Pattern = []int{-2, 0, -1, -2}
+
+// fillStubBuffers fills the start of each channel's buffer with numElems complex elements by repeating pattern.
+//
+// The values for each channel are scaled by the channel's index in buff plus 1.
+func fillStubBuffers[T int | int16](buff [][]T, numElems uint, pattern []T) {
+	for ch := range buff {
+		scale := T(ch + 1)
+		for i := 0; i < int(2*numElems); i++ {
+			buff[ch][i] = pattern[i%len(pattern)] * scale
+		}
+	}
+}
+
+// cu8Pattern is the repeating pattern of CU8 values that StubDevice returns for channel index 0.
+var cu8Pattern = []uint8{126, 128, 127, 126}
+
+// fillStubCU8Buffers fills the start of each channel's buffer with numElems complex elements by repeating
+// cu8Pattern.
+//
+// The offsets of the values from 128 are scaled by the channel's index in buff plus 1.
+func fillStubCU8Buffers(buff [][]uint8, numElems uint) {
+	for ch := range buff {
+		scale := ch + 1
+		for i := 0; i < int(2*numElems); i++ {
+			buff[ch][i] = uint8(128 + (int(cu8Pattern[i%len(cu8Pattern)])-128)*scale)
+		}
+	}
+}
+
+// cs16Pattern is the repeating pattern of CS16 values that StubDevice returns for channel index 0.
+var cs16Pattern = []int16{-512, 0, -256, -512}
+
+// fillStubCF32Buffers fills the start of each channel's buffer with numElems complex elements.
+//
+// The values for each channel are scaled by the channel's index in buff plus 1.
+func fillStubCF32Buffers(buff [][]complex64, numElems uint) {
+	pattern := []complex64{complex(-0.25, 0), complex(-0.125, -0.25)}
+	for ch := range buff {
+		scale := complex(float32(ch+1), 0)
+		for i := 0; i < int(numElems); i++ {
+			buff[ch][i] = pattern[i%len(pattern)] * scale
 		}
 	}
-	outputFlags[0] = int(device.StreamFlagHasTime)
-	return uint(time.Now().UTC().Nanosecond()), numElemsToRead, nil
 }
@@ -0,0 +1,132 @@
+package sdr_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubDevice_Calls(t *testing.T) {
+	stub := sdr.StubDevice{}
+	require.Nil(t, stub.SetOverallCenterFrequency(device.DirectionRX, 1, 433.92e6, nil))
+	assert.Equal(t, 433.92e6, stub.GetOverallCenterFrequency(device.DirectionRX, 1))
+	require.Nil(t, stub.SetOverallGain(device.DirectionRX, 0, 30.))
+
+	assert.Equal(t, []sdr.StubCall{
+		{Method: "SetOverallCenterFrequency", Args: []any{device.DirectionRX, uint(1), 433.92e6, map[string]string(nil)}},
+		{Method: "GetOverallCenterFrequency", Args: []any{device.DirectionRX, uint(1)}},
+		{Method: "SetOverallGain", Args: []any{device.DirectionRX, uint(0), 30.}},
+	}, stub.Calls())
+	assert.Equal(t, 1, stub.CallCount("SetOverallGain"))
+	assert.Equal(t, 0, stub.CallCount("SetAntenna"))
+}
+
+func TestStubDevice_Errors(t *testing.T) {
+	testLogger, _ := logger.NewFileLogger("stdout")
+	stub := sdr.StubDevice{Errors: map[string]error{"SetAntenna": errors.New("antenna switch failed")}}
+	err := sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 1, "RX2")
+	require.NotNil(t, err)
+	assert.Equal(t, "antenna switch failed", err.Error())
+	assert.Equal(t, "RX", sdr.GetCurrentAntenna(&stub, testLogger, device.DirectionRX, 1))
+	assert.Equal(t, 1, stub.CallCount("SetAntenna"))
+
+	delete(stub.Errors, "SetAntenna")
+	require.Nil(t, sdr.SetAntenna(&stub, testLogger, device.DirectionRX, 1, "RX2"))
+	assert.Equal(t, "RX2", sdr.GetCurrentAntenna(&stub, testLogger, device.DirectionRX, 1))
+}
+
+func TestStubDevice_Latency(t *testing.T) {
+	stub := sdr.StubDevice{Latency: map[string]time.Duration{"SetSampleRate": 20 * time.Millisecond}}
+	start := time.Now()
+	require.Nil(t, stub.SetSampleRate(device.DirectionRX, 0, 1.024e6))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	start = time.Now()
+	stub.GetSampleRate(device.DirectionRX, 0)
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestStubDevice_Reads(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{
+		{NumElems: 100},
+		{GapNs: 1000000, Flags: device.StreamFlagEndBurst},
+		{Err: sdr.ErrStreamOverflow, Flags: device.StreamFlagEndAbrupt},
+	}}
+	stream, err := sdr.SetupCS8Stream(&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stub.ActivateCS8Stream(stream, 0, 0, 0))
+	buffer := [][]int{make([]int, 2000)}
+	var outputFlags [1]int
+
+	timeNs, numElemsRead, err := stub.ReadCS8Stream(stream, buffer, 1000, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(0), timeNs)
+	assert.Equal(t, uint(100), numElemsRead)
+	assert.Equal(t, int(device.StreamFlagHasTime), outputFlags[0])
+
+	// Elements are 500 ns apart, and the second read follows a 1 ms gap.
+	timeNs, numElemsRead, err = stub.ReadCS8Stream(stream, buffer, 1000, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(100*500+1000000), timeNs)
+	assert.Equal(t, uint(1000), numElemsRead)
+	assert.Equal(t, int(device.StreamFlagHasTime|device.StreamFlagEndBurst), outputFlags[0])
+
+	_, numElemsRead, err = stub.ReadCS8Stream(stream, buffer, 1000, &outputFlags, 0)
+	assert.ErrorIs(t, err, sdr.ErrStreamOverflow)
+	assert.Equal(t, uint(0), numElemsRead)
+	assert.Equal(t, int(device.StreamFlagEndAbrupt), outputFlags[0])
+
+	// Once the scripted reads have been used, every read returns the requested number of elements.
+	timeNs, numElemsRead, err = stub.ReadCS8Stream(stream, buffer, 1000, &outputFlags, 0)
+	require.Nil(t, err)
+	assert.Equal(t, uint(1100*500+1000000), timeNs)
+	assert.Equal(t, uint(1000), numElemsRead)
+	assert.Equal(t, 4, stub.CallCount("ReadCS8Stream"))
+}
+
+func TestStubDevice_LoopReads(t *testing.T) {
+	var log strings.Builder
+	testLogger := logger.New(&log)
+	stub := sdr.StubDevice{Reads: []sdr.StubRead{{NumElems: 10}, {NumElems: 20}}, LoopReads: true}
+	stream, err := sdr.SetupFormatStream[int16](&stub, testLogger, device.DirectionRX, []uint{0})
+	require.Nil(t, err)
+	defer stream.Close(testLogger)
+	require.Nil(t, stream.Activate(testLogger, 0, 0, 0))
+	buffer := [][]int16{make([]int16, 200)}
+	var outputFlags [1]int
+	for _, expected := range []uint{10, 20, 10, 20} {
+		_, numElemsRead, err := stream.ReadFromStream(testLogger, buffer, expected, &outputFlags, 0)
+		require.Nil(t, err)
+		assert.Equal(t, expected, numElemsRead)
+	}
+	// Each read is satisfied by a single scripted device read.
+	assert.Equal(t, 4, stub.CallCount("ReadCS16Stream"))
+}
+
+// TestStubDevice_Independent checks that the state of each StubDevice is its own, so that tests that use stubs can
+// run in parallel.
+func TestStubDevice_Independent(t *testing.T) {
+	for _, freq := range []float64{88.5e6, 145e6, 433.92e6} {
+		t.Run(strconv.FormatFloat(freq, 'f', 0, 64), func(t *testing.T) {
+			t.Parallel()
+			stub := sdr.StubDevice{}
+			for range 100 {
+				require.Nil(t, stub.SetOverallCenterFrequency(device.DirectionRX, 0, freq, nil))
+				require.Nil(t, stub.EnableAgc(device.DirectionRX, 0, freq > 100e6))
+				assert.Equal(t, freq, stub.GetOverallCenterFrequency(device.DirectionRX, 0))
+				assert.Equal(t, freq > 100e6, stub.AgcIsEnabled(device.DirectionRX, 0))
+			}
+		})
+	}
+}
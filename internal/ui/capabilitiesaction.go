@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+)
+
+// makeCapabilitiesAction creates the toolbar action that displays the capabilities of the selected SDR.
+func makeCapabilitiesAction() *widget.ToolbarAction {
+	return widget.NewToolbarAction(theme.InfoIcon(), capabilitiesCallback)
+}
+
+// capabilitiesCallback displays the capabilities of the selected SDR as JSON.
+func capabilitiesCallback() {
+	jsdrLogger.Log(logger.Debug, "In capabilitiesCallback\n")
+	dev, selected := selectedDevice()
+	capsDev, ok := dev.(sdr.CapabilitiesDevice)
+	if !ok || selected == nil {
+		noSdr := dialog.NewInformation("No Selected SDR", "Select an SDR in the settings dialog, then try again.",
+			mainWin)
+		noSdr.Show()
+		return
+	}
+	var caps sdr.Capabilities
+	if err := control(func(_ sdr.ControlDevice) error {
+		caps = selected.GetCapabilities(capsDev, jsdrLogger)
+		return nil
+	}); err != nil {
+		dialog.NewError(err, mainWin).Show()
+		return
+	}
+	data, err := caps.JSON()
+	if err != nil {
+		jsdrLogger.Logf(logger.Error, "Could not convert capabilities to JSON: %s\n", err.Error())
+		dialog.NewError(errors.New("could not display the SDR's capabilities"), mainWin).Show()
+		return
+	}
+	text := widget.NewLabelWithStyle(string(data), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	scroll := container.NewScroll(text)
+	scroll.SetMinSize(fyne.NewSize(500, 400))
+	dialog.NewCustom("SDR Capabilities", "Close", scroll, mainWin).Show()
+}
+
+// selectedSdr returns the Sdr that was made for dev, or nil if dev has not been made.
+func selectedSdr(dev settingsDevice) *sdr.Sdr {
+	switch d := dev.(type) {
+	case *sdr.SoapyDevice:
+		return d.Device
+	case *sdr.FileDevice:
+		return d.Device
+	case *sdr.RtlTcpDevice:
+		return d.Device
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// controlTimeout is the longest time that the UI waits for an operation on the selected SDR to complete.
+const controlTimeout = 2 * time.Second
+
+var deviceController *sdr.DeviceController
+var stopController context.CancelFunc
+
+// startDeviceController starts the controller that serializes the operations on dev, the device that was made for the
+// selected SDR. It must be called with selMutex held.
+func startDeviceController(dev settingsDevice) {
+	stopDeviceController()
+	ctx, cancel := context.WithCancel(context.Background())
+	deviceController = sdr.NewDeviceController(dev, jsdrLogger)
+	stopController = cancel
+	deviceController.Start(ctx)
+}
+
+// stopDeviceController stops the controller for the selected SDR. Call stopDeviceController before unmaking the
+// device. It must be called with selMutex held.
+func stopDeviceController() {
+	if deviceController != nil {
+		stopController()
+		deviceController.Wait()
+		deviceController, stopController = nil, nil
+	}
+}
+
+// control runs op on the controller for the selected SDR, so that the UI's operations on the device are serialized
+// with those of any other go routine. op may use the other interfaces of the selected device, such as
+// sdr.FrontendCorrections, as well as sdrD.
+//
+// Returns an error if no SDR is selected, op does not complete within controlTimeout, or op returns an error.
+func control(op func(sdrD sdr.ControlDevice) error) error {
+	selMutex.Lock()
+	controller := deviceController
+	selMutex.Unlock()
+	return controlWith(controller, op)
+}
+
+// controlSampleRate sets the receive sample rate of the selected SDR through its controller, so that the stream
+// taggers see the change.
+//
+// Returns an error if no SDR is selected, the sample rate is not set within controlTimeout, or it cannot be set.
+func controlSampleRate(rate float64) error {
+	selMutex.Lock()
+	controller := deviceController
+	selMutex.Unlock()
+	if controller == nil {
+		return errors.New("no SDR has been selected")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	return controller.SetSampleRate(ctx, device.DirectionRX, rxChannel, rate)
+}
+
+// controlWith runs op on controller. It is used instead of control while selMutex is held, and on go routines that
+// must not wait for selMutex. See control.
+func controlWith(controller *sdr.DeviceController, op func(sdrD sdr.ControlDevice) error) error {
+	if controller == nil {
+		return errors.New("no SDR has been selected")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	return controller.Do(ctx, op)
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// deviceSettingsForm holds a control for each of the selected SDR's device specific settings.
+var deviceSettingsForm *widget.Form
+
+// makeDeviceSettingsForm creates the form that holds the controls for the selected SDR's device specific settings.
+func makeDeviceSettingsForm() *widget.Form {
+	deviceSettingsForm = widget.NewForm()
+	return deviceSettingsForm
+}
+
+// updateDeviceSettings replaces the controls in the device settings form with controls for the device and receive
+// channel settings of dev. The form is empty if dev does not have settings. If dev is not nil, updateDeviceSettings
+// must be called with selMutex held.
+func updateDeviceSettings(dev settingsDevice) {
+	deviceSettingsForm.Items = nil
+	settingsDev, ok := dev.(sdr.Settings)
+	if ok {
+		var settings []sdr.Setting
+		var values []string
+		// The readings are only used if the operation completed, because it may still be running if it timed out.
+		err := controlWith(deviceController, func(_ sdr.ControlDevice) error {
+			settings = sdr.GetSettings(settingsDev, jsdrLogger)
+			settings = append(settings, sdr.GetChannelSettings(settingsDev, jsdrLogger, device.DirectionRX,
+				rxChannel)...)
+			values = make([]string, 0, len(settings))
+			for _, setting := range settings {
+				values = append(values, sdr.ReadSetting(settingsDev, jsdrLogger, setting))
+			}
+			return nil
+		})
+		if err == nil {
+			for i, setting := range settings {
+				item := widget.NewFormItem(setting.Name, makeSettingControl(settingsDev, setting, values[i]))
+				item.HintText = setting.Description
+				deviceSettingsForm.AppendItem(item)
+			}
+		}
+	}
+	deviceSettingsForm.Refresh()
+}
+
+// makeSettingControl creates a control that displays the setting's value and writes changes to the device: a check
+// for SettingBool, a select for SettingEnum, and an entry for the other kinds of setting.
+func makeSettingControl(dev sdr.Settings, setting sdr.Setting, value string) fyne.CanvasObject {
+	switch setting.Kind {
+	case sdr.SettingBool:
+		check := widget.NewCheck(setting.Unit, nil)
+		check.Checked = value == "true"
+		check.OnChanged = func(checked bool) {
+			if !writeSetting(dev, setting, strconv.FormatBool(checked)) {
+				// Setting Checked directly, rather than calling SetChecked, does not call OnChanged again.
+				check.Checked = readSetting(dev, setting) == "true"
+				check.Refresh()
+			}
+		}
+		return check
+	case sdr.SettingEnum:
+		sel := widget.NewSelect(setting.OptionNames, nil)
+		sel.Selected = optionName(setting, value)
+		sel.OnChanged = func(name string) {
+			for i, n := range setting.OptionNames {
+				if n == name && !writeSetting(dev, setting, setting.Options[i]) {
+					sel.Selected = optionName(setting, readSetting(dev, setting))
+					sel.Refresh()
+				}
+			}
+		}
+		return sel
+	default:
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		if setting.Kind != sdr.SettingString {
+			entry.Validator = func(text string) error {
+				_, err := setting.Validate(text)
+				return err
+			}
+		}
+		entry.OnSubmitted = func(text string) {
+			writeSetting(dev, setting, text)
+			entry.SetText(readSetting(dev, setting))
+		}
+		return entry
+	}
+}
+
+// optionName returns the displayable name of the setting's option with the specified value, or "" if value is not
+// one of the setting's options.
+func optionName(setting sdr.Setting, value string) string {
+	for i, option := range setting.Options {
+		if option == value {
+			return setting.OptionNames[i]
+		}
+	}
+	return ""
+}
+
+// readSetting reads the value of a setting through the selected SDR's controller. Returns "" if the setting could not
+// be read.
+func readSetting(dev sdr.Settings, setting sdr.Setting) string {
+	var value string
+	if err := control(func(_ sdr.ControlDevice) error {
+		value = sdr.ReadSetting(dev, jsdrLogger, setting)
+		return nil
+	}); err != nil {
+		return ""
+	}
+	return value
+}
+
+// writeSetting writes a value to a setting through the selected SDR's controller, and displays an error dialog if the
+// value could not be written.
+//
+// Returns true if the value was written.
+func writeSetting(dev sdr.Settings, setting sdr.Setting, value string) bool {
+	jsdrLogger.Logf(logger.Debug, "Setting %s changed to %s\n", setting.Key, value)
+	if err := control(func(_ sdr.ControlDevice) error {
+		return sdr.WriteSetting(dev, jsdrLogger, setting, value)
+	}); err != nil {
+		dialog.NewError(err, mainWin).Show()
+		return false
+	}
+	return true
+}
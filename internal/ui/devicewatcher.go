@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+)
+
+// deviceWatchInterval is the time between checks for SDRs that have been attached or removed.
+const deviceWatchInterval = 2 * time.Second
+
+var deviceWatcher *sdr.DeviceWatcher
+var stopDeviceWatcher context.CancelFunc
+
+// startDeviceWatcher starts watching for SoapySDR devices that are attached or removed.
+func startDeviceWatcher() {
+	ctx, cancel := context.WithCancel(context.Background())
+	deviceWatcher = sdr.NewDeviceWatcher(SoapyDev, jsdrLogger, deviceWatchInterval, devicesChanged)
+	stopDeviceWatcher = cancel
+	deviceWatcher.Start(ctx)
+}
+
+// StopDeviceWatcher stops watching for SDRs that are attached or removed.
+func StopDeviceWatcher() {
+	if deviceWatcher != nil {
+		stopDeviceWatcher()
+		deviceWatcher.Wait()
+		deviceWatcher, stopDeviceWatcher = nil, nil
+	}
+}
+
+// watchSelectedDevice watches the device that was made for the selected SDR, so that it is unmade if the SDR is
+// removed. Only SoapySDR devices are watched. It must be called with selMutex held.
+func watchSelectedDevice(args map[string]string) {
+	if selDevice == SoapyDev && deviceWatcher != nil {
+		deviceWatcher.WatchMadeDevice(SoapyDev, args, releaseSelectedDevice)
+	}
+}
+
+// releaseSelectedDevice stops using the device that was made for the selected SDR and clears the selection, so that
+// the watcher can unmake the device. It is called on the watcher's go routine.
+func releaseSelectedDevice() {
+	selMutex.Lock()
+	defer selMutex.Unlock()
+	stopStreamStatus()
+	stopSensorPanel()
+	stopDeviceController()
+	selDevice, selSdr = nil, nil
+}
+
+// unwatchSelectedDevice stops watching the device that was made for the selected SDR. If the watcher is unmaking the
+// device because the SDR was removed, unwatchSelectedDevice waits until the device has been unmade. It must not be
+// called with selMutex held.
+func unwatchSelectedDevice() {
+	if deviceWatcher != nil {
+		deviceWatcher.UnwatchMadeDevice()
+	}
+}
+
+// devicesChanged logs the SDRs that have been attached or removed, and tells the user if the selected SDR has been
+// removed. It is called on the watcher's go routine.
+func devicesChanged(events []sdr.DeviceEvent) {
+	for _, event := range events {
+		jsdrLogger.Logf(logger.Debug, "SDR %s\n", event.String())
+		if event.Unmade {
+			removed := dialog.NewInformation("SDR Removed",
+				fmt.Sprintf("%s has been removed.\nSelect an SDR in the settings dialog.", event.Args["label"]),
+				mainWin)
+			removed.Show()
+		}
+	}
+}
@@ -16,9 +16,13 @@ func MakeMainWindow(jsdrApp *fyne.App, log *logger.Logger) fyne.Window {
 
 	jsdrLogger.Log(logger.Debug, "Creating main window content\n")
 	settingsAction := makeSettingsAction()
-	toolbar := widget.NewToolbar(settingsAction)
-	mainWin.SetContent(container.NewBorder(toolbar, nil, nil, nil))
+	capabilitiesAction := makeCapabilitiesAction()
+	toolbar := widget.NewToolbar(settingsAction, capabilitiesAction)
+	sensorPanel := makeSensorPanel()
+	statusBar := makeStatusBar()
+	mainWin.SetContent(container.NewBorder(toolbar, statusBar, nil, sensorPanel))
 	mainWin.Resize(fyne.NewSize(800, 400))
+	startDeviceWatcher()
 	jsdrLogger.Log(logger.Debug, "Main window content created\n")
 	return mainWin
 }
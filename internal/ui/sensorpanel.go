@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// sensorPollInterval is the time between readings of the selected SDR's sensors.
+const sensorPollInterval = time.Second
+
+// sensorsForm holds a row for each of the selected SDR's sensors.
+var sensorsForm *widget.Form
+
+// sensorLabels holds the label that displays each sensor's reading. It is only changed while the poller is stopped.
+var sensorLabels map[sdr.Sensor]*widget.Label
+var sensorPoller *sdr.SensorPoller
+var stopSensorPoller context.CancelFunc
+
+// makeSensorPanel creates the status panel that displays the selected SDR's sensor readings.
+func makeSensorPanel() fyne.CanvasObject {
+	jsdrLogger.Log(logger.Debug, "Creating the sensor status panel\n")
+	sensorsForm = widget.NewForm()
+	return widget.NewCard("Sensors", "", container.NewVScroll(sensorsForm))
+}
+
+// startSensorPanel displays the sensors of dev and starts polling them through the selected SDR's controller. Nothing
+// is displayed if dev does not have sensors. It must be called with selMutex held.
+func startSensorPanel(dev settingsDevice) {
+	stopSensorPanel()
+	sensorsDev, ok := dev.(sdr.Sensors)
+	if !ok {
+		return
+	}
+	var sensors []sdr.Sensor
+	// The sensors are only used if the operation completed, because it may still be running if it timed out.
+	if controlWith(deviceController, func(_ sdr.ControlDevice) error {
+		sensors = sdr.GetSensors(sensorsDev, jsdrLogger, device.DirectionRX, rxChannel)
+		return nil
+	}) != nil {
+		return
+	}
+	sensorLabels = make(map[sdr.Sensor]*widget.Label)
+	for _, sensor := range sensors {
+		label := widget.NewLabel("")
+		sensorLabels[sensor] = label
+		sensorsForm.Append(sensor.String(), label)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	controlled := controlledSensors{Sensors: sensorsDev, controller: deviceController}
+	sensorPoller = sdr.NewSensorPoller(controlled, jsdrLogger, sensors, sensorPollInterval, sensorsChanged)
+	stopSensorPoller = cancel
+	sensorPoller.Start(ctx)
+}
+
+// stopSensorPanel stops polling the sensors and removes them from the panel.
+func stopSensorPanel() {
+	if sensorPoller != nil {
+		stopSensorPoller()
+		sensorPoller.Wait()
+		sensorPoller, stopSensorPoller = nil, nil
+	}
+	sensorLabels = nil
+	if sensorsForm != nil {
+		sensorsForm.Items = nil
+		sensorsForm.Refresh()
+	}
+}
+
+// sensorsChanged displays the sensor readings that have changed. It is called on the poller's go routine.
+func sensorsChanged(values []sdr.SensorValue) {
+	for _, value := range values {
+		if label, ok := sensorLabels[value.Sensor]; ok {
+			label.SetText(value.String())
+		}
+	}
+}
+
+// controlledSensors reads sensors through a DeviceController, so that the poller's readings are serialized with the
+// other operations on the selected SDR. A sensor cannot be read once the controller has stopped.
+type controlledSensors struct {
+	sdr.Sensors
+	controller *sdr.DeviceController
+}
+
+func (s controlledSensors) GetSensorInfo(key string) device.SDRArgInfo {
+	var info device.SDRArgInfo
+	if controlWith(s.controller, func(_ sdr.ControlDevice) error {
+		info = s.Sensors.GetSensorInfo(key)
+		return nil
+	}) != nil {
+		return device.SDRArgInfo{}
+	}
+	return info
+}
+
+func (s controlledSensors) ReadSensor(key string) (value string, err error) {
+	if ctrlErr := controlWith(s.controller, func(_ sdr.ControlDevice) error {
+		value, err = s.Sensors.ReadSensor(key)
+		return nil
+	}); ctrlErr != nil {
+		return "", ctrlErr
+	}
+	return value, err
+}
+
+func (s controlledSensors) GetChannelSensorInfo(direction device.Direction, channel uint,
+	key string) device.SDRArgInfo {
+	var info device.SDRArgInfo
+	if controlWith(s.controller, func(_ sdr.ControlDevice) error {
+		info = s.Sensors.GetChannelSensorInfo(direction, channel, key)
+		return nil
+	}) != nil {
+		return device.SDRArgInfo{}
+	}
+	return info
+}
+
+func (s controlledSensors) ReadChannelSensor(direction device.Direction, channel uint, key string) (value string,
+	err error) {
+	if ctrlErr := controlWith(s.controller, func(_ sdr.ControlDevice) error {
+		value, err = s.Sensors.ReadChannelSensor(direction, channel, key)
+		return nil
+	}); ctrlErr != nil {
+		return "", ctrlErr
+	}
+	return value, err
+}
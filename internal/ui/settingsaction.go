@@ -1,6 +1,10 @@
 package ui
 
 import (
+	"strconv"
+	"strings"
+	"sync"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -9,6 +13,8 @@ import (
 
 	"github.com/jimorc/jsdr/internal/logger"
 	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
 )
 
 var sdrsSelect *widget.Select
@@ -17,9 +23,38 @@ var sdrsSelect *widget.Select
 var sdrs map[string]map[string]string
 var selSdr *sdr.Sdr
 var sampleRatesSelect *widget.Select
+
+// sampleRates are the sample rates in sampleRatesSelect, in the same order as its options.
+var sampleRates []float64
 var antennaSelect *widget.Select
+var autoDCCheck *widget.Check
+var ppmEntry *widget.Entry
+var ppmDown *widget.Button
+var ppmUp *widget.Button
 var SoapyDev = &sdr.SoapyDevice{}
 
+// FileDev plays back the IQ recordings in FileDev.Dir as though they were attached SDRs.
+var FileDev = &sdr.FileDevice{}
+
+// RtlTcpDev connects to the rtl_tcp servers in RtlTcpDev.Servers.
+var RtlTcpDev = &sdr.RtlTcpDevice{}
+
+// settingsDevice is the set of interfaces that the settings dialog uses.
+type settingsDevice interface {
+	sdr.MakeDevice
+	sdr.ControlDevice
+}
+
+// selDevice is the device that was made for the selected SDR, or nil if no SDR has been selected.
+var selDevice settingsDevice
+
+// selMutex guards selDevice and selSdr, and everything that is started for the selected SDR. The device watcher
+// releases the selected SDR on its own go routine when the SDR is removed.
+var selMutex sync.Mutex
+
+// rxChannel is the receive channel of the selected SDR that the settings apply to.
+var rxChannel uint
+
 func makeSettingsAction() *widget.ToolbarAction {
 	jsdrLogger.Log(logger.Debug, "Entered ui.makeSettingsAction\n")
 	action := widget.NewToolbarAction(theme.SettingsIcon(), settingsCallback)
@@ -30,6 +65,11 @@ func makeSettingsAction() *widget.ToolbarAction {
 func settingsCallback() {
 	jsdrLogger.Log(logger.Debug, "In settingsCallback\n")
 	sdrs = sdr.EnumerateWithoutAudio(SoapyDev, jsdrLogger)
+	for _, dev := range []sdr.Enumerate{FileDev, RtlTcpDev} {
+		for label, args := range sdr.EnumerateWithoutAudio(dev, jsdrLogger) {
+			sdrs[label] = args
+		}
+	}
 	jsdrLogger.Logf(logger.Debug, "Number of sdr devices returned from EnumerateWithoutAudio: %d\n", len(sdrs))
 	if len(sdrs) == 0 {
 		noDevices := dialog.NewInformation("No Attached SDRs",
@@ -50,9 +90,17 @@ func settingsCallback() {
 		antennaLabel := widget.NewLabel("Antenna:")
 		antennaLabel.Alignment = fyne.TextAlignTrailing
 		antennaSelect = widget.NewSelect([]string{}, antennaChanged)
+		autoDCLabel := widget.NewLabel("Auto DC Offset:")
+		autoDCLabel.Alignment = fyne.TextAlignTrailing
+		autoDCCheck = widget.NewCheck("", autoDCChanged)
+		ppmLabel := widget.NewLabel("Frequency Correction (PPM):")
+		ppmLabel.Alignment = fyne.TextAlignTrailing
+		ppmSpinner := makePPMSpinner()
+		updateCorrections(nil)
 		grid := container.NewGridWithColumns(2, sdrsLabel, sdrsSelect, sampleRateLabel, sampleRatesSelect,
-			antennaLabel, antennaSelect)
-		settings := dialog.NewCustomConfirm("SDR Settings", "Accept", "Close", grid, settingsDialogCallback, mainWin)
+			antennaLabel, antennaSelect, autoDCLabel, autoDCCheck, ppmLabel, ppmSpinner)
+		content := container.NewVBox(grid, widget.NewSeparator(), makeDeviceSettingsForm())
+		settings := dialog.NewCustomConfirm("SDR Settings", "Accept", "Close", content, settingsDialogCallback, mainWin)
 		settings.Show()
 		if len(sdrLabels) == 1 {
 			sdrsSelect.SetSelectedIndex(0)
@@ -62,6 +110,14 @@ func settingsCallback() {
 
 func antennaChanged(antenna string) {
 	jsdrLogger.Logf(logger.Debug, "Antenna selected: %s\n", antenna)
+	if dev, _ := selectedDevice(); dev == nil {
+		return
+	}
+	if err := control(func(sdrD sdr.ControlDevice) error {
+		return sdr.SetAntenna(sdrD, jsdrLogger, device.DirectionRX, rxChannel, antenna)
+	}); err != nil {
+		dialog.NewError(err, mainWin).Show()
+	}
 }
 
 func settingsDialogCallback(accept bool) {
@@ -71,28 +127,216 @@ func settingsDialogCallback(accept bool) {
 func sdrChanged(value string) {
 	jsdrLogger.Logf(logger.Debug, "SDR selected: %s\n", value)
 	devProps := sdrs[value]
-	if SoapyDev.Device != nil {
-		sdr.Unmake(SoapyDev, jsdrLogger)
+	UnmakeDevice(jsdrLogger)
+	// The device watcher waits for the selected SDR to be set up before it can release it.
+	selMutex.Lock()
+	defer selMutex.Unlock()
+	var dev settingsDevice = SoapyDev
+	switch devProps["driver"] {
+	case "file":
+		dev = FileDev
+	case "rtltcp":
+		dev = RtlTcpDev
 	}
-	err := sdr.Make(SoapyDev, devProps, jsdrLogger)
+	err := sdr.Make(dev, devProps, jsdrLogger)
 	if err != nil {
 		errDialog := dialog.NewError(err, mainWin)
 		errDialog.Show()
 	} else {
-
-		sampleRatesSelect.Options = sdr.GetSampleRates(SoapyDev, jsdrLogger)
-		sampleRatesSelect.Selected = sdr.GetSampleRate(SoapyDev, jsdrLogger)
-		sampleRatesSelect.Refresh()
-		antennaSelect.Options = sdr.GetAntennaNames(SoapyDev, jsdrLogger)
-		if len(antennaSelect.Options) == 1 {
-			antennaSelect.SetSelectedIndex(0)
-		} else {
-			antennaSelect.SetSelected(sdr.GetCurrentAntenna(SoapyDev, jsdrLogger))
+		selDevice = dev
+		selSdr = selectedSdr(dev)
+		startDeviceController(dev)
+		watchSelectedDevice(devProps)
+		var rates []float64
+		var currentRate float64
+		var antennas []string
+		var antenna string
+		// The readings are only used if the operation completed, because it may still be running if it timed out.
+		if err := controlWith(deviceController, func(sdrD sdr.ControlDevice) error {
+			rates = sdr.GetSampleRates(sdrD, jsdrLogger, device.DirectionRX, rxChannel)
+			currentRate = sdr.GetSampleRate(sdrD, jsdrLogger, device.DirectionRX, rxChannel)
+			antennas = sdr.GetAntennaNames(sdrD, jsdrLogger, device.DirectionRX, rxChannel)
+			antenna = sdr.GetCurrentAntenna(sdrD, jsdrLogger, device.DirectionRX, rxChannel)
+			return nil
+		}); err != nil {
+			dialog.NewError(err, mainWin).Show()
+			return
 		}
+		sampleRates = rates
+		sampleRatesSelect.Options = make([]string, 0, len(sampleRates))
+		for _, rate := range sampleRates {
+			sampleRatesSelect.Options = append(sampleRatesSelect.Options, sdr.FormatSampleRate(rate))
+		}
+		sampleRatesSelect.Selected = sdr.FormatSampleRate(currentRate)
+		sampleRatesSelect.Refresh()
+		antennaSelect.Options = antennas
+		// Setting Selected directly, rather than calling SetSelected, does not call antennaChanged, which would wait
+		// for selMutex.
+		antennaSelect.Selected = antenna
 		antennaSelect.Refresh()
+		updateCorrections(dev)
+		updateDeviceSettings(dev)
+		startSensorPanel(dev)
+		startStreamStatus(dev)
+	}
+}
+
+// UnmakeDevice unmakes the device that was made for the selected SDR, if there is one and it has not already been
+// unmade because the SDR was removed.
+func UnmakeDevice(log *logger.Logger) {
+	// The device is unwatched first, so that the watcher does not unmake it too. If the watcher has already unmade
+	// it, the selection has been cleared.
+	unwatchSelectedDevice()
+	selMutex.Lock()
+	defer selMutex.Unlock()
+	if selDevice != nil {
+		stopStreamStatus()
+		stopSensorPanel()
+		stopDeviceController()
+		sdr.Unmake(selDevice, log)
+		selDevice, selSdr = nil, nil
 	}
 }
 
+// selectedDevice returns the device and the Sdr that were made for the selected SDR, or nils if no SDR is selected.
+func selectedDevice() (settingsDevice, *sdr.Sdr) {
+	selMutex.Lock()
+	defer selMutex.Unlock()
+	return selDevice, selSdr
+}
+
 func sampleRateChanged(rate string) {
-	jsdrLogger.Logf(logger.Debug, "Sample rate selected: %s\n", rate)
+	i := sampleRatesSelect.SelectedIndex()
+	if dev, _ := selectedDevice(); i < 0 || i >= len(sampleRates) || dev == nil {
+		return
+	}
+	jsdrLogger.Logf(logger.Debug, "Sample rate selected: %s (%.1f)\n", rate, sampleRates[i])
+	// The sample rate is set through the controller, which updates the sample rate that the stream is monitored
+	// against.
+	if err := controlSampleRate(sampleRates[i]); err != nil {
+		dialog.NewError(err, mainWin).Show()
+	}
+}
+
+// makePPMSpinner creates the frequency correction entry, with buttons that step the correction down and up by 1 PPM.
+func makePPMSpinner() fyne.CanvasObject {
+	ppmEntry = widget.NewEntry()
+	ppmEntry.OnSubmitted = ppmSubmitted
+	ppmDown = widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() { stepPPM(-1.) })
+	ppmUp = widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() { stepPPM(1.) })
+	return container.NewBorder(nil, nil, ppmDown, ppmUp, ppmEntry)
+}
+
+// selectedCorrections returns the selected device's front end corrections, or nil if no device is selected or the
+// device does not support front end corrections.
+func selectedCorrections() sdr.FrontendCorrections {
+	dev, _ := selectedDevice()
+	corrections, ok := dev.(sdr.FrontendCorrections)
+	if !ok {
+		return nil
+	}
+	return corrections
+}
+
+// updateCorrections shows the front end corrections for dev, and enables only those controls that dev supports. If
+// dev is not nil, updateCorrections must be called with selMutex held.
+func updateCorrections(dev settingsDevice) {
+	var hasDCOffsetMode, dcOffsetMode, hasFrequencyCorrection bool
+	var ppm float64
+	if corrections, ok := dev.(sdr.FrontendCorrections); ok {
+		var hasDC, dc, hasPPM bool
+		var value float64
+		// The readings are only used if the operation completed, because it may still be running if it timed out.
+		if controlWith(deviceController, func(_ sdr.ControlDevice) error {
+			hasDC = sdr.HasDCOffsetMode(corrections, jsdrLogger, device.DirectionRX, rxChannel)
+			if hasDC {
+				dc = sdr.GetDCOffsetMode(corrections, jsdrLogger, device.DirectionRX, rxChannel)
+			}
+			hasPPM = sdr.HasFrequencyCorrection(corrections, jsdrLogger, device.DirectionRX, rxChannel)
+			if hasPPM {
+				value = sdr.GetFrequencyCorrection(corrections, jsdrLogger, device.DirectionRX, rxChannel)
+			}
+			return nil
+		}) == nil {
+			hasDCOffsetMode, dcOffsetMode, hasFrequencyCorrection, ppm = hasDC, dc, hasPPM, value
+		}
+	}
+	autoDCCheck.Checked = dcOffsetMode
+	if hasDCOffsetMode {
+		autoDCCheck.Enable()
+	} else {
+		autoDCCheck.Disable()
+	}
+	autoDCCheck.Refresh()
+	if hasFrequencyCorrection {
+		ppmEntry.SetText(formatPPM(ppm))
+		ppmEntry.Enable()
+		ppmDown.Enable()
+		ppmUp.Enable()
+	} else {
+		ppmEntry.SetText("")
+		ppmEntry.Disable()
+		ppmDown.Disable()
+		ppmUp.Disable()
+	}
+}
+
+func autoDCChanged(automatic bool) {
+	jsdrLogger.Logf(logger.Debug, "Auto DC offset selected: %v\n", automatic)
+	corrections := selectedCorrections()
+	if corrections == nil {
+		return
+	}
+	if err := control(func(_ sdr.ControlDevice) error {
+		return sdr.SetDCOffsetMode(corrections, jsdrLogger, device.DirectionRX, rxChannel, automatic)
+	}); err != nil {
+		dialog.NewError(err, mainWin).Show()
+		var mode bool
+		if control(func(_ sdr.ControlDevice) error {
+			mode = sdr.GetDCOffsetMode(corrections, jsdrLogger, device.DirectionRX, rxChannel)
+			return nil
+		}) == nil {
+			// Setting Checked directly, rather than calling SetChecked, does not call autoDCChanged again.
+			autoDCCheck.Checked = mode
+			autoDCCheck.Refresh()
+		}
+	}
+}
+
+// stepPPM adds step to the frequency correction in the PPM entry and applies it.
+func stepPPM(step float64) {
+	ppm, err := strconv.ParseFloat(strings.TrimSpace(ppmEntry.Text), 64)
+	if err != nil {
+		ppm = 0.
+	}
+	ppmSubmitted(formatPPM(ppm + step))
+}
+
+// ppmSubmitted applies the frequency correction in text, then shows the correction that the device is using.
+func ppmSubmitted(text string) {
+	jsdrLogger.Logf(logger.Debug, "Frequency correction entered: %s\n", text)
+	corrections := selectedCorrections()
+	if corrections == nil {
+		return
+	}
+	ppm, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err == nil {
+		err = control(func(_ sdr.ControlDevice) error {
+			return sdr.SetFrequencyCorrection(corrections, jsdrLogger, device.DirectionRX, rxChannel, ppm)
+		})
+	}
+	if err != nil {
+		dialog.NewError(err, mainWin).Show()
+	}
+	if control(func(_ sdr.ControlDevice) error {
+		ppm = sdr.GetFrequencyCorrection(corrections, jsdrLogger, device.DirectionRX, rxChannel)
+		return nil
+	}) == nil {
+		ppmEntry.SetText(formatPPM(ppm))
+	}
+}
+
+// formatPPM formats a frequency correction for the PPM entry.
+func formatPPM(ppm float64) string {
+	return strconv.FormatFloat(ppm, 'f', -1, 64)
 }
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jimorc/jsdr/internal/logger"
+	"github.com/jimorc/jsdr/internal/sdr"
+
+	"github.com/pothosware/go-soapy-sdr/pkg/device"
+)
+
+// streamStatusInterval is the time between updates of the stream statistics in the status bar.
+const streamStatusInterval = time.Second
+
+// notStreaming is displayed in the status bar when no stream is being monitored.
+const notStreaming = "Not streaming"
+
+// streamStatusLabel displays the statistics of the stream that is being read from the selected SDR.
+var streamStatusLabel *widget.Label
+
+// streamReceiver reads the receive stream of the selected SDR, so that the health of the stream can be displayed.
+var streamReceiver *sdr.Receiver
+var stopStreamReceiver context.CancelFunc
+var stopStreamStatusPublisher context.CancelFunc
+var streamStatusDone <-chan struct{}
+
+// makeStatusBar creates the status bar that displays the health of the stream that is being read.
+func makeStatusBar() fyne.CanvasObject {
+	jsdrLogger.Log(logger.Debug, "Creating the status bar\n")
+	streamStatusLabel = widget.NewLabel(notStreaming)
+	streamStatusLabel.Truncation = fyne.TextTruncateEllipsis
+	return streamStatusLabel
+}
+
+// startStreamStatus starts a receiver that reads the receive stream of dev, the device that was made for the selected
+// SDR, and displays the statistics of the stream in the status bar until stopStreamStatus is called. The blocks that
+// are read are discarded. Nothing is displayed if dev does not have streams. It must be called with selMutex held.
+func startStreamStatus(dev settingsDevice) {
+	stopStreamStatus()
+	streams, ok := dev.(sdr.SampleStreams)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), controlTimeout)
+	defer cancel()
+	// The tagger holds the sample rate that the stream is monitored against, and is updated when it changes.
+	tagger, err := deviceController.StreamTagger(ctx, device.DirectionRX, rxChannel)
+	if err != nil {
+		jsdrLogger.Logf(logger.Error, "Cannot monitor the stream: %s\n", err.Error())
+		return
+	}
+	var source sdr.SampleSource
+	// The source is only used if the operation completed, because it may still be running if it timed out.
+	if err := controlWith(deviceController, func(_ sdr.ControlDevice) error {
+		var err error
+		source, err = sdr.NewSampleSource(streams, jsdrLogger, device.DirectionRX, []uint{rxChannel})
+		return err
+	}); err != nil {
+		jsdrLogger.Logf(logger.Error, "Cannot monitor the stream: %s\n", err.Error())
+		return
+	}
+	receiver := sdr.NewReceiver(source, jsdrLogger, 0.0, func(block sdr.Block) { block.Release() })
+	receiver.SetStreamTagger(tagger)
+	receiverCtx, stopReceiver := context.WithCancel(context.Background())
+	if err := receiver.Start(receiverCtx); err != nil {
+		stopReceiver()
+		return
+	}
+	streamReceiver, stopStreamReceiver = receiver, stopReceiver
+
+	publisherCtx, stopPublisher := context.WithCancel(context.Background())
+	stopStreamStatusPublisher = stopPublisher
+	streamStatusDone = receiver.Monitor().Publish(publisherCtx, streamStatusInterval, func(stats sdr.StreamStats) {
+		select {
+		case <-receiver.Done():
+			streamStatusLabel.SetText(notStreaming)
+		default:
+			streamStatsChanged(stats)
+		}
+	})
+}
+
+// stopStreamStatus stops displaying stream statistics in the status bar, and stops the receiver. Call
+// stopStreamStatus before unmaking the device. It must be called with selMutex held.
+func stopStreamStatus() {
+	if stopStreamStatusPublisher != nil {
+		stopStreamStatusPublisher()
+		<-streamStatusDone
+		stopStreamStatusPublisher, streamStatusDone = nil, nil
+	}
+	if streamReceiver != nil {
+		stopStreamReceiver()
+		if err := streamReceiver.Wait(); err != nil {
+			jsdrLogger.Logf(logger.Error, "The stream stopped: %s\n", err.Error())
+		}
+		streamReceiver, stopStreamReceiver = nil, nil
+	}
+	if streamStatusLabel != nil {
+		streamStatusLabel.SetText(notStreaming)
+	}
+}
+
+// streamStatsChanged displays stream statistics in the status bar, with a warning when the stream is not healthy. It
+// is called on the monitor's publishing go routine.
+func streamStatsChanged(stats sdr.StreamStats) {
+	if !stats.Healthy() {
+		streamStatusLabel.SetText("Samples lost: " + stats.String())
+		return
+	}
+	streamStatusLabel.SetText(stats.String())
+}